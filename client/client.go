@@ -23,6 +23,8 @@ type Client struct {
 	*DockMgr
 	*PoolMgr
 	*VolumeMgr
+	*EventMgr
+	*WatchMgr
 
 	cfg *Config
 }
@@ -44,6 +46,8 @@ func NewClient(c *Config) *Client {
 		DockMgr:    NewDockMgr(c.Endpoint),
 		PoolMgr:    NewPoolMgr(c.Endpoint),
 		VolumeMgr:  NewVolumeMgr(c.Endpoint),
+		EventMgr:   NewEventMgr(c.Endpoint),
+		WatchMgr:   NewWatchMgr(c.Endpoint),
 	}
 }
 
@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+func NewEventMgr(edp string) *EventMgr {
+	return &EventMgr{
+		Receiver: NewReceiver(),
+		Endpoint: edp,
+	}
+}
+
+type EventMgr struct {
+	Receiver
+
+	Endpoint string
+}
+
+// ListEvents lists the events which match the filter, the supported keys
+// are resourceType, resourceId, since and until.
+func (e *EventMgr) ListEvents(filter ParamOption) ([]*model.EventSpec, error) {
+	var res []*model.EventSpec
+	url := e.Endpoint + "/v1alpha/events"
+
+	if err := e.Recv(request, url, "GET", filter, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
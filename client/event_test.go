@@ -0,0 +1,105 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+func NewFakeEventReceiver() Receiver {
+	return &fakeEventReceiver{}
+}
+
+type fakeEventReceiver struct{}
+
+func (*fakeEventReceiver) Recv(
+	f reqFunc,
+	string,
+	method string,
+	in interface{},
+	out interface{},
+) error {
+	if strings.ToUpper(method) != "GET" {
+		return errors.New("method not supported!")
+	}
+	if _, ok := in.(ParamOption); !ok {
+		return errors.New("input format not supported!")
+	}
+
+	switch out.(type) {
+	case *[]*model.EventSpec:
+		if err := json.Unmarshal([]byte(sampleEvents), out); err != nil {
+			return err
+		}
+		break
+	default:
+		return errors.New("output format not supported!")
+	}
+
+	return nil
+}
+
+var fe = &EventMgr{
+	Receiver: NewFakeEventReceiver(),
+}
+
+func TestListEvents(t *testing.T) {
+	expected := []*model.EventSpec{
+		{
+			BaseModel: &model.BaseModel{
+				Id:        "fd1e3cf2-b7e3-11e7-a2b8-2f8b0d3bc3c7",
+				CreatedAt: "2017-10-24T16:21:32",
+			},
+			Actor:        "127.0.0.1",
+			Source:       "controller",
+			ResourceType: "volume",
+			ResourceId:   "bd5b12a8-a101-11e7-941e-d77981b584d8",
+			Action:       "create",
+			Outcome:      "Success",
+		},
+	}
+
+	evts, err := fe.ListEvents(ParamOption{"resourceType": "volume"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !reflect.DeepEqual(evts, expected) {
+		t.Errorf("Expected %v, got %v", expected, evts)
+		return
+	}
+}
+
+var (
+	sampleEvents = `[
+		{
+			"id": "fd1e3cf2-b7e3-11e7-a2b8-2f8b0d3bc3c7",
+			"createdAt": "2017-10-24T16:21:32",
+			"actor": "127.0.0.1",
+			"source": "controller",
+			"resourceType": "volume",
+			"resourceId": "bd5b12a8-a101-11e7-941e-d77981b584d8",
+			"action": "create",
+			"outcome": "Success"
+		}
+	]`
+)
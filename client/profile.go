@@ -67,11 +67,13 @@ func (p *ProfileMgr) GetProfile(prfID string) (*model.ProfileSpec, error) {
 	return &res, nil
 }
 
-func (p *ProfileMgr) ListProfiles() ([]*model.ProfileSpec, error) {
+// ListProfiles lists the profiles, the options of pagination, sorting and
+// filtering, such as limit, marker, sort_key and name, could be specified.
+func (p *ProfileMgr) ListProfiles(opts ...ParamOption) ([]*model.ProfileSpec, error) {
 	var res []*model.ProfileSpec
 	url := p.Endpoint + "/v1alpha/profiles"
 
-	if err := p.Recv(request, url, "GET", nil, &res); err != nil {
+	if err := p.Recv(request, url, "GET", mergeParamOptions(opts...), &res); err != nil {
 		fmt.Println(err)
 		return nil, err
 	}
@@ -114,3 +116,15 @@ func (p *ProfileMgr) RemoveExtraProperty(prfID, extraKey string) error {
 
 	return p.Recv(request, url, "DELETE", nil, nil)
 }
+
+func (p *ProfileMgr) GetExtraSchema() (*model.ExtraSchema, error) {
+	var res model.ExtraSchema
+	url := p.Endpoint + "/v1alpha/profiles/schema"
+
+	if err := p.Recv(request, url, "GET", nil, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -75,6 +75,12 @@ func (*fakeProfileReceiver) Recv(
 				return err
 			}
 			break
+		case *model.ExtraSchema:
+			body, _ := json.Marshal(model.ProfileExtraSchema)
+			if err := json.Unmarshal(body, out); err != nil {
+				return err
+			}
+			break
 		default:
 			return errors.New("output format not supported!")
 		}
@@ -224,6 +230,19 @@ func TestRemoveExtraProperty(t *testing.T) {
 	}
 }
 
+func TestGetExtraSchema(t *testing.T) {
+	schema, err := fpr.GetExtraSchema()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !reflect.DeepEqual(schema, model.ProfileExtraSchema) {
+		t.Errorf("Expected %v, got %v", model.ProfileExtraSchema, schema)
+		return
+	}
+}
+
 var (
 	sampleProfile = `{
 		"id": "1106b972-66ef-11e7-b172-db03f3689c9c",
@@ -20,8 +20,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/astaxie/beego/httplib"
+	"github.com/satori/go.uuid"
 )
 
 type reqFunc func(string, string, interface{}) *httplib.BeegoHTTPRequest
@@ -30,31 +32,83 @@ type Receiver interface {
 	Recv(reqFunc, string, string, interface{}, interface{}) error
 }
 
+const (
+	// idempotencyKeyHeader carries the key which identifies the create
+	// request across its retries.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	defaultRetries       = 3
+	defaultRetryInterval = 500 * time.Millisecond
+)
+
+// idempotentCreates are the paths of create requests whose idempotency keys
+// are honored by the server, so they could be retried safely.
+var idempotentCreates = []string{
+	"/v1alpha/block/volumes",
+	"/v1alpha/block/snapshots",
+	"/v1alpha/block/attachments",
+}
+
 func NewReceiver() Receiver {
-	return &receiver{}
+	return &receiver{
+		retries:  defaultRetries,
+		interval: defaultRetryInterval,
+	}
 }
 
-type receiver struct{}
+type receiver struct {
+	// The request is retried at most retries times if the server can't be
+	// reached or is unavailable, or the original create request is still in
+	// progress. The interval doubles after every retry.
+	retries  int
+	interval time.Duration
+}
 
-func (*receiver) Recv(
+func (r *receiver) Recv(
 	f reqFunc,
 	url string,
 	method string,
 	input interface{},
 	output interface{},
 ) error {
-	req := f(url, method, input)
+	// All retries of the create request share the same idempotency key, so
+	// that the resource is created only once.
+	retriable := isRetriable(url, method)
+	var key string
+	if retriable && strings.ToUpper(method) == "POST" {
+		key = uuid.NewV4().String()
+	}
 
 	// Get http response.
-	resp, err := req.Response()
+	var resp *http.Response
+	var err error
+	for i := 0; ; i++ {
+		req := f(url, method, input)
+		if key != "" {
+			req.Header(idempotencyKeyHeader, key)
+		}
+		resp, err = req.Response()
+		// The key is in use by the original request if a retry conflicts,
+		// which goes on after the client timed out. Its result is replayed
+		// once it's done.
+		inProgress := key != "" && i > 0
+		if !retriable || i >= r.retries || !shouldRetry(resp, err, inProgress) {
+			break
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		time.Sleep(r.interval << uint(i))
+	}
 	if err != nil {
 		return err
 	}
 	if err = checkHTTPResponseStatusCode(resp); err != nil {
 		return err
 	}
-	// If the method is DELETE, consider it successfully deleted.
-	if strings.ToUpper(method) == "DELETE" {
+	// If the method is DELETE or no output is expected, consider it
+	// successfully done.
+	if strings.ToUpper(method) == "DELETE" || output == nil {
 		return nil
 	}
 
@@ -69,8 +123,56 @@ func (*receiver) Recv(
 	return nil
 }
 
+// isRetriable checks whether the request could be sent again without being
+// done twice. The other POST and PUT requests are never retried, nor are the
+// DELETE requests since a retry fails if the resource has been deleted.
+func isRetriable(url, method string) bool {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return true
+	case "POST":
+		for _, path := range idempotentCreates {
+			if strings.HasSuffix(url, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shouldRetry checks whether the request failed for a transient reason, such
+// as network error or the server being unavailable for a while. A conflict is
+// transient if the original request is in progress.
+func shouldRetry(resp *http.Response, err error, inProgress bool) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case 502, 503, 504:
+		return true
+	case 409:
+		return inProgress
+	}
+	return false
+}
+
 type ParamOption map[string]string
 
+// mergeParamOptions merges the options into one, it returns nil if no option
+// is specified so that no parameter is sent.
+func mergeParamOptions(opts ...ParamOption) interface{} {
+	if len(opts) == 0 {
+		return nil
+	}
+	var merged = ParamOption{}
+	for _, opt := range opts {
+		for k, v := range opt {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
 func request(
 	url string,
 	method string,
@@ -13,3 +13,135 @@
 //    under the License.
 
 package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+func TestMergeParamOptions(t *testing.T) {
+	if opt := mergeParamOptions(); opt != nil {
+		t.Errorf("Expected nil, got %v", opt)
+	}
+
+	expected := ParamOption{"limit": "10", "status": "available", "sort_key": "size"}
+	opt := mergeParamOptions(
+		ParamOption{"limit": "5", "status": "available"},
+		ParamOption{"limit": "10", "sort_key": "size"},
+	)
+	if !reflect.DeepEqual(opt, expected) {
+		t.Errorf("Expected %v, got %v", expected, opt)
+	}
+}
+
+func TestRecvRetryWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		// The server is unavailable for the first two attempts.
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}`))
+	}))
+	defer srv.Close()
+
+	var vol model.VolumeSpec
+	r := &receiver{retries: defaultRetries}
+	if err := r.Recv(request, srv.URL+"/v1alpha/block/volumes", "POST", &model.VolumeSpec{Name: "sample-volume"}, &vol); err != nil {
+		t.Fatal(err)
+	}
+	if vol.Id != "bd5b12a8-a101-11e7-941e-d77981b584d8" {
+		t.Errorf("Expected volume created, got %v", vol)
+	}
+	if len(keys) != 3 || keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Errorf("Expected all retries carry the same key, got %v", keys)
+	}
+}
+
+func TestRecvNoRetryOnBadRequest(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	r := &receiver{retries: defaultRetries}
+	if err := r.Recv(request, srv.URL, "GET", nil, &model.VolumeSpec{}); err == nil {
+		t.Error("Expected bad request failed")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected no retry, got %d attempts", attempts)
+	}
+}
+
+func TestRecvNoRetryOnNonIdempotentPost(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// The server doesn't honor idempotency key of the request, so it could be
+	// done twice if retried.
+	r := &receiver{retries: defaultRetries}
+	if err := r.Recv(request, srv.URL+"/v1alpha/block/volumes/manage", "POST", &model.VolumeManageSpec{}, &model.VolumeSpec{}); err == nil {
+		t.Error("Expected unavailable server failed")
+	}
+	if len(keys) != 1 || keys[0] != "" {
+		t.Errorf("Expected no retry nor idempotency key, got %v", keys)
+	}
+}
+
+func TestRecvRetryInProgress(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			// The client times out while the volume is being created.
+			w.WriteHeader(http.StatusGatewayTimeout)
+		case 2:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var vol model.VolumeSpec
+	r := &receiver{retries: defaultRetries}
+	if err := r.Recv(request, srv.URL+"/v1alpha/block/volumes", "POST", &model.VolumeSpec{Name: "sample-volume"}, &vol); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 || vol.Id != "bd5b12a8-a101-11e7-941e-d77981b584d8" {
+		t.Errorf("Expected volume created after %d attempts, got %v", attempts, vol)
+	}
+}
+
+func TestRecvNoRetryOnDelete(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer srv.Close()
+
+	// The volume may have been deleted by the request, so a retry fails.
+	r := &receiver{retries: defaultRetries}
+	if err := r.Recv(request, srv.URL+"/v1alpha/block/volumes/bd5b12a8-a101-11e7-941e-d77981b584d8", "DELETE", nil, nil); err == nil {
+		t.Error("Expected timed out request failed")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected no retry, got %d attempts", attempts)
+	}
+}
@@ -35,6 +35,11 @@ type VolumeAttachmentBuilder *model.VolumeAttachmentSpec
 // struct, but it could be discussed if it's better to define an interface.
 type VolumeSnapshotBuilder *model.VolumeSnapshotSpec
 
+// VolumeTransferBuilder contains request body of handling a volume transfer
+// request. Currently it's assigned as the pointer of VolumeTransferSpec
+// struct, but it could be discussed if it's better to define an interface.
+type VolumeTransferBuilder *model.VolumeTransferSpec
+
 func NewVolumeMgr(edp string) *VolumeMgr {
 	return &VolumeMgr{
 		Receiver: NewReceiver(),
@@ -72,11 +77,13 @@ func (v *VolumeMgr) GetVolume(volID string) (*model.VolumeSpec, error) {
 	return &res, nil
 }
 
-func (v *VolumeMgr) ListVolumes() ([]*model.VolumeSpec, error) {
+// ListVolumes lists the volumes, the options of pagination, sorting and
+// filtering, such as limit, marker, sort_key and status, could be specified.
+func (v *VolumeMgr) ListVolumes(opts ...ParamOption) ([]*model.VolumeSpec, error) {
 	var res []*model.VolumeSpec
 	url := v.Endpoint + "/v1alpha/block/volumes"
 
-	if err := v.Recv(request, url, "GET", nil, &res); err != nil {
+	if err := v.Recv(request, url, "GET", mergeParamOptions(opts...), &res); err != nil {
 		fmt.Println(err)
 		return nil, err
 	}
@@ -90,6 +97,28 @@ func (v *VolumeMgr) DeleteVolume(volID string, body VolumeBuilder) error {
 	return v.Recv(request, url, "DELETE", body, nil)
 }
 
+// ManageVolume brings an existing volume of the backend under the management
+// of opensds.
+func (v *VolumeMgr) ManageVolume(body *model.VolumeManageSpec) (*model.VolumeSpec, error) {
+	var res model.VolumeSpec
+	url := v.Endpoint + "/v1alpha/block/volumes/manage"
+
+	if err := v.Recv(request, url, "POST", body, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// UnmanageVolume removes the volume from opensds without deleting its data
+// in the backend.
+func (v *VolumeMgr) UnmanageVolume(volID string) error {
+	url := v.Endpoint + "/v1alpha/block/volumes/" + volID + "/unmanage"
+
+	return v.Recv(request, url, "POST", nil, nil)
+}
+
 func (v *VolumeMgr) CreateVolumeAttachment(body VolumeAttachmentBuilder) (*model.VolumeAttachmentSpec, error) {
 	var res model.VolumeAttachmentSpec
 	url := v.Endpoint + "/v1alpha/block/attachments"
@@ -102,9 +131,14 @@ func (v *VolumeMgr) CreateVolumeAttachment(body VolumeAttachmentBuilder) (*model
 	return &res, nil
 }
 
-func (v *VolumeMgr) GetVolumeAttachment(atcID string) (*model.VolumeAttachmentSpec, error) {
+// GetVolumeAttachment shows the attachment of the specified volume, the
+// attachment will be looked up across all volumes if volID is empty.
+func (v *VolumeMgr) GetVolumeAttachment(volID, atcID string) (*model.VolumeAttachmentSpec, error) {
 	var res model.VolumeAttachmentSpec
 	url := v.Endpoint + "/v1alpha/block/attachments/" + atcID
+	if volID != "" {
+		url = v.Endpoint + "/v1alpha/block/volumes/" + volID + "/attachments/" + atcID
+	}
 
 	if err := v.Recv(request, url, "GET", nil, &res); err != nil {
 		fmt.Println(err)
@@ -114,11 +148,13 @@ func (v *VolumeMgr) GetVolumeAttachment(atcID string) (*model.VolumeAttachmentSp
 	return &res, nil
 }
 
-func (v *VolumeMgr) ListVolumeAttachments() ([]*model.VolumeAttachmentSpec, error) {
+// ListVolumeAttachments lists the volume attachments, the options of pagination, sorting and
+// filtering, such as limit, marker, sort_key, status and volumeId, could be specified.
+func (v *VolumeMgr) ListVolumeAttachments(opts ...ParamOption) ([]*model.VolumeAttachmentSpec, error) {
 	var res []*model.VolumeAttachmentSpec
 	url := v.Endpoint + "/v1alpha/block/attachments"
 
-	if err := v.Recv(request, url, "GET", nil, &res); err != nil {
+	if err := v.Recv(request, url, "GET", mergeParamOptions(opts...), &res); err != nil {
 		fmt.Println(err)
 		return nil, err
 	}
@@ -126,6 +162,20 @@ func (v *VolumeMgr) ListVolumeAttachments() ([]*model.VolumeAttachmentSpec, erro
 	return res, nil
 }
 
+// UpdateVolumeAttachment reports the mountpoint and host info of the
+// attachment, the volume will be re-exported if the initiator is changed.
+func (v *VolumeMgr) UpdateVolumeAttachment(atcID string, body VolumeAttachmentBuilder) (*model.VolumeAttachmentSpec, error) {
+	var res model.VolumeAttachmentSpec
+	url := v.Endpoint + "/v1alpha/block/attachments/" + atcID
+
+	if err := v.Recv(request, url, "PUT", body, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
 func (v *VolumeMgr) DeleteVolumeAttachment(atcID string, body VolumeAttachmentBuilder) error {
 	url := v.Endpoint + "/v1alpha/block/attachments/" + atcID
 
@@ -156,11 +206,13 @@ func (v *VolumeMgr) GetVolumeSnapshot(snpID string) (*model.VolumeSnapshotSpec,
 	return &res, nil
 }
 
-func (v *VolumeMgr) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+// ListVolumeSnapshots lists the volume snapshots, the options of pagination, sorting and
+// filtering, such as limit, marker, sort_key and status, could be specified.
+func (v *VolumeMgr) ListVolumeSnapshots(opts ...ParamOption) ([]*model.VolumeSnapshotSpec, error) {
 	var res []*model.VolumeSnapshotSpec
 	url := v.Endpoint + "/v1alpha/block/snapshots"
 
-	if err := v.Recv(request, url, "GET", nil, &res); err != nil {
+	if err := v.Recv(request, url, "GET", mergeParamOptions(opts...), &res); err != nil {
 		fmt.Println(err)
 		return nil, err
 	}
@@ -173,3 +225,83 @@ func (v *VolumeMgr) DeleteVolumeSnapshot(snpID string, body VolumeSnapshotBuilde
 
 	return v.Recv(request, url, "DELETE", body, nil)
 }
+
+// CreateVolumeTransfer creates a transfer of the volume, the auth key in the
+// result is only returned once and should be sent to the target project.
+// ManageVolumeSnapshot brings an existing snapshot of the backend under the
+// management of opensds.
+func (v *VolumeMgr) ManageVolumeSnapshot(body *model.VolumeSnapshotManageSpec) (*model.VolumeSnapshotSpec, error) {
+	var res model.VolumeSnapshotSpec
+	url := v.Endpoint + "/v1alpha/block/snapshots/manage"
+
+	if err := v.Recv(request, url, "POST", body, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// UnmanageVolumeSnapshot removes the snapshot from opensds without deleting
+// its data in the backend.
+func (v *VolumeMgr) UnmanageVolumeSnapshot(snpID string) error {
+	url := v.Endpoint + "/v1alpha/block/snapshots/" + snpID + "/unmanage"
+
+	return v.Recv(request, url, "POST", nil, nil)
+}
+
+func (v *VolumeMgr) CreateVolumeTransfer(body VolumeTransferBuilder) (*model.VolumeTransferSpec, error) {
+	var res model.VolumeTransferSpec
+	url := v.Endpoint + "/v1alpha/block/transfers"
+
+	if err := v.Recv(request, url, "POST", body, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (v *VolumeMgr) GetVolumeTransfer(trID string) (*model.VolumeTransferSpec, error) {
+	var res model.VolumeTransferSpec
+	url := v.Endpoint + "/v1alpha/block/transfers/" + trID
+
+	if err := v.Recv(request, url, "GET", nil, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (v *VolumeMgr) ListVolumeTransfers(opts ...ParamOption) ([]*model.VolumeTransferSpec, error) {
+	var res []*model.VolumeTransferSpec
+	url := v.Endpoint + "/v1alpha/block/transfers"
+
+	if err := v.Recv(request, url, "GET", mergeParamOptions(opts...), &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// AcceptVolumeTransfer accepts the transfer on behalf of the target project,
+// the volume handed to the project is returned.
+func (v *VolumeMgr) AcceptVolumeTransfer(trID string, body *model.VolumeTransferAcceptSpec) (*model.VolumeSpec, error) {
+	var res model.VolumeSpec
+	url := v.Endpoint + "/v1alpha/block/transfers/" + trID + "/accept"
+
+	if err := v.Recv(request, url, "POST", body, &res); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (v *VolumeMgr) DeleteVolumeTransfer(trID string, body VolumeTransferBuilder) error {
+	url := v.Endpoint + "/v1alpha/block/transfers/" + trID
+
+	return v.Recv(request, url, "DELETE", body, nil)
+}
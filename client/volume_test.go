@@ -59,6 +59,13 @@ func (*fakeVolumeReceiver) Recv(
 				return err
 			}
 			break
+		case *model.VolumeTransferSpec:
+			if err := json.Unmarshal([]byte(sampleTransfer), out); err != nil {
+				return err
+			}
+			break
+		case nil:
+			break
 		default:
 			return errors.New("output format not supported!")
 		}
@@ -99,6 +106,17 @@ func (*fakeVolumeReceiver) Recv(
 			return errors.New("output format not supported!")
 		}
 		break
+	case "PUT":
+		switch out.(type) {
+		case *model.VolumeAttachmentSpec:
+			if err := json.Unmarshal([]byte(sampleAttachment), out); err != nil {
+				return err
+			}
+			break
+		default:
+			return errors.New("output format not supported!")
+		}
+		break
 	case "DELETE":
 		break
 	default:
@@ -254,7 +272,7 @@ func TestGetVolumeAttachment(t *testing.T) {
 		},
 	}
 
-	atc, err := fv.GetVolumeAttachment(atcID)
+	atc, err := fv.GetVolumeAttachment("bd5b12a8-a101-11e7-941e-d77981b584d8", atcID)
 	if err != nil {
 		t.Error(err)
 		return
@@ -301,6 +319,23 @@ func TestListVolumeAttachments(t *testing.T) {
 	}
 }
 
+func TestUpdateVolumeAttachment(t *testing.T) {
+	var atcID = "f2dda3d2-bf79-11e7-8665-f750b088f63e"
+
+	atc, err := fv.UpdateVolumeAttachment(atcID, &model.VolumeAttachmentSpec{
+		Mountpoint: "/mnt/opensds",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if atc.GetId() != atcID {
+		t.Errorf("Expected %v, got %v", atcID, atc.GetId())
+		return
+	}
+}
+
 func TestDeleteVolumeAttachment(t *testing.T) {
 	var atcID = "f2dda3d2-bf79-11e7-8665-f750b088f63e"
 
@@ -410,6 +445,100 @@ func TestDeleteVolumeSnapshot(t *testing.T) {
 	}
 }
 
+func TestManageVolume(t *testing.T) {
+	expected := &model.VolumeSpec{
+		BaseModel: &model.BaseModel{
+			Id: "bd5b12a8-a101-11e7-941e-d77981b584d8",
+		},
+		Name:        "sample-volume",
+		Description: "This is a sample volume for testing",
+		Size:        int64(1),
+		Status:      "available",
+		PoolId:      "084bf71e-a102-11e7-88a8-e31fe6d52248",
+		ProfileId:   "1106b972-66ef-11e7-b172-db03f3689c9c",
+	}
+
+	vol, err := fv.ManageVolume(&model.VolumeManageSpec{
+		Identifier: "volume-bd5b12a8",
+		PoolId:     "084bf71e-a102-11e7-88a8-e31fe6d52248",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !reflect.DeepEqual(vol, expected) {
+		t.Errorf("Expected %v, got %v", expected, vol)
+		return
+	}
+}
+
+func TestUnmanageVolume(t *testing.T) {
+	if err := fv.UnmanageVolume("bd5b12a8-a101-11e7-941e-d77981b584d8"); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestManageVolumeSnapshot(t *testing.T) {
+	snp, err := fv.ManageVolumeSnapshot(&model.VolumeSnapshotManageSpec{
+		Identifier: "_snapshot-3769855c",
+		VolumeId:   "bd5b12a8-a101-11e7-941e-d77981b584d8",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if snp.GetVolumeId() != "bd5b12a8-a101-11e7-941e-d77981b584d8" {
+		t.Errorf("Expected snapshot of volume bd5b12a8-a101-11e7-941e-d77981b584d8, got %v", snp)
+		return
+	}
+}
+
+func TestUnmanageVolumeSnapshot(t *testing.T) {
+	if err := fv.UnmanageVolumeSnapshot("3769855c-a102-11e7-b772-17b880d2f537"); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestCreateVolumeTransfer(t *testing.T) {
+	expected := &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{
+			Id: "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e",
+		},
+		Name:      "sample-transfer",
+		VolumeId:  "bd5b12a8-a101-11e7-941e-d77981b584d8",
+		ProjectId: "a7b1b6c6-bc3f-11e7-9a4e-2b8b6b0a1b3c",
+		AuthKey:   "3b4a0c5d9e8f7a61",
+	}
+
+	tr, err := fv.CreateVolumeTransfer(&model.VolumeTransferSpec{
+		VolumeId: "bd5b12a8-a101-11e7-941e-d77981b584d8",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !reflect.DeepEqual(tr, expected) {
+		t.Errorf("Expected %v, got %v", expected, tr)
+		return
+	}
+}
+
+func TestDeleteVolumeTransfer(t *testing.T) {
+	var trID = "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e"
+
+	if err := fv.DeleteVolumeTransfer(trID, &model.VolumeTransferSpec{
+		ProjectId: "a7b1b6c6-bc3f-11e7-9a4e-2b8b6b0a1b3c",
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
 var (
 	sampleVolume = `{
 		"id": "bd5b12a8-a101-11e7-941e-d77981b584d8",
@@ -498,4 +627,12 @@ var (
 			"volumeId": "bd5b12a8-a101-11e7-941e-d77981b584d8"	
 		}
 	]`
+
+	sampleTransfer = `{
+		"id": "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e",
+		"name": "sample-transfer",
+		"volumeId": "bd5b12a8-a101-11e7-941e-d77981b584d8",
+		"projectId": "a7b1b6c6-bc3f-11e7-9a4e-2b8b6b0a1b3c",
+		"authKey": "3b4a0c5d9e8f7a61"
+	}`
 )
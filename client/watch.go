@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// The max size of one line in the event stream.
+const maxWatchLineSize = 1024 * 1024
+
+func NewWatchMgr(edp string) *WatchMgr {
+	return &WatchMgr{
+		Endpoint: edp,
+	}
+}
+
+type WatchMgr struct {
+	Endpoint string
+}
+
+// Watch pushes the creation, update and deletion of the resource, which is
+// one of model.WatchResources, until stop is closed or the stream is broken.
+// The returned channel is closed when the watch ends.
+func (w *WatchMgr) Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error) {
+	u := w.Endpoint + "/v1alpha/watch?" + url.Values{"resource": {resource}}.Encode()
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Cancel = stop
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		rbody, _ := ioutil.ReadAll(resp.Body)
+		return nil, errors.New(string(rbody))
+	}
+
+	events := make(chan *model.WatchEventSpec)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		// Every event ends with an empty line, and only the data field is
+		// used since it carries the type of the event as well.
+		var data []byte
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 4096), maxWatchLineSize)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if bytes.HasPrefix(line, []byte("data:")) {
+				data = append(data, bytes.TrimSpace(line[len("data:"):])...)
+				continue
+			}
+			if len(line) != 0 || len(data) == 0 {
+				continue
+			}
+
+			var evt = &model.WatchEventSpec{}
+			err := json.Unmarshal(data, evt)
+			data = nil
+			if err != nil {
+				fmt.Println("Parse watch event failed:", err)
+				continue
+			}
+			select {
+			case events <- evt:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+func TestWatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1alpha/watch" || r.URL.Query().Get("resource") != "volumes" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: created\n"+
+			`data: {"type":"created","resource":"volumes","object":{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}}`+"\n\n"+
+			"event: deleted\n"+
+			`data: {"type":"deleted","resource":"volumes","object":{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}}`+"\n\n")
+	}))
+	defer srv.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	events, err := NewWatchMgr(srv.URL).Watch(model.WatchResourceVolumes, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for evt := range events {
+		if string(evt.Object) != `{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}` {
+			t.Errorf("Unexpected object of event: %s", evt.Object)
+		}
+		types = append(types, evt.Type)
+	}
+	if len(types) != 2 || types[0] != model.WatchEventCreated || types[1] != model.WatchEventDeleted {
+		t.Errorf("Expected created and deleted events, got %v", types)
+	}
+
+	if _, err := NewWatchMgr(srv.URL).Watch("pools", stop); err == nil {
+		t.Error("Expected watch pools failed")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the all-in-one OpenSDS service, which
+runs the controller and the dock in one process so that the whole stack runs
+on a single node. The dock listens on a local grpc endpoint which the
+controller talks to, and the memory database could be used in place of etcd.
+
+*/
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/golang/glog"
+
+	"github.com/opensds/opensds/pkg/api"
+	c "github.com/opensds/opensds/pkg/controller"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/db/drivers/memory"
+	"github.com/opensds/opensds/pkg/db/migration"
+	"github.com/opensds/opensds/pkg/dock"
+	app "github.com/opensds/opensds/pkg/dock/discovery"
+	dockServer "github.com/opensds/opensds/pkg/dock/server"
+	. "github.com/opensds/opensds/pkg/utils/config"
+	"github.com/opensds/opensds/pkg/utils/logs"
+)
+
+func init() {
+	def := GetDefaultConfig()
+	flag := &CONF.Flag
+	flag.StringVar(&CONF.OsdsLet.ApiEndpoint, "api-endpoint", def.OsdsLet.ApiEndpoint, "Listen endpoint of controller service")
+	flag.StringVar(&CONF.OsdsDock.ApiEndpoint, "dock-endpoint", def.OsdsDock.ApiEndpoint, "Listen endpoint of dock service")
+	flag.StringVar(&CONF.OsdsDock.MetricsEndpoint, "metrics-endpoint", def.OsdsDock.MetricsEndpoint, "Listen endpoint of metrics service")
+	flag.StringVar(&CONF.Database.Endpoint, "db-endpoint", def.Database.Endpoint, "Connection endpoint of database service, or the data file of memory database which defaults to "+memory.DefaultDataFile)
+	flag.StringVar(&CONF.Database.Driver, "db-driver", def.Database.Driver, "Driver name of database service")
+	flag.StringVar(&CONF.Database.Credential, "db-credential", def.Database.Credential, "Connection credential of database service")
+	CONF.Load("/etc/opensds/opensds.conf")
+}
+
+func main() {
+	// Open OpenSDS all-in-one service log file.
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	// Set up database session.
+	db.Init(&CONF.Database)
+
+	// Warn if the database hasn't been migrated to the schema of this release.
+	if store, ok := db.C.(migration.Store); ok {
+		if err := migration.CheckVersion(store); err != nil {
+			log.Warning(err)
+		}
+	}
+
+	// Set up the drivers of enabled backends, which are kept until shutdown.
+	if err := dock.SetupBackends(CONF.Backends); err != nil {
+		panic(err)
+	}
+
+	// Automatically discover dock and pool resources from backends.
+	if err := app.Discovery(app.NewDiscover()); err != nil {
+		panic(err)
+	}
+	if CONF.OsdsDock.ReconcileInterval > 0 {
+		dock.StartReconcilers(&CONF.OsdsDock)
+	}
+	dockServer.ServeMetrics(CONF.OsdsDock.MetricsEndpoint)
+
+	// Start the dock on its local endpoint, which is recorded in the docks
+	// discovered above, so the controller reaches it as a remote one.
+	go dockServer.ListenAndServe(dockServer.NewDockServer(CONF.OsdsDock.ApiEndpoint))
+
+	// Initialize Controller object.
+	c.Brain = c.NewController()
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		c.NewOsdsletRunner(&CONF.OsdsLet).Run(stop)
+		close(done)
+	}()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		close(stop)
+		<-done
+		dock.UnsetBackends()
+		logs.FlushLogs()
+		os.Exit(0)
+	}()
+
+	// Start OpenSDS northbound REST service.
+	api.Run(CONF.OsdsLet.ApiEndpoint)
+}
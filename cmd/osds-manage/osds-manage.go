@@ -0,0 +1,188 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the management tool of OpenSDS, which maintains the
+data in database such as migrating it between schema versions and backing
+it up.
+
+*/
+
+package main
+
+import (
+	gflag "flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/db/backup"
+	"github.com/opensds/opensds/pkg/db/migration"
+	. "github.com/opensds/opensds/pkg/utils/config"
+)
+
+const usage = `Usage: osds-manage [flags] db <command> [options]
+
+Commands:
+  db version                       Show the schema version of the database
+  db sync [-dry-run] [-version N]  Migrate the database up to the version, the latest by default
+  db export [-o FILE] [-format F]  Export all resources into a json or yaml bundle, stdout by default
+  db import -f FILE [-format F]    Import all resources of the bundle into the database
+`
+
+func init() {
+	def := GetDefaultConfig()
+	flag := &CONF.Flag
+	flag.StringVar(&CONF.Database.Endpoint, "db-endpoint", def.Database.Endpoint, "Connection endpoint of database service")
+	flag.StringVar(&CONF.Database.Driver, "db-driver", def.Database.Driver, "Driver name of database service")
+	flag.StringVar(&CONF.Database.Credential, "db-credential", def.Database.Credential, "Connection credential of database service")
+	CONF.Load("/etc/opensds/opensds.conf")
+}
+
+func main() {
+	args := gflag.Args()
+	if len(args) < 2 || args[0] != "db" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Set up database session.
+	db.Init(&CONF.Database)
+
+	switch args[1] {
+	case "version":
+		dbVersion(migrationStore())
+	case "sync":
+		dbSync(migrationStore(), args[2:])
+	case "export":
+		dbExport(args[2:])
+	case "import":
+		dbImport(args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func migrationStore() migration.Store {
+	store, ok := db.C.(migration.Store)
+	if !ok {
+		fatalf("The %s database doesn't support migration\n", CONF.Database.Driver)
+	}
+	return store
+}
+
+func dbVersion(store migration.Store) {
+	current, err := store.GetSchemaVersion()
+	if err != nil {
+		fatalf("Get schema version failed: %v\n", err)
+	}
+	fmt.Printf("Current version: %d\nLatest version: %d\n", current, migration.LatestVersion())
+}
+
+func dbSync(store migration.Store, args []string) {
+	fs := gflag.NewFlagSet("db sync", gflag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Show the changes without writing them")
+	version := fs.Int("version", 0, "Schema version to migrate to, the latest by default")
+	fs.Parse(args)
+
+	report, err := migration.Sync(store, *version, *dryRun)
+	if report != nil {
+		printReport(report)
+	}
+	if err != nil {
+		fatalf("%v\n", err)
+	}
+}
+
+func printReport(r *migration.Report) {
+	if len(r.Steps) == 0 {
+		fmt.Printf("Database is already at version %d\n", r.From)
+		return
+	}
+	for _, s := range r.Steps {
+		fmt.Printf("Migration %d: %s\n", s.Version, s.Description)
+		for _, c := range s.Changes {
+			fmt.Printf("  %-6s %s\n", c.Op, c.Key)
+		}
+	}
+	if r.DryRun {
+		fmt.Printf("Dry run, database would be migrated from version %d to %d\n", r.From, r.To)
+		return
+	}
+	fmt.Printf("Database is migrated from version %d to %d\n", r.From, r.Steps[len(r.Steps)-1].Version)
+}
+
+func dbExport(args []string) {
+	fs := gflag.NewFlagSet("db export", gflag.ExitOnError)
+	output := fs.String("o", "", "File to write the bundle to, stdout by default")
+	format := fs.String("format", "", "Format of the bundle, json or yaml, guessed by the file extension by default")
+	fs.Parse(args)
+
+	b, err := backup.Export(db.C)
+	if err != nil {
+		fatalf("%v\n", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fatalf("%v\n", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "" {
+		*format = backup.FormatOf(*output)
+	}
+	if err = backup.Encode(w, b, *format); err != nil {
+		fatalf("Write bundle failed: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %s\n", b.Summary())
+}
+
+func dbImport(args []string) {
+	fs := gflag.NewFlagSet("db import", gflag.ExitOnError)
+	input := fs.String("f", "", "File to read the bundle from")
+	format := fs.String("format", "", "Format of the bundle, json or yaml, guessed by the file extension by default")
+	fs.Parse(args)
+	if *input == "" {
+		fatalf("The bundle file must be given by -f\n")
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		fatalf("%v\n", err)
+	}
+	defer f.Close()
+	if *format == "" {
+		*format = backup.FormatOf(*input)
+	}
+	b, err := backup.Decode(f, *format)
+	if err != nil {
+		fatalf("Read bundle failed: %v\n", err)
+	}
+
+	if err = backup.Import(db.C, b); err != nil {
+		fatalf("%v\n", err)
+	}
+	fmt.Printf("Imported %s\n", b.Summary())
+}
+
+func fatalf(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, a...)
+	os.Exit(1)
+}
@@ -20,7 +20,12 @@ This module implements a entry into the OpenSDS REST service.
 package main
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/dock"
 	app "github.com/opensds/opensds/pkg/dock/discovery"
 	dockServer "github.com/opensds/opensds/pkg/dock/server"
 	. "github.com/opensds/opensds/pkg/utils/config"
@@ -29,8 +34,9 @@ import (
 
 func init() {
 	def := GetDefaultConfig()
-	flag := CONF.Flag
+	flag := &CONF.Flag
 	flag.StringVar(&CONF.OsdsDock.ApiEndpoint, "api-endpoint", def.OsdsDock.ApiEndpoint, "Listen endpoint of controller service")
+	flag.StringVar(&CONF.OsdsDock.MetricsEndpoint, "metrics-endpoint", def.OsdsDock.MetricsEndpoint, "Listen endpoint of metrics service")
 	flag.StringVar(&CONF.Database.Endpoint, "db-endpoint", def.Database.Endpoint, "Connection endpoint of database service")
 	flag.StringVar(&CONF.Database.Driver, "db-driver", def.Database.Driver, "Driver name of database service")
 	flag.StringVar(&CONF.Database.Credential, "db-credential", def.Database.Credential, "Connection credential of database service")
@@ -45,11 +51,33 @@ func main() {
 	// Set up database session.
 	db.Init(&CONF.Database)
 
+	// Set up the drivers of enabled backends, which are kept until shutdown.
+	if err := dock.SetupBackends(CONF.Backends); err != nil {
+		panic(err)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		dock.UnsetBackends()
+		logs.FlushLogs()
+		os.Exit(0)
+	}()
+
 	// Automatically discover dock and pool resources from backends.
 	if err := app.Discovery(app.NewDiscover()); err != nil {
 		panic(err)
 	}
 
+	// Periodically reconcile the volumes and snapshots recorded in database
+	// with the ones existing in backends of this dock.
+	if CONF.OsdsDock.ReconcileInterval > 0 {
+		dock.StartReconcilers(&CONF.OsdsDock)
+	}
+
+	// Expose the metrics of dock module for prometheus to scrape.
+	dockServer.ServeMetrics(CONF.OsdsDock.MetricsEndpoint)
+
 	// Construct dock module grpc server struct and do some initialization.
 	ds := dockServer.NewDockServer(CONF.OsdsDock.ApiEndpoint)
 	// Start the listen mechanism of dock module.
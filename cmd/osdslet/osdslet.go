@@ -20,16 +20,23 @@ This module implements a entry into the OpenSDS REST service.
 package main
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/golang/glog"
+
 	"github.com/opensds/opensds/pkg/api"
 	c "github.com/opensds/opensds/pkg/controller"
 	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/db/migration"
 	. "github.com/opensds/opensds/pkg/utils/config"
 	"github.com/opensds/opensds/pkg/utils/logs"
 )
 
 func init() {
 	def := GetDefaultConfig()
-	flag := CONF.Flag
+	flag := &CONF.Flag
 	flag.StringVar(&CONF.OsdsLet.ApiEndpoint, "api-endpoint", def.OsdsLet.ApiEndpoint, "Listen endpoint of controller service")
 	flag.StringVar(&CONF.Database.Endpoint, "db-endpoint", def.Database.Endpoint, "Connection endpoint of database service")
 	flag.StringVar(&CONF.Database.Driver, "db-driver", def.Database.Driver, "Driver name of database service")
@@ -45,9 +52,33 @@ func main() {
 	// Set up database session.
 	db.Init(&CONF.Database)
 
+	// Warn if the database hasn't been migrated to the schema of this release.
+	if store, ok := db.C.(migration.Store); ok {
+		if err := migration.CheckVersion(store); err != nil {
+			log.Warning(err)
+		}
+	}
+
 	// Initialize Controller object.
 	c.Brain = c.NewController()
 
+	// Run the background jobs on the leader of osdslet replicas, and hand
+	// over the leadership before exiting.
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		c.NewOsdsletRunner(&CONF.OsdsLet).Run(stop)
+		close(done)
+	}()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		close(stop)
+		<-done
+		logs.FlushLogs()
+		os.Exit(0)
+	}()
+
 	// Start OpenSDS northbound REST service.
 	api.Run(CONF.OsdsLet.ApiEndpoint)
 }
@@ -31,10 +31,12 @@ import (
 	"github.com/ceph/go-ceph/rados"
 	"github.com/ceph/go-ceph/rbd"
 	log "github.com/golang/glog"
+	osdsctx "github.com/opensds/opensds/pkg/context"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
 	"github.com/opensds/opensds/pkg/utils/config"
 	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 	"gopkg.in/yaml.v2"
 )
 
@@ -44,6 +46,12 @@ const (
 	sizeShiftBit         = 20
 )
 
+const (
+	rbdPool         = "rbd"
+	qosIopsLimitKey = "conf_rbd_qos_iops_limit"
+	qosBpsLimitKey  = "conf_rbd_qos_bps_limit"
+)
+
 const (
 	globalSize = iota
 	globalAvail
@@ -89,7 +97,7 @@ func (c *CephConfig) Load(file string) error {
 	}
 	err = yaml.Unmarshal([]byte(confYaml), c)
 	if err != nil {
-		log.Fatal("Parse error: %v", err)
+		log.Fatalf("Parse error: %v", err)
 		return err
 	}
 	return nil
@@ -149,61 +157,145 @@ func execCmd(cmd string) (string, error) {
 	return string(ret[:len(ret)-1]), nil
 }
 
+// runCmd runs the command with its arguments rather than through shell, so
+// the arguments taken from requests are never interpreted by shell.
+func runCmd(name string, args ...string) (string, error) {
+	ret, err := exec.Command(name, args...).Output()
+	if err != nil {
+		log.Error(err.Error())
+		return "", err
+	}
+	return strings.TrimSuffix(string(ret), "\n"), nil
+}
+
 type Driver struct {
-	conn  *rados.Conn
-	ioctx *rados.IOContext
+	// The connection to ceph cluster is opened by the first call and shared
+	// by the concurrent calls, it's closed only when the driver is unset and
+	// no call is using it.
+	connLock  sync.RWMutex
+	connected bool
+	conn      *rados.Conn
+	ioctx     *rados.IOContext
+	// ConfigPath is the config file of the driver, the one in osdsdock
+	// section is used if it's empty.
+	ConfigPath string
+	conf       *CephConfig
 }
 
-func (d *Driver) Setup() error { return nil }
+func (d *Driver) Setup() error {
+	if d.ConfigPath == "" {
+		return nil
+	}
+	conf := &CephConfig{ConfigFile: "/etc/ceph/ceph.conf"}
+	if err := conf.Load(d.ConfigPath); err != nil {
+		return err
+	}
+	d.conf = conf
+	return nil
+}
 
-func (d *Driver) Unset() error { return nil }
+// getConfig returns the config of the ceph cluster served by the driver, which
+// is the one in osdsdock section unless the driver is set up with its own.
+func (d *Driver) getConfig() *CephConfig {
+	if d.conf != nil {
+		return d.conf
+	}
+	return getConfig()
+}
+
+func (d *Driver) Unset() error {
+	d.connLock.Lock()
+	defer d.connLock.Unlock()
+
+	if d.ioctx != nil {
+		d.ioctx.Destroy()
+	}
+	if d.conn != nil {
+		d.conn.Shutdown()
+	}
+	d.conn, d.ioctx, d.connected = nil, nil, false
+	return nil
+}
 
+// initConn opens the connection to ceph cluster unless it has been opened,
+// and keeps it from being closed until releaseConn is called.
 func (d *Driver) initConn() error {
+	for {
+		d.connLock.RLock()
+		if d.connected {
+			return nil
+		}
+		d.connLock.RUnlock()
+
+		d.connLock.Lock()
+		if !d.connected {
+			if err := d.connect(); err != nil {
+				d.connLock.Unlock()
+				return err
+			}
+			d.connected = true
+		}
+		d.connLock.Unlock()
+	}
+}
+
+func (d *Driver) releaseConn() {
+	d.connLock.RUnlock()
+}
+
+func (d *Driver) connect() error {
 	conn, err := rados.NewConn()
 	if err != nil {
 		log.Error("New connect failed:", err)
 		return err
 	}
 
-	if err = conn.ReadConfigFile(getConfig().ConfigFile); err != nil {
+	if err = conn.ReadConfigFile(d.getConfig().ConfigFile); err != nil {
 		log.Error("Read config file failed:", err)
+		conn.Shutdown()
 		return err
 	}
 	if err = conn.Connect(); err != nil {
 		log.Error("Connect failed:", err)
+		conn.Shutdown()
 		return err
 	}
-	d.ioctx, err = conn.OpenIOContext("rbd")
+	ioctx, err := conn.OpenIOContext(rbdPool)
 	if err != nil {
 		log.Error("Open IO context failed:", err)
+		conn.Shutdown()
 		return err
 	}
-	d.conn = conn
+	d.conn, d.ioctx = conn, ioctx
 	return nil
 }
 
-func (d *Driver) destroyConn() {
-	defer d.conn.Shutdown()
-	defer d.ioctx.Destroy()
-}
+func (d *Driver) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
 
-func (d *Driver) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
 	name := opt.GetName()
 	size := opt.GetSize()
 	if err := d.initConn(); err != nil {
-		log.Error("Connect ceph failed.")
+		logger.Error("Connect ceph failed.")
 		return nil, err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 
 	imgName := NewName(name)
-	_, err := rbd.Create(d.ioctx, imgName.GetFullName(), uint64(size)<<sizeShiftBit, 20)
+	img, err := rbd.Create(d.ioctx, imgName.GetFullName(), uint64(size)<<sizeShiftBit, 20)
 	if err != nil {
-		log.Errorf("Create rbd image (%s) failed, (%v)", name, err)
+		logger.Errorf("Create rbd image (%s) failed, (%v)", name, err)
+		return nil, err
+	}
+	if err = d.setQos(imgName.GetFullName(), opt.GetQos()); err != nil {
+		logger.Errorf("Set qos of rbd image (%s) failed, (%v)", name, err)
+		if img != nil {
+			img.Remove()
+		}
 		return nil, err
 	}
 
-	log.Infof("Create volume %s (%s) success.", name, imgName.GetUUID())
+	logger.Infof("Create volume %s (%s) success.", name, imgName.GetUUID())
 	return &model.VolumeSpec{
 		BaseModel: &model.BaseModel{
 			Id: imgName.GetUUID(),
@@ -215,6 +307,31 @@ func (d *Driver) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, erro
 	}, nil
 }
 
+// setQos applies the qos limits on the rbd image through image metadata, which
+// will be honored by librbd clients when the image is opened.
+func (d *Driver) setQos(imgName string, qos *pb.Qos) error {
+	if qos == nil {
+		return nil
+	}
+
+	var meta = make(map[string]int64)
+	if qos.GetMaxIOPS() > 0 {
+		meta[qosIopsLimitKey] = qos.GetMaxIOPS()
+	}
+	if qos.GetMaxBandwidth() > 0 {
+		// Bandwidth is configured in MB/s but librbd expects bytes/s.
+		meta[qosBpsLimitKey] = qos.GetMaxBandwidth() << sizeShiftBit
+	}
+	for key, val := range meta {
+		_, err := runCmd("rbd", "image-meta", "set", rbdPool+"/"+imgName, key,
+			strconv.FormatInt(val, 10), "-c", d.getConfig().ConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *Driver) getImage(volID string) (*rbd.Image, *Name, error) {
 	imgNames, err := rbd.GetImageNames(d.ioctx)
 	if err != nil {
@@ -245,14 +362,45 @@ func (d *Driver) getSize(img *rbd.Image) int64 {
 	return int64(size >> sizeShiftBit)
 }
 
+// adoptImage finds the rbd image which isn't created by opensds by its name,
+// and renames it after opensds as cinder does when managing rbd images, so
+// that it could be found by the volume id later.
+func (d *Driver) adoptImage(imgName string) (*rbd.Image, *Name, error) {
+	imgNames, err := rbd.GetImageNames(d.ioctx)
+	if err != nil {
+		log.Error("When getImageNames:", err)
+		return nil, nil, err
+	}
+	for _, fullName := range imgNames {
+		if fullName != imgName || ParseName(fullName) != nil {
+			continue
+		}
+		if strings.Contains(imgName, splitChar) {
+			return nil, nil, fmt.Errorf("Image name %s contains %s", imgName, splitChar)
+		}
+		name := NewName(imgName)
+		if err = rbd.GetImage(d.ioctx, imgName).Rename(name.GetFullName()); err != nil {
+			log.Error("When rename image:", err)
+			return nil, nil, err
+		}
+		return rbd.GetImage(d.ioctx, name.GetFullName()), name, nil
+	}
+	return nil, nil, rbd.RbdErrorNotFound
+}
+
+// PullVolume pulls the volume by its id, or by the name of rbd image which
+// isn't created by opensds.
 func (d *Driver) PullVolume(volID string) (*model.VolumeSpec, error) {
 	if err := d.initConn(); err != nil {
 		log.Error("Connect ceph failed.")
 		return nil, err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 
 	img, name, err := d.getImage(volID)
+	if err == rbd.RbdErrorNotFound {
+		img, name, err = d.adoptImage(volID)
+	}
 	if err != nil {
 		log.Error("When get image:", err)
 		return nil, err
@@ -269,35 +417,73 @@ func (d *Driver) PullVolume(volID string) (*model.VolumeSpec, error) {
 	}, nil
 }
 
-func (d *Driver) DeleteVolume(opt *pb.DeleteVolumeOpts) error {
+// ListVolumes lists all the rbd images which are created by opensds, the
+// images not named by opensds are ignored.
+func (d *Driver) ListVolumes() ([]*model.VolumeSpec, error) {
 	if err := d.initConn(); err != nil {
 		log.Error("Connect ceph failed.")
+		return nil, err
+	}
+	defer d.releaseConn()
+
+	imgNames, err := rbd.GetImageNames(d.ioctx)
+	if err != nil {
+		log.Error("When getImageNames:", err)
+		return nil, err
+	}
+
+	var vols []*model.VolumeSpec
+	for _, fullName := range imgNames {
+		name := ParseName(fullName)
+		if name == nil {
+			continue
+		}
+		vols = append(vols, &model.VolumeSpec{
+			BaseModel: &model.BaseModel{
+				Id: name.GetUUID(),
+			},
+			Name:             name.GetName(),
+			Size:             d.getSize(rbd.GetImage(d.ioctx, fullName)),
+			AvailabilityZone: "ceph",
+			PoolId:           uuid.NewV5(uuid.NamespaceOID, rbdPool).String(),
+		})
+	}
+	return vols, nil
+}
+
+func (d *Driver) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
+	if err := d.initConn(); err != nil {
+		logger.Error("Connect ceph failed.")
 		return err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 
 	img, _, err := d.getImage(opt.GetId())
 	if err != nil {
 		return err
 	}
 	if err = img.Remove(); err != nil {
-		log.Error("When remove image:", err)
+		logger.Error("When remove image:", err)
 		return err
 	}
-	log.Info("Remove image success, volume id =", opt.GetId())
+	logger.Info("Remove image success, volume id =", opt.GetId())
 	return nil
 }
 
-func (d *Driver) InitializeConnection(opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+func (d *Driver) InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := d.initConn(); err != nil {
-		log.Error("Connect ceph failed.")
+		logger.Error("Connect ceph failed.")
 		return nil, err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 
 	vol, err := d.PullVolume(opt.GetId())
 	if err != nil {
-		log.Error("When get image:", err)
+		logger.Error("When get image:", err)
 		return nil, err
 	}
 
@@ -315,32 +501,36 @@ func (d *Driver) InitializeConnection(opt *pb.CreateAttachmentOpts) (*model.Conn
 	}, nil
 }
 
-func (d *Driver) TerminateConnection(opt *pb.DeleteAttachmentOpts) error { return nil }
+func (d *Driver) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	return nil
+}
+
+func (d *Driver) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
 
-func (d *Driver) CreateSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
 	if err := d.initConn(); err != nil {
-		log.Error("Connect ceph failed.")
+		logger.Error("Connect ceph failed.")
 		return nil, err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 
 	img, _, err := d.getImage(opt.GetId())
 	if err != nil {
-		log.Error("When get image:", err)
+		logger.Error("When get image:", err)
 		return nil, err
 	}
 	if err = img.Open(); err != nil {
-		log.Error("When open image:", err)
+		logger.Error("When open image:", err)
 		return nil, err
 	}
 	defer img.Close()
 
 	fullName := NewName(opt.GetName())
 	if _, err = img.CreateSnapshot(fullName.GetFullName()); err != nil {
-		log.Error("When create snapshot:", err)
+		logger.Error("When create snapshot:", err)
 		return nil, err
 	}
-	log.Infof("Create snapshot (name:%s, id:%s, volID:%s) success", opt.GetName(), opt.GetId(), fullName.GetUUID())
+	logger.Infof("Create snapshot (name:%s, id:%s, volID:%s) success", opt.GetName(), opt.GetId(), fullName.GetUUID())
 	return &model.VolumeSnapshotSpec{
 		BaseModel: &model.BaseModel{
 			Id: fullName.GetUUID(),
@@ -391,7 +581,7 @@ func (d *Driver) PullSnapshot(snapID string) (*model.VolumeSnapshotSpec, error)
 		log.Error("Connect ceph failed.")
 		return nil, err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 	var snapshot *model.VolumeSnapshotSpec
 	err := d.visitSnapshot(snapID, func(volName *Name, img *rbd.Image, snap *rbd.SnapInfo) error {
 		snapName := ParseName(snap.Name)
@@ -408,23 +598,75 @@ func (d *Driver) PullSnapshot(snapID string) (*model.VolumeSnapshotSpec, error)
 	return snapshot, err
 }
 
-func (d *Driver) DeleteSnapshot(opt *pb.DeleteVolumeSnapshotOpts) error {
+// ListSnapshots lists the snapshots of all the rbd images which are created
+// by opensds.
+func (d *Driver) ListSnapshots() ([]*model.VolumeSnapshotSpec, error) {
 	if err := d.initConn(); err != nil {
 		log.Error("Connect ceph failed.")
+		return nil, err
+	}
+	defer d.releaseConn()
+
+	imgNames, err := rbd.GetImageNames(d.ioctx)
+	if err != nil {
+		log.Error("When getImageNames:", err)
+		return nil, err
+	}
+
+	var snps []*model.VolumeSnapshotSpec
+	for _, fullName := range imgNames {
+		volName := ParseName(fullName)
+		if volName == nil {
+			continue
+		}
+		img := rbd.GetImage(d.ioctx, fullName)
+		if err = img.Open(); err != nil {
+			log.Error("When open image:", err)
+			return nil, err
+		}
+		snapInfos, err := img.GetSnapshotNames()
+		img.Close()
+		if err != nil {
+			log.Error("When GetSnapshotNames:", err)
+			return nil, err
+		}
+		for _, snapInfo := range snapInfos {
+			snapName := ParseName(snapInfo.Name)
+			if snapName == nil {
+				continue
+			}
+			snps = append(snps, &model.VolumeSnapshotSpec{
+				BaseModel: &model.BaseModel{
+					Id: snapName.GetUUID(),
+				},
+				Name:     snapName.GetName(),
+				Size:     int64(snapInfo.Size >> sizeShiftBit),
+				VolumeId: volName.ID,
+			})
+		}
+	}
+	return snps, nil
+}
+
+func (d *Driver) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
+	if err := d.initConn(); err != nil {
+		logger.Error("Connect ceph failed.")
 		return err
 	}
-	defer d.destroyConn()
+	defer d.releaseConn()
 	err := d.visitSnapshot(opt.GetId(), func(volName *Name, img *rbd.Image, snap *rbd.SnapInfo) error {
 		if err := img.Open(snap.Name); err != nil {
-			log.Error("When open image:", err)
+			logger.Error("When open image:", err)
 		}
 		snapshot := img.GetSnapshot(snap.Name)
 		if err := snapshot.Remove(); err != nil {
-			log.Error("When remove snapshot:", err)
+			logger.Error("When remove snapshot:", err)
 			return err
 		}
 		img.Close()
-		log.Infof("Delete snapshot (%s) success", ParseName(snap.Name).GetUUID())
+		logger.Infof("Delete snapshot (%s) success", ParseName(snap.Name).GetUUID())
 		return nil
 	})
 	return err
@@ -457,7 +699,7 @@ func (d *Driver) parseCapStr(cap string) int64 {
 
 func (d *Driver) getPoolsCapInfo() ([][]string, error) {
 	const poolStartLine = 5
-	output, err := execCmd("ceph df -c " + getConfig().ConfigFile)
+	output, err := execCmd("ceph df -c " + d.getConfig().ConfigFile)
 	if err != nil {
 		log.Error("[Error]:", err)
 		return nil, err
@@ -472,7 +714,7 @@ func (d *Driver) getPoolsCapInfo() ([][]string, error) {
 
 func (d *Driver) getGlobalCapInfo() ([]string, error) {
 	const globalCapInfoLine = 2
-	output, err := execCmd("ceph df -c " + getConfig().ConfigFile)
+	output, err := execCmd("ceph df -c " + d.getConfig().ConfigFile)
 	if err != nil {
 		log.Error("[Error]:", err)
 		return nil, err
@@ -482,7 +724,7 @@ func (d *Driver) getGlobalCapInfo() ([]string, error) {
 }
 
 func (d *Driver) getPoolsAttr() (map[string][]string, error) {
-	cmd := "ceph osd pool ls detail -c " + getConfig().ConfigFile + "| grep \"^pool\"| awk '{print $3, $4, $6, $10}'"
+	cmd := "ceph osd pool ls detail -c " + d.getConfig().ConfigFile + "| grep \"^pool\"| awk '{print $3, $4, $6, $10}'"
 	output, err := execCmd(cmd)
 	if err != nil {
 		log.Error("[Error]:", err)
@@ -507,6 +749,7 @@ func (d *Driver) buildPoolParam(line []string, proper PoolProperties) *map[strin
 	param["diskType"] = proper.DiskType
 	param["iops"] = proper.IOPS
 	param["bandwidth"] = proper.BandWidth
+	param[model.QosSupportedKey] = true
 	param["redundancyType"] = line[poolType]
 	if param["redundancyType"] == "replicated" {
 		param["replicateSize"] = line[poolTypeSize]
@@ -537,7 +780,7 @@ func (d *Driver) ListPools() ([]*model.StoragePoolSpec, error) {
 	var pols []*model.StoragePoolSpec
 	for i := range pc {
 		name := pc[i][poolName]
-		c := getConfig()
+		c := d.getConfig()
 		if _, ok := c.Pool[name]; !ok {
 			continue
 		}
@@ -16,7 +16,9 @@ package ceph
 
 import (
 	"errors"
+	"sync"
 	"testing"
+	"time"
 	"unsafe"
 
 	"strings"
@@ -27,12 +29,13 @@ import (
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/utils/config"
 	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 )
 
 func TestCreateVolume(t *testing.T) {
 
 	defer monkey.UnpatchAll()
-	monkey.Patch((*Driver).initConn, func(d *Driver) error { return nil })
+	monkey.Patch((*Driver).connect, func(d *Driver) error { return nil })
 	monkey.Patch(rbd.Create, func(ioctx *rados.IOContext, name string, size uint64, order int,
 		args ...uint64) (*rbd.Image, error) {
 		return nil, nil
@@ -42,7 +45,7 @@ func TestCreateVolume(t *testing.T) {
 
 	// case 1
 	d := Driver{}
-	resp, err := d.CreateVolume(&pb.CreateVolumeOpts{Name: "volume001", Size: 1})
+	resp, err := d.CreateVolume(context.Background(), &pb.CreateVolumeOpts{Name: "volume001", Size: 1})
 	if err != nil {
 		t.Errorf("Test Create volume error")
 	}
@@ -57,12 +60,12 @@ func TestCreateVolume(t *testing.T) {
 	}
 
 	//case 2
-	monkey.Unpatch((*Driver).initConn)
-	monkey.Patch((*Driver).initConn, func(d *Driver) error {
+	monkey.Unpatch((*Driver).connect)
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
 		return errors.New("Fake error")
 	})
 	d = Driver{}
-	_, err = d.CreateVolume(&pb.CreateVolumeOpts{Name: "volume001", Size: 1})
+	_, err = d.CreateVolume(context.Background(), &pb.CreateVolumeOpts{Name: "volume001", Size: 1})
 	if err == nil {
 		t.Errorf("Test Create volume error")
 	}
@@ -73,15 +76,50 @@ func TestCreateVolume(t *testing.T) {
 		args ...uint64) (*rbd.Image, error) {
 		return nil, errors.New("Fake error")
 	})
-	_, err = d.CreateVolume(&pb.CreateVolumeOpts{Name: "volume001", Size: 1})
+	_, err = d.CreateVolume(context.Background(), &pb.CreateVolumeOpts{Name: "volume001", Size: 1})
 	if err == nil {
 		t.Errorf("Test Create volume error")
 	}
+
+	//case 4
+	monkey.Unpatch((*Driver).connect)
+	monkey.Patch((*Driver).connect, func(d *Driver) error { return nil })
+	monkey.Unpatch(rbd.Create)
+	monkey.Patch(rbd.Create, func(ioctx *rados.IOContext, name string, size uint64, order int,
+		args ...uint64) (*rbd.Image, error) {
+		return nil, nil
+	})
+	config.CONF.OsdsDock.CephConfig = "testdata/ceph.yaml"
+	var cmds [][]string
+	monkey.Patch(runCmd, func(name string, args ...string) (string, error) {
+		cmds = append(cmds, append([]string{name}, args...))
+		return "", nil
+	})
+	_, err = d.CreateVolume(context.Background(), &pb.CreateVolumeOpts{
+		Name: "volume'; rm -rf /tmp/x; '",
+		Size: 1,
+		Qos:  &pb.Qos{MaxIOPS: 1000, MaxBandwidth: 100},
+	})
+	if err != nil {
+		t.Errorf("Test Create volume with qos error")
+	}
+	var qosCmds []string
+	for _, cmd := range cmds {
+		// The image name is passed as one argument without shell.
+		if len(cmd) != 8 || cmd[0] != "rbd" || !strings.HasPrefix(cmd[3], "rbd/OPENSDS:volume'; rm -rf /tmp/x; ':") {
+			t.Errorf("Test Create volume qos error, got command: %v", cmd)
+		}
+		qosCmds = append(qosCmds, strings.Join(cmd[4:6], " "))
+	}
+	if strings.Join(qosCmds, ",") != "conf_rbd_qos_iops_limit 1000,conf_rbd_qos_bps_limit 104857600" &&
+		strings.Join(qosCmds, ",") != "conf_rbd_qos_bps_limit 104857600,conf_rbd_qos_iops_limit 1000" {
+		t.Errorf("Test Create volume qos error, got commands: %v", cmds)
+	}
 }
 
 func TestGetVolume(t *testing.T) {
 	defer monkey.UnpatchAll()
-	monkey.Patch((*Driver).initConn, func(d *Driver) error {
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
 		return nil
 	})
 	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
@@ -120,11 +158,35 @@ func TestGetVolume(t *testing.T) {
 	if err != rbd.RbdErrorNotFound {
 		t.Errorf("Test Get volume error")
 	}
+
+	// case 2: the image which isn't created by opensds is pulled by its name.
+	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
+		return []string{opensdsPrefix + ":volume001:7ee11866-1f40-4f3c-b093-7a3684523a19", "legacy-image"}, nil
+	})
+	var renamed string
+	monkey.Patch((*rbd.Image).Rename, func(r *rbd.Image, destname string) error {
+		renamed = destname
+		return nil
+	})
+	resp, err = d.PullVolume("legacy-image")
+	if err != nil {
+		t.Fatalf("Test Get volume by image name error: %v", err)
+	}
+	if resp.Name != "legacy-image" || resp.Size != 1 {
+		t.Errorf("Test Get volume by image name error, got %v", resp)
+	}
+	if renamed != opensdsPrefix+":legacy-image:"+resp.Id {
+		t.Errorf("Expected image renamed after opensds, got %s", renamed)
+	}
+	resp, err = d.PullVolume(opensdsPrefix + ":volume001:7ee11866-1f40-4f3c-b093-7a3684523a19")
+	if err != rbd.RbdErrorNotFound {
+		t.Errorf("Expected image of opensds not pulled by its name, got %v", err)
+	}
 }
 
 func TestDeleteVolme(t *testing.T) {
 	defer monkey.UnpatchAll()
-	monkey.Patch((*Driver).initConn, func(d *Driver) error {
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
 		return nil
 	})
 	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
@@ -144,7 +206,7 @@ func TestDeleteVolme(t *testing.T) {
 	// case 1
 	d := Driver{}
 	opt := &pb.DeleteVolumeOpts{Id: "7ee11866-1f40-4f3c-b093-7a3684523a19"}
-	err := d.DeleteVolume(opt)
+	err := d.DeleteVolume(context.Background(), opt)
 	if err != nil {
 		t.Errorf("Test Delete volume error")
 	}
@@ -152,7 +214,7 @@ func TestDeleteVolme(t *testing.T) {
 
 func TestCreateSnapshot(t *testing.T) {
 	defer monkey.UnpatchAll()
-	monkey.Patch((*Driver).initConn, func(d *Driver) error {
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
 		return nil
 	})
 	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
@@ -183,7 +245,7 @@ func TestCreateSnapshot(t *testing.T) {
 
 	// case 1
 	d := Driver{}
-	resp, err := d.CreateSnapshot(&pb.CreateVolumeSnapshotOpts{
+	resp, err := d.CreateSnapshot(context.Background(), &pb.CreateVolumeSnapshotOpts{
 		Name:        "snapshot001",
 		Id:          "7ee11866-1f40-4f3c-b093-7a3684523a19",
 		Description: "unite test"})
@@ -204,7 +266,7 @@ func TestCreateSnapshot(t *testing.T) {
 
 func TestGetSnapshot(t *testing.T) {
 	defer monkey.UnpatchAll()
-	monkey.Patch((*Driver).initConn, func(d *Driver) error {
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
 		return nil
 	})
 	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
@@ -262,7 +324,7 @@ func TestGetSnapshot(t *testing.T) {
 
 func TestDeleteSnapshot(t *testing.T) {
 	defer monkey.UnpatchAll()
-	monkey.Patch((*Driver).initConn, func(d *Driver) error {
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
 		return nil
 	})
 	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
@@ -296,12 +358,76 @@ func TestDeleteSnapshot(t *testing.T) {
 
 	// case 1
 	d := Driver{}
-	err := d.DeleteSnapshot(&pb.DeleteVolumeSnapshotOpts{Id: "25f5d7a2-553d-4d6c-904d-179a9e698cf8"})
+	err := d.DeleteSnapshot(context.Background(), &pb.DeleteVolumeSnapshotOpts{Id: "25f5d7a2-553d-4d6c-904d-179a9e698cf8"})
 	if err != nil {
 		t.Errorf("Test Delete snapshot error")
 	}
 }
 
+func TestConcurrentCalls(t *testing.T) {
+	defer monkey.UnpatchAll()
+	var mu sync.Mutex
+	var connects, closes int
+	monkey.Patch((*Driver).connect, func(d *Driver) error {
+		mu.Lock()
+		connects++
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		d.conn, d.ioctx = &rados.Conn{}, &rados.IOContext{}
+		return nil
+	})
+	monkey.Patch((*rados.Conn).Shutdown, func(c *rados.Conn) {
+		mu.Lock()
+		closes++
+		mu.Unlock()
+	})
+	monkey.Patch((*rados.IOContext).Destroy, func(ioctx *rados.IOContext) {})
+	monkey.Patch(rbd.GetImageNames, func(ioctx *rados.IOContext) (names []string, err error) {
+		if ioctx == nil {
+			return nil, errors.New("IO context is used after destroyed")
+		}
+		time.Sleep(time.Millisecond)
+		return nil, nil
+	})
+
+	// The connection is shared by the concurrent calls and kept open.
+	d := &Driver{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := d.ListVolumes(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if connects != 1 || closes != 0 {
+		t.Errorf("Expected connection opened once and kept, got %d opened and %d closed", connects, closes)
+	}
+
+	// The connection isn't closed until no call is using it.
+	if err := d.initConn(); err != nil {
+		t.Fatal(err)
+	}
+	unset := make(chan struct{})
+	go func() {
+		d.Unset()
+		close(unset)
+	}()
+	select {
+	case <-unset:
+		t.Error("Expected driver unset after the call released connection")
+	case <-time.After(20 * time.Millisecond):
+	}
+	d.releaseConn()
+	<-unset
+	if closes != 1 || d.connected {
+		t.Errorf("Expected connection closed when driver is unset, got %d closed", closes)
+	}
+}
+
 func TestCephConfig(t *testing.T) {
 	config.CONF.OsdsDock.CephConfig = "testdata/ceph.yaml"
 	conf := getConfig()
@@ -328,6 +454,20 @@ func TestCephConfig(t *testing.T) {
 	}
 }
 
+func TestDriverConfig(t *testing.T) {
+	config.CONF.OsdsDock.CephConfig = "testdata/ceph.yaml"
+	d := &Driver{ConfigPath: "testdata/ceph-ssd.yaml"}
+	if err := d.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if conf := d.getConfig(); conf.ConfigFile != "/etc/ceph/ceph-ssd.conf" || len(conf.Pool) != 1 {
+		t.Errorf("Expected config of driver loaded, got %v", conf)
+	}
+	if conf := (&Driver{}).getConfig(); conf.ConfigFile != "/etc/ceph/ceph.conf" {
+		t.Errorf("Expected config in osdsdock section used, got %v", conf)
+	}
+}
+
 func TestListPools(t *testing.T) {
 
 	defer monkey.UnpatchAll()
@@ -13,21 +13,28 @@
 //    under the License.
 
 /*
-This module defines an standard table of storage driver. The default storage
-driver is sample driver used for testing. If you want to use other storage
-plugin, just modify Init() method.
+This module defines an standard table of storage driver. The sample driver,
+named "sample" or "default", is used for testing. If you want to use other
+storage plugin, just modify InitBackend() method, or serve it out of tree as a
+plugin which is selected by driver name "plugin:<socket>".
 
 */
 
 package drivers
 
 import (
+	"fmt"
+
 	"github.com/opensds/opensds/contrib/drivers/ceph"
 	"github.com/opensds/opensds/contrib/drivers/lvm"
 	"github.com/opensds/opensds/contrib/drivers/openstack/cinder"
+	"github.com/opensds/opensds/contrib/drivers/plugin"
+	"github.com/opensds/opensds/contrib/drivers/plugin/sdk"
 	"github.com/opensds/opensds/contrib/drivers/sample"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils/config"
+	"golang.org/x/net/context"
 )
 
 type VolumeDriver interface {
@@ -36,38 +43,61 @@ type VolumeDriver interface {
 	//Any operation the volume driver does while stoping.
 	Unset() error
 
-	CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error)
+	CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error)
 
 	PullVolume(volIdentifier string) (*model.VolumeSpec, error)
 
-	DeleteVolume(opt *pb.DeleteVolumeOpts) error
+	DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error
 
-	InitializeConnection(opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error)
+	InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error)
 
-	TerminateConnection(opt *pb.DeleteAttachmentOpts) error
+	TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error
 
-	CreateSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error)
+	CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error)
 
 	PullSnapshot(snapIdentifier string) (*model.VolumeSnapshotSpec, error)
 
-	DeleteSnapshot(opt *pb.DeleteVolumeSnapshotOpts) error
+	DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error
 
 	ListPools() ([]*model.StoragePoolSpec, error)
+
+	// ListVolumes lists all the volumes which exist in the backend, it's used
+	// for reconciling the backend with database.
+	ListVolumes() ([]*model.VolumeSpec, error)
+
+	// ListSnapshots lists all the volume snapshots which exist in the backend.
+	ListSnapshots() ([]*model.VolumeSnapshotSpec, error)
+}
+
+// The drivers of plugins are implemented as VolumeDriver too.
+var _ sdk.VolumeDriver = VolumeDriver(nil)
+
+func Init(resourceType string) (VolumeDriver, error) {
+	return InitBackend(config.BackendProperties{DriverName: resourceType})
 }
 
-func Init(resourceType string) VolumeDriver {
-	switch resourceType {
-	case "cinder":
-		var d = &cinder.Driver{}
-		d.Setup()
-		return d
-	case "ceph":
-		return &ceph.Driver{}
-	case "lvm":
-		var d = &lvm.Driver{}
-		d.Setup()
-		return d
+// InitBackend initializes the driver of the backend with its config file, so
+// that several backends of the same driver could be served. An error is
+// returned if the driver is unknown or fails to be set up.
+func InitBackend(b config.BackendProperties) (VolumeDriver, error) {
+	var d VolumeDriver
+	switch resourceType := b.DriverName; {
+	case plugin.IsPlugin(resourceType):
+		d = plugin.NewDriver(resourceType, b.ConfigPath)
+	case resourceType == "cinder":
+		d = &cinder.Driver{ConfigPath: b.ConfigPath}
+	case resourceType == "ceph":
+		d = &ceph.Driver{ConfigPath: b.ConfigPath}
+	case resourceType == "lvm":
+		d = &lvm.Driver{ConfigPath: b.ConfigPath}
+	case resourceType == "sample", resourceType == "default":
+		d = &sample.Driver{}
 	default:
-		return &sample.Driver{}
+		return nil, fmt.Errorf("Unknown driver %q of backend %s", resourceType, b.Name)
+	}
+
+	if err := d.Setup(); err != nil {
+		return nil, fmt.Errorf("Set up driver %q of backend %s failed: %v", b.DriverName, b.Name, err)
 	}
+	return d, nil
 }
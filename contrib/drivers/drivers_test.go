@@ -15,20 +15,52 @@
 package drivers
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	_ "github.com/opensds/opensds/contrib/drivers/ceph"
+	"github.com/opensds/opensds/contrib/drivers/plugin"
+	"github.com/opensds/opensds/contrib/drivers/plugin/sdk"
 	"github.com/opensds/opensds/contrib/drivers/sample"
 )
 
 func TestInit(t *testing.T) {
-	var rsList = []string{"others"}
-	var expectedVd = []VolumeDriver{&sample.Driver{}}
+	var rsList = []string{"sample", "default"}
+	var expectedVd = []VolumeDriver{&sample.Driver{}, &sample.Driver{}}
 
 	for i, rs := range rsList {
-		if vp := Init(rs); !reflect.DeepEqual(vp, expectedVd[i]) {
-			t.Errorf("Expected %v, got %v\n", expectedVd, vp)
+		if vp, err := Init(rs); err != nil || !reflect.DeepEqual(vp, expectedVd[i]) {
+			t.Errorf("Expected %v, got %v, %v\n", expectedVd[i], vp, err)
 		}
 	}
+
+	// The backends of unknown drivers aren't served by sample driver.
+	if vp, err := Init("others"); err == nil {
+		t.Errorf("Expected error of unknown driver, got %v", vp)
+	}
+}
+
+func TestInitPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "sample.sock")
+	go sdk.Serve(socket, &sample.Driver{})
+
+	vp, err := Init(plugin.Prefix + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vp.Unset()
+	if d, ok := vp.(*plugin.Driver); !ok || d.Socket != socket {
+		t.Errorf("Expected proxy driver of plugin, got %v", vp)
+	}
+	if _, err = vp.ListPools(); err != nil {
+		t.Error(err)
+	}
 }
@@ -18,31 +18,43 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os/exec"
 	"strconv"
 	"strings"
 
 	log "github.com/golang/glog"
 	"github.com/opensds/opensds/contrib/drivers/lvm/targets"
+	osdsctx "github.com/opensds/opensds/pkg/context"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
 	"github.com/opensds/opensds/pkg/utils/config"
 	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 	"gopkg.in/yaml.v2"
 )
 
 const (
+	// vgName is the volume group served if it isn't configured.
 	vgName = "vg001"
-)
 
-var conf = LVMConfig{}
+	maxIOPSKey      = "maxIOPS"
+	maxBandwidthKey = "maxBandwidth"
+)
 
 type Driver struct {
 	config LVMConfig
+	// ConfigPath is the config file of the driver, the one in osdsdock
+	// section is used if it's empty.
+	ConfigPath string
+	// target exports the logic volumes, it's the iscsi target if it's nil.
+	target targets.Target
 }
 
 type LVMConfig struct {
-	Pool map[string]PoolProperties `yaml:"pool,flow"`
+	VolumeGroup string                    `yaml:"volumeGroup,omitempty"`
+	Pool        map[string]PoolProperties `yaml:"pool,flow"`
 }
 
 type PoolProperties struct {
@@ -53,13 +65,18 @@ type PoolProperties struct {
 
 func (d *Driver) Setup() error {
 	// Read lvm config file
-	confYaml, err := ioutil.ReadFile(config.CONF.LVMConfig)
+	path := d.ConfigPath
+	if path == "" {
+		path = config.CONF.LVMConfig
+	}
+	confYaml, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Read lvm config yaml file (%s) failed, reason:(%v)", config.CONF.LVMConfig, err)
+		log.Fatalf("Read lvm config yaml file (%s) failed, reason:(%v)", path, err)
 		return err
 	}
+	var conf LVMConfig
 	if err = yaml.Unmarshal(confYaml, &conf); err != nil {
-		log.Fatal("Parse error: %v", err)
+		log.Fatalf("Parse error: %v", err)
 		return err
 	}
 	d.config = conf
@@ -69,21 +86,39 @@ func (d *Driver) Setup() error {
 
 func (*Driver) Unset() error { return nil }
 
-func (d *Driver) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+func (d *Driver) getTarget() targets.Target {
+	if d.target != nil {
+		return d.target
+	}
+	return targets.NewTarget()
+}
+
+// volumeGroup returns the volume group served by the driver, so that several
+// volume groups could be served by the backends of lvm driver.
+func (d *Driver) volumeGroup() string {
+	if d.config.VolumeGroup != "" {
+		return d.config.VolumeGroup
+	}
+	return vgName
+}
+
+func (d *Driver) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	var size = fmt.Sprint(opt.GetSize()) + "G"
 
-	cmd := strings.Join([]string{"lvcreate", "-n", opt.GetName(), "-L", size, vgName}, " ")
+	cmd := strings.Join([]string{"lvcreate", "-n", opt.GetName(), "-L", size, d.volumeGroup()}, " ")
 	if _, err := d.execCmd(cmd); err != nil {
-		log.Error("Failed to create logic volume:", err)
+		logger.Error("Failed to create logic volume:", err)
 		return nil, err
 	}
 
 	var lvPath, lvStatus string
 	// Display and parse some metadata in logic volume returned.
-	lvPath = strings.Join([]string{"/dev", vgName, opt.GetName()}, "/")
+	lvPath = strings.Join([]string{"/dev", d.volumeGroup(), opt.GetName()}, "/")
 	lv, err := d.execCmd("lvdisplay " + lvPath)
 	if err != nil {
-		log.Error("Failed to display logic volume:", err)
+		logger.Error("Failed to display logic volume:", err)
 		return nil, err
 	}
 
@@ -104,74 +139,158 @@ func (d *Driver) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, erro
 		Size:        opt.GetSize(),
 		Description: opt.GetDescription(),
 		Status:      lvStatus,
-		Metadata: map[string]string{
-			"lvPath": lvPath,
-		},
+		Metadata:    buildVolumeMetadata(lvPath, opt.GetQos()),
 	}, nil
 }
 
+// buildVolumeMetadata records the device path and the qos limits of logic
+// volume, lvm has no native qos support so these limits will be passed to
+// the host as blkio throttle hints when initializing connection.
+func buildVolumeMetadata(lvPath string, qos *pb.Qos) map[string]string {
+	var meta = map[string]string{
+		"lvPath": lvPath,
+	}
+	if qos.GetMaxIOPS() > 0 {
+		meta[maxIOPSKey] = fmt.Sprint(qos.GetMaxIOPS())
+	}
+	if qos.GetMaxBandwidth() > 0 {
+		meta[maxBandwidthKey] = fmt.Sprint(qos.GetMaxBandwidth())
+	}
+	return meta
+}
+
+// buildThrottleHints builds the cgroup blkio throttle settings which should
+// be written for the attached device by the host.
+func buildThrottleHints(meta map[string]string) map[string]interface{} {
+	var hints = make(map[string]interface{})
+	if iops, err := strconv.ParseInt(meta[maxIOPSKey], 10, 64); err == nil && iops > 0 {
+		hints["blkio.throttle.read_iops_device"] = iops
+		hints["blkio.throttle.write_iops_device"] = iops
+	}
+	if bw, err := strconv.ParseInt(meta[maxBandwidthKey], 10, 64); err == nil && bw > 0 {
+		// Bandwidth is configured in MB/s but cgroup expects bytes/s.
+		hints["blkio.throttle.read_bps_device"] = bw << 20
+		hints["blkio.throttle.write_bps_device"] = bw << 20
+	}
+	return hints
+}
+
+// PullVolume displays the logic volume identified by its path, such as
+// /dev/vg001/volume001, so that it could be managed by opensds.
 func (d *Driver) PullVolume(volIdentifier string) (*model.VolumeSpec, error) {
 	// Display and parse some metadata in logic volume returned.
-	lv, err := d.execCmd("lvmdisplay " + volIdentifier)
+	out, err := d.execCmd("lvdisplay " + volIdentifier)
 	if err != nil {
 		log.Error("Failed to display logic volume:", err)
 		return nil, err
 	}
-	var lvStatus string
-	for _, line := range strings.Split(lv, "\n") {
-		if strings.Contains(line, "LV Status") {
-			lvStatus = strings.Fields(line)[2]
-		}
-	}
+	lv := parseLvDisplay(out)
 
 	return &model.VolumeSpec{
-		Status: lvStatus,
+		BaseModel: &model.BaseModel{},
+		Name:      lv.name,
+		Size:      lv.size,
+		Status:    lv.status,
+		Metadata: map[string]string{
+			"lvPath": lv.path,
+		},
 	}, nil
 }
 
-func (d *Driver) DeleteVolume(opt *pb.DeleteVolumeOpts) error {
+type lvInfo struct {
+	name, path, status string
+	// The size in GB, which is rounded up.
+	size int64
+}
+
+// parseLvDisplay parses the output of lvdisplay, in which every property of
+// the logic volume is one line, such as "LV Size    1.00 GiB".
+func parseLvDisplay(out string) *lvInfo {
+	var lv = &lvInfo{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "LV" {
+			continue
+		}
+		switch fields[1] {
+		case "Path":
+			lv.path = fields[2]
+		case "Name":
+			lv.name = fields[2]
+		case "Status":
+			lv.status = fields[2]
+		case "Size":
+			if len(fields) < 4 {
+				continue
+			}
+			size, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[3] {
+			case "MiB":
+				size /= 1024
+			case "TiB":
+				size *= 1024
+			}
+			lv.size = int64(math.Ceil(size))
+		}
+	}
+	return lv
+}
+
+func (d *Driver) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	lvPath, ok := opt.GetMetadata()["lvPath"]
 	if !ok {
 		err := errors.New("Failed to find logic volume path in volume metadata!")
-		log.Error(err)
+		logger.Error(err)
 		return err
 	}
 	cmd := strings.Join([]string{"lvremove", "-f", lvPath}, " ")
 	if _, err := d.execCmd(cmd); err != nil {
-		log.Error("Failed to remove logic volume:", err)
+		logger.Error("Failed to remove logic volume:", err)
 		return err
 	}
 
 	return nil
 }
 
-func (*Driver) InitializeConnection(opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+func (d *Driver) InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	var initiator string
 	if initiator = opt.HostInfo.GetInitiator(); initiator == "" {
 		initiator = "ALL"
 	}
-	// TODO	Add lvm path in Metadata field.
 	lvPath, ok := opt.GetMetadata()["lvPath"]
 	if !ok {
 		err := errors.New("Failed to find logic volume path in volume attachment metadata!")
-		log.Error(err)
+		logger.Error(err)
 		return nil, err
 	}
 
-	t := targets.NewTarget()
+	t := d.getTarget()
 	expt, err := t.CreateExport(lvPath, initiator)
 	if err != nil {
-		log.Error("Failed to initialize connection of logic volume:", err)
+		logger.Error("Failed to initialize connection of logic volume:", err)
 		return nil, err
 	}
 
+	if hints := buildThrottleHints(opt.GetMetadata()); len(hints) != 0 {
+		expt["blkioThrottle"] = hints
+	}
+
 	return &model.ConnectionInfo{
 		DriverVolumeType: "iscsi",
 		ConnectionData:   expt,
 	}, nil
 }
 
-func (*Driver) TerminateConnection(opt *pb.DeleteAttachmentOpts) error {
+func (d *Driver) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	var initiator string
 	if initiator = opt.HostInfo.GetInitiator(); initiator == "" {
 		initiator = "ALL"
@@ -180,40 +299,42 @@ func (*Driver) TerminateConnection(opt *pb.DeleteAttachmentOpts) error {
 	lvPath, ok := opt.GetMetadata()["lvPath"]
 	if !ok {
 		err := errors.New("Failed to find logic volume path in volume attachment metadata!")
-		log.Error(err)
+		logger.Error(err)
 		return err
 	}
 
-	t := targets.NewTarget()
+	t := d.getTarget()
 	if err := t.RemoveExport(lvPath, initiator); err != nil {
-		log.Error("Failed to initialize connection of logic volume:", err)
+		logger.Error("Failed to initialize connection of logic volume:", err)
 		return err
 	}
 
 	return nil
 }
 
-func (d *Driver) CreateSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+func (d *Driver) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	var size = fmt.Sprint(opt.GetSize()) + "G"
 	lvPath, ok := opt.GetMetadata()["lvPath"]
 	if !ok {
 		err := errors.New("Failed to find logic volume path in volume snapshot metadata!")
-		log.Error(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	cmd := strings.Join([]string{"lvcreate", "-n", opt.GetName(), "-L", size, "-p r", "-s", lvPath}, " ")
 	if _, err := d.execCmd(cmd); err != nil {
-		log.Error("Failed to create logic volume snapshot:", err)
+		logger.Error("Failed to create logic volume snapshot:", err)
 		return nil, err
 	}
 
 	var lvsPath, lvStatus string
-	lvsPath = strings.Join([]string{"/dev", vgName, opt.GetName()}, "/")
+	lvsPath = strings.Join([]string{"/dev", d.volumeGroup(), opt.GetName()}, "/")
 	// Display and parse some metadata in logic volume snapshot returned.
 	lvs, err := d.execCmd("lvdisplay " + lvsPath)
 	if err != nil {
-		log.Error("Failed to display logic volume snapshot:", err)
+		logger.Error("Failed to display logic volume snapshot:", err)
 		return nil, err
 	}
 	for _, line := range strings.Split(lvs, "\n") {
@@ -240,43 +361,132 @@ func (d *Driver) CreateSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.Volume
 	}, nil
 }
 
+// PullSnapshot displays the logic volume snapshot identified by its path,
+// such as /dev/vg001/snapshot001, so that it could be managed by opensds.
 func (d *Driver) PullSnapshot(snapIdentifier string) (*model.VolumeSnapshotSpec, error) {
 	// Display and parse some metadata in logic volume snapshot returned.
-	lv, err := d.execCmd("lvmdisplay " + snapIdentifier)
+	out, err := d.execCmd("lvdisplay " + snapIdentifier)
 	if err != nil {
 		log.Error("Failed to display logic volume snapshot:", err)
 		return nil, err
 	}
-	var lvStatus string
-	for _, line := range strings.Split(lv, "\n") {
-		if strings.Contains(line, "LV Status") {
-			lvStatus = strings.Fields(line)[2]
-		}
-	}
+	lvs := parseLvDisplay(out)
 
 	return &model.VolumeSnapshotSpec{
-		Status: lvStatus,
+		BaseModel: &model.BaseModel{},
+		Name:      lvs.name,
+		Size:      lvs.size,
+		Status:    lvs.status,
+		Metadata: map[string]string{
+			"lvsPath": lvs.path,
+		},
 	}, nil
 }
 
-func (d *Driver) DeleteSnapshot(opt *pb.DeleteVolumeSnapshotOpts) error {
+func (d *Driver) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	lvsPath, ok := opt.GetMetadata()["lvsPath"]
 	if !ok {
 		err := errors.New("Failed to find logic volume snapshot path in volume snapshot metadata!")
-		log.Error(err)
+		logger.Error(err)
 		return err
 	}
 	cmd := strings.Join([]string{"lvremove", "-f", lvsPath}, " ")
 	if _, err := d.execCmd(cmd); err != nil {
-		log.Error("Failed to remove logic volume:", err)
+		logger.Error("Failed to remove logic volume:", err)
 		return err
 	}
 
 	return nil
 }
 
+// ListVolumes lists all the logic volumes in the volume group, the volumes
+// are identified by their paths since lvm records no opensds id.
+func (d *Driver) ListVolumes() ([]*model.VolumeSpec, error) {
+	out, err := d.execCmd(d.lvsCmd())
+	if err != nil {
+		log.Error("Failed to list logic volumes:", err)
+		return nil, err
+	}
+
+	var vols []*model.VolumeSpec
+	for _, lv := range parseLvs(out) {
+		if lv.origin != "" {
+			continue
+		}
+		vols = append(vols, &model.VolumeSpec{
+			BaseModel: &model.BaseModel{},
+			Name:      lv.name,
+			Size:      lv.size,
+			PoolId:    uuid.NewV5(uuid.NamespaceOID, d.volumeGroup()).String(),
+			Metadata: map[string]string{
+				"lvPath": lv.path,
+			},
+		})
+	}
+	return vols, nil
+}
+
+// ListSnapshots lists all the logic volume snapshots in the volume group.
+func (d *Driver) ListSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+	out, err := d.execCmd(d.lvsCmd())
+	if err != nil {
+		log.Error("Failed to list logic volume snapshots:", err)
+		return nil, err
+	}
+
+	var snps []*model.VolumeSnapshotSpec
+	for _, lv := range parseLvs(out) {
+		if lv.origin == "" {
+			continue
+		}
+		snps = append(snps, &model.VolumeSnapshotSpec{
+			BaseModel: &model.BaseModel{},
+			Name:      lv.name,
+			Size:      lv.size,
+			Metadata: map[string]string{
+				"lvsPath": lv.path,
+			},
+		})
+	}
+	return snps, nil
+}
+
+// lvsCmd reports one logic volume per line in the volume group, such as
+// "volume001,/dev/vg001/volume001,1.00," and the snapshots have the name of
+// their origin volumes in the last field.
+func (d *Driver) lvsCmd() string {
+	return "lvs --noheadings --nosuffix --units g --separator , -o lv_name,lv_path,lv_size,origin " + d.volumeGroup()
+}
+
+type lvsInfo struct {
+	lvInfo
+	origin string
+}
+
+func parseLvs(out string) []*lvsInfo {
+	var lvs []*lvsInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 4 {
+			continue
+		}
+		size, _ := strconv.ParseFloat(fields[2], 64)
+		lvs = append(lvs, &lvsInfo{
+			lvInfo: lvInfo{
+				name: fields[0],
+				path: fields[1],
+				size: int64(math.Ceil(size)),
+			},
+			origin: fields[3],
+		})
+	}
+	return lvs
+}
+
 func (d *Driver) ListPools() ([]*model.StoragePoolSpec, error) {
-	vgs, err := d.execCmd("vgdisplay")
+	vgs, err := d.execCmd("vgdisplay " + d.volumeGroup())
 	if err != nil {
 		return nil, err
 	}
@@ -297,15 +507,16 @@ func (d *Driver) ListPools() ([]*model.StoragePoolSpec, error) {
 	}
 
 	var pols []*model.StoragePoolSpec
-	if _, ok := d.config.Pool[vgName]; !ok {
+	vg := d.volumeGroup()
+	if _, ok := d.config.Pool[vg]; !ok {
 		return pols, nil
 	}
-	param := d.buildPoolParam(d.config.Pool[vgName])
+	param := d.buildPoolParam(d.config.Pool[vg])
 	pol := &model.StoragePoolSpec{
 		BaseModel: &model.BaseModel{
-			Id: uuid.NewV5(uuid.NamespaceOID, vgName).String(),
+			Id: uuid.NewV5(uuid.NamespaceOID, vg).String(),
 		},
-		Name:          vgName,
+		Name:          vg,
 		TotalCapacity: tCapacity,
 		FreeCapacity:  fCapacity,
 		Parameters:    *param,
@@ -320,6 +531,7 @@ func (*Driver) buildPoolParam(proper PoolProperties) *map[string]interface{} {
 	param["diskType"] = proper.DiskType
 	param["iops"] = proper.IOPS
 	param["bandwidth"] = proper.BandWidth
+	param[model.QosSupportedKey] = true
 
 	return &param
 }
@@ -327,8 +539,25 @@ func (*Driver) buildPoolParam(proper PoolProperties) *map[string]interface{} {
 func (*Driver) execCmd(cmd string) (string, error) {
 	ret, err := exec.Command("bash", "-c", cmd).Output()
 	if err != nil {
+		if e, ok := err.(*exec.ExitError); ok {
+			err = fmt.Errorf("%v: %s", err, strings.TrimSpace(string(e.Stderr)))
+		}
 		log.Error(err.Error())
-		return "", err
+		return "", lvmError(err)
 	}
 	return string(ret), nil
 }
+
+// lvmError converts the errors of lvm commands reporting that the logic
+// volume doesn't exist or already exists.
+func lvmError(err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "Failed to find logical volume"),
+		strings.Contains(msg, "not found"):
+		return utils.NotFoundError(msg)
+	case strings.Contains(msg, "already exists"):
+		return utils.AlreadyExistsError(msg)
+	}
+	return err
+}
@@ -13,3 +13,225 @@
 //    under the License.
 
 package lvm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bouk/monkey"
+	drivertest "github.com/opensds/opensds/contrib/drivers/testing"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+func TestBuildThrottleHints(t *testing.T) {
+	var meta = buildVolumeMetadata("/dev/vg001/volume001", &pb.Qos{
+		MaxIOPS:      1000,
+		MaxBandwidth: 100,
+	})
+	var expectedMeta = map[string]string{
+		"lvPath":       "/dev/vg001/volume001",
+		"maxIOPS":      "1000",
+		"maxBandwidth": "100",
+	}
+	if !reflect.DeepEqual(expectedMeta, meta) {
+		t.Fatalf("Expected %v, got %v", expectedMeta, meta)
+	}
+
+	var expectedHints = map[string]interface{}{
+		"blkio.throttle.read_iops_device":  int64(1000),
+		"blkio.throttle.write_iops_device": int64(1000),
+		"blkio.throttle.read_bps_device":   int64(104857600),
+		"blkio.throttle.write_bps_device":  int64(104857600),
+	}
+	if hints := buildThrottleHints(meta); !reflect.DeepEqual(expectedHints, hints) {
+		t.Fatalf("Expected %v, got %v", expectedHints, hints)
+	}
+
+	// Test if no hints would be built when no qos assigned.
+	meta = buildVolumeMetadata("/dev/vg001/volume001", nil)
+	if hints := buildThrottleHints(meta); len(hints) != 0 {
+		t.Fatalf("Expected no hints, got %v", hints)
+	}
+}
+
+func TestParseLvDisplay(t *testing.T) {
+	var out = `  --- Logical volume ---
+  LV Path                /dev/vg001/volume001
+  LV Name                volume001
+  VG Name                vg001
+  LV UUID                Gb0RgF-6TQd-tHyb-0YRl-yH4V-ekpm-IbQcdd
+  LV Write Access        read/write
+  LV Status              available
+  # open                 0
+  LV Size                1.50 GiB
+  Current LE             384
+`
+	var expected = &lvInfo{
+		name:   "volume001",
+		path:   "/dev/vg001/volume001",
+		status: "available",
+		size:   2,
+	}
+	if lv := parseLvDisplay(out); !reflect.DeepEqual(expected, lv) {
+		t.Fatalf("Expected %v, got %v", expected, lv)
+	}
+}
+
+func TestParseLvs(t *testing.T) {
+	var out = `  volume001,/dev/vg001/volume001,1.50,
+  snapshot001,/dev/vg001/snapshot001,1.00,volume001
+`
+	var expected = []*lvsInfo{
+		{
+			lvInfo: lvInfo{name: "volume001", path: "/dev/vg001/volume001", size: 2},
+		},
+		{
+			lvInfo: lvInfo{name: "snapshot001", path: "/dev/vg001/snapshot001", size: 1},
+			origin: "volume001",
+		},
+	}
+	if lvs := parseLvs(out); !reflect.DeepEqual(expected, lvs) {
+		t.Fatalf("Expected %v, got %v", expected, lvs)
+	}
+}
+
+// fakeLVM emulates the lvm commands called by the driver on volume group
+// vg001, whose logic volumes are kept in memory.
+type fakeLVM struct {
+	size   int64
+	lvs    map[string]*lvsInfo
+	errMsg string
+}
+
+func newFakeLVM(size int64) *fakeLVM {
+	return &fakeLVM{size: size, lvs: map[string]*lvsInfo{}}
+}
+
+func (f *fakeLVM) lv(path string) (*lvsInfo, error) {
+	name := strings.TrimPrefix(path, "/dev/"+vgName+"/")
+	if lv, ok := f.lvs[name]; ok {
+		return lv, nil
+	}
+	return nil, lvmError(fmt.Errorf("exit status 5: Failed to find logical volume \"%s/%s\"", vgName, name))
+}
+
+func (f *fakeLVM) execCmd(cmd string) (string, error) {
+	args := strings.Fields(cmd)
+	switch args[0] {
+	case "lvcreate":
+		name, size, origin := args[2], args[4], ""
+		if args[len(args)-2] == "-s" {
+			origin = strings.TrimPrefix(args[len(args)-1], "/dev/"+vgName+"/")
+		}
+		if _, ok := f.lvs[name]; ok {
+			return "", lvmError(fmt.Errorf("exit status 5: Logical Volume \"%s\" already exists in volume group \"%s\"", name, vgName))
+		}
+		var sz int64
+		fmt.Sscanf(size, "%dG", &sz)
+		f.lvs[name] = &lvsInfo{
+			lvInfo: lvInfo{name: name, path: "/dev/" + vgName + "/" + name, status: "available", size: sz},
+			origin: origin,
+		}
+		return "", nil
+	case "lvdisplay":
+		lv, err := f.lv(args[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("  LV Path %s\n  LV Name %s\n  LV Status %s\n  LV Size %d.00 GiB\n",
+			lv.path, lv.name, lv.status, lv.size), nil
+	case "lvremove":
+		lv, err := f.lv(args[2])
+		if err != nil {
+			return "", err
+		}
+		delete(f.lvs, lv.name)
+		return "", nil
+	case "lvs":
+		var out string
+		for _, lv := range f.lvs {
+			out += fmt.Sprintf("  %s,%s,%d.00,%s\n", lv.name, lv.path, lv.size, lv.origin)
+		}
+		return out, nil
+	case "vgdisplay":
+		var used int64
+		for _, lv := range f.lvs {
+			used += lv.size
+		}
+		return fmt.Sprintf("  VG Size               %d.00 GiB\n  Free  PE / Size       0 / %d.00 GiB\n",
+			f.size, f.size-used), nil
+	}
+	return "", errors.New("Unexpected command " + cmd)
+}
+
+// fakeTarget records the exported logic volumes instead of calling tgtadm.
+type fakeTarget map[string]bool
+
+func (t fakeTarget) CreateExport(path, initiator string) (map[string]interface{}, error) {
+	t[path] = true
+	return map[string]interface{}{"targetIQN": "iqn.2017-10.io.opensds:volume:00000001"}, nil
+}
+
+func (t fakeTarget) RemoveExport(path, initiator string) error {
+	if !t[path] {
+		return utils.NotFoundError("Can't find lun with path " + path)
+	}
+	delete(t, path)
+	return nil
+}
+
+func TestVolumeGroup(t *testing.T) {
+	defer monkey.UnpatchAll()
+	var cmds []string
+	monkey.Patch((*Driver).execCmd, func(_ *Driver, cmd string) (string, error) {
+		cmds = append(cmds, cmd)
+		return "  VG Size               100.00 GiB\n  Free  PE / Size       0 / 50.00 GiB\n", nil
+	})
+
+	d := &Driver{ConfigPath: "testdata/lvm.yaml"}
+	if err := d.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	pols, err := d.ListPools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pols) != 1 || pols[0].Name != "vg002" || pols[0].FreeCapacity != 50 {
+		t.Errorf("Expected pool of volume group vg002, got %v", pols)
+	}
+	d.ListVolumes()
+	expected := []string{"vgdisplay vg002", d.lvsCmd()}
+	if !reflect.DeepEqual(expected, cmds) || !strings.HasSuffix(d.lvsCmd(), " vg002") {
+		t.Errorf("Expected %v, got %v", expected, cmds)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	defer monkey.UnpatchAll()
+	lvm, target := newFakeLVM(100), fakeTarget{}
+	monkey.Patch((*Driver).execCmd, func(_ *Driver, cmd string) (string, error) {
+		return lvm.execCmd(cmd)
+	})
+
+	d := &Driver{
+		config: LVMConfig{Pool: map[string]PoolProperties{vgName: {DiskType: "SSD"}}},
+		target: target,
+	}
+	s := &drivertest.Suite{
+		Driver: d,
+		// The logic volumes are identified by their paths.
+		VolumeIdentifier: func(vol *model.VolumeSpec) string {
+			return vol.Metadata["lvPath"]
+		},
+		SnapshotIdentifier: func(snp *model.VolumeSnapshotSpec) string {
+			return snp.Metadata["lvsPath"]
+		},
+		MissingIdentifier: "/dev/" + vgName + "/not-exist",
+	}
+	s.Run(t)
+}
@@ -15,7 +15,7 @@
 package targets
 
 import (
-	"errors"
+	"github.com/opensds/opensds/pkg/utils"
 )
 
 const (
@@ -74,7 +74,7 @@ func (t *iscsiTarget) RemoveExport(path, initiator string) error {
 
 	lun := t.GetLun(path)
 	if lun == -1 {
-		return errors.New("Can't find lun with path " + path)
+		return utils.NotFoundError("Can't find lun with path " + path)
 	}
 	if err := t.RemoveLun(lun); err != nil {
 		return err
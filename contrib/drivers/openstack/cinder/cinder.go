@@ -31,21 +31,24 @@ import (
 	"github.com/gophercloud/gophercloud/openstack/blockstorage/extensions/volumeactions"
 	snapshotsv2 "github.com/gophercloud/gophercloud/openstack/blockstorage/v2/snapshots"
 	volumesv2 "github.com/gophercloud/gophercloud/openstack/blockstorage/v2/volumes"
+	osdsctx "github.com/opensds/opensds/pkg/context"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
 	"github.com/opensds/opensds/pkg/utils/config"
 	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 	"gopkg.in/yaml.v2"
 )
 
-var conf = CinderConfig{}
-
 type Driver struct {
 	// Current block storage version
 	blockStoragev2 *gophercloud.ServiceClient
 	blockStoragev3 *gophercloud.ServiceClient
 
 	config CinderConfig
+	// ConfigPath is the config file of the driver, the one in osdsdock
+	// section is used if it's empty.
+	ConfigPath string
 }
 
 type AuthOptions struct {
@@ -71,14 +74,19 @@ type CinderConfig struct {
 
 func (d *Driver) Setup() error {
 	// Read cinder config file
-	confYaml, err := ioutil.ReadFile(config.CONF.CinderConfig)
+	path := d.ConfigPath
+	if path == "" {
+		path = config.CONF.CinderConfig
+	}
+	confYaml, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Read cinder config yaml file (%s) failed, reason:(%v)", config.CONF.CinderConfig, err)
+		log.Fatalf("Read cinder config yaml file (%s) failed, reason:(%v)", path, err)
 		return err
 	}
+	var conf CinderConfig
 	err = yaml.Unmarshal([]byte(confYaml), &conf)
 	if err != nil {
-		log.Fatal("Parse error: %v", err)
+		log.Fatalf("Parse error: %v", err)
 		return err
 	}
 	d.config = conf
@@ -91,6 +99,9 @@ func (d *Driver) Setup() error {
 		Password:         d.config.Password,
 		TenantID:         d.config.TenantID,
 		TenantName:       d.config.TenantName,
+		// The client is kept as long as the driver, so it authenticates
+		// again once the token expires.
+		AllowReauth: true,
 	}
 
 	provider, err := openstack.AuthenticatedClient(opts)
@@ -109,7 +120,9 @@ func (d *Driver) Setup() error {
 
 func (d *Driver) Unset() error { return nil }
 
-func (d *Driver) CreateVolume(req *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+func (d *Driver) CreateVolume(ctx context.Context, req *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	//Configure create request body.
 	opts := &volumesv2.CreateOpts{
 		Name:             req.GetName(),
@@ -117,10 +130,18 @@ func (d *Driver) CreateVolume(req *pb.CreateVolumeOpts) (*model.VolumeSpec, erro
 		Size:             int(req.GetSize()),
 		AvailabilityZone: req.GetAvailabilityZone(),
 	}
+	if req.GetQos() != nil {
+		typeName, err := d.ensureQosType(req.GetQos())
+		if err != nil {
+			logger.Error("Cannot apply qos on volume:", err)
+			return nil, err
+		}
+		opts.VolumeType = typeName
+	}
 
 	vol, err := volumesv2.Create(d.blockStoragev2, opts).Extract()
 	if err != nil {
-		log.Error("Cannot create volume:", err)
+		logger.Error("Cannot create volume:", err)
 		return nil, err
 	}
 
@@ -155,16 +176,20 @@ func (d *Driver) PullVolume(volID string) (*model.VolumeSpec, error) {
 	}, nil
 }
 
-func (d *Driver) DeleteVolume(opt *pb.DeleteVolumeOpts) error {
+func (d *Driver) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := volumesv2.Delete(d.blockStoragev2, opt.GetId()).ExtractErr(); err != nil {
-		log.Error("Cannot delete volume:", err)
+		logger.Error("Cannot delete volume:", err)
 		return err
 	}
 
 	return nil
 }
 
-func (d *Driver) InitializeConnection(req *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+func (d *Driver) InitializeConnection(ctx context.Context, req *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	opts := &volumeactions.InitializeConnectionOpts{
 		IP:        req.HostInfo.GetIp(),
 		Host:      req.HostInfo.GetHost(),
@@ -176,7 +201,7 @@ func (d *Driver) InitializeConnection(req *pb.CreateAttachmentOpts) (*model.Conn
 
 	conn, err := volumeactions.InitializeConnection(d.blockStoragev2, req.GetVolumeId(), opts).Extract()
 	if err != nil {
-		log.Error("Cannot initialize volume connection:", err)
+		logger.Error("Cannot initialize volume connection:", err)
 		return nil, err
 	}
 
@@ -186,9 +211,13 @@ func (d *Driver) InitializeConnection(req *pb.CreateAttachmentOpts) (*model.Conn
 	}, nil
 }
 
-func (d *Driver) TerminateConnection(opt *pb.DeleteAttachmentOpts) error { return nil }
+func (d *Driver) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	return nil
+}
+
+func (d *Driver) CreateSnapshot(ctx context.Context, req *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
 
-func (d *Driver) CreateSnapshot(req *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
 	opts := &snapshotsv2.CreateOpts{
 		VolumeID:    req.GetVolumeId(),
 		Name:        req.GetName(),
@@ -197,7 +226,7 @@ func (d *Driver) CreateSnapshot(req *pb.CreateVolumeSnapshotOpts) (*model.Volume
 
 	snp, err := snapshotsv2.Create(d.blockStoragev2, opts).Extract()
 	if err != nil {
-		log.Error("Cannot create snapshot:", err)
+		logger.Error("Cannot create snapshot:", err)
 		return nil, err
 	}
 
@@ -232,22 +261,82 @@ func (d *Driver) PullSnapshot(snapID string) (*model.VolumeSnapshotSpec, error)
 	}, nil
 }
 
-func (d *Driver) DeleteSnapshot(req *pb.DeleteVolumeSnapshotOpts) error {
+func (d *Driver) DeleteSnapshot(ctx context.Context, req *pb.DeleteVolumeSnapshotOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := snapshotsv2.Delete(d.blockStoragev2, req.GetId()).ExtractErr(); err != nil {
-		log.Error("Cannot delete snapshot:", err)
+		logger.Error("Cannot delete snapshot:", err)
 		return err
 	}
 
 	return nil
 }
 
+func (d *Driver) ListVolumes() ([]*model.VolumeSpec, error) {
+	pages, err := volumesv2.List(d.blockStoragev2, volumesv2.ListOpts{}).AllPages()
+	if err != nil {
+		log.Error("Cannot list volumes:", err)
+		return nil, err
+	}
+	vols, err := volumesv2.ExtractVolumes(pages)
+	if err != nil {
+		log.Error("Cannot extract volumes:", err)
+		return nil, err
+	}
+
+	var res []*model.VolumeSpec
+	for _, vol := range vols {
+		res = append(res, &model.VolumeSpec{
+			BaseModel: &model.BaseModel{
+				Id: vol.ID,
+			},
+			Name:             vol.Name,
+			Description:      vol.Description,
+			Size:             int64(vol.Size),
+			AvailabilityZone: vol.AvailabilityZone,
+			Status:           vol.Status,
+		})
+	}
+	return res, nil
+}
+
+func (d *Driver) ListSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+	pages, err := snapshotsv2.List(d.blockStoragev2, snapshotsv2.ListOpts{}).AllPages()
+	if err != nil {
+		log.Error("Cannot list snapshots:", err)
+		return nil, err
+	}
+	snps, err := snapshotsv2.ExtractSnapshots(pages)
+	if err != nil {
+		log.Error("Cannot extract snapshots:", err)
+		return nil, err
+	}
+
+	var res []*model.VolumeSnapshotSpec
+	for _, snp := range snps {
+		res = append(res, &model.VolumeSnapshotSpec{
+			BaseModel: &model.BaseModel{
+				Id: snp.ID,
+			},
+			Name:        snp.Name,
+			Description: snp.Description,
+			Size:        int64(snp.Size),
+			Status:      snp.Status,
+			VolumeId:    snp.VolumeID,
+		})
+	}
+	return res, nil
+}
+
 func (d *Driver) buildPoolParam(proper PoolProperties) *map[string]interface{} {
 	param := make(map[string]interface{})
 	param["diskType"] = proper.DiskType
 	param["iops"] = proper.IOPS
 	param["bandwidth"] = proper.BandWidth
+	param[model.QosSupportedKey] = true
 	return &param
 }
+
 func (d *Driver) ListPools() ([]*model.StoragePoolSpec, error) {
 	opts := &schedulerstats.ListOpts{}
 
@@ -23,6 +23,9 @@ package cinder
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/bouk/monkey"
@@ -34,6 +37,7 @@ import (
 	"github.com/gophercloud/gophercloud/pagination"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/utils/config"
+	"golang.org/x/net/context"
 )
 
 func TestSetup(t *testing.T) {
@@ -73,6 +77,9 @@ func TestSetup(t *testing.T) {
 	if opt.TenantName != "admin" {
 		t.Error("TenantName error.")
 	}
+	if !opt.AllowReauth {
+		t.Error("AllowReauth error.")
+	}
 
 	if d.config.Pool["pool1"].DiskType != "SSD" {
 		t.Error("Test config pool1 DiskType error")
@@ -146,7 +153,7 @@ func TestCreateVolume(t *testing.T) {
 		Size:             2,
 	}
 	d := Driver{}
-	resp, err := d.CreateVolume(opt)
+	resp, err := d.CreateVolume(context.Background(), opt)
 
 	if err != nil {
 		t.Error("Create volume error")
@@ -215,7 +222,7 @@ func TestDeleteVolume(t *testing.T) {
 		Id: "6edbc2f4-1507-44f8-ac0d-eed1d2608d38",
 	}
 	d := Driver{}
-	err := d.DeleteVolume(opt)
+	err := d.DeleteVolume(context.Background(), opt)
 	if err != nil {
 		t.Error("Delete volume error")
 	}
@@ -251,7 +258,7 @@ func TestCreateSnapshot(t *testing.T) {
 		VolumeId:    "5aa119a8-d25b-45a7-8d1b-88e127885635",
 	}
 	d := Driver{}
-	resp, err := d.CreateSnapshot(opt)
+	resp, err := d.CreateSnapshot(context.Background(), opt)
 	if err != nil {
 		t.Error("Create volume snapshot error")
 	}
@@ -312,7 +319,7 @@ func TestDeleteSnapshot(t *testing.T) {
 		})
 	opt := &pb.DeleteVolumeSnapshotOpts{Id: "2bb856e1-b3d8-4432-a858-09e4ce939389"}
 	d := Driver{}
-	err := d.DeleteSnapshot(opt)
+	err := d.DeleteSnapshot(context.Background(), opt)
 	if err != nil {
 		t.Error("Delete volume snapshot error")
 	}
@@ -402,3 +409,54 @@ func TestListPools(t *testing.T) {
 	}
 }
 
+func TestEnsureQosTypeRollback(t *testing.T) {
+	var deleted []string
+	var associateStatus = http.StatusInternalServerError
+	mux := http.NewServeMux()
+	mux.HandleFunc("/types", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`{"volume_types": []}`))
+			return
+		}
+		w.Write([]byte(`{"volume_type": {"id": "type-1"}}`))
+	})
+	mux.HandleFunc("/qos-specs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"qos_specs": {"id": "qos-1"}}`))
+	})
+	mux.HandleFunc("/qos-specs/qos-1/associate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(associateStatus)
+	})
+	for _, path := range []string{"/types/type-1", "/qos-specs/qos-1"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			deleted = append(deleted, r.Method+" "+r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		})
+	}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	d := &Driver{blockStoragev2: &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       srv.URL + "/",
+	}}
+	qos := &pb.Qos{MaxIOPS: 1000}
+
+	// Test if the volume type and qos specs are deleted when they fail to be
+	// associated.
+	if _, err := d.ensureQosType(qos); err == nil {
+		t.Error("Expected an error when associating qos specs failed")
+	}
+	expected := []string{"DELETE /qos-specs/qos-1", "DELETE /types/type-1"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("Expected %v, got %v", expected, deleted)
+	}
+
+	deleted, associateStatus = nil, http.StatusOK
+	name, err := d.ensureQosType(qos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != qosTypeName(qos) || len(deleted) != 0 {
+		t.Errorf("Unexpected volume type %s, deleted %v", name, deleted)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package cinder
+
+import (
+	"fmt"
+
+	log "github.com/golang/glog"
+	"github.com/gophercloud/gophercloud"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+)
+
+const qosTypePrefix = "opensds-qos"
+
+type volumeType struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type qosSpecs struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// qosTypeName returns the name of volume type (and the qos specs associated
+// with it) which represents the given qos limits.
+func qosTypeName(qos *pb.Qos) string {
+	return fmt.Sprintf("%s-iops-%d-bw-%d", qosTypePrefix, qos.GetMaxIOPS(), qos.GetMaxBandwidth())
+}
+
+// buildQosSpecs converts the qos limits into cinder front-end qos specs.
+func buildQosSpecs(name string, qos *pb.Qos) map[string]string {
+	var specs = map[string]string{
+		"name":     name,
+		"consumer": "front-end",
+	}
+	if qos.GetMaxIOPS() > 0 {
+		specs["total_iops_sec"] = fmt.Sprint(qos.GetMaxIOPS())
+	}
+	if qos.GetMaxBandwidth() > 0 {
+		// Bandwidth is configured in MB/s but cinder expects bytes/s.
+		specs["total_bytes_sec"] = fmt.Sprint(qos.GetMaxBandwidth() << 20)
+	}
+	return specs
+}
+
+// ensureQosType makes sure there is a volume type associated with the qos
+// specs representing the given qos limits, since cinder could only apply qos
+// through volume types. It returns the name of the volume type.
+func (d *Driver) ensureQosType(qos *pb.Qos) (string, error) {
+	var client = d.blockStoragev2
+	var name = qosTypeName(qos)
+
+	var typesResp struct {
+		VolumeTypes []volumeType `json:"volume_types"`
+	}
+	if _, err := client.Get(client.ServiceURL("types"), &typesResp, nil); err != nil {
+		log.Error("Cannot list volume types:", err)
+		return "", err
+	}
+	for _, vt := range typesResp.VolumeTypes {
+		if vt.Name == name {
+			return name, nil
+		}
+	}
+
+	var typeResp struct {
+		VolumeType volumeType `json:"volume_type"`
+	}
+	typeBody := map[string]interface{}{
+		"volume_type": map[string]string{"name": name},
+	}
+	// Cinder responds to the creation of volume types and qos specs with 200.
+	if _, err := client.Post(client.ServiceURL("types"), typeBody, &typeResp, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	}); err != nil {
+		log.Error("Cannot create volume type:", err)
+		return "", err
+	}
+
+	var qosResp struct {
+		QosSpecs qosSpecs `json:"qos_specs"`
+	}
+	qosBody := map[string]interface{}{
+		"qos_specs": buildQosSpecs(name, qos),
+	}
+	if _, err := client.Post(client.ServiceURL("qos-specs"), qosBody, &qosResp, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	}); err != nil {
+		log.Error("Cannot create qos specs:", err)
+		d.deleteQosType(typeResp.VolumeType.ID, "")
+		return "", err
+	}
+
+	url := client.ServiceURL("qos-specs", qosResp.QosSpecs.ID, "associate") +
+		"?vol_type_id=" + typeResp.VolumeType.ID
+	if _, err := client.Get(url, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{200, 202},
+	}); err != nil {
+		log.Error("Cannot associate qos specs with volume type:", err)
+		d.deleteQosType(typeResp.VolumeType.ID, qosResp.QosSpecs.ID)
+		return "", err
+	}
+
+	return name, nil
+}
+
+// deleteQosType removes the volume type and the qos specs which are created
+// by ensureQosType if it fails halfway, otherwise the volume type would be
+// found next time without the qos specs associated. The qos specs are skipped
+// if qosID is empty.
+func (d *Driver) deleteQosType(typeID, qosID string) {
+	var client = d.blockStoragev2
+
+	if qosID != "" {
+		if _, err := client.Delete(client.ServiceURL("qos-specs", qosID), nil); err != nil {
+			log.Errorf("Cannot delete qos specs %s: %v\n", qosID, err)
+		}
+	}
+	if _, err := client.Delete(client.ServiceURL("types", typeID), nil); err != nil {
+		log.Errorf("Cannot delete volume type %s: %v\n", typeID, err)
+	}
+}
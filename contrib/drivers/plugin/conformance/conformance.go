@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the conformance tests of volume driver plugins. A
+plugin runs them in its own tests against the socket it serves on, which
+checks the plugin through the same proxy driver as osdsdock uses:
+
+	func TestConformance(t *testing.T) {
+		go sdk.Serve(socket, &vendor.Driver{})
+		conformance.Run(t, socket)
+	}
+
+The tests are the conformance test suite of in-tree drivers, see the package
+contrib/drivers/testing for the lifecycles and error semantics checked.
+
+*/
+
+package conformance
+
+import (
+	"testing"
+
+	"github.com/opensds/opensds/contrib/drivers/plugin"
+	drivertest "github.com/opensds/opensds/contrib/drivers/testing"
+)
+
+// Run runs the conformance tests against the plugin listening on socket.
+func Run(t *testing.T, socket string) {
+	RunSuite(t, socket, &drivertest.Suite{})
+}
+
+// RunSuite runs the conformance tests with the settings of suite, whose
+// driver is set to the proxy driver of plugin.
+func RunSuite(t *testing.T, socket string, s *drivertest.Suite) {
+	d := plugin.NewDriver(plugin.Prefix+socket, "")
+	if err := d.Setup(); err != nil {
+		t.Fatal("Setup plugin failed:", err)
+	}
+	defer func() {
+		if err := d.Unset(); err != nil {
+			t.Error("Unset plugin failed:", err)
+		}
+	}()
+
+	s.Driver = d
+	s.Run(t)
+}
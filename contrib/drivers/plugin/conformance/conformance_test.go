@@ -0,0 +1,119 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package conformance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensds/opensds/contrib/drivers/plugin"
+	"github.com/opensds/opensds/contrib/drivers/plugin/sdk"
+	"github.com/opensds/opensds/contrib/drivers/sample"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
+)
+
+func TestSamplePlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "sample.sock")
+
+	errs := make(chan error, 1)
+	go func() { errs <- sdk.Serve(socket, &sample.Driver{}) }()
+
+	Run(t, socket)
+
+	// The errors of driver are returned by the proxy driver as they are.
+	d := plugin.NewDriver(plugin.Prefix+socket, "")
+	defer d.Unset()
+	_, err = d.PullVolume("not-exist")
+	if !utils.IsNotFound(err) || err.Error() != "Can't find volume not-exist" {
+		t.Errorf("Expected error of sample driver, got %v", err)
+	}
+	if _, err = d.CreateVolume(context.Background(), &pb.CreateVolumeOpts{Size: 1000}); err == nil || utils.IsNotFound(err) {
+		t.Errorf("Expected error of insufficient capacity, got %v", err)
+	}
+
+	select {
+	case err = <-errs:
+		t.Error("Plugin stopped serving:", err)
+	default:
+	}
+}
+
+// configuredDriver records the config path and the calls of Setup and Unset.
+type configuredDriver struct {
+	sample.Driver
+	configPath   string
+	setup, unset int
+}
+
+func (d *configuredDriver) SetConfigPath(path string) { d.configPath = path }
+
+func (d *configuredDriver) Setup() error { d.setup++; return nil }
+
+func (d *configuredDriver) Unset() error { d.unset++; return nil }
+
+func TestSharedPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "configured.sock")
+
+	vendor := &configuredDriver{}
+	go sdk.Serve(socket, vendor)
+
+	const configPath = "/etc/opensds/driver/vendor.yaml"
+	d1 := plugin.NewDriver(plugin.Prefix+socket, configPath)
+	d2 := plugin.NewDriver(plugin.Prefix+socket, configPath)
+	if err = d1.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if err = d2.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if vendor.configPath != configPath || vendor.setup != 1 {
+		t.Errorf("Expected plugin set up once with %s, got %d times with %q",
+			configPath, vendor.setup, vendor.configPath)
+	}
+
+	// The plugin set up with another config can't be shared.
+	d3 := plugin.NewDriver(plugin.Prefix+socket, "/etc/opensds/driver/other.yaml")
+	if err = d3.Setup(); err == nil {
+		t.Error("Expected error of plugin set up with another config")
+	}
+
+	// The connection is kept until the last driver of plugin is unset.
+	if err = d1.Unset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d2.ListPools(); err != nil || vendor.unset != 0 {
+		t.Errorf("Expected plugin kept for the other driver, got %v and %d unset", err, vendor.unset)
+	}
+	if err = d2.Unset(); err != nil {
+		t.Fatal(err)
+	}
+	if vendor.unset != 1 {
+		t.Errorf("Expected plugin unset once, got %d", vendor.unset)
+	}
+}
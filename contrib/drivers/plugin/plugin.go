@@ -0,0 +1,332 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the proxy driver of out-of-tree volume driver plugins.
+A plugin serves the Driver protocol defined in dock.proto on a unix socket,
+and is selected by setting "driver_name = plugin:<socket>" in the backend
+section of configuration.
+
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/opensds/opensds/contrib/drivers/plugin/sdk"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Prefix is the prefix of driver name which selects a plugin, followed by the
+// path of its unix socket.
+const Prefix = "plugin:"
+
+// IsPlugin returns whether the driver name selects a plugin.
+func IsPlugin(driverName string) bool {
+	return strings.HasPrefix(driverName, Prefix)
+}
+
+// setupTimeout is how long Setup waits for the plugin to listen on its socket,
+// since the plugin may be started along with the dock.
+const setupTimeout = 30 * time.Second
+
+// The connections are shared by all drivers of the same plugin, so that the
+// plugin is set up only once when it's connected first, and unset when the
+// last driver of it is unset.
+var (
+	connsLock sync.Mutex
+	conns     = map[string]*pluginConn{}
+)
+
+type pluginConn struct {
+	conn       *grpc.ClientConn
+	client     pb.DriverClient
+	configPath string
+	refs       int
+}
+
+// Driver forwards the calls of VolumeDriver to the plugin.
+type Driver struct {
+	// Socket is the path of unix socket which the plugin listens on.
+	Socket string
+	// ConfigPath is the path of configuration file given to the plugin when
+	// it's set up.
+	ConfigPath string
+
+	// client is guarded by connsLock.
+	client pb.DriverClient
+}
+
+// NewDriver returns the proxy driver of the plugin selected by driver name,
+// which gives configPath to the plugin.
+func NewDriver(driverName, configPath string) *Driver {
+	return &Driver{
+		Socket:     strings.TrimPrefix(driverName, Prefix),
+		ConfigPath: configPath,
+	}
+}
+
+func dialUnix(addr string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", addr, timeout)
+}
+
+func (d *Driver) Setup() error {
+	connsLock.Lock()
+	defer connsLock.Unlock()
+
+	return d.setup()
+}
+
+// setup connects the plugin unless the driver has done, and must be called
+// with connsLock held.
+func (d *Driver) setup() error {
+	if d.client != nil {
+		return nil
+	}
+	if pc, ok := conns[d.Socket]; ok {
+		if pc.configPath != d.ConfigPath {
+			return fmt.Errorf("Plugin %s is set up with config %q, not %q",
+				d.Socket, pc.configPath, d.ConfigPath)
+		}
+		pc.refs++
+		d.client = pc.client
+		return nil
+	}
+
+	conn, err := grpc.Dial(d.Socket, grpc.WithInsecure(), grpc.WithDialer(dialUnix))
+	if err != nil {
+		log.Errorf("When connecting to plugin %s: %v", d.Socket, err)
+		return err
+	}
+	client := pb.NewDriverClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+	opt := &pb.DriverOpts{ConfigPath: d.ConfigPath}
+	res, err := client.Setup(ctx, opt, grpc.FailFast(false))
+	if err = decode(res, err, nil); err != nil {
+		log.Errorf("When setting up plugin %s: %v", d.Socket, err)
+		conn.Close()
+		return err
+	}
+	conns[d.Socket] = &pluginConn{
+		conn:       conn,
+		client:     client,
+		configPath: d.ConfigPath,
+		refs:       1,
+	}
+	d.client = client
+	return nil
+}
+
+// Unset releases the driver, and the last driver of the plugin releases the
+// plugin and closes the connection shared by them.
+func (d *Driver) Unset() error {
+	connsLock.Lock()
+	defer connsLock.Unlock()
+
+	if d.client == nil {
+		return nil
+	}
+	d.client = nil
+	pc, ok := conns[d.Socket]
+	if !ok {
+		return nil
+	}
+	if pc.refs--; pc.refs > 0 {
+		return nil
+	}
+	delete(conns, d.Socket)
+	defer pc.conn.Close()
+
+	opt := &pb.DriverOpts{ConfigPath: pc.configPath}
+	res, err := pc.client.Unset(context.Background(), opt)
+	return decode(res, err, nil)
+}
+
+// driver returns the client of the plugin, which is set up if it isn't yet.
+func (d *Driver) driver() (pb.DriverClient, error) {
+	connsLock.Lock()
+	defer connsLock.Unlock()
+
+	if err := d.setup(); err != nil {
+		return nil, err
+	}
+	return d.client, nil
+}
+
+// decode returns the error of the call or the plugin, or decodes the result
+// into v unless v is nil.
+func decode(res *pb.GenericResponse, err error, v interface{}) error {
+	if err != nil {
+		return err
+	}
+	if e := res.GetError(); e != nil {
+		switch e.GetCode() {
+		case sdk.CodeNotFound:
+			return utils.NotFoundError(e.GetDescription())
+		case sdk.CodeAlreadyExists:
+			return utils.AlreadyExistsError(e.GetDescription())
+		}
+		return errors.New(e.GetDescription())
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(res.GetResult().GetMessage()), v)
+}
+
+func (d *Driver) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var vol = &model.VolumeSpec{}
+	res, err := client.CreateVolume(ctx, opt)
+	if err = decode(res, err, vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func (d *Driver) PullVolume(volIdentifier string) (*model.VolumeSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var vol = &model.VolumeSpec{}
+	opt := &pb.PullOpts{Identifier: volIdentifier}
+	res, err := client.PullVolume(context.Background(), opt)
+	if err = decode(res, err, vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func (d *Driver) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	client, err := d.driver()
+	if err != nil {
+		return err
+	}
+	res, err := client.DeleteVolume(ctx, opt)
+	return decode(res, err, nil)
+}
+
+func (d *Driver) InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var info = &model.ConnectionInfo{}
+	res, err := client.InitializeConnection(ctx, opt)
+	if err = decode(res, err, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (d *Driver) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	client, err := d.driver()
+	if err != nil {
+		return err
+	}
+	res, err := client.TerminateConnection(ctx, opt)
+	return decode(res, err, nil)
+}
+
+func (d *Driver) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var snp = &model.VolumeSnapshotSpec{}
+	res, err := client.CreateSnapshot(ctx, opt)
+	if err = decode(res, err, snp); err != nil {
+		return nil, err
+	}
+	return snp, nil
+}
+
+func (d *Driver) PullSnapshot(snapIdentifier string) (*model.VolumeSnapshotSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var snp = &model.VolumeSnapshotSpec{}
+	opt := &pb.PullOpts{Identifier: snapIdentifier}
+	res, err := client.PullSnapshot(context.Background(), opt)
+	if err = decode(res, err, snp); err != nil {
+		return nil, err
+	}
+	return snp, nil
+}
+
+func (d *Driver) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error {
+	client, err := d.driver()
+	if err != nil {
+		return err
+	}
+	res, err := client.DeleteSnapshot(ctx, opt)
+	return decode(res, err, nil)
+}
+
+func (d *Driver) ListPools() ([]*model.StoragePoolSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var pols []*model.StoragePoolSpec
+	res, err := client.ListPools(context.Background(), &pb.DriverOpts{})
+	if err = decode(res, err, &pols); err != nil {
+		return nil, err
+	}
+	return pols, nil
+}
+
+func (d *Driver) ListVolumes() ([]*model.VolumeSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var vols []*model.VolumeSpec
+	res, err := client.ListVolumes(context.Background(), &pb.DriverOpts{})
+	if err = decode(res, err, &vols); err != nil {
+		return nil, err
+	}
+	return vols, nil
+}
+
+func (d *Driver) ListSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+	client, err := d.driver()
+	if err != nil {
+		return nil, err
+	}
+	var snps []*model.VolumeSnapshotSpec
+	res, err := client.ListSnapshots(context.Background(), &pb.DriverOpts{})
+	if err = decode(res, err, &snps); err != nil {
+		return nil, err
+	}
+	return snps, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the SDK of out-of-tree volume driver plugins. A plugin
+implements VolumeDriver and serves it on a unix socket, for example:
+
+	func main() {
+		if err := sdk.Serve("/var/run/opensds/vendor.sock", &vendor.Driver{}); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+Then osdsdock uses the plugin by setting "driver_name = plugin:<socket>" in
+the backend section of configuration.
+
+*/
+
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// VolumeDriver is the interface which plugins implement. It's the same as
+// the VolumeDriver of osdsdock, without depending on the in-tree drivers.
+type VolumeDriver interface {
+	//Any initialization the volume driver does while starting.
+	Setup() error
+	//Any operation the volume driver does while stoping.
+	Unset() error
+
+	CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error)
+
+	PullVolume(volIdentifier string) (*model.VolumeSpec, error)
+
+	DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error
+
+	InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error)
+
+	TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error
+
+	CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error)
+
+	PullSnapshot(snapIdentifier string) (*model.VolumeSnapshotSpec, error)
+
+	DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error
+
+	ListPools() ([]*model.StoragePoolSpec, error)
+
+	ListVolumes() ([]*model.VolumeSpec, error)
+
+	ListSnapshots() ([]*model.VolumeSnapshotSpec, error)
+}
+
+// Configurable is implemented by plugins which read the config_path of the
+// backend, which is given to the plugin before it's set up.
+type Configurable interface {
+	SetConfigPath(path string)
+}
+
+// Serve listens on the unix socket and serves the driver until it fails. The
+// socket file left by the last run is removed first.
+func Serve(socket string, d VolumeDriver) error {
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	s := grpc.NewServer()
+	pb.RegisterDriverServer(s, NewServer(d))
+	return s.Serve(lis)
+}
+
+// NewServer returns the server of Driver protocol which calls the driver,
+// it could be registered to a grpc server made by the plugin itself.
+func NewServer(d VolumeDriver) pb.DriverServer {
+	return &server{d: d}
+}
+
+type server struct {
+	d VolumeDriver
+}
+
+// The codes of errors in responses, so that the proxy driver could return
+// the same errors as the driver.
+const (
+	CodeBadRequest    = "400"
+	CodeNotFound      = "404"
+	CodeAlreadyExists = "409"
+)
+
+// response returns the error of driver, or the result encoded in json.
+func response(result interface{}, err error) (*pb.GenericResponse, error) {
+	if err != nil {
+		var code = CodeBadRequest
+		switch err.(type) {
+		case utils.NotFoundError:
+			code = CodeNotFound
+		case utils.AlreadyExistsError:
+			code = CodeAlreadyExists
+		}
+		return &pb.GenericResponse{
+			Reply: &pb.GenericResponse_Error_{
+				Error: &pb.GenericResponse_Error{
+					Code:        code,
+					Description: fmt.Sprint(err),
+				},
+			},
+		}, nil
+	}
+
+	var msg string
+	if result != nil {
+		msgJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		msg = string(msgJSON)
+	}
+	return &pb.GenericResponse{
+		Reply: &pb.GenericResponse_Result_{
+			Result: &pb.GenericResponse_Result{Message: msg},
+		},
+	}, nil
+}
+
+func (s *server) Setup(ctx context.Context, opt *pb.DriverOpts) (*pb.GenericResponse, error) {
+	if c, ok := s.d.(Configurable); ok {
+		c.SetConfigPath(opt.GetConfigPath())
+	}
+	return response(nil, s.d.Setup())
+}
+
+func (s *server) Unset(ctx context.Context, opt *pb.DriverOpts) (*pb.GenericResponse, error) {
+	return response(nil, s.d.Unset())
+}
+
+func (s *server) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*pb.GenericResponse, error) {
+	return response(s.d.CreateVolume(ctx, opt))
+}
+
+func (s *server) PullVolume(ctx context.Context, opt *pb.PullOpts) (*pb.GenericResponse, error) {
+	return response(s.d.PullVolume(opt.GetIdentifier()))
+}
+
+func (s *server) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) (*pb.GenericResponse, error) {
+	return response(nil, s.d.DeleteVolume(ctx, opt))
+}
+
+func (s *server) InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*pb.GenericResponse, error) {
+	return response(s.d.InitializeConnection(ctx, opt))
+}
+
+func (s *server) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) (*pb.GenericResponse, error) {
+	return response(nil, s.d.TerminateConnection(ctx, opt))
+}
+
+func (s *server) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*pb.GenericResponse, error) {
+	return response(s.d.CreateSnapshot(ctx, opt))
+}
+
+func (s *server) PullSnapshot(ctx context.Context, opt *pb.PullOpts) (*pb.GenericResponse, error) {
+	return response(s.d.PullSnapshot(opt.GetIdentifier()))
+}
+
+func (s *server) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) (*pb.GenericResponse, error) {
+	return response(nil, s.d.DeleteSnapshot(ctx, opt))
+}
+
+func (s *server) ListPools(ctx context.Context, opt *pb.DriverOpts) (*pb.GenericResponse, error) {
+	return response(s.d.ListPools())
+}
+
+func (s *server) ListVolumes(ctx context.Context, opt *pb.DriverOpts) (*pb.GenericResponse, error) {
+	return response(s.d.ListVolumes())
+}
+
+func (s *server) ListSnapshots(ctx context.Context, opt *pb.DriverOpts) (*pb.GenericResponse, error) {
+	return response(s.d.ListSnapshots())
+}
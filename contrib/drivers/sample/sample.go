@@ -13,75 +13,355 @@
 //    under the License.
 
 /*
-This module implements a sample driver for OpenSDS. This driver will handle all
-operations of volume and return a fake value.
+This module implements a sample driver for OpenSDS. This driver simulates a
+storage backend in memory, which keeps the volumes, snapshots and exports of
+its pools and enforces the capacity of pools. The latency and failures of the
+calls could be injected by the sample section of configuration, so that the
+paths of scheduler, quota and rollback could be exercised without storage.
 
 */
 
 package sample
 
 import (
-	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
 
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/opensds/opensds/pkg/utils/config"
+	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 )
 
+// Driver keeps nothing itself. The simulated backend is shared by all drivers
+// in the process, since the dock creates a driver for every call.
 type Driver struct{}
 
+// backend is the state of the simulated storage.
+type backend struct {
+	lock      sync.Mutex
+	pools     []*model.StoragePoolSpec
+	volumes   map[string]*model.VolumeSpec
+	snapshots map[string]*model.VolumeSnapshotSpec
+	// exports are the hosts which each volume is exported to.
+	exports map[string]map[string]bool
+}
+
+func newBackend() *backend {
+	b := &backend{
+		volumes:   map[string]*model.VolumeSpec{},
+		snapshots: map[string]*model.VolumeSnapshotSpec{},
+		exports:   map[string]map[string]bool{},
+	}
+	for i := range samplePools {
+		pol := samplePools[i]
+		pol.FreeCapacity = pol.TotalCapacity
+		b.pools = append(b.pools, &pol)
+	}
+	return b
+}
+
+var be = newBackend()
+
+// inject delays the call and fails it as configured in the sample section.
+func inject(op string) error {
+	conf := config.CONF.Sample
+	if conf.Latency > 0 {
+		time.Sleep(time.Duration(conf.Latency) * time.Millisecond)
+	}
+	for _, name := range conf.FailOperations {
+		if name == op {
+			return fmt.Errorf("Injected failure of %s", op)
+		}
+	}
+	if conf.FailureRate > 0 && rand.Intn(100) < conf.FailureRate {
+		return fmt.Errorf("Injected random failure of %s", op)
+	}
+	return nil
+}
+
+func (b *backend) pool(polID string) *model.StoragePoolSpec {
+	for _, pol := range b.pools {
+		if pol.GetId() == polID {
+			return pol
+		}
+	}
+	return nil
+}
+
+// allocate takes the capacity from the pool, or from the first pool which
+// has enough capacity if no pool is given.
+func (b *backend) allocate(polID string, size int64) (*model.StoragePoolSpec, error) {
+	if polID == "" {
+		for _, pol := range b.pools {
+			if pol.FreeCapacity >= size {
+				pol.FreeCapacity -= size
+				return pol, nil
+			}
+		}
+		return nil, fmt.Errorf("No pool has %dGB free capacity", size)
+	}
+
+	pol := b.pool(polID)
+	if pol == nil {
+		return nil, utils.NotFoundError("Can't find pool " + polID)
+	}
+	if pol.FreeCapacity < size {
+		return nil, fmt.Errorf("Pool %s has %dGB free capacity, %dGB is requested", polID, pol.FreeCapacity, size)
+	}
+	pol.FreeCapacity -= size
+	return pol, nil
+}
+
+func (b *backend) release(polID string, size int64) {
+	if pol := b.pool(polID); pol != nil {
+		pol.FreeCapacity += size
+	}
+}
+
 func (*Driver) Setup() error { return nil }
 
 func (*Driver) Unset() error { return nil }
 
-func (*Driver) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
-	return &sampleVolume, nil
+func (*Driver) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	if err := inject("create_volume"); err != nil {
+		return nil, err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	if snpID := opt.GetSnapshotId(); snpID != "" {
+		snp, ok := be.snapshots[snpID]
+		if !ok {
+			return nil, utils.NotFoundError("Can't find snapshot " + snpID)
+		}
+		if opt.GetSize() < snp.Size {
+			return nil, fmt.Errorf("Volume size %dGB is smaller than snapshot size %dGB", opt.GetSize(), snp.Size)
+		}
+	}
+	id := opt.GetId()
+	if id == "" {
+		id = uuid.NewV4().String()
+	}
+	if _, ok := be.volumes[id]; ok {
+		return nil, utils.AlreadyExistsError("Volume " + id + " already exists")
+	}
+	pol, err := be.allocate(opt.GetPoolId(), opt.GetSize())
+	if err != nil {
+		return nil, err
+	}
+
+	vol := &model.VolumeSpec{
+		BaseModel:        &model.BaseModel{Id: id},
+		Name:             opt.GetName(),
+		Description:      opt.GetDescription(),
+		Size:             opt.GetSize(),
+		AvailabilityZone: opt.GetAvailabilityZone(),
+		Status:           model.VolumeAvailable,
+		PoolId:           pol.GetId(),
+	}
+	be.volumes[id] = vol
+	copied := *vol
+	return &copied, nil
 }
 
 func (*Driver) PullVolume(volIdentifier string) (*model.VolumeSpec, error) {
-	if volIdentifier == sampleVolume.GetId() {
-		return &sampleVolume, nil
+	if err := inject("pull_volume"); err != nil {
+		return nil, err
 	}
 
-	return nil, errors.New("Can't find volume " + volIdentifier)
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	if vol, ok := be.volumes[volIdentifier]; ok {
+		copied := *vol
+		return &copied, nil
+	}
+	return nil, utils.NotFoundError("Can't find volume " + volIdentifier)
 }
 
-func (*Driver) DeleteVolume(opt *pb.DeleteVolumeOpts) error {
+// DeleteVolume succeeds if the volume doesn't exist, so that it could be
+// retried safely.
+func (*Driver) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	if err := inject("delete_volume"); err != nil {
+		return err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	vol, ok := be.volumes[opt.GetId()]
+	if !ok {
+		return nil
+	}
+	if len(be.exports[vol.Id]) != 0 {
+		return fmt.Errorf("Volume %s is still exported", vol.Id)
+	}
+	for _, snp := range be.snapshots {
+		if snp.VolumeId == vol.Id {
+			return fmt.Errorf("Volume %s still has snapshot %s", vol.Id, snp.Id)
+		}
+	}
+	be.release(vol.PoolId, vol.Size)
+	delete(be.volumes, vol.Id)
 	return nil
 }
 
-func (*Driver) InitializeConnection(opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
-	return &sampleConnection, nil
+func (*Driver) InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+	if err := inject("initialize_connection"); err != nil {
+		return nil, err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	volID := opt.GetVolumeId()
+	if _, ok := be.volumes[volID]; !ok {
+		return nil, utils.NotFoundError("Can't find volume " + volID)
+	}
+	if be.exports[volID] == nil {
+		be.exports[volID] = map[string]bool{}
+	}
+	be.exports[volID][opt.GetHostInfo().GetHost()] = true
+
+	return &model.ConnectionInfo{
+		DriverVolumeType: sampleConnection.DriverVolumeType,
+		ConnectionData: map[string]interface{}{
+			"targetDiscovered": true,
+			"targetIqn":        "iqn.2017-10.io.opensds:volume:" + volID,
+			"targetPortal":     "127.0.0.1:3260",
+			"discard":          false,
+		},
+	}, nil
 }
 
-func (*Driver) TerminateConnection(opt *pb.DeleteAttachmentOpts) error { return nil }
+// TerminateConnection succeeds if the volume isn't exported to the host.
+func (*Driver) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	if err := inject("terminate_connection"); err != nil {
+		return err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	delete(be.exports[opt.GetVolumeId()], opt.GetHostInfo().GetHost())
+	return nil
+}
+
+func (*Driver) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	if err := inject("create_snapshot"); err != nil {
+		return nil, err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	vol, ok := be.volumes[opt.GetVolumeId()]
+	if !ok {
+		return nil, utils.NotFoundError("Can't find volume " + opt.GetVolumeId())
+	}
+	id := opt.GetId()
+	if id == "" {
+		id = uuid.NewV4().String()
+	}
+	if _, ok := be.snapshots[id]; ok {
+		return nil, utils.AlreadyExistsError("Snapshot " + id + " already exists")
+	}
+	// The snapshot takes as much capacity as its volume from the same pool.
+	if _, err := be.allocate(vol.PoolId, vol.Size); err != nil {
+		return nil, err
+	}
 
-func (*Driver) CreateSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
-	return &sampleSnapshots[0], nil
+	snp := &model.VolumeSnapshotSpec{
+		BaseModel:   &model.BaseModel{Id: id},
+		Name:        opt.GetName(),
+		Description: opt.GetDescription(),
+		Size:        vol.Size,
+		Status:      "available",
+		VolumeId:    vol.Id,
+	}
+	be.snapshots[id] = snp
+	copied := *snp
+	return &copied, nil
 }
 
 func (*Driver) PullSnapshot(snapIdentifier string) (*model.VolumeSnapshotSpec, error) {
-	for _, snapshot := range sampleSnapshots {
-		if snapIdentifier == snapshot.GetId() {
-			return &snapshot, nil
-		}
+	if err := inject("pull_snapshot"); err != nil {
+		return nil, err
 	}
 
-	return nil, errors.New("Can't find snapshot " + snapIdentifier)
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	if snp, ok := be.snapshots[snapIdentifier]; ok {
+		copied := *snp
+		return &copied, nil
+	}
+	return nil, utils.NotFoundError("Can't find snapshot " + snapIdentifier)
 }
 
-func (*Driver) DeleteSnapshot(opt *pb.DeleteVolumeSnapshotOpts) error {
+// DeleteSnapshot succeeds if the snapshot doesn't exist.
+func (*Driver) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error {
+	if err := inject("delete_snapshot"); err != nil {
+		return err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	snp, ok := be.snapshots[opt.GetId()]
+	if !ok {
+		return nil
+	}
+	if vol, ok := be.volumes[snp.VolumeId]; ok {
+		be.release(vol.PoolId, snp.Size)
+	}
+	delete(be.snapshots, snp.Id)
 	return nil
 }
 
 func (*Driver) ListPools() ([]*model.StoragePoolSpec, error) {
-	var pols []*model.StoragePoolSpec
+	if err := inject("list_pools"); err != nil {
+		return nil, err
+	}
 
-	for i := range samplePools {
-		pols = append(pols, &samplePools[i])
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	var pols []*model.StoragePoolSpec
+	for _, pol := range be.pools {
+		copied := *pol
+		pols = append(pols, &copied)
 	}
 	return pols, nil
 }
 
+func (*Driver) ListVolumes() ([]*model.VolumeSpec, error) {
+	if err := inject("list_volumes"); err != nil {
+		return nil, err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	var vols = []*model.VolumeSpec{}
+	for _, vol := range be.volumes {
+		copied := *vol
+		vols = append(vols, &copied)
+	}
+	return vols, nil
+}
+
+func (*Driver) ListSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+	if err := inject("list_snapshots"); err != nil {
+		return nil, err
+	}
+
+	be.lock.Lock()
+	defer be.lock.Unlock()
+	var snps = []*model.VolumeSnapshotSpec{}
+	for _, snp := range be.snapshots {
+		copied := *snp
+		snps = append(snps, &copied)
+	}
+	return snps, nil
+}
+
 var (
 	samplePools = []model.StoragePoolSpec{
 		{
@@ -93,9 +373,10 @@ var (
 			TotalCapacity: int64(100),
 			FreeCapacity:  int64(90),
 			Parameters: map[string]interface{}{
-				"diskType":  "SSD",
-				"iops":      1000,
-				"bandwidth": 1000,
+				"diskType":     "SSD",
+				"iops":         1000,
+				"bandwidth":    1000,
+				"qosSupported": true,
 			},
 		},
 		{
@@ -107,25 +388,14 @@ var (
 			TotalCapacity: int64(200),
 			FreeCapacity:  int64(170),
 			Parameters: map[string]interface{}{
-				"diskType":  "SAS",
-				"iops":      800,
-				"bandwidth": 800,
+				"diskType":     "SAS",
+				"iops":         800,
+				"bandwidth":    800,
+				"qosSupported": true,
 			},
 		},
 	}
 
-	sampleVolume = model.VolumeSpec{
-		BaseModel: &model.BaseModel{
-			Id: "bd5b12a8-a101-11e7-941e-d77981b584d8",
-		},
-		Name:        "sample-volume",
-		Description: "This is a sample volume for testing",
-		Size:        int64(1),
-		Status:      "available",
-		PoolId:      "084bf71e-a102-11e7-88a8-e31fe6d52248",
-		ProfileId:   "1106b972-66ef-11e7-b172-db03f3689c9c",
-	}
-
 	sampleConnection = model.ConnectionInfo{
 		DriverVolumeType: "iscsi",
 		ConnectionData: map[string]interface{}{
@@ -135,27 +405,4 @@ var (
 			"discard":          false,
 		},
 	}
-
-	sampleSnapshots = []model.VolumeSnapshotSpec{
-		{
-			BaseModel: &model.BaseModel{
-				Id: "3769855c-a102-11e7-b772-17b880d2f537",
-			},
-			Name:        "sample-snapshot-01",
-			Description: "This is the first sample snapshot for testing",
-			Size:        int64(1),
-			Status:      "created",
-			VolumeId:    "bd5b12a8-a101-11e7-941e-d77981b584d8",
-		},
-		{
-			BaseModel: &model.BaseModel{
-				Id: "3bfaf2cc-a102-11e7-8ecb-63aea739d755",
-			},
-			Name:        "sample-snapshot-02",
-			Description: "This is the second sample snapshot for testing",
-			Size:        int64(1),
-			Status:      "created",
-			VolumeId:    "bd5b12a8-a101-11e7-941e-d77981b584d8",
-		},
-	}
 )
@@ -0,0 +1,154 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package sample
+
+import (
+	"testing"
+
+	drivertest "github.com/opensds/opensds/contrib/drivers/testing"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils/config"
+	"golang.org/x/net/context"
+)
+
+var ctx = context.Background()
+
+func freeCapacity(t *testing.T, d *Driver, polID string) int64 {
+	pols, err := d.ListPools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pol := range pols {
+		if pol.Id == polID {
+			return pol.FreeCapacity
+		}
+	}
+	t.Fatalf("Can't find pool %s", polID)
+	return 0
+}
+
+func TestConformance(t *testing.T) {
+	be = newBackend()
+	drivertest.Run(t, &Driver{})
+}
+
+func TestVolumeLifecycle(t *testing.T) {
+	be = newBackend()
+	d := &Driver{}
+	polID := samplePools[0].Id
+
+	vol, err := d.CreateVolume(ctx, &pb.CreateVolumeOpts{Name: "vol", Size: 10, PoolId: polID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.Status != model.VolumeAvailable || vol.PoolId != polID {
+		t.Errorf("Unexpected created volume %+v", vol)
+	}
+	if free := freeCapacity(t, d, polID); free != 90 {
+		t.Errorf("Expected free capacity 90, got %d", free)
+	}
+	if got, err := d.PullVolume(vol.Id); err != nil || got.Name != "vol" {
+		t.Errorf("Expected volume pulled, got %v, %v", got, err)
+	}
+
+	snp, err := d.CreateSnapshot(ctx, &pb.CreateVolumeSnapshotOpts{VolumeId: vol.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free := freeCapacity(t, d, polID); free != 80 {
+		t.Errorf("Expected free capacity 80, got %d", free)
+	}
+	if _, err = d.InitializeConnection(ctx, &pb.CreateAttachmentOpts{
+		VolumeId: vol.Id,
+		HostInfo: &pb.HostInfo{Host: "host"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The volume can't be deleted until its snapshots and exports are gone.
+	if err = d.DeleteVolume(ctx, &pb.DeleteVolumeOpts{Id: vol.Id}); err == nil {
+		t.Error("Expected deleting exported volume failed")
+	}
+	if err = d.TerminateConnection(ctx, &pb.DeleteAttachmentOpts{
+		VolumeId: vol.Id,
+		HostInfo: &pb.HostInfo{Host: "host"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.DeleteVolume(ctx, &pb.DeleteVolumeOpts{Id: vol.Id}); err == nil {
+		t.Error("Expected deleting volume with snapshot failed")
+	}
+	if err = d.DeleteSnapshot(ctx, &pb.DeleteVolumeSnapshotOpts{Id: snp.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if err = d.DeleteVolume(ctx, &pb.DeleteVolumeOpts{Id: vol.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if free := freeCapacity(t, d, polID); free != 100 {
+		t.Errorf("Expected free capacity 100, got %d", free)
+	}
+
+	// Deleting again succeeds, so that the calls could be retried.
+	if err = d.DeleteVolume(ctx, &pb.DeleteVolumeOpts{Id: vol.Id}); err != nil {
+		t.Error(err)
+	}
+	if vols, _ := d.ListVolumes(); len(vols) != 0 {
+		t.Errorf("Expected no volume left, got %v", vols)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	be = newBackend()
+	d := &Driver{}
+
+	if _, err := d.CreateVolume(ctx, &pb.CreateVolumeOpts{Size: 101, PoolId: samplePools[0].Id}); err == nil {
+		t.Error("Expected creating volume larger than pool failed")
+	}
+	// The first pool with enough capacity is chosen if no pool is given.
+	vol, err := d.CreateVolume(ctx, &pb.CreateVolumeOpts{Size: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.PoolId != samplePools[1].Id {
+		t.Errorf("Expected volume created in pool %s, got %s", samplePools[1].Id, vol.PoolId)
+	}
+	if _, err = d.CreateVolume(ctx, &pb.CreateVolumeOpts{Size: 101}); err == nil {
+		t.Error("Expected creating volume failed when no pool has enough capacity")
+	}
+	if _, err = d.CreateSnapshot(ctx, &pb.CreateVolumeSnapshotOpts{VolumeId: vol.Id}); err == nil {
+		t.Error("Expected creating snapshot failed when pool is full")
+	}
+}
+
+func TestInjectFailure(t *testing.T) {
+	be = newBackend()
+	d := &Driver{}
+	defer func() { config.CONF.Sample = config.Sample{} }()
+
+	config.CONF.Sample.FailOperations = []string{"", "create_volume"}
+	if _, err := d.CreateVolume(ctx, &pb.CreateVolumeOpts{Size: 1}); err == nil {
+		t.Error("Expected create_volume failed")
+	}
+	if _, err := d.ListPools(); err != nil {
+		t.Errorf("Expected list_pools succeeded, got %v", err)
+	}
+
+	config.CONF.Sample.FailOperations = nil
+	config.CONF.Sample.FailureRate = 100
+	if _, err := d.ListPools(); err == nil {
+		t.Error("Expected list_pools failed")
+	}
+}
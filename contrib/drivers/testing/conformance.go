@@ -0,0 +1,306 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the conformance test suite of volume drivers, which
+runs the lifecycles of pools, volumes, snapshots and connections against any
+driver with the same assertions. The drivers run it in their own tests, with
+fakes of their backends if needed:
+
+	func TestConformance(t *testing.T) {
+		drivertest.Run(t, &Driver{})
+	}
+
+The errors of drivers should follow these semantics:
+  - pulling a resource which doesn't exist returns utils.NotFoundError;
+  - deleting a resource or connection which doesn't exist succeeds, or
+    returns utils.NotFoundError;
+  - creating a resource which already exists returns utils.AlreadyExistsError,
+    unless the driver generates the ids of resources itself.
+
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"github.com/opensds/opensds/contrib/drivers/plugin/sdk"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// Suite is the conformance test suite of a volume driver. The driver is
+// declared as sdk.VolumeDriver, which is the same as drivers.VolumeDriver and
+// could be imported by the tests of in-tree drivers.
+type Suite struct {
+	Driver sdk.VolumeDriver
+
+	// VolumeIdentifier returns the identifier of volume which is passed to
+	// PullVolume and used to find it in ListVolumes, the id of volume is used
+	// if it's nil.
+	VolumeIdentifier func(vol *model.VolumeSpec) string
+	// SnapshotIdentifier is the same as VolumeIdentifier for snapshots.
+	SnapshotIdentifier func(snp *model.VolumeSnapshotSpec) string
+	// MissingIdentifier identifies no volume or snapshot in the backend, a
+	// new uuid is used if it's empty.
+	MissingIdentifier string
+}
+
+// Run runs the conformance tests against the driver with default settings.
+func Run(t *testing.T, d sdk.VolumeDriver) {
+	(&Suite{Driver: d}).Run(t)
+}
+
+// Run runs the conformance tests, each lifecycle creates and deletes its own
+// resources in the first pool of driver.
+func (s *Suite) Run(t *testing.T) {
+	if s.VolumeIdentifier == nil {
+		s.VolumeIdentifier = func(vol *model.VolumeSpec) string { return vol.GetId() }
+	}
+	if s.SnapshotIdentifier == nil {
+		s.SnapshotIdentifier = func(snp *model.VolumeSnapshotSpec) string { return snp.GetId() }
+	}
+	if s.MissingIdentifier == "" {
+		s.MissingIdentifier = uuid.NewV4().String()
+	}
+
+	t.Run("Pools", s.testPools)
+	t.Run("VolumeLifecycle", s.testVolumeLifecycle)
+	t.Run("SnapshotLifecycle", s.testSnapshotLifecycle)
+	t.Run("ConnectionLifecycle", s.testConnectionLifecycle)
+	t.Run("NotFound", s.testNotFound)
+	t.Run("AlreadyExists", s.testAlreadyExists)
+}
+
+var ctx = context.Background()
+
+// notFoundOrNil checks the error of deleting a resource which doesn't exist.
+func notFoundOrNil(t *testing.T, op string, err error) {
+	if err != nil && !utils.IsNotFound(err) {
+		t.Errorf("%s of missing resource should succeed or return NotFoundError, got %v", op, err)
+	}
+}
+
+func (s *Suite) testPools(t *testing.T) {
+	pols, err := s.Driver.ListPools()
+	if err != nil {
+		t.Fatal("ListPools failed:", err)
+	}
+	if len(pols) == 0 {
+		t.Fatal("ListPools returned no pool")
+	}
+	for _, pol := range pols {
+		if pol.GetId() == "" || pol.GetName() == "" {
+			t.Errorf("Pool %+v has no id or name", pol)
+		}
+		if pol.FreeCapacity > pol.TotalCapacity {
+			t.Errorf("Pool %s has more free capacity %d than total %d",
+				pol.GetId(), pol.FreeCapacity, pol.TotalCapacity)
+		}
+	}
+}
+
+// newVolumeOpts returns the options of a 1GB volume in the first pool.
+func (s *Suite) newVolumeOpts(t *testing.T) *pb.CreateVolumeOpts {
+	pols, err := s.Driver.ListPools()
+	if err != nil || len(pols) == 0 {
+		t.Fatalf("ListPools returned %v, %v", pols, err)
+	}
+	id := uuid.NewV4().String()
+	return &pb.CreateVolumeOpts{
+		Id:     id,
+		Name:   "conformance-" + id[:8],
+		Size:   1,
+		PoolId: pols[0].GetId(),
+	}
+}
+
+func (s *Suite) createVolume(t *testing.T) *model.VolumeSpec {
+	opt := s.newVolumeOpts(t)
+	vol, err := s.Driver.CreateVolume(ctx, opt)
+	if err != nil {
+		t.Fatal("CreateVolume failed:", err)
+	}
+	if vol.GetId() == "" || vol.Name != opt.Name || vol.Size != opt.Size {
+		t.Errorf("CreateVolume returned %+v, expected name %s and size %d", vol, opt.Name, opt.Size)
+	}
+	return vol
+}
+
+func (s *Suite) deleteVolume(t *testing.T, vol *model.VolumeSpec) {
+	opt := &pb.DeleteVolumeOpts{Id: vol.GetId(), Metadata: vol.Metadata}
+	if err := s.Driver.DeleteVolume(ctx, opt); err != nil {
+		t.Error("DeleteVolume failed:", err)
+	}
+}
+
+func (s *Suite) listVolumes(t *testing.T) []string {
+	vols, err := s.Driver.ListVolumes()
+	if err != nil {
+		t.Fatal("ListVolumes failed:", err)
+	}
+	var ids []string
+	for _, vol := range vols {
+		ids = append(ids, s.VolumeIdentifier(vol))
+	}
+	return ids
+}
+
+func (s *Suite) listSnapshots(t *testing.T) []string {
+	snps, err := s.Driver.ListSnapshots()
+	if err != nil {
+		t.Fatal("ListSnapshots failed:", err)
+	}
+	var ids []string
+	for _, snp := range snps {
+		ids = append(ids, s.SnapshotIdentifier(snp))
+	}
+	return ids
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Suite) testVolumeLifecycle(t *testing.T) {
+	vol := s.createVolume(t)
+	volID := s.VolumeIdentifier(vol)
+
+	got, err := s.Driver.PullVolume(volID)
+	if err != nil {
+		t.Error("PullVolume failed:", err)
+	} else if got.Size != vol.Size {
+		t.Errorf("PullVolume returned size %d, expected %d", got.Size, vol.Size)
+	}
+	if !contains(s.listVolumes(t), volID) {
+		t.Errorf("ListVolumes doesn't contain created volume %s", volID)
+	}
+
+	s.deleteVolume(t, vol)
+	if contains(s.listVolumes(t), volID) {
+		t.Errorf("ListVolumes still contains deleted volume %s", volID)
+	}
+	if _, err = s.Driver.PullVolume(volID); !utils.IsNotFound(err) {
+		t.Errorf("PullVolume of deleted volume should return NotFoundError, got %v", err)
+	}
+	err = s.Driver.DeleteVolume(ctx, &pb.DeleteVolumeOpts{Id: vol.GetId(), Metadata: vol.Metadata})
+	notFoundOrNil(t, "DeleteVolume", err)
+}
+
+func (s *Suite) testSnapshotLifecycle(t *testing.T) {
+	vol := s.createVolume(t)
+	defer s.deleteVolume(t, vol)
+
+	id := uuid.NewV4().String()
+	snp, err := s.Driver.CreateSnapshot(ctx, &pb.CreateVolumeSnapshotOpts{
+		Id:       id,
+		Name:     "conformance-" + id[:8],
+		Size:     vol.Size,
+		VolumeId: vol.GetId(),
+		Metadata: vol.Metadata,
+	})
+	if err != nil {
+		t.Fatal("CreateSnapshot failed:", err)
+	}
+	if snp.GetId() == "" || snp.VolumeId != vol.GetId() {
+		t.Errorf("CreateSnapshot returned %+v, expected volume id %s", snp, vol.GetId())
+	}
+	snpID := s.SnapshotIdentifier(snp)
+
+	if _, err = s.Driver.PullSnapshot(snpID); err != nil {
+		t.Error("PullSnapshot failed:", err)
+	}
+	if !contains(s.listSnapshots(t), snpID) {
+		t.Errorf("ListSnapshots doesn't contain created snapshot %s", snpID)
+	}
+	if contains(s.listVolumes(t), snpID) {
+		t.Errorf("ListVolumes contains snapshot %s", snpID)
+	}
+
+	opt := &pb.DeleteVolumeSnapshotOpts{Id: snp.GetId(), VolumeId: vol.GetId(), Metadata: snp.Metadata}
+	if err = s.Driver.DeleteSnapshot(ctx, opt); err != nil {
+		t.Fatal("DeleteSnapshot failed:", err)
+	}
+	if contains(s.listSnapshots(t), snpID) {
+		t.Errorf("ListSnapshots still contains deleted snapshot %s", snpID)
+	}
+	if _, err = s.Driver.PullSnapshot(snpID); !utils.IsNotFound(err) {
+		t.Errorf("PullSnapshot of deleted snapshot should return NotFoundError, got %v", err)
+	}
+	notFoundOrNil(t, "DeleteSnapshot", s.Driver.DeleteSnapshot(ctx, opt))
+}
+
+func (s *Suite) testConnectionLifecycle(t *testing.T) {
+	vol := s.createVolume(t)
+	defer s.deleteVolume(t, vol)
+
+	host := &pb.HostInfo{Host: "conformance", Initiator: "iqn.2017-10.io.opensds:conformance"}
+	info, err := s.Driver.InitializeConnection(ctx, &pb.CreateAttachmentOpts{
+		VolumeId: vol.GetId(),
+		HostInfo: host,
+		Metadata: vol.Metadata,
+	})
+	if err != nil {
+		t.Fatal("InitializeConnection failed:", err)
+	}
+	if info.DriverVolumeType == "" || len(info.ConnectionData) == 0 {
+		t.Errorf("InitializeConnection returned incomplete connection %+v", info)
+	}
+
+	opt := &pb.DeleteAttachmentOpts{VolumeId: vol.GetId(), HostInfo: host, Metadata: vol.Metadata}
+	if err = s.Driver.TerminateConnection(ctx, opt); err != nil {
+		t.Fatal("TerminateConnection failed:", err)
+	}
+	notFoundOrNil(t, "TerminateConnection", s.Driver.TerminateConnection(ctx, opt))
+}
+
+func (s *Suite) testNotFound(t *testing.T) {
+	if _, err := s.Driver.PullVolume(s.MissingIdentifier); !utils.IsNotFound(err) {
+		t.Errorf("PullVolume of missing volume should return NotFoundError, got %v", err)
+	}
+	if _, err := s.Driver.PullSnapshot(s.MissingIdentifier); !utils.IsNotFound(err) {
+		t.Errorf("PullSnapshot of missing snapshot should return NotFoundError, got %v", err)
+	}
+}
+
+func (s *Suite) testAlreadyExists(t *testing.T) {
+	opt := s.newVolumeOpts(t)
+	vol, err := s.Driver.CreateVolume(ctx, opt)
+	if err != nil {
+		t.Fatal("CreateVolume failed:", err)
+	}
+	defer s.deleteVolume(t, vol)
+
+	dup, err := s.Driver.CreateVolume(ctx, opt)
+	if err == nil {
+		// The driver which generates ids itself creates another volume.
+		defer s.deleteVolume(t, dup)
+		if dup.GetId() == vol.GetId() {
+			t.Errorf("CreateVolume of existing volume %s should return AlreadyExistsError", vol.GetId())
+		}
+		return
+	}
+	if !utils.IsAlreadyExists(err) {
+		t.Errorf("CreateVolume of existing volume should return AlreadyExistsError, got %v", err)
+	}
+}
@@ -24,7 +24,6 @@ import (
 	"fmt"
 
 	"github.com/astaxie/beego"
-	log "github.com/golang/glog"
 	"github.com/opensds/opensds/pkg/db"
 	"github.com/opensds/opensds/pkg/utils"
 )
@@ -34,13 +33,15 @@ type DockPortal struct {
 }
 
 func (this *DockPortal) ListDocks() {
+	logger := newLogger(this.Ctx)
+
 	// Call db api module to handle list docks request.
 	result, err := db.C.ListDocks()
 	if err != nil {
 		reason := fmt.Sprintf("List docks failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -50,7 +51,7 @@ func (this *DockPortal) ListDocks() {
 		reason := fmt.Sprintf("Marshal docks failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -60,6 +61,8 @@ func (this *DockPortal) ListDocks() {
 }
 
 func (this *DockPortal) GetDock() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":dockId")
 
 	result, err := db.C.GetDock(id)
@@ -67,7 +70,7 @@ func (this *DockPortal) GetDock() {
 		reason := fmt.Sprintf("Get dock failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -77,7 +80,7 @@ func (this *DockPortal) GetDock() {
 		reason := fmt.Sprintf("Marshal dock failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the OpenSDS northbound service.
+
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+type EventPortal struct {
+	beego.Controller
+}
+
+// ListEvents returns the events in the order they happened, which could be
+// filtered by resourceType, resourceId and a time range given by since and
+// until in the format of utils.TimeFormat, as well as the common list options.
+func (this *EventPortal) ListEvents() {
+	logger := newLogger(this.Ctx)
+
+	opts, err := parseListOptions(this.Ctx)
+	if err != nil {
+		reason := fmt.Sprintf("Parse list options failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	opts.CreatedSince, opts.CreatedUntil = opts.Filters["since"], opts.Filters["until"]
+	delete(opts.Filters, "since")
+	delete(opts.Filters, "until")
+	for _, t := range []string{opts.CreatedSince, opts.CreatedUntil} {
+		if t == "" {
+			continue
+		}
+		if _, err := time.Parse(utils.TimeFormat, t); err != nil {
+			reason := fmt.Sprintf("Parse time range failed: %s", err.Error())
+			this.Ctx.Output.SetStatus(StatusBadRequest)
+			this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+			logger.Error(reason)
+			return
+		}
+	}
+
+	// Call db api module to handle list events request.
+	result, err := db.C.ListEvents(opts)
+	if err != nil {
+		reason := fmt.Sprintf("List events failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal events listed result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusOK)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+// recordEvent stores the outcome of an operation requested through the api.
+// It never fails the request, the event is dropped with an error log if it
+// can't be stored.
+func recordEvent(ctx *context.Context, resType, resID, action string, err error) {
+	logger := newLogger(ctx)
+
+	evt := model.NewEvent(model.EventSourceController, ctx.Input.IP(),
+		resType, resID, action, err)
+	evt.RequestId = requestId(ctx)
+	if err := utils.ValidateData(evt, utils.S); err != nil {
+		logger.Error("When validate event data:", err)
+		return
+	}
+	if err := db.C.CreateEvent(evt); err != nil {
+		logger.Error("When create event in db module:", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/stretchr/testify/mock"
+)
+
+func init() {
+	var eventPortal EventPortal
+	beego.Router("/v1alpha/events", &eventPortal, "get:ListEvents")
+}
+
+var (
+	fakeEvents = []*model.EventSpec{
+		{
+			BaseModel: &model.BaseModel{
+				Id:        "3b5b3b70-b7e4-11e7-9d36-3f5b6ba2a2b6",
+				CreatedAt: "2017-10-25T09:00:00",
+			},
+			Actor:        "127.0.0.1",
+			Source:       "controller",
+			ResourceType: "volume",
+			ResourceId:   "f4a5e666-c669-4c64-a2a1-8f9ecd560c78",
+			Action:       "delete",
+			Outcome:      "Failure",
+			Error:        "volume is in use",
+		},
+		{
+			BaseModel: &model.BaseModel{
+				Id:        "fd1e3cf2-b7e3-11e7-a2b8-2f8b0d3bc3c7",
+				CreatedAt: "2017-10-24T16:21:32",
+			},
+			Actor:        "127.0.0.1",
+			Source:       "controller",
+			ResourceType: "volume",
+			ResourceId:   "f4a5e666-c669-4c64-a2a1-8f9ecd560c78",
+			Action:       "create",
+			Outcome:      "Success",
+		},
+		{
+			BaseModel: &model.BaseModel{
+				Id:        "6a1e4bde-b7e4-11e7-8d2b-8f1d9b8c7a1e",
+				CreatedAt: "2017-10-26T10:00:00",
+			},
+			Actor:        "127.0.0.1",
+			Source:       "controller",
+			ResourceType: "profile",
+			ResourceId:   "d3a109ff-3e51-4625-9054-32604c79fa90",
+			Action:       "update",
+			Outcome:      "Success",
+		},
+	}
+)
+
+// selectEvents filters and sorts the fake events as db module does.
+func selectEvents(opts *model.ListOptions) []*model.EventSpec {
+	items, err := utils.SelectItems(fakeEvents, opts)
+	if err != nil {
+		return nil
+	}
+	return items.([]*model.EventSpec)
+}
+
+func TestListEvents(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListEvents", mock.Anything).Return(selectEvents, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/events", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	var output []*model.EventSpec
+	json.Unmarshal(w.Body.Bytes(), &output)
+
+	// The events should be sorted by the time they happened.
+	expected := []*model.EventSpec{fakeEvents[1], fakeEvents[0], fakeEvents[2]}
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+
+	if !reflect.DeepEqual(expected, output) {
+		t.Errorf("Expected %v, actual %v", expected, output)
+	}
+}
+
+func TestListEventsWithFilter(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListEvents", mock.Anything).Return(selectEvents, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET",
+		"/v1alpha/events?resourceType=volume&resourceId=f4a5e666-c669-4c64-a2a1-8f9ecd560c78&since=2017-10-25T00:00:00&until=2017-10-26T00:00:00", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	var output []*model.EventSpec
+	json.Unmarshal(w.Body.Bytes(), &output)
+
+	expected := []*model.EventSpec{fakeEvents[0]}
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+
+	if !reflect.DeepEqual(expected, output) {
+		t.Errorf("Expected %v, actual %v", expected, output)
+	}
+}
+
+func TestListEventsWithBadTimeRange(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/events?since=yesterday", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Errorf("Expected 400, actual %v", w.Code)
+	}
+}
+
+func TestListEventsWithBadRequest(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListEvents", mock.Anything).Return(nil, errors.New("db error"))
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/events", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Errorf("Expected 400, actual %v", w.Code)
+	}
+}
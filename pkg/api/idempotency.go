@@ -0,0 +1,214 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the idempotency of create requests, so that the
+retries of a request identified by the same key get the original result
+instead of creating a duplicate resource. The keys are scoped by the project
+and user sending the request, so the same key given by others never gets the
+result of the request.
+
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/astaxie/beego/context"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+const (
+	// IdempotencyKeyHeader carries the idempotency key of create request,
+	// which could also be given by the clientToken field of request body.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on the response replayed from the
+	// original result.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// ProjectIdHeader and UserIdHeader carry the project and user sending the
+	// request, which are set by the authentication in front of the api. The
+	// project is taken from the projectId field of request body if it's not
+	// given by header.
+	ProjectIdHeader = "X-Project-Id"
+	UserIdHeader    = "X-User-Id"
+
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+// readCreateBody reads the body of create request and the idempotency key of
+// the request, the key in header takes precedence over the one in body.
+func readCreateBody(ctx *context.Context) ([]byte, string, error) {
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if key := ctx.Input.Header(IdempotencyKeyHeader); key != "" {
+		return body, key, nil
+	}
+
+	var token struct {
+		ClientToken string `json:"clientToken"`
+	}
+	if err = json.Unmarshal(body, &token); err != nil {
+		return nil, "", err
+	}
+	return body, token.ClientToken, nil
+}
+
+// idempotentRequest guards the create request identified by the key, all of
+// its methods do nothing if no key is given.
+type idempotentRequest struct {
+	ctx      *context.Context
+	key      string
+	resource string
+	// id is the id of the key stored in db, and fingerprint tells whether the
+	// request is the same as the one the key has been used for.
+	id          string
+	fingerprint string
+	createdAt   string
+
+	reserved, completed bool
+}
+
+func newIdempotentRequest(ctx *context.Context, key, resource string, body []byte) *idempotentRequest {
+	r := &idempotentRequest{ctx: ctx, key: key, resource: resource}
+	if key == "" {
+		return r
+	}
+
+	project := ctx.Input.Header(ProjectIdHeader)
+	if project == "" {
+		var owner struct {
+			ProjectId string `json:"projectId"`
+		}
+		json.Unmarshal(body, &owner)
+		project = owner.ProjectId
+	}
+	r.id = idempotencyKeyId(project, ctx.Input.Header(UserIdHeader), key)
+	r.fingerprint = requestFingerprint(ctx.Input.Method(), ctx.Request.URL.Path, body)
+	return r
+}
+
+// idempotencyKeyId returns the id of the key given by the user of the project,
+// so that the same key given by others is stored apart.
+func idempotencyKeyId(project, user, key string) string {
+	sum := sha256.Sum256([]byte(project + "\x00" + user + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint returns the hash of the method, path and body of the
+// request.
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// reserve stores the key before the resource is created. It returns false if
+// the request shouldn't go on, in which case the original result has been
+// replayed or the error has been responded.
+func (r *idempotentRequest) reserve() bool {
+	if r.key == "" {
+		return true
+	}
+	logger := newLogger(r.ctx)
+
+	r.createdAt = time.Now().Format(utils.TimeFormat)
+	existing, err := db.C.CreateIdempotencyKey(&model.IdempotencyKeySpec{
+		BaseModel: &model.BaseModel{
+			Id:        r.id,
+			CreatedAt: r.createdAt,
+		},
+		Resource:    r.resource,
+		Fingerprint: r.fingerprint,
+		Status:      model.IdempotencyKeyPending,
+	}, idempotencyKeyTTL)
+	if err != nil {
+		reason := fmt.Sprintf("Reserve idempotency key failed: %s", err.Error())
+		r.ctx.Output.SetStatus(StatusInternalServerError)
+		r.ctx.Output.Body(utils.ErrorStatus(r.ctx.Output.Status, reason))
+		logger.Error(reason)
+		return false
+	}
+	if existing == nil {
+		r.reserved = true
+		return true
+	}
+
+	var code int
+	var reason string
+	switch {
+	case existing.GetResource() != r.resource:
+		code = StatusUnprocessableEntity
+		reason = fmt.Sprintf("Idempotency key %s has been used for creating %s", r.key, existing.GetResource())
+	case existing.Fingerprint != r.fingerprint:
+		code = StatusUnprocessableEntity
+		reason = fmt.Sprintf("Idempotency key %s has been used for another request", r.key)
+	case existing.GetStatus() != model.IdempotencyKeyCompleted:
+		code = StatusConflict
+		reason = fmt.Sprintf("Request with idempotency key %s is in progress", r.key)
+	default:
+		logger.Infof("Replay the result of request with idempotency key %s\n", r.key)
+		r.ctx.Output.Header(IdempotentReplayedHeader, "true")
+		r.ctx.Output.SetStatus(existing.Code)
+		r.ctx.Output.Body(existing.Result)
+		return false
+	}
+	r.ctx.Output.SetStatus(code)
+	r.ctx.Output.Body(utils.ErrorStatus(r.ctx.Output.Status, reason))
+	logger.Error(reason)
+	return false
+}
+
+// complete records the result of the request for its replays.
+func (r *idempotentRequest) complete(code int, body []byte) {
+	if !r.reserved {
+		return
+	}
+	r.completed = true
+
+	if err := db.C.UpdateIdempotencyKey(&model.IdempotencyKeySpec{
+		BaseModel: &model.BaseModel{
+			Id:        r.id,
+			CreatedAt: r.createdAt,
+		},
+		Resource:    r.resource,
+		Fingerprint: r.fingerprint,
+		Status:      model.IdempotencyKeyCompleted,
+		Code:        code,
+		Result:      body,
+	}); err != nil {
+		newLogger(r.ctx).Error("When record result of idempotency key:", err)
+	}
+}
+
+// release removes the key if the request fails, so that the request could
+// be retried with the same key.
+func (r *idempotentRequest) release() {
+	if !r.reserved || r.completed {
+		return
+	}
+	if err := db.C.DeleteIdempotencyKey(r.id); err != nil {
+		newLogger(r.ctx).Error("When release idempotency key:", err)
+	}
+}
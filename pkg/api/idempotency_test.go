@@ -0,0 +1,152 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateVolumeReplayed(t *testing.T) {
+	var body = `{"name":"sample-volume","size":1}`
+	var result = []byte(`{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8","name":"sample-volume"}`)
+	var id = idempotencyKeyId("project-a", "user-a", "key-01")
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("CreateIdempotencyKey", mock.Anything, idempotencyKeyTTL).
+		Return(&model.IdempotencyKeySpec{
+			BaseModel:   &model.BaseModel{Id: id},
+			Resource:    model.EventResourceVolume,
+			Fingerprint: requestFingerprint("POST", "/v1alpha/block/volumes", []byte(body)),
+			Status:      model.IdempotencyKeyCompleted,
+			Code:        StatusAccepted,
+			Result:      result,
+		}, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("POST", "/v1alpha/block/volumes", bytes.NewBufferString(body))
+	r.Header.Set(IdempotencyKeyHeader, "key-01")
+	r.Header.Set(ProjectIdHeader, "project-a")
+	r.Header.Set(UserIdHeader, "user-a")
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != StatusAccepted {
+		t.Errorf("Expected %v, actual %v", StatusAccepted, w.Code)
+	}
+	if !bytes.Equal(w.Body.Bytes(), result) {
+		t.Errorf("Expected %s, actual %s", result, w.Body.Bytes())
+	}
+	if w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("Expected the response marked as replayed")
+	}
+	mockClient.AssertCalled(t, "CreateIdempotencyKey", mock.MatchedBy(func(key *model.IdempotencyKeySpec) bool {
+		return key.Id == id && key.Status == model.IdempotencyKeyPending
+	}), idempotencyKeyTTL)
+}
+
+func TestIdempotencyKeyScope(t *testing.T) {
+	// The same key given by other projects or users is stored apart.
+	id := idempotencyKeyId("project-a", "user-a", "key-01")
+	for _, other := range []string{
+		idempotencyKeyId("project-b", "user-a", "key-01"),
+		idempotencyKeyId("project-a", "user-b", "key-01"),
+		idempotencyKeyId("project-a", "user-a", "key-02"),
+	} {
+		if other == id {
+			t.Errorf("Expected key %s scoped by project and user", other)
+		}
+	}
+
+	// The project is taken from the request body unless given by header.
+	r, _ := http.NewRequest("POST", "/v1alpha/block/volumes",
+		bytes.NewBufferString(`{"name":"sample-volume","projectId":"project-a"}`))
+	ctx := context.NewContext()
+	ctx.Reset(httptest.NewRecorder(), r)
+	body, key, err := readCreateBody(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := newIdempotentRequest(ctx, key, model.EventResourceVolume, body); req.id != "" {
+		t.Errorf("Expected no key stored without key given, got %s", req.id)
+	}
+	ctx.Request.Header.Set(UserIdHeader, "user-a")
+	if req := newIdempotentRequest(ctx, "key-01", model.EventResourceVolume, body); req.id != id {
+		t.Errorf("Expected key %s, got %s", id, req.id)
+	}
+}
+
+func TestCreateRequestWithKeyInUse(t *testing.T) {
+	var body = `{"volumeId":"bd5b12a8-a101-11e7-941e-d77981b584d8","clientToken":"key-02"}`
+	var fingerprint = requestFingerprint("POST", "/v1alpha/block/snapshots", []byte(body))
+	testCases := []struct {
+		existing *model.IdempotencyKeySpec
+		code     int
+	}{
+		// The original request is still in progress.
+		{
+			existing: &model.IdempotencyKeySpec{
+				BaseModel:   &model.BaseModel{Id: "key-02"},
+				Resource:    model.EventResourceSnapshot,
+				Fingerprint: fingerprint,
+				Status:      model.IdempotencyKeyPending,
+			},
+			code: StatusConflict,
+		},
+		// The key has been used for another request of the same resource.
+		{
+			existing: &model.IdempotencyKeySpec{
+				BaseModel:   &model.BaseModel{Id: "key-02"},
+				Resource:    model.EventResourceSnapshot,
+				Fingerprint: requestFingerprint("POST", "/v1alpha/block/snapshots", []byte(`{"volumeId":"another"}`)),
+				Status:      model.IdempotencyKeyCompleted,
+			},
+			code: StatusUnprocessableEntity,
+		},
+		// The key has been used for creating another type of resource.
+		{
+			existing: &model.IdempotencyKeySpec{
+				BaseModel: &model.BaseModel{Id: "key-02"},
+				Resource:  model.EventResourceVolume,
+				Status:    model.IdempotencyKeyCompleted,
+			},
+			code: StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		mockClient := new(dbtest.MockClient)
+		mockClient.On("CreateIdempotencyKey", mock.Anything, idempotencyKeyTTL).Return(tc.existing, nil)
+		db.C = mockClient
+
+		// The key is given by the clientToken field of request body.
+		r, _ := http.NewRequest("POST", "/v1alpha/block/snapshots", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+		if w.Code != tc.code {
+			t.Errorf("Expected %v, actual %v", tc.code, w.Code)
+		}
+		mockClient.AssertNotCalled(t, "DeleteIdempotencyKey", mock.Anything)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the parsing of pagination, sorting and filtering
+parameters of list apis.
+
+*/
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego/context"
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// The query parameters of list apis, the other parameters are considered as
+// filters on the resource fields, such as status, poolId, profileId and name.
+const (
+	LimitParam    = "limit"
+	OffsetParam   = "offset"
+	MarkerParam   = "marker"
+	SortKeyParam  = "sort_key"
+	SortDirParam  = "sort_dir"
+	MetadataParam = "metadata"
+)
+
+// parseListOptions parses the list options from query parameters. Metadata
+// filter is specified as comma-separated key=value pairs, e.g.
+// metadata=tier=gold,zone=default.
+func parseListOptions(ctx *context.Context) (*model.ListOptions, error) {
+	var opts = &model.ListOptions{
+		Filters:  map[string]string{},
+		Metadata: map[string]string{},
+	}
+
+	for key, values := range ctx.Request.URL.Query() {
+		if len(values) == 0 {
+			continue
+		}
+		value := values[0]
+
+		var err error
+		switch key {
+		case LimitParam:
+			opts.Limit, err = strconv.Atoi(value)
+		case OffsetParam:
+			opts.Offset, err = strconv.Atoi(value)
+		case MarkerParam:
+			opts.Marker = value
+		case SortKeyParam:
+			opts.SortKey = value
+		case SortDirParam:
+			opts.SortDir = value
+		case MetadataParam:
+			for _, pair := range strings.Split(value, ",") {
+				kv := strings.SplitN(pair, "=", 2)
+				if len(kv) != 2 || kv[0] == "" {
+					return nil, fmt.Errorf("Invalid metadata filter %s, should be key=value", pair)
+				}
+				opts.Metadata[kv[0]] = kv[1]
+			}
+		default:
+			opts.Filters[key] = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s %s: %v", key, value, err)
+		}
+	}
+	if opts.Limit < 0 || opts.Offset < 0 {
+		return nil, fmt.Errorf("Limit and offset could not be negative")
+	}
+	return opts, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the OpenSDS northbound service.
+
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opensds/opensds/pkg/controller"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+func (this *VolumePortal) ManageVolume() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var in model.VolumeManageSpec
+
+	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&in); err != nil {
+		reason := fmt.Sprintf("Parse volume manage request body failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// Call global controller variable to handle manage volume request.
+	result, err := controller.Brain.ManageVolume(ctx, &in)
+	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceVolume, in.Identifier, "manage", err)
+		reason := fmt.Sprintf("Manage volume failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	recordEvent(this.Ctx, model.EventResourceVolume, result.GetId(), "manage", nil)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume managed result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumePortal) UnmanageVolume() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var volume = model.VolumeSpec{
+		BaseModel: &model.BaseModel{
+			Id: this.Ctx.Input.Param(":volumeId"),
+		},
+	}
+
+	// Call global controller variable to handle unmanage volume request.
+	result := controller.Brain.UnmanageVolume(ctx, &volume)
+	if result.Status != "Success" {
+		recordEvent(this.Ctx, model.EventResourceVolume, volume.GetId(), "unmanage", errors.New(result.GetError()))
+		reason := fmt.Sprintf("Unmanage volume failed: %s", result.GetError())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	recordEvent(this.Ctx, model.EventResourceVolume, volume.GetId(), "unmanage", nil)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume unmanaged result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumeSnapshotPortal) ManageVolumeSnapshot() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var in model.VolumeSnapshotManageSpec
+
+	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&in); err != nil {
+		reason := fmt.Sprintf("Parse volume snapshot manage request body failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// Call global controller variable to handle manage volume snapshot request.
+	result, err := controller.Brain.ManageVolumeSnapshot(ctx, &in)
+	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceSnapshot, in.Identifier, "manage", err)
+		reason := fmt.Sprintf("Manage volume snapshot failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	recordEvent(this.Ctx, model.EventResourceSnapshot, result.GetId(), "manage", nil)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume snapshot managed result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumeSnapshotPortal) UnmanageVolumeSnapshot() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var snapshot = model.VolumeSnapshotSpec{
+		BaseModel: &model.BaseModel{
+			Id: this.Ctx.Input.Param(":snapshotId"),
+		},
+	}
+
+	// Call global controller variable to handle unmanage volume snapshot request.
+	result := controller.Brain.UnmanageVolumeSnapshot(ctx, &snapshot)
+	if result.Status != "Success" {
+		recordEvent(this.Ctx, model.EventResourceSnapshot, snapshot.GetId(), "unmanage", errors.New(result.GetError()))
+		reason := fmt.Sprintf("Unmanage volume snapshot failed: %s", result.GetError())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	recordEvent(this.Ctx, model.EventResourceSnapshot, snapshot.GetId(), "unmanage", nil)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume snapshot unmanaged result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the metrics of osdslet, which are exposed on /metrics
+endpoint of the REST service:
+
+  opensds_api_requests_total{route,method,code}
+      counter of the api requests, route is the pattern of matched router
+      such as /v1alpha/block/volumes/:volumeId.
+  opensds_api_request_duration_seconds{route,method}
+      histogram of the latencies of api requests.
+  opensds_pool_total_capacity_bytes{pool,dock}
+  opensds_pool_free_capacity_bytes{pool,dock}
+      capacities of the storage pools reported by docks.
+  opensds_volumes{status}
+      number of volumes in each status.
+
+*/
+
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/astaxie/beego/context"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/utils/metrics"
+)
+
+const (
+	// The key of the start time stored in the input data of beego context.
+	startTimeDataKey = "startTime"
+
+	bytesPerGB = 1 << 30
+)
+
+var (
+	apiRequestsTotal = metrics.NewCounterVec(
+		"opensds_api_requests_total",
+		"Total number of api requests by route, method and status code.",
+		"route", "method", "code",
+	)
+	apiRequestDuration = metrics.NewHistogramVec(
+		"opensds_api_request_duration_seconds",
+		"Latency of api requests in seconds by route and method.",
+		metrics.DefBuckets, "route", "method",
+	)
+	poolTotalCapacity = metrics.NewGaugeFunc(
+		"opensds_pool_total_capacity_bytes",
+		"Total capacity of storage pools in bytes.",
+		[]string{"pool", "dock"}, collectPoolCapacity(false),
+	)
+	poolFreeCapacity = metrics.NewGaugeFunc(
+		"opensds_pool_free_capacity_bytes",
+		"Free capacity of storage pools in bytes.",
+		[]string{"pool", "dock"}, collectPoolCapacity(true),
+	)
+	volumesByStatus = metrics.NewGaugeFunc(
+		"opensds_volumes",
+		"Number of volumes by status.",
+		[]string{"status"}, collectVolumes,
+	)
+)
+
+func init() {
+	metrics.MustRegister(apiRequestsTotal, apiRequestDuration,
+		poolTotalCapacity, poolFreeCapacity, volumesByStatus)
+}
+
+// beforeRequest records the start time of api request.
+func beforeRequest(ctx *context.Context) {
+	ctx.Input.SetData(startTimeDataKey, time.Now())
+}
+
+// afterRequest observes the status and latency of api request, it should be
+// inserted with returnOnOutput disabled so that it's called after the
+// response is written. Note that the requests which don't match any router
+// are not observed since beego skips the finish filters for them.
+func afterRequest(ctx *context.Context) {
+	start, ok := ctx.Input.GetData(startTimeDataKey).(time.Time)
+	if !ok {
+		return
+	}
+	route, _ := ctx.Input.GetData("RouterPattern").(string)
+	code := ctx.ResponseWriter.Status
+	if code == 0 {
+		code = StatusOK
+	}
+	method := ctx.Input.Method()
+
+	apiRequestsTotal.Inc(route, method, strconv.Itoa(code))
+	apiRequestDuration.Observe(time.Since(start).Seconds(), route, method)
+}
+
+func collectPoolCapacity(free bool) func() ([]metrics.Sample, error) {
+	return func() ([]metrics.Sample, error) {
+		pols, err := db.C.ListPools()
+		if err != nil {
+			return nil, err
+		}
+		var samples []metrics.Sample
+		for _, pol := range pols {
+			capacity := pol.GetTotalCapacity()
+			if free {
+				capacity = pol.GetFreeCapacity()
+			}
+			samples = append(samples, metrics.Sample{
+				LabelValues: []string{pol.GetId(), pol.GetDockId()},
+				Value:       float64(capacity) * bytesPerGB,
+			})
+		}
+		return samples, nil
+	}
+}
+
+func collectVolumes() ([]metrics.Sample, error) {
+	vols, err := db.C.ListVolumes(nil)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, vol := range vols {
+		counts[vol.GetStatus()]++
+	}
+	var samples []metrics.Sample
+	for status, n := range counts {
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{status},
+			Value:       float64(n),
+		})
+	}
+	return samples, nil
+}
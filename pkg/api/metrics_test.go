@@ -0,0 +1,81 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils/metrics"
+)
+
+func init() {
+	beego.InsertFilter("*", beego.BeforeRouter, beforeRequest)
+	beego.InsertFilter("*", beego.FinishRouter, afterRequest, false)
+	beego.Handler("/metrics", metrics.Handler())
+}
+
+func TestScrapeMetrics(t *testing.T) {
+	var fakePools = []*model.StoragePoolSpec{
+		{
+			BaseModel:     &model.BaseModel{Id: "084bf71e-a102-11e7-88a8-e31fe6d52248"},
+			DockId:        "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
+			TotalCapacity: 100,
+			FreeCapacity:  90,
+		},
+	}
+	var fakeVolumes = []*model.VolumeSpec{
+		{BaseModel: &model.BaseModel{Id: "bd5b12a8-a101-11e7-941e-d77981b584d8"}, Status: "available"},
+		{BaseModel: &model.BaseModel{Id: "f4a5e666-c669-4c64-a2a1-8f9ecd560c78"}, Status: "available"},
+		{BaseModel: &model.BaseModel{Id: "9193c3ec-771f-11e7-8ca3-d32c0a8b2725"}, Status: "error"},
+	}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListPools").Return(fakePools, nil)
+	mockClient.On("ListVolumes", (*model.ListOptions)(nil)).Return(fakeVolumes, nil)
+	db.C = mockClient
+
+	// The first scrape is observed after it's responded, so that it shows up
+	// in the output of the second one.
+	r, _ := http.NewRequest("GET", "/metrics", nil)
+	beego.BeeApp.Handlers.ServeHTTP(httptest.NewRecorder(), r)
+
+	r, _ = http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+	output := w.Body.String()
+	for _, expected := range []string{
+		`opensds_api_requests_total{route="/metrics",method="GET",code="200"} 1`,
+		`opensds_api_request_duration_seconds_count{route="/metrics",method="GET"} 1`,
+		`opensds_pool_total_capacity_bytes{pool="084bf71e-a102-11e7-88a8-e31fe6d52248",dock="b7602e18-771e-11e7-8f38-dbd6d291f4e0"} 1.073741824e+11`,
+		`opensds_pool_free_capacity_bytes{pool="084bf71e-a102-11e7-88a8-e31fe6d52248",dock="b7602e18-771e-11e7-8f38-dbd6d291f4e0"} 9.663676416e+10`,
+		`opensds_volumes{status="available"} 2`,
+		`opensds_volumes{status="error"} 1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in metrics output:\n%s", expected, output)
+		}
+	}
+}
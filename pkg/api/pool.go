@@ -24,7 +24,6 @@ import (
 	"fmt"
 
 	"github.com/astaxie/beego"
-	log "github.com/golang/glog"
 	"github.com/opensds/opensds/pkg/db"
 	"github.com/opensds/opensds/pkg/utils"
 )
@@ -34,13 +33,15 @@ type PoolPortal struct {
 }
 
 func (this *PoolPortal) ListPools() {
+	logger := newLogger(this.Ctx)
+
 	// Call db api module to handle list pools request.
 	result, err := db.C.ListPools()
 	if err != nil {
 		reason := fmt.Sprintf("List pools failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -50,7 +51,7 @@ func (this *PoolPortal) ListPools() {
 		reason := fmt.Sprintf("Marshal pools failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -60,6 +61,8 @@ func (this *PoolPortal) ListPools() {
 }
 
 func (this *PoolPortal) GetPool() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":poolId")
 
 	result, err := db.C.GetPool(id)
@@ -67,7 +70,7 @@ func (this *PoolPortal) GetPool() {
 		reason := fmt.Sprintf("Get pool failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -77,7 +80,7 @@ func (this *PoolPortal) GetPool() {
 		reason := fmt.Sprintf("Marshal pool failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
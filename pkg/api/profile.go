@@ -24,7 +24,6 @@ import (
 	"fmt"
 
 	"github.com/astaxie/beego"
-	log "github.com/golang/glog"
 	"github.com/opensds/opensds/pkg/db"
 	"github.com/opensds/opensds/pkg/model"
 	"github.com/opensds/opensds/pkg/utils"
@@ -35,6 +34,8 @@ type ProfilePortal struct {
 }
 
 func (this *ProfilePortal) CreateProfile() {
+	logger := newLogger(this.Ctx)
+
 	var profile = model.ProfileSpec{
 		BaseModel: &model.BaseModel{},
 	}
@@ -44,7 +45,16 @@ func (this *ProfilePortal) CreateProfile() {
 		reason := fmt.Sprintf("Parse profile request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
+		return
+	}
+
+	// Validate the profile extras against the schema.
+	if err := model.ProfileExtraSchema.Validate(profile.Extra); err != nil {
+		reason := fmt.Sprintf("Validate profile extras failed: %v", err)
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
 		return
 	}
 
@@ -53,18 +63,20 @@ func (this *ProfilePortal) CreateProfile() {
 		reason := fmt.Sprintf("Validate profile data failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
 	// Call db api module to handle create profile request.
 	if err := db.C.CreateProfile(&profile); err != nil {
+		recordEvent(this.Ctx, model.EventResourceProfile, profile.GetId(), "create", err)
 		reason := fmt.Sprintf("Create profile failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceProfile, profile.GetId(), "create", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(&profile)
@@ -72,7 +84,7 @@ func (this *ProfilePortal) CreateProfile() {
 		reason := fmt.Sprintf("Marshal profile created result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -82,12 +94,23 @@ func (this *ProfilePortal) CreateProfile() {
 }
 
 func (this *ProfilePortal) ListProfiles() {
-	result, err := db.C.ListProfiles()
+	logger := newLogger(this.Ctx)
+
+	opts, err := parseListOptions(this.Ctx)
+	if err != nil {
+		reason := fmt.Sprintf("Parse list options failed: %v", err)
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	result, err := db.C.ListProfiles(opts)
 	if err != nil {
 		reason := fmt.Sprintf("List profiles failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -97,7 +120,7 @@ func (this *ProfilePortal) ListProfiles() {
 		reason := fmt.Sprintf("Marshal profiles listed result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -107,6 +130,8 @@ func (this *ProfilePortal) ListProfiles() {
 }
 
 func (this *ProfilePortal) GetProfile() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":profileId")
 
 	result, err := db.C.GetProfile(id)
@@ -114,7 +139,7 @@ func (this *ProfilePortal) GetProfile() {
 		reason := fmt.Sprintf("Get profile failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -124,7 +149,7 @@ func (this *ProfilePortal) GetProfile() {
 		reason := fmt.Sprintf("Marshal profile got result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -134,6 +159,8 @@ func (this *ProfilePortal) GetProfile() {
 }
 
 func (this *ProfilePortal) UpdateProfile() {
+	logger := newLogger(this.Ctx)
+
 	var profile = model.ProfileSpec{
 		BaseModel: &model.BaseModel{},
 	}
@@ -143,18 +170,29 @@ func (this *ProfilePortal) UpdateProfile() {
 		reason := fmt.Sprintf("Parse profile request body failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
+		return
+	}
+
+	// Validate the profile extras against the schema.
+	if err := model.ProfileExtraSchema.Validate(profile.Extra); err != nil {
+		reason := fmt.Sprintf("Validate profile extras failed: %v", err)
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
 		return
 	}
 
 	result, err := db.C.UpdateProfile(id, &profile)
 	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceProfile, id, "update", err)
 		reason := fmt.Sprintf("Update profiles failed: %v", err)
-		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.SetStatus(updateErrorStatus(err))
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceProfile, id, "update", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -162,7 +200,7 @@ func (this *ProfilePortal) UpdateProfile() {
 		reason := fmt.Sprintf("Marshal profile updated result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -172,15 +210,19 @@ func (this *ProfilePortal) UpdateProfile() {
 }
 
 func (this *ProfilePortal) DeleteProfile() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":profileId")
 
 	if err := db.C.DeleteProfile(id); err != nil {
+		recordEvent(this.Ctx, model.EventResourceProfile, id, "delete", err)
 		reason := fmt.Sprintf("Delete profiles failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceProfile, id, "delete", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(&model.Response{
@@ -191,7 +233,7 @@ func (this *ProfilePortal) DeleteProfile() {
 		reason := fmt.Sprintf("Marshal profile deleted result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -201,6 +243,8 @@ func (this *ProfilePortal) DeleteProfile() {
 }
 
 func (this *ProfilePortal) AddExtraProperty() {
+	logger := newLogger(this.Ctx)
+
 	var extra model.ExtraSpec
 	id := this.Ctx.Input.Param(":profileId")
 
@@ -208,18 +252,29 @@ func (this *ProfilePortal) AddExtraProperty() {
 		reason := fmt.Sprintf("Parse extra request body failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
+		return
+	}
+
+	// Validate the extra properties against the schema.
+	if err := model.ProfileExtraSchema.Validate(extra); err != nil {
+		reason := fmt.Sprintf("Validate extra properties failed: %v", err)
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
 		return
 	}
 
 	result, err := db.C.AddExtraProperty(id, extra)
 	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceProfile, id, "update", err)
 		reason := fmt.Sprintf("Create extra property failed: %s", err)
-		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.SetStatus(updateErrorStatus(err))
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceProfile, id, "update", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -227,7 +282,7 @@ func (this *ProfilePortal) AddExtraProperty() {
 		reason := fmt.Sprintf("Marshal extra property added result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -237,6 +292,8 @@ func (this *ProfilePortal) AddExtraProperty() {
 }
 
 func (this *ProfilePortal) ListExtraProperties() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":profileId")
 
 	result, err := db.C.ListExtraProperties(id)
@@ -244,7 +301,7 @@ func (this *ProfilePortal) ListExtraProperties() {
 		reason := fmt.Sprintf("List extra properties failed: %s", err)
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -254,7 +311,7 @@ func (this *ProfilePortal) ListExtraProperties() {
 		reason := fmt.Sprintf("Marshal extra properties listed result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -264,16 +321,20 @@ func (this *ProfilePortal) ListExtraProperties() {
 }
 
 func (this *ProfilePortal) RemoveExtraProperty() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":profileId")
 	extraKey := this.Ctx.Input.Param(":extraKey")
 
 	if err := db.C.RemoveExtraProperty(id, extraKey); err != nil {
+		recordEvent(this.Ctx, model.EventResourceProfile, id, "update", err)
 		reason := fmt.Sprintf("Remove extra property failed: %s", err.Error())
-		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.SetStatus(updateErrorStatus(err))
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceProfile, id, "update", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(&model.Response{
@@ -284,7 +345,25 @@ func (this *ProfilePortal) RemoveExtraProperty() {
 		reason := fmt.Sprintf("Marshal extra property removed result failed: %v", err)
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusOK)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *ProfilePortal) GetExtraSchema() {
+	logger := newLogger(this.Ctx)
+
+	// Marshal the result.
+	body, err := json.Marshal(model.ProfileExtraSchema)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal profile extras schema failed: %v", err)
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
 		return
 	}
 
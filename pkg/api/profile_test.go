@@ -30,11 +30,13 @@ import (
 	"github.com/opensds/opensds/pkg/model"
 	"github.com/opensds/opensds/pkg/utils"
 	mockSetter "github.com/opensds/opensds/pkg/utils/testing"
+	"github.com/stretchr/testify/mock"
 )
 
 func init() {
 	var profilePortal ProfilePortal
 	beego.Router("/v1alpha/profiles", &profilePortal, "post:CreateProfile;get:ListProfiles")
+	beego.Router("/v1alpha/profiles/schema", &profilePortal, "get:GetExtraSchema")
 	beego.Router("/v1alpha/profiles/:profileId", &profilePortal, "get:GetProfile;put:UpdateProfile;delete:DeleteProfile")
 	beego.Router("/v1alpha/profiles/:profileId/extras", &profilePortal, "post:AddExtraProperty;get:ListExtraProperties")
 	beego.Router("/v1alpha/profiles/:profileId/extras/:extraKey", &profilePortal, "delete:RemoveExtraProperty")
@@ -49,12 +51,9 @@ func init() {
 
 var (
 	fakeExtras = model.ExtraSpec{
-		"key1": "val1",
-		"key2": "val2",
-		"key3": map[string]interface{}{
-			"subKey1": "subVal1",
-			"subKey2": "subVal2",
-		},
+		"diskType":      "SSD",
+		"iops":          float64(1000),
+		"thinProvision": true,
 	}
 	fakeProfile = &model.ProfileSpec{
 		BaseModel: &model.BaseModel{
@@ -86,6 +85,7 @@ func TestCreateProfile(t *testing.T) {
 		},
 		Name:        "Gold",
 		Description: "Gold service"}).Return(nil)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
 	db.C = mockClient
 
 	r, _ := http.NewRequest("POST", "/v1alpha/profiles", strings.NewReader(fakeBody))
@@ -116,10 +116,33 @@ func TestCreateProfile(t *testing.T) {
 	}
 }
 
+func TestCreateProfileWithInvalidExtras(t *testing.T) {
+	var fakeBody = `{
+			"name": "Gold",
+			"description": "Gold service",
+			"extras": {
+				"iops": "fast"
+			}
+		}`
+
+	mockClient := new(dbtest.MockClient)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("POST", "/v1alpha/profiles", strings.NewReader(fakeBody))
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Errorf("Expected 400, actual %v", w.Code)
+	}
+	mockClient.AssertNotCalled(t, "CreateProfile")
+}
+
 func TestUpdateProfile(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
 	mockClient.On("UpdateProfile", "f4a5e666-c669-4c64-a2a1-8f9ecd560c78", fakeProfile).Return(fakeProfile, nil)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
 	db.C = mockClient
 
 	var fakeBody = `
@@ -130,12 +153,9 @@ func TestUpdateProfile(t *testing.T) {
 			"createdAt": "2017-10-24T16:21:32",
 			"updatedAt": "",
 			"extras": {
-				"key1": "val1",
-				"key2": "val2",
-				"key3": {
-					"subKey1": "subVal1",
-					"subKey2": "subVal2"
-				}
+				"diskType": "SSD",
+				"iops": 1000,
+				"thinProvision": true
 			}	
 		}`
 	r, _ := http.NewRequest("PUT", "/v1alpha/profiles/f4a5e666-c669-4c64-a2a1-8f9ecd560c78", strings.NewReader(fakeBody))
@@ -153,12 +173,9 @@ func TestUpdateProfile(t *testing.T) {
 			"createdAt": "2017-10-24T16:21:32",
 			"updatedAt": "",
 			"extras": {
-				"key1": "val1",
-				"key2": "val2",
-				"key3": {
-					"subKey1": "subVal1",
-					"subKey2": "subVal2"
-				}
+				"diskType": "SSD",
+				"iops": 1000,
+				"thinProvision": true
 			}	
 		}`
 
@@ -174,10 +191,27 @@ func TestUpdateProfile(t *testing.T) {
 	}
 }
 
+func TestUpdateProfileWithConflict(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("UpdateProfile", "f4a5e666-c669-4c64-a2a1-8f9ecd560c78", mock.Anything).Return(nil, utils.ErrConflict)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
+	db.C = mockClient
+
+	var fakeBody = `{"name": "Gold", "resourceVersion": 7}`
+	r, _ := http.NewRequest("PUT", "/v1alpha/profiles/f4a5e666-c669-4c64-a2a1-8f9ecd560c78", strings.NewReader(fakeBody))
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 409 {
+		t.Errorf("Expected 409, actual %v", w.Code)
+	}
+}
+
 func TestListProfiles(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
-	mockClient.On("ListProfiles").Return(fakeProfiles, nil)
+	mockClient.On("ListProfiles", mock.Anything).Return(fakeProfiles, nil)
 	db.C = mockClient
 
 	r, _ := http.NewRequest("GET", "/v1alpha/profiles", nil)
@@ -195,12 +229,9 @@ func TestListProfiles(t *testing.T) {
 			"createdAt": "2017-10-24T16:21:32",
 			"updatedAt": "",
 			"extras": {
-				"key1": "val1",
-				"key2": "val2",
-				"key3": {
-					"subKey1": "subVal1",
-					"subKey2": "subVal2"
-				}
+				"diskType": "SSD",
+				"iops": 1000,
+				"thinProvision": true
 			}	
 		}
 	]`
@@ -220,7 +251,7 @@ func TestListProfiles(t *testing.T) {
 func TestListProfilesWithBadRequest(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
-	mockClient.On("ListProfiles").Return(nil, errors.New("db error"))
+	mockClient.On("ListProfiles", mock.Anything).Return(nil, errors.New("db error"))
 	db.C = mockClient
 
 	r, _ := http.NewRequest("GET", "/v1alpha/profiles", nil)
@@ -253,12 +284,9 @@ func TestGetProfile(t *testing.T) {
 			"createdAt": "2017-10-24T16:21:32",
 			"updatedAt": "",
 			"extras": {
-				"key1": "val1",
-				"key2": "val2",
-				"key3": {
-					"subKey1": "subVal1",
-					"subKey2": "subVal2"
-				}
+				"diskType": "SSD",
+				"iops": 1000,
+				"thinProvision": true
 			}	
 		}`
 
@@ -295,6 +323,7 @@ func TestDeleteProfile(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
 	mockClient.On("DeleteProfile", "f4a5e666-c669-4c64-a2a1-8f9ecd560c78").Return(nil)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
 	db.C = mockClient
 
 	r, _ := http.NewRequest("DELETE",
@@ -305,6 +334,15 @@ func TestDeleteProfile(t *testing.T) {
 	if w.Code != 200 {
 		t.Errorf("Expected 200, actual %v", w.Code)
 	}
+	reqID := w.Header().Get("X-Openstack-Request-Id")
+	if !strings.HasPrefix(reqID, "req-") {
+		t.Errorf("Expected request id in response header, actual %v", reqID)
+	}
+	mockClient.AssertCalled(t, "CreateEvent", mock.MatchedBy(func(evt *model.EventSpec) bool {
+		return evt.ResourceId == "f4a5e666-c669-4c64-a2a1-8f9ecd560c78" &&
+			evt.Action == "delete" && evt.Outcome == model.EventOutcomeSuccess &&
+			evt.RequestId == reqID
+	}))
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -325,12 +363,9 @@ func TestListExtraProperties(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &output)
 
 	expectedJson := `{
-		"key1": "val1",
-		"key2": "val2",
-		"key3": {
-			"subKey1": "subVal1",
-			"subKey2": "subVal2"
-		}
+		"diskType": "SSD",
+		"iops": 1000,
+		"thinProvision": true
 	}`
 
 	var expected model.ExtraSpec
@@ -364,16 +399,14 @@ func TestAddExtraProperty(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
 	mockClient.On("AddExtraProperty", "f4a5e666-c669-4c64-a2a1-8f9ecd560c78", fakeExtras).Return(&fakeExtras, nil)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
 	db.C = mockClient
 
 	var fakeBody = `
 		{
-				"key1": "val1",
-				"key2": "val2",
-				"key3": {
-					"subKey1": "subVal1",
-					"subKey2": "subVal2"
-				}
+				"diskType": "SSD",
+				"iops": 1000,
+				"thinProvision": true
 		}`
 	r, _ := http.NewRequest("POST", "/v1alpha/profiles/f4a5e666-c669-4c64-a2a1-8f9ecd560c78/extras", strings.NewReader(fakeBody))
 	w := httptest.NewRecorder()
@@ -384,12 +417,9 @@ func TestAddExtraProperty(t *testing.T) {
 
 	expectedJson := `
 		{
-				"key1": "val1",
-				"key2": "val2",
-				"key3": {
-					"subKey1": "subVal1",
-					"subKey2": "subVal2"
-				}	
+				"diskType": "SSD",
+				"iops": 1000,
+				"thinProvision": true	
 		}`
 
 	var expected model.ExtraSpec
@@ -404,10 +434,27 @@ func TestAddExtraProperty(t *testing.T) {
 	}
 }
 
+func TestAddExtraPropertyWithUnknownKey(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	db.C = mockClient
+
+	var fakeBody = `{"key1": "val1"}`
+	r, _ := http.NewRequest("POST", "/v1alpha/profiles/f4a5e666-c669-4c64-a2a1-8f9ecd560c78/extras", strings.NewReader(fakeBody))
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Errorf("Expected 400, actual %v", w.Code)
+	}
+	mockClient.AssertNotCalled(t, "AddExtraProperty")
+}
+
 func TestRemoveExtraProperty(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
 	mockClient.On("RemoveExtraProperty", "f4a5e666-c669-4c64-a2a1-8f9ecd560c78", "key1").Return(nil)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
 	db.C = mockClient
 
 	r, _ := http.NewRequest("DELETE",
@@ -419,3 +466,22 @@ func TestRemoveExtraProperty(t *testing.T) {
 		t.Errorf("Expected 200, actual %v", w.Code)
 	}
 }
+
+func TestGetExtraSchema(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/v1alpha/profiles/schema", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	var output model.ExtraSchema
+	json.Unmarshal(w.Body.Bytes(), &output)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+	if output.Version != model.ExtraSchemaVersion {
+		t.Errorf("Expected version %v, actual %v", model.ExtraSchemaVersion, output.Version)
+	}
+	if p, ok := output.Properties["bandwidth"]; !ok || p.Type != "integer" || p.Unit != "MB/s" {
+		t.Errorf("Expected bandwidth in MB/s, actual %v", p)
+	}
+}
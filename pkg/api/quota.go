@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the OpenSDS northbound service.
+
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+type QuotaPortal struct {
+	beego.Controller
+}
+
+func (this *QuotaPortal) GetQuota() {
+	logger := newLogger(this.Ctx)
+
+	prjID := this.Ctx.Input.Param(":projectId")
+
+	result, err := db.C.GetQuota(prjID)
+	if err != nil {
+		reason := fmt.Sprintf("Get quota failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal quota showed result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusOK)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *QuotaPortal) UpdateQuota() {
+	logger := newLogger(this.Ctx)
+
+	var quota = model.QuotaSpec{
+		BaseModel: &model.BaseModel{},
+	}
+	prjID := this.Ctx.Input.Param(":projectId")
+
+	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&quota); err != nil {
+		reason := fmt.Sprintf("Parse quota request body failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	for res, limit := range quota.GetResourceList() {
+		if limit < 0 {
+			reason := fmt.Sprintf("Update quota failed: limit of %s can't be negative", res)
+			this.Ctx.Output.SetStatus(StatusBadRequest)
+			this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+			logger.Error(reason)
+			return
+		}
+	}
+
+	result, err := db.C.UpdateQuota(prjID, &quota)
+	if err != nil {
+		reason := fmt.Sprintf("Update quota failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(updateErrorStatus(err))
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal quota updated result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusOK)
+	this.Ctx.Output.Body(body)
+	return
+}
@@ -24,6 +24,10 @@ import (
 
 	"github.com/astaxie/beego"
 	"github.com/astaxie/beego/context"
+	osdsctx "github.com/opensds/opensds/pkg/context"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/opensds/opensds/pkg/utils/metrics"
+	netctx "golang.org/x/net/context"
 )
 
 const (
@@ -34,12 +38,27 @@ const (
 	StatusUnauthorized = http.StatusUnauthorized
 	StatusForbidden    = http.StatusForbidden
 	StatusNotFound     = http.StatusNotFound
+	StatusConflict     = http.StatusConflict
+
+	StatusUnprocessableEntity = http.StatusUnprocessableEntity
 
 	StatusInternalServerError = http.StatusInternalServerError
 	StatusNotImplemented      = http.StatusNotImplemented
 )
 
+// The key of the request id stored in the input data of beego context.
+const requestIdDataKey = "requestId"
+
 func Run(host string) {
+	// Generate a request id for every api call, which is returned to client
+	// in the response header.
+	beego.InsertFilter("*", beego.BeforeRouter, func(ctx *context.Context) {
+		requestId(ctx)
+	})
+	// Observe the count and latency of every api call, the finish filter
+	// must be run even if the response has been written.
+	beego.InsertFilter("*", beego.BeforeRouter, beforeRequest)
+	beego.InsertFilter("*", beego.FinishRouter, afterRequest, false)
 
 	// add router for v1alpha api
 	ns :=
@@ -61,12 +80,25 @@ func Run(host string) {
 			// CreateProfile, UpdateProfile and DeleteProfile are used for admin only
 			// ListProfiles and GetProfile are used for both admin and users
 			beego.NSRouter("/profiles", &ProfilePortal{}, "post:CreateProfile;get:ListProfiles"),
+			// The schema of profile extras, which could be used for rendering profile forms
+			beego.NSRouter("/profiles/schema", &ProfilePortal{}, "get:GetExtraSchema"),
 			beego.NSRouter("/profiles/:profileId", &ProfilePortal{}, "get:GetProfile;put:UpdateProfile;delete:DeleteProfile"),
 
 			// All operations of extras are used for Admin only
 			beego.NSRouter("/profiles/:profileId/extras", &ProfilePortal{}, "post:AddExtraProperty;get:ListExtraProperties"),
 			beego.NSRouter("/profiles/:profileId/extras/:extraKey", &ProfilePortal{}, "delete:RemoveExtraProperty"),
 
+			// Event is the audit record of operations on resources, which could be
+			// filtered by resource and time range
+			beego.NSRouter("/events", &EventPortal{}, "get:ListEvents"),
+
+			// Watch streams the creation, update and deletion of the resource given
+			// by the resource parameter as server-sent events
+			beego.NSRouter("/watch", &WatchPortal{}, "get:Watch"),
+
+			// Quota limits the volumes and gigabytes a project could own, admin only
+			beego.NSRouter("/quotas/:projectId", &QuotaPortal{}, "get:GetQuota;put:UpdateQuota"),
+
 			beego.NSNamespace("/block",
 				// Pool is the virtual description of backend storage, usually divided into block, file and object,
 				// and every pool is atomic, which means every pool contains a specific set of features.
@@ -77,7 +109,13 @@ func Run(host string) {
 				// Volume is the logical description of a piece of storage, which can be directly used by users.
 				// All operations of volume can be used for both admin and users.
 				beego.NSRouter("/volumes", &VolumePortal{}, "post:CreateVolume;get:ListVolumes"),
+				// Manages an existing volume of the backend, and unmanages a volume without deleting its data.
+				beego.NSRouter("/volumes/manage", &VolumePortal{}, "post:ManageVolume"),
 				beego.NSRouter("/volumes/:volumeId", &VolumePortal{}, "get:GetVolume;put:UpdateVolume;delete:DeleteVolume"),
+				beego.NSRouter("/volumes/:volumeId/unmanage", &VolumePortal{}, "post:UnmanageVolume"),
+				// Lists and shows attachments which belong to the specified volume.
+				beego.NSRouter("/volumes/:volumeId/attachments", &VolumeAttachmentPortal{}, "get:ListVolumeAttachments"),
+				beego.NSRouter("/volumes/:volumeId/attachments/:attachmentId", &VolumeAttachmentPortal{}, "get:GetVolumeAttachment;put:UpdateVolumeAttachment"),
 
 				// Creates, shows, lists, unpdates and deletes attachment.
 				beego.NSRouter("/attachments", &VolumeAttachmentPortal{}, "post:CreateVolumeAttachment;get:ListVolumeAttachments"),
@@ -86,7 +124,15 @@ func Run(host string) {
 				// Snapshot is a point-in-time copy of the data that a volume contains.
 				// Creates, shows, lists, unpdates and deletes snapshot.
 				beego.NSRouter("/snapshots", &VolumeSnapshotPortal{}, "post:CreateVolumeSnapshot;get:ListVolumeSnapshots"),
+				beego.NSRouter("/snapshots/manage", &VolumeSnapshotPortal{}, "post:ManageVolumeSnapshot"),
 				beego.NSRouter("/snapshots/:snapshotId", &VolumeSnapshotPortal{}, "get:GetVolumeSnapshot;put:UpdateVolumeSnapshot;delete:DeleteVolumeSnapshot"),
+				beego.NSRouter("/snapshots/:snapshotId/unmanage", &VolumeSnapshotPortal{}, "post:UnmanageVolumeSnapshot"),
+
+				// Transfer hands a volume from the project owning it to another one.
+				// Creates, shows, lists, accepts and deletes transfer.
+				beego.NSRouter("/transfers", &VolumeTransferPortal{}, "post:CreateVolumeTransfer;get:ListVolumeTransfers"),
+				beego.NSRouter("/transfers/:transferId", &VolumeTransferPortal{}, "get:GetVolumeTransfer;delete:DeleteVolumeTransfer"),
+				beego.NSRouter("/transfers/:transferId/accept", &VolumeTransferPortal{}, "post:AcceptVolumeTransfer"),
 			),
 		)
 
@@ -96,6 +142,42 @@ func Run(host string) {
 	beego.Router("/", &VersionPortal{}, "get:ListVersions")
 	beego.Router("/:apiVersion", &VersionPortal{}, "get:GetVersion")
 
+	// add handler for prometheus to scrape metrics
+	beego.Handler("/metrics", metrics.Handler())
+
 	// start service
 	beego.Run(host)
 }
+
+// requestId returns the id of the api request, and generates it if it's not
+// generated yet.
+func requestId(ctx *context.Context) string {
+	if reqID, ok := ctx.Input.GetData(requestIdDataKey).(string); ok && reqID != "" {
+		return reqID
+	}
+	reqID := osdsctx.NewRequestId()
+	ctx.Input.SetData(requestIdDataKey, reqID)
+	ctx.Output.Header(osdsctx.RequestIdHeader, reqID)
+	return reqID
+}
+
+// newContext returns the context which carries the request id to controller
+// and dock modules.
+func newContext(ctx *context.Context) netctx.Context {
+	return osdsctx.NewRequestContext(requestId(ctx))
+}
+
+// newLogger returns the logger which prefixes log lines with the request id.
+func newLogger(ctx *context.Context) *osdsctx.Logger {
+	return &osdsctx.Logger{RequestId: requestId(ctx)}
+}
+
+// updateErrorStatus returns the status code of the failed update. A conflict
+// is reported as 409 so that the client could get the latest version of the
+// resource and retry.
+func updateErrorStatus(err error) int {
+	if err == utils.ErrConflict {
+		return StatusConflict
+	}
+	return StatusBadRequest
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the OpenSDS northbound service.
+
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/controller"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+type VolumeTransferPortal struct {
+	beego.Controller
+}
+
+func (this *VolumeTransferPortal) CreateVolumeTransfer() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var transfer = model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{},
+	}
+
+	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&transfer); err != nil {
+		reason := fmt.Sprintf("Parse volume transfer request body failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	result, err := controller.Brain.CreateVolumeTransfer(ctx, &transfer)
+	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceTransfer, transfer.GetId(), "create", err)
+		reason := fmt.Sprintf("Create volume transfer failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	recordEvent(this.Ctx, model.EventResourceTransfer, result.GetId(), "create", nil)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume transfer created result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumeTransferPortal) ListVolumeTransfers() {
+	logger := newLogger(this.Ctx)
+
+	opts, err := parseListOptions(this.Ctx)
+	if err != nil {
+		reason := fmt.Sprintf("Parse list options failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	result, err := db.C.ListVolumeTransfers(opts)
+	if err != nil {
+		reason := fmt.Sprintf("List volume transfers failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	for _, tr := range result {
+		hideTransferSecret(tr)
+	}
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume transfers listed result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusOK)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumeTransferPortal) GetVolumeTransfer() {
+	logger := newLogger(this.Ctx)
+
+	id := this.Ctx.Input.Param(":transferId")
+
+	result, err := db.C.GetVolumeTransfer(id)
+	if err != nil {
+		reason := fmt.Sprintf("Get volume transfer failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	hideTransferSecret(result)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume transfer showed result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusOK)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumeTransferPortal) AcceptVolumeTransfer() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var accept = model.VolumeTransferAcceptSpec{}
+	id := this.Ctx.Input.Param(":transferId")
+
+	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&accept); err != nil {
+		reason := fmt.Sprintf("Parse volume transfer accept request body failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	result, err := controller.Brain.AcceptVolumeTransfer(ctx, id, &accept)
+	recordEvent(this.Ctx, model.EventResourceTransfer, id, "accept", err)
+	if err != nil {
+		reason := fmt.Sprintf("Accept volume transfer failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(updateErrorStatus(err))
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume transfer accepted result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+func (this *VolumeTransferPortal) DeleteVolumeTransfer() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
+	var transfer = model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{},
+	}
+	id := this.Ctx.Input.Param(":transferId")
+
+	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&transfer); err != nil {
+		reason := fmt.Sprintf("Parse volume transfer request body failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	transfer.Id = id
+
+	result := controller.Brain.DeleteVolumeTransfer(ctx, &transfer)
+	if result.Status != "Success" {
+		recordEvent(this.Ctx, model.EventResourceTransfer, id, "delete", errors.New(result.GetError()))
+		reason := fmt.Sprintf("Delete volume transfer failed: %s", result.GetError())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+	recordEvent(this.Ctx, model.EventResourceTransfer, id, "delete", nil)
+
+	// Marshal the result.
+	body, err := json.Marshal(result)
+	if err != nil {
+		reason := fmt.Sprintf("Marshal volume transfer deleted result failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	this.Ctx.Output.SetStatus(StatusAccepted)
+	this.Ctx.Output.Body(body)
+	return
+}
+
+// hideTransferSecret removes the salted hash of the auth key, which should
+// never leave the db.
+func hideTransferSecret(tr *model.VolumeTransferSpec) {
+	tr.AuthKey, tr.Salt, tr.AuthKeyHash = "", "", ""
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func init() {
+	beego.Router("/v1alpha/block/transfers", &VolumeTransferPortal{},
+		"post:CreateVolumeTransfer;get:ListVolumeTransfers")
+	beego.Router("/v1alpha/block/transfers/:transferId", &VolumeTransferPortal{},
+		"get:GetVolumeTransfer;delete:DeleteVolumeTransfer")
+	beego.Router("/v1alpha/quotas/:projectId", &QuotaPortal{},
+		"get:GetQuota;put:UpdateQuota")
+}
+
+func newFakeTransfer() *model.VolumeTransferSpec {
+	return &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{
+			Id:        "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e",
+			CreatedAt: "2017-10-24T16:21:32",
+		},
+		Name:        "fake transfer",
+		VolumeId:    "f4a5e666-c669-4c64-a2a1-8f9ecd560c78",
+		ProjectId:   "a7b1b6c6-bc3f-11e7-9a4e-2b8b6b0a1b3c",
+		Salt:        "8d1e0f3a2b4c5d6e",
+		AuthKeyHash: "0a1b2c3d4e5f",
+	}
+}
+
+func TestGetVolumeTransfer(t *testing.T) {
+	var tr = newFakeTransfer()
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeTransfer", tr.Id).Return(tr, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/block/transfers/"+tr.Id, nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+	// The hash of auth key should never be returned.
+	if bytes.Contains(w.Body.Bytes(), []byte("authKeyHash")) ||
+		bytes.Contains(w.Body.Bytes(), []byte("salt")) {
+		t.Errorf("Expected auth key hash hidden, actual %s", w.Body.String())
+	}
+}
+
+func TestListVolumeTransfers(t *testing.T) {
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumeTransfers", mock.Anything).
+		Return([]*model.VolumeTransferSpec{newFakeTransfer()}, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/block/transfers", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	var output []model.VolumeTransferSpec
+	json.Unmarshal(w.Body.Bytes(), &output)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+	if len(output) != 1 || output[0].AuthKeyHash != "" || output[0].Salt != "" {
+		t.Errorf("Expected auth key hash hidden, actual %v", output)
+	}
+}
+
+func TestUpdateQuota(t *testing.T) {
+	var prjID = "a7b1b6c6-bc3f-11e7-9a4e-2b8b6b0a1b3c"
+	var quota = &model.QuotaSpec{
+		BaseModel:    &model.BaseModel{Id: prjID},
+		ResourceList: map[string]int64{model.QuotaVolumes: 10},
+	}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("UpdateQuota", prjID, mock.Anything).Return(quota, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("PUT", "/v1alpha/quotas/"+prjID,
+		bytes.NewBufferString(`{"resourceList": {"volumes": 10}}`))
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+
+	r, _ = http.NewRequest("PUT", "/v1alpha/quotas/"+prjID,
+		bytes.NewBufferString(`{"resourceList": {"volumes": -1}}`))
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Errorf("Expected 400, actual %v", w.Code)
+	}
+	mockClient.AssertNumberOfCalls(t, "UpdateQuota", 1)
+}
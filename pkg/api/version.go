@@ -24,7 +24,6 @@ import (
 	"fmt"
 
 	"github.com/astaxie/beego"
-	log "github.com/golang/glog"
 	"github.com/opensds/opensds/pkg/utils"
 )
 
@@ -42,12 +41,14 @@ type VersionPortal struct {
 }
 
 func (this *VersionPortal) ListVersions() {
+	logger := newLogger(this.Ctx)
+
 	body, err := json.Marshal(KnownVersions)
 	if err != nil {
 		reason := fmt.Sprintf("Marshal versions failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -57,6 +58,8 @@ func (this *VersionPortal) ListVersions() {
 }
 
 func (this *VersionPortal) GetVersion() {
+	logger := newLogger(this.Ctx)
+
 	apiVersion := this.Ctx.Input.Param(":apiVersion")
 
 	// Find version by specified api version
@@ -71,7 +74,7 @@ func (this *VersionPortal) GetVersion() {
 		reason := fmt.Sprintf("Can't find the version: %s", apiVersion)
 		this.Ctx.Output.SetStatus(StatusNotFound)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -81,7 +84,7 @@ func (this *VersionPortal) GetVersion() {
 		reason := fmt.Sprintf("Marshal version failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
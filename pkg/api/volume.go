@@ -21,10 +21,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
-	log "github.com/golang/glog"
-
 	"github.com/opensds/opensds/pkg/controller"
 	"github.com/opensds/opensds/pkg/db"
 	"github.com/opensds/opensds/pkg/model"
@@ -38,28 +37,45 @@ type VolumePortal struct {
 }
 
 func (this *VolumePortal) CreateVolume() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var volume = model.VolumeSpec{
 		BaseModel: &model.BaseModel{},
 	}
 
 	// Unmarshal the request body
-	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&volume); err != nil {
+	reqBody, key, err := readCreateBody(this.Ctx)
+	if err == nil {
+		err = json.Unmarshal(reqBody, &volume)
+	}
+	if err != nil {
 		reason := fmt.Sprintf("Parse volume request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
+		return
+	}
+
+	// The retries of the request with the same idempotency key get the
+	// original result.
+	idem := newIdempotentRequest(this.Ctx, key, model.EventResourceVolume, reqBody)
+	if !idem.reserve() {
 		return
 	}
+	defer idem.release()
 
 	// Call global controller variable to handle create volume request.
-	result, err := controller.Brain.CreateVolume(&volume)
+	result, err := controller.Brain.CreateVolume(ctx, &volume)
 	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceVolume, volume.GetId(), "create", err)
 		reason := fmt.Sprintf("Create volume failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceVolume, result.GetId(), "create", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -67,23 +83,35 @@ func (this *VolumePortal) CreateVolume() {
 		reason := fmt.Sprintf("Marshal volume created result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
+	idem.complete(StatusAccepted, body)
 	this.Ctx.Output.SetStatus(StatusAccepted)
 	this.Ctx.Output.Body(body)
 	return
 }
 
 func (this *VolumePortal) ListVolumes() {
+	logger := newLogger(this.Ctx)
+
+	opts, err := parseListOptions(this.Ctx)
+	if err != nil {
+		reason := fmt.Sprintf("Parse list options failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
 	// Call db api module to handle list volumes request.
-	result, err := db.C.ListVolumes()
+	result, err := db.C.ListVolumes(opts)
 	if err != nil {
 		reason := fmt.Sprintf("List volumes failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -93,7 +121,7 @@ func (this *VolumePortal) ListVolumes() {
 		reason := fmt.Sprintf("Marshal volumes listed result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -103,6 +131,8 @@ func (this *VolumePortal) ListVolumes() {
 }
 
 func (this *VolumePortal) GetVolume() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":volumeId")
 
 	// Call db api module to handle get volume request.
@@ -111,7 +141,7 @@ func (this *VolumePortal) GetVolume() {
 		reason := fmt.Sprintf("Get volume failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -121,7 +151,7 @@ func (this *VolumePortal) GetVolume() {
 		reason := fmt.Sprintf("Marshal volume showed result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -136,6 +166,9 @@ func (this *VolumePortal) UpdateVolume() {
 }
 
 func (this *VolumePortal) DeleteVolume() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var volume = model.VolumeSpec{
 		BaseModel: &model.BaseModel{},
 	}
@@ -145,20 +178,22 @@ func (this *VolumePortal) DeleteVolume() {
 		reason := fmt.Sprintf("Parse volume request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 	volume.Id = volId
 
 	// Call global controller variable to handle delete volume request.
-	result := controller.Brain.DeleteVolume(&volume)
+	result := controller.Brain.DeleteVolume(ctx, &volume)
 	if result.Status != "Success" {
+		recordEvent(this.Ctx, model.EventResourceVolume, volume.GetId(), "delete", errors.New(result.GetError()))
 		reason := fmt.Sprintf("Delete volume failed: %s", result.GetError())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceVolume, volume.GetId(), "delete", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -166,7 +201,7 @@ func (this *VolumePortal) DeleteVolume() {
 		reason := fmt.Sprintf("Marshal volume deleted result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -180,27 +215,44 @@ type VolumeAttachmentPortal struct {
 }
 
 func (this *VolumeAttachmentPortal) CreateVolumeAttachment() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var attachment = model.VolumeAttachmentSpec{
 		BaseModel: &model.BaseModel{},
 	}
 
-	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&attachment); err != nil {
+	reqBody, key, err := readCreateBody(this.Ctx)
+	if err == nil {
+		err = json.Unmarshal(reqBody, &attachment)
+	}
+	if err != nil {
 		reason := fmt.Sprintf("Parse volume attachment request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
+	// The retries of the request with the same idempotency key get the
+	// original result.
+	idem := newIdempotentRequest(this.Ctx, key, model.EventResourceAttachment, reqBody)
+	if !idem.reserve() {
+		return
+	}
+	defer idem.release()
+
 	// Call global controller variable to handle create volume attachment request.
-	result, err := controller.Brain.CreateVolumeAttachment(&attachment)
+	result, err := controller.Brain.CreateVolumeAttachment(ctx, &attachment)
 	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceAttachment, attachment.GetId(), "create", err)
 		reason := fmt.Sprintf("Create volume attachment failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceAttachment, result.GetId(), "create", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -208,24 +260,44 @@ func (this *VolumeAttachmentPortal) CreateVolumeAttachment() {
 		reason := fmt.Sprintf("Marshal volume attachment created result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
+	idem.complete(StatusAccepted, body)
 	this.Ctx.Output.SetStatus(StatusAccepted)
 	this.Ctx.Output.Body(body)
 	return
 }
 
 func (this *VolumeAttachmentPortal) ListVolumeAttachments() {
-	volId := this.GetString("volumeId")
+	logger := newLogger(this.Ctx)
 
-	result, err := db.C.ListVolumeAttachments(volId)
+	opts, err := parseListOptions(this.Ctx)
+	if err != nil {
+		reason := fmt.Sprintf("Parse list options failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	// The volume id is passed separately since the attachments are stored
+	// under the volume, it's taken from the path if the request is scoped to
+	// a volume, otherwise from the volumeId filter. Attachments of all volumes
+	// will be listed if neither is specified.
+	volId := opts.Filters["volumeId"]
+	delete(opts.Filters, "volumeId")
+	if id := this.Ctx.Input.Param(":volumeId"); id != "" {
+		volId = id
+	}
+
+	result, err := db.C.ListVolumeAttachments(volId, opts)
 	if err != nil {
 		reason := fmt.Sprintf("List volume attachments failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -235,7 +307,7 @@ func (this *VolumeAttachmentPortal) ListVolumeAttachments() {
 		reason := fmt.Sprintf("Marshal volume attachments listed result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -245,15 +317,20 @@ func (this *VolumeAttachmentPortal) ListVolumeAttachments() {
 }
 
 func (this *VolumeAttachmentPortal) GetVolumeAttachment() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":attachmentId")
-	volId := this.GetString("volumeId")
+	volId := this.Ctx.Input.Param(":volumeId")
+	if volId == "" {
+		volId = this.GetString("volumeId")
+	}
 
 	result, err := db.C.GetVolumeAttachment(volId, id)
 	if err != nil {
 		reason := fmt.Sprintf("Get volume attachment failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -263,7 +340,7 @@ func (this *VolumeAttachmentPortal) GetVolumeAttachment() {
 		reason := fmt.Sprintf("Marshal volume attachment showed result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -273,8 +350,9 @@ func (this *VolumeAttachmentPortal) GetVolumeAttachment() {
 }
 
 func (this *VolumeAttachmentPortal) UpdateVolumeAttachment() {
-	// TODO Please note that this function has been disabled in controller
-	// module.
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var attachment = model.VolumeAttachmentSpec{
 		BaseModel: &model.BaseModel{},
 	}
@@ -284,19 +362,24 @@ func (this *VolumeAttachmentPortal) UpdateVolumeAttachment() {
 		reason := fmt.Sprintf("Parse volume attachment request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 	attachment.Id = id
+	if volId := this.Ctx.Input.Param(":volumeId"); volId != "" {
+		attachment.VolumeId = volId
+	}
 
-	result, err := controller.Brain.UpdateVolumeAttachment(&attachment)
+	result, err := controller.Brain.UpdateVolumeAttachment(ctx, &attachment)
 	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceAttachment, attachment.GetId(), "update", err)
 		reason := fmt.Sprintf("Update volume attachment failed: %s", err.Error())
-		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.SetStatus(updateErrorStatus(err))
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceAttachment, result.GetId(), "update", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -304,7 +387,7 @@ func (this *VolumeAttachmentPortal) UpdateVolumeAttachment() {
 		reason := fmt.Sprintf("Marshal volume attachment updated result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -314,6 +397,9 @@ func (this *VolumeAttachmentPortal) UpdateVolumeAttachment() {
 }
 
 func (this *VolumeAttachmentPortal) DeleteVolumeAttachment() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var attachment = model.VolumeAttachmentSpec{
 		BaseModel: &model.BaseModel{},
 	}
@@ -323,20 +409,22 @@ func (this *VolumeAttachmentPortal) DeleteVolumeAttachment() {
 		reason := fmt.Sprintf("Parse volume attachment request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 	attachment.Id = id
 
 	// Call global controller variable to handle delete volume attachment request.
-	result := controller.Brain.DeleteVolumeAttachment(&attachment)
+	result := controller.Brain.DeleteVolumeAttachment(ctx, &attachment)
 	if result.Status != "Success" {
+		recordEvent(this.Ctx, model.EventResourceAttachment, attachment.GetId(), "delete", errors.New(result.GetError()))
 		reason := fmt.Sprintf("Delete volume attachment failed: %s", result.GetError())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceAttachment, attachment.GetId(), "delete", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -344,7 +432,7 @@ func (this *VolumeAttachmentPortal) DeleteVolumeAttachment() {
 		reason := fmt.Sprintf("Marshal volume attachment deleted result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -358,27 +446,44 @@ type VolumeSnapshotPortal struct {
 }
 
 func (this *VolumeSnapshotPortal) CreateVolumeSnapshot() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var snapshot = model.VolumeSnapshotSpec{
 		BaseModel: &model.BaseModel{},
 	}
 
-	if err := json.NewDecoder(this.Ctx.Request.Body).Decode(&snapshot); err != nil {
+	reqBody, key, err := readCreateBody(this.Ctx)
+	if err == nil {
+		err = json.Unmarshal(reqBody, &snapshot)
+	}
+	if err != nil {
 		reason := fmt.Sprintf("Parse volume snapshot request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
+		return
+	}
+
+	// The retries of the request with the same idempotency key get the
+	// original result.
+	idem := newIdempotentRequest(this.Ctx, key, model.EventResourceSnapshot, reqBody)
+	if !idem.reserve() {
 		return
 	}
+	defer idem.release()
 
 	// Call global controller variable to handle create volume snapshot request.
-	result, err := controller.Brain.CreateVolumeSnapshot(&snapshot)
+	result, err := controller.Brain.CreateVolumeSnapshot(ctx, &snapshot)
 	if err != nil {
+		recordEvent(this.Ctx, model.EventResourceSnapshot, snapshot.GetId(), "create", err)
 		reason := fmt.Sprintf("Create volume snapshot failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceSnapshot, result.GetId(), "create", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -386,22 +491,34 @@ func (this *VolumeSnapshotPortal) CreateVolumeSnapshot() {
 		reason := fmt.Sprintf("Marshal volume snapshot created result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
+	idem.complete(StatusAccepted, body)
 	this.Ctx.Output.SetStatus(StatusAccepted)
 	this.Ctx.Output.Body(body)
 	return
 }
 
 func (this *VolumeSnapshotPortal) ListVolumeSnapshots() {
-	result, err := db.C.ListVolumeSnapshots()
+	logger := newLogger(this.Ctx)
+
+	opts, err := parseListOptions(this.Ctx)
+	if err != nil {
+		reason := fmt.Sprintf("Parse list options failed: %s", err.Error())
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	result, err := db.C.ListVolumeSnapshots(opts)
 	if err != nil {
 		reason := fmt.Sprintf("List volume snapshots failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -411,7 +528,7 @@ func (this *VolumeSnapshotPortal) ListVolumeSnapshots() {
 		reason := fmt.Sprintf("Marshal volume snapshots listed result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -421,6 +538,8 @@ func (this *VolumeSnapshotPortal) ListVolumeSnapshots() {
 }
 
 func (this *VolumeSnapshotPortal) GetVolumeSnapshot() {
+	logger := newLogger(this.Ctx)
+
 	id := this.Ctx.Input.Param(":snapshotId")
 
 	result, err := db.C.GetVolumeSnapshot(id)
@@ -428,7 +547,7 @@ func (this *VolumeSnapshotPortal) GetVolumeSnapshot() {
 		reason := fmt.Sprintf("Get volume snapshot failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -438,7 +557,7 @@ func (this *VolumeSnapshotPortal) GetVolumeSnapshot() {
 		reason := fmt.Sprintf("Marshal volume snapshot showed result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -452,6 +571,9 @@ func (this *VolumeSnapshotPortal) UpdateVolumeSnapshot() {
 }
 
 func (this *VolumeSnapshotPortal) DeleteVolumeSnapshot() {
+	ctx := newContext(this.Ctx)
+	logger := newLogger(this.Ctx)
+
 	var snapshot = model.VolumeSnapshotSpec{
 		BaseModel: &model.BaseModel{},
 	}
@@ -461,20 +583,22 @@ func (this *VolumeSnapshotPortal) DeleteVolumeSnapshot() {
 		reason := fmt.Sprintf("Parse volume snapshot request body failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusInternalServerError)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 	snapshot.Id = id
 
 	// Call global controller variable to handle delete volume snapshot request.
-	result := controller.Brain.DeleteVolumeSnapshot(&snapshot)
+	result := controller.Brain.DeleteVolumeSnapshot(ctx, &snapshot)
 	if result.Status != "Success" {
+		recordEvent(this.Ctx, model.EventResourceSnapshot, snapshot.GetId(), "delete", errors.New(result.GetError()))
 		reason := fmt.Sprintf("Delete volume snapshot failed: %s", result.GetError())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
+	recordEvent(this.Ctx, model.EventResourceSnapshot, snapshot.GetId(), "delete", nil)
 
 	// Marshal the result.
 	body, err := json.Marshal(result)
@@ -482,7 +606,7 @@ func (this *VolumeSnapshotPortal) DeleteVolumeSnapshot() {
 		reason := fmt.Sprintf("Marshal volume snapshot deleted result failed: %s", err.Error())
 		this.Ctx.Output.SetStatus(StatusBadRequest)
 		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
-		log.Error(reason)
+		logger.Error(reason)
 		return
 	}
 
@@ -31,6 +31,7 @@ import (
 	"github.com/opensds/opensds/pkg/model"
 	"github.com/opensds/opensds/pkg/utils"
 	mockSetter "github.com/opensds/opensds/pkg/utils/testing"
+	"github.com/stretchr/testify/mock"
 )
 
 func init() {
@@ -43,6 +44,10 @@ func init() {
 		"post:CreateVolumeAttachment;get:ListVolumeAttachments")
 	beego.Router("/v1alpha/block/attachments/:attachmentId", &VolumeAttachmentPortal{},
 		"get:GetVolumeAttachment;put:UpdateVolumeAttachment;delete:DeleteVolumeAttachment")
+	beego.Router("/v1alpha/block/volumes/:volumeId/attachments", &VolumeAttachmentPortal{},
+		"get:ListVolumeAttachments")
+	beego.Router("/v1alpha/block/volumes/:volumeId/attachments/:attachmentId", &VolumeAttachmentPortal{},
+		"get:GetVolumeAttachment")
 
 	beego.Router("/v1alpha/block/snapshots", &VolumeSnapshotPortal{},
 		"post:CreateVolumeSnapshot;get:ListVolumeSnapshots")
@@ -82,7 +87,7 @@ var (
 func TestListVolumes(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
-	mockClient.On("ListVolumes").Return(fakeVolumes, nil)
+	mockClient.On("ListVolumes", mock.Anything).Return(fakeVolumes, nil)
 	db.C = mockClient
 
 	r, _ := http.NewRequest("GET", "/v1alpha/block/volumes", nil)
@@ -119,7 +124,7 @@ func TestListVolumes(t *testing.T) {
 func TestListVolumesWithBadRequest(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
-	mockClient.On("ListVolumes").Return(nil, errors.New("db error"))
+	mockClient.On("ListVolumes", mock.Anything).Return(nil, errors.New("db error"))
 	db.C = mockClient
 
 	r, _ := http.NewRequest("GET", "/v1alpha/block/volumes", nil)
@@ -131,6 +136,49 @@ func TestListVolumesWithBadRequest(t *testing.T) {
 	}
 }
 
+func TestListVolumesWithOptions(t *testing.T) {
+
+	expectedOpts := &model.ListOptions{
+		Limit:    10,
+		Offset:   1,
+		Marker:   "bd5b12a8-a101-11e7-941e-d77981b584d8",
+		SortKey:  "size",
+		SortDir:  "desc",
+		Filters:  map[string]string{"status": "available", "poolId": "831fa5fb-17cf-4410-bec6-1f4b06208eef"},
+		Metadata: map[string]string{"tier": "gold", "zone": "default"},
+	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumes", expectedOpts).Return(fakeVolumes, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/block/volumes?limit=10&offset=1"+
+		"&marker=bd5b12a8-a101-11e7-941e-d77981b584d8&sort_key=size&sort_dir=desc"+
+		"&status=available&poolId=831fa5fb-17cf-4410-bec6-1f4b06208eef"+
+		"&metadata=tier=gold,zone=default", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestListVolumesWithInvalidOptions(t *testing.T) {
+
+	for _, query := range []string{"limit=abc", "offset=-1", "metadata=tier"} {
+		db.C = new(dbtest.MockClient)
+
+		r, _ := http.NewRequest("GET", "/v1alpha/block/volumes?"+query, nil)
+		w := httptest.NewRecorder()
+		beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+		if w.Code != 400 {
+			t.Errorf("Expected 400 with query %s, actual %v", query, w.Code)
+		}
+	}
+}
+
 func TestGetVolume(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
@@ -200,12 +248,91 @@ var (
 		VolumeId:    "d3a109ff-3e51-4625-9054-32604c79fa90",
 	}
 	fakeSnapshots = []*model.VolumeSnapshotSpec{fakeSnapshot}
+
+	fakeAttachment = &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{
+			Id:        "f2dda3d2-bf79-11e7-8665-f750b088f63e",
+			CreatedAt: "2017-10-24T16:21:32",
+		},
+		Status:   "available",
+		VolumeId: "bd5b12a8-a101-11e7-941e-d77981b584d8",
+	}
+	fakeAttachments = []*model.VolumeAttachmentSpec{fakeAttachment}
 )
 
+func TestListVolumeAttachments(t *testing.T) {
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumeAttachments", "", mock.Anything).Return(fakeAttachments, nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/block/attachments", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	var output []model.VolumeAttachmentSpec
+	json.Unmarshal(w.Body.Bytes(), &output)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+
+	if !reflect.DeepEqual(fakeAttachments[0].Id, output[0].Id) {
+		t.Errorf("Expected %v, actual %v", fakeAttachments, output)
+	}
+}
+
+func TestListVolumeAttachmentsOfVolume(t *testing.T) {
+	var volId = "bd5b12a8-a101-11e7-941e-d77981b584d8"
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumeAttachments", volId, mock.Anything).Return(fakeAttachments, nil)
+	db.C = mockClient
+
+	for _, url := range []string{
+		"/v1alpha/block/volumes/" + volId + "/attachments",
+		"/v1alpha/block/attachments?volumeId=" + volId,
+	} {
+		r, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+		if w.Code != 200 {
+			t.Errorf("Expected 200, actual %v", w.Code)
+		}
+	}
+	mockClient.AssertNumberOfCalls(t, "ListVolumeAttachments", 2)
+}
+
+func TestGetVolumeAttachmentOfVolume(t *testing.T) {
+	var volId = "bd5b12a8-a101-11e7-941e-d77981b584d8"
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeAttachment", volId, "f2dda3d2-bf79-11e7-8665-f750b088f63e").
+		Return(fakeAttachments[0], nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET",
+		"/v1alpha/block/volumes/"+volId+"/attachments/f2dda3d2-bf79-11e7-8665-f750b088f63e", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	var output model.VolumeAttachmentSpec
+	json.Unmarshal(w.Body.Bytes(), &output)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+
+	if output.GetVolumeId() != volId {
+		t.Errorf("Expected volume %v, actual %v", volId, output.GetVolumeId())
+	}
+}
+
 func TestListVolumeSnapshots(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
-	mockClient.On("ListVolumeSnapshots").Return(fakeSnapshots, nil)
+	mockClient.On("ListVolumeSnapshots", mock.Anything).Return(fakeSnapshots, nil)
 	db.C = mockClient
 
 	r, _ := http.NewRequest("GET", "/v1alpha/block/snapshots", nil)
@@ -240,7 +367,7 @@ func TestListVolumeSnapshots(t *testing.T) {
 func TestListVolumeSnapshotsWithBadRequest(t *testing.T) {
 
 	mockClient := new(dbtest.MockClient)
-	mockClient.On("ListVolumeSnapshots").Return(nil, errors.New("db error"))
+	mockClient.On("ListVolumeSnapshots", mock.Anything).Return(nil, errors.New("db error"))
 	db.C = mockClient
 
 	r, _ := http.NewRequest("GET", "/v1alpha/block/snapshots", nil)
@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the OpenSDS northbound service.
+
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+type WatchPortal struct {
+	beego.Controller
+}
+
+// Watch streams the changes of the resource given by the resource parameter
+// as server-sent events, the name of every event is the type of the change
+// and the data is the model.WatchEventSpec in json. The stream lasts until
+// the client goes away.
+func (this *WatchPortal) Watch() {
+	logger := newLogger(this.Ctx)
+
+	resource := this.GetString("resource")
+	if !model.IsWatchResource(resource) {
+		reason := fmt.Sprintf("Watch failed: resource must be one of %s",
+			strings.Join(model.WatchResources, ", "))
+		this.Ctx.Output.SetStatus(StatusBadRequest)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// Call db api module to handle watch request.
+	events, err := db.C.Watch(resource, stop)
+	if err != nil {
+		reason := fmt.Sprintf("Watch %s failed: %s", resource, err.Error())
+		this.Ctx.Output.SetStatus(StatusInternalServerError)
+		this.Ctx.Output.Body(utils.ErrorStatus(this.Ctx.Output.Status, reason))
+		logger.Error(reason)
+		return
+	}
+
+	w := this.Ctx.ResponseWriter
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(StatusOK)
+	w.Flush()
+
+	closed := w.CloseNotify()
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			body, err := json.Marshal(evt)
+			if err != nil {
+				logger.Error("When marshal watch event:", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, body); err != nil {
+				logger.Error("When write watch event:", err)
+				return
+			}
+			w.Flush()
+		case <-closed:
+			return
+		}
+	}
+}
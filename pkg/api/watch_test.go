@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func init() {
+	var watchPortal WatchPortal
+	beego.Router("/v1alpha/watch", &watchPortal, "get:Watch")
+}
+
+func TestWatch(t *testing.T) {
+	// The watch ends after the buffered events are pushed.
+	events := make(chan *model.WatchEventSpec, 2)
+	events <- &model.WatchEventSpec{
+		Type:     model.WatchEventCreated,
+		Resource: model.WatchResourceVolumes,
+		Object:   []byte(`{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8","status":"creating"}`),
+	}
+	events <- &model.WatchEventSpec{
+		Type:     model.WatchEventDeleted,
+		Resource: model.WatchResourceVolumes,
+		Object:   []byte(`{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}`),
+	}
+	close(events)
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("Watch", model.WatchResourceVolumes, mock.Anything).
+		Return((<-chan *model.WatchEventSpec)(events), nil)
+	db.C = mockClient
+
+	r, _ := http.NewRequest("GET", "/v1alpha/watch?resource=volumes", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Errorf("Expected 200, actual %v", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected event stream, actual %v", ct)
+	}
+	expected := "event: created\n" +
+		`data: {"type":"created","resource":"volumes","object":{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8","status":"creating"}}` + "\n\n" +
+		"event: deleted\n" +
+		`data: {"type":"deleted","resource":"volumes","object":{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}}` + "\n\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %q, actual %q", expected, w.Body.String())
+	}
+}
+
+func TestWatchUnknownResource(t *testing.T) {
+	db.C = new(dbtest.MockClient)
+
+	r, _ := http.NewRequest("GET", "/v1alpha/watch?resource=pools", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Errorf("Expected 400, actual %v", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "volumes") {
+		t.Errorf("Expected watchable resources listed, actual %v", w.Body.String())
+	}
+}
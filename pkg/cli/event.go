@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a entry into the OpenSDS service.
+
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	c "github.com/opensds/opensds/client"
+	"github.com/spf13/cobra"
+)
+
+var eventCommand = &cobra.Command{
+	Use:   "event",
+	Short: "query the operation events in the cluster",
+	Run:   eventAction,
+}
+
+var eventListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "list the operation events in the cluster",
+	Run:   eventListAction,
+}
+
+var (
+	evtResType string
+	evtResId   string
+	evtSince   string
+	evtUntil   string
+)
+
+func init() {
+	eventCommand.AddCommand(eventListCommand)
+	eventListCommand.Flags().StringVarP(&evtResType, "resource-type", "t", "", "the type of resource, such as volume, attachment, snapshot and profile")
+	eventListCommand.Flags().StringVarP(&evtResId, "resource-id", "r", "", "the id of resource")
+	eventListCommand.Flags().StringVarP(&evtSince, "since", "s", "", "list events happened since the time, e.g. 2017-10-24T16:21:32")
+	eventListCommand.Flags().StringVarP(&evtUntil, "until", "u", "", "list events happened until the time, e.g. 2017-10-25T16:21:32")
+}
+
+func eventAction(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+	os.Exit(1)
+}
+
+func eventListAction(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Println("The number of args is not correct!")
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	var filter = c.ParamOption{}
+	for k, v := range map[string]string{
+		"resourceType": evtResType,
+		"resourceId":   evtResId,
+		"since":        evtSince,
+		"until":        evtUntil,
+	} {
+		if v != "" {
+			filter[k] = v
+		}
+	}
+
+	resp, err := client.ListEvents(filter)
+	if err != nil {
+		fmt.Println(err)
+	}
+	keys := KeyList{"CreatedAt", "Actor", "Source", "ResourceType", "ResourceId", "Action", "Outcome", "Error", "RequestId"}
+	PrintList(resp, keys, FormatterList{})
+}
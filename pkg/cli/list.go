@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common flags of list commands.
+
+*/
+
+package cli
+
+import (
+	"strconv"
+
+	c "github.com/opensds/opensds/client"
+	"github.com/spf13/cobra"
+)
+
+// listFlags holds the pagination, sorting and metadata filter flags, which
+// are shared by list commands.
+type listFlags struct {
+	limit    int
+	offset   int
+	marker   string
+	sortKey  string
+	sortDir  string
+	metadata string
+}
+
+func (f *listFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.limit, "limit", 0, "the max number of resources listed")
+	cmd.Flags().IntVar(&f.offset, "offset", 0, "the number of resources skipped")
+	cmd.Flags().StringVar(&f.marker, "marker", "", "the id of the last resource in previous page")
+	cmd.Flags().StringVar(&f.sortKey, "sort-key", "", "the field which resources are sorted by, such as name and createdAt")
+	cmd.Flags().StringVar(&f.sortDir, "sort-dir", "", "the direction of sorting, asc or desc")
+}
+
+// registerMetadata registers the metadata filter flag for the commands
+// listing resources which have metadata.
+func (f *listFlags) registerMetadata(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.metadata, "metadata", "m", "", "list resources with the metadata, e.g. tier=gold,zone=default")
+}
+
+// paramOption builds the query parameters with the flags specified and the
+// field filters, the empty ones are ignored.
+func (f *listFlags) paramOption(filters map[string]string) c.ParamOption {
+	var opt = c.ParamOption{}
+	for k, v := range filters {
+		if v != "" {
+			opt[k] = v
+		}
+	}
+	if f.limit > 0 {
+		opt["limit"] = strconv.Itoa(f.limit)
+	}
+	if f.offset > 0 {
+		opt["offset"] = strconv.Itoa(f.offset)
+	}
+	for k, v := range map[string]string{
+		"marker":   f.marker,
+		"sort_key": f.sortKey,
+		"sort_dir": f.sortDir,
+		"metadata": f.metadata,
+	} {
+		if v != "" {
+			opt[k] = v
+		}
+	}
+	return opt
+}
@@ -46,6 +46,7 @@ func init() {
 	rootCommand.AddCommand(dockCommand)
 	rootCommand.AddCommand(poolCommand)
 	rootCommand.AddCommand(profileCommand)
+	rootCommand.AddCommand(eventCommand)
 }
 
 func Run() error {
@@ -58,10 +58,17 @@ var profileDeleteCommand = &cobra.Command{
 	Run:   profileDeleteAction,
 }
 
+var (
+	prfListFlags listFlags
+	prfListName  string
+)
+
 func init() {
 	profileCommand.AddCommand(profileCreateCommand)
 	profileCommand.AddCommand(profileShowCommand)
 	profileCommand.AddCommand(profileListCommand)
+	profileListCommand.Flags().StringVarP(&prfListName, "name", "n", "", "list profiles with the name")
+	prfListFlags.register(profileListCommand)
 	profileCommand.AddCommand(profileDeleteCommand)
 }
 
@@ -114,7 +121,9 @@ func profileListAction(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	resp, err := client.ListProfiles()
+	resp, err := client.ListProfiles(prfListFlags.paramOption(map[string]string{
+		"name": prfListName,
+	}))
 	if err != nil {
 		fmt.Println(err)
 	}
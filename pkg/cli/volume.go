@@ -20,6 +20,7 @@ This module implements a entry into the OpenSDS service.
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -64,6 +65,12 @@ var (
 	volName   string
 	volDesp   string
 	volAz     string
+
+	volListFlags  listFlags
+	volListName   string
+	volListStatus string
+	volListPoolId string
+	volListWatch  bool
 )
 
 func init() {
@@ -75,6 +82,12 @@ func init() {
 	volumeCreateCommand.Flags().StringVarP(&volAz, "az", "a", "", "the availabilty zone of created volume")
 	volumeCommand.AddCommand(volumeShowCommand)
 	volumeCommand.AddCommand(volumeListCommand)
+	volumeListCommand.Flags().StringVarP(&volListName, "name", "n", "", "list volumes with the name")
+	volumeListCommand.Flags().StringVarP(&volListStatus, "status", "s", "", "list volumes in the status, such as available and error")
+	volumeListCommand.Flags().StringVar(&volListPoolId, "pool", "", "list volumes in the pool")
+	volumeListCommand.Flags().BoolVarP(&volListWatch, "watch", "w", false, "watch the changes of volumes after listing them")
+	volListFlags.register(volumeListCommand)
+	volListFlags.registerMetadata(volumeListCommand)
 	volumeCommand.AddCommand(volumeDeleteCommand)
 
 	volumeCommand.AddCommand(volumeSnapshotCommand)
@@ -137,13 +150,40 @@ func volumeListAction(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	resp, err := client.ListVolumes()
+	// The profile flag is used as a filter when listing volumes.
+	resp, err := client.ListVolumes(volListFlags.paramOption(map[string]string{
+		"name":      volListName,
+		"status":    volListStatus,
+		"poolId":    volListPoolId,
+		"profileId": profileId,
+	}))
 	if err != nil {
 		fmt.Println(err)
 	}
 	keys := KeyList{"Id", "Name", "Description", "Size",
 		"AvailabilityZone", "Status", "PoolId", "ProfileId"}
 	PrintList(resp, keys, FormatterList{})
+
+	if volListWatch {
+		volumeWatch()
+	}
+}
+
+// volumeWatch prints the changes of volumes until the watch is interrupted.
+func volumeWatch() {
+	events, err := client.Watch(model.WatchResourceVolumes, nil)
+	if err != nil {
+		log.Fatalf("error watching volumes: %+v", err)
+	}
+	for evt := range events {
+		var vol = &model.VolumeSpec{BaseModel: &model.BaseModel{}}
+		if err := json.Unmarshal(evt.Object, vol); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("%-8s %s %s %s\n", evt.Type, vol.Id, vol.Name, vol.Status)
+	}
+	log.Fatal("the watch of volumes is broken")
 }
 
 func volumeDeleteAction(cmd *cobra.Command, args []string) {
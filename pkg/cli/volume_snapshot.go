@@ -60,6 +60,11 @@ var volumeSnapshotDeleteCommand = &cobra.Command{
 var (
 	volSnapshotName string
 	volSnapshotDesp string
+
+	snpListFlags    listFlags
+	snpListName     string
+	snpListStatus   string
+	snpListVolumeId string
 )
 
 func init() {
@@ -68,6 +73,11 @@ func init() {
 	volumeSnapshotCreateCommand.Flags().StringVarP(&volSnapshotDesp, "description", "d", "", "description of created volume snapshot")
 	volumeSnapshotCommand.AddCommand(volumeSnapshotShowCommand)
 	volumeSnapshotCommand.AddCommand(volumeSnapshotListCommand)
+	volumeSnapshotListCommand.Flags().StringVarP(&snpListName, "name", "n", "", "list volume snapshots with the name")
+	volumeSnapshotListCommand.Flags().StringVarP(&snpListStatus, "status", "s", "", "list volume snapshots in the status")
+	volumeSnapshotListCommand.Flags().StringVar(&snpListVolumeId, "volume", "", "list volume snapshots of the volume")
+	snpListFlags.register(volumeSnapshotListCommand)
+	snpListFlags.registerMetadata(volumeSnapshotListCommand)
 	volumeSnapshotCommand.AddCommand(volumeSnapshotDeleteCommand)
 }
 
@@ -119,7 +129,11 @@ func volumeSnapshotListAction(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	resp, err := client.ListVolumeSnapshots()
+	resp, err := client.ListVolumeSnapshots(snpListFlags.paramOption(map[string]string{
+		"name":     snpListName,
+		"status":   snpListStatus,
+		"volumeId": snpListVolumeId,
+	}))
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -0,0 +1,114 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the request context which is carried from the api
+service to the dock service and storage drivers, so that the logs of one
+operation could be correlated by the request id.
+
+*/
+
+package context
+
+import (
+	"fmt"
+
+	log "github.com/golang/glog"
+	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestIdHeader is the http header which returns the request id to
+	// the api client.
+	RequestIdHeader = "X-Openstack-Request-Id"
+
+	// The grpc metadata keys must be in lower case.
+	requestIdKey = "x-openstack-request-id"
+)
+
+// NewRequestId generates a random request id in the style of OpenStack.
+func NewRequestId() string {
+	return "req-" + uuid.NewV4().String()
+}
+
+// NewContext returns a context carrying the request id, which will be sent
+// as grpc metadata when the context is passed to a grpc call.
+func NewContext(parent context.Context, reqID string) context.Context {
+	md, _ := metadata.FromContext(parent)
+	md = metadata.Join(md, metadata.Pairs(requestIdKey, reqID))
+	return metadata.NewContext(parent, md)
+}
+
+// NewRequestContext returns a root context carrying the request id, which
+// is used at the beginning of an operation such as receiving an api call.
+func NewRequestContext(reqID string) context.Context {
+	return NewContext(context.Background(), reqID)
+}
+
+// RequestId returns the request id carried by the context, either set by
+// NewContext or received from grpc metadata.
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	md, ok := metadata.FromContext(ctx)
+	if !ok || len(md[requestIdKey]) == 0 {
+		return ""
+	}
+	// The last one takes precedence when the request id is overwritten.
+	return md[requestIdKey][len(md[requestIdKey])-1]
+}
+
+// Logger wraps glog and prefixes every line with the request id.
+type Logger struct {
+	RequestId string
+}
+
+// GetLogger returns the logger of the request carried by the context.
+func GetLogger(ctx context.Context) *Logger {
+	return &Logger{RequestId: RequestId(ctx)}
+}
+
+func (l *Logger) prefix(args []interface{}) []interface{} {
+	if l.RequestId == "" {
+		return args
+	}
+	return append([]interface{}{"[" + l.RequestId + "] "}, args...)
+}
+
+func (l *Logger) Info(args ...interface{}) {
+	log.InfoDepth(1, l.prefix(args)...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	log.InfoDepth(1, l.prefix([]interface{}{fmt.Sprintf(format, args...)})...)
+}
+
+func (l *Logger) Warning(args ...interface{}) {
+	log.WarningDepth(1, l.prefix(args)...)
+}
+
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	log.WarningDepth(1, l.prefix([]interface{}{fmt.Sprintf(format, args...)})...)
+}
+
+func (l *Logger) Error(args ...interface{}) {
+	log.ErrorDepth(1, l.prefix(args)...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	log.ErrorDepth(1, l.prefix([]interface{}{fmt.Sprintf(format, args...)})...)
+}
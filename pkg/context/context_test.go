@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package context
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNewRequestId(t *testing.T) {
+	reqID := NewRequestId()
+	if !strings.HasPrefix(reqID, "req-") {
+		t.Errorf("Expected request id with prefix req-, got %s\n", reqID)
+	}
+	if reqID == NewRequestId() {
+		t.Errorf("Expected different request ids, got %s twice\n", reqID)
+	}
+}
+
+func TestRequestId(t *testing.T) {
+	var reqID = "req-f4a5e666-c669-4c64-a2a1-8f9ecd560c78"
+
+	if result := RequestId(context.Background()); result != "" {
+		t.Errorf("Expected empty request id, got %s\n", result)
+	}
+
+	ctx := NewRequestContext(reqID)
+	if result := RequestId(ctx); result != reqID {
+		t.Errorf("Expected %s, got %s\n", reqID, result)
+	}
+
+	// The request id should be carried by grpc metadata.
+	md, _ := metadata.FromContext(ctx)
+	if result := md[requestIdKey]; len(result) != 1 || result[0] != reqID {
+		t.Errorf("Expected metadata %s, got %v\n", reqID, result)
+	}
+
+	// The other metadata should be kept when the request id is overwritten.
+	ctx = metadata.NewContext(ctx, metadata.Join(md, metadata.Pairs("foo", "bar")))
+	ctx = NewContext(ctx, "req-new")
+	if result := RequestId(ctx); result != "req-new" {
+		t.Errorf("Expected req-new, got %s\n", result)
+	}
+	if md, _ = metadata.FromContext(ctx); len(md["foo"]) != 1 {
+		t.Errorf("Expected metadata foo kept, got %v\n", md)
+	}
+}
+
+func TestLoggerPrefix(t *testing.T) {
+	var logger = GetLogger(NewRequestContext("req-001"))
+	var expected = []interface{}{"[req-001] ", "message"}
+
+	if result := logger.prefix([]interface{}{"message"}); len(result) != 2 ||
+		result[0] != expected[0] || result[1] != expected[1] {
+		t.Errorf("Expected %v, got %v\n", expected, result)
+	}
+
+	logger = GetLogger(context.Background())
+	if result := logger.prefix([]interface{}{"message"}); len(result) != 1 {
+		t.Errorf("Expected no prefix, got %v\n", result)
+	}
+}
@@ -19,24 +19,26 @@ This module implements a entry into the OpenSDS northbound service.
 package controller
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 
-	log "github.com/golang/glog"
-
+	osdsctx "github.com/opensds/opensds/pkg/context"
 	"github.com/opensds/opensds/pkg/controller/policy"
 	"github.com/opensds/opensds/pkg/controller/selector"
 	"github.com/opensds/opensds/pkg/controller/volume"
+	"github.com/opensds/opensds/pkg/db"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
 )
 
 const (
-	CREATE_LIFECIRCLE_FLAG = iota + 1
-	GET_LIFECIRCLE_FLAG
-	LIST_LIFECIRCLE_FLAG
-	DELETE_LIFECIRCLE_FLAG
+	CREATE_LIFECIRCLE_FLAG   = policy.CREATE_LIFECIRCLE_FLAG
+	GET_LIFECIRCLE_FLAG      = policy.GET_LIFECIRCLE_FLAG
+	LIST_LIFECIRCLE_FLAG     = policy.LIST_LIFECIRCLE_FLAG
+	DELETE_LIFECIRCLE_FLAG   = policy.DELETE_LIFECIRCLE_FLAG
+	ATTACH_LIFECIRCLE_FLAG   = policy.ATTACH_LIFECIRCLE_FLAG
+	SNAPSHOT_LIFECIRCLE_FLAG = policy.SNAPSHOT_LIFECIRCLE_FLAG
 )
 
 var Brain *Controller
@@ -55,27 +57,39 @@ type Controller struct {
 	policyController policy.Controller
 }
 
-func (c *Controller) CreateVolume(in *model.VolumeSpec) (*model.VolumeSpec, error) {
+func (c *Controller) CreateVolume(ctx context.Context, in *model.VolumeSpec) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if prjID := in.GetProjectId(); prjID != "" {
+		if err := checkQuota(prjID, 1, in.GetSize()); err != nil {
+			logger.Error("When check quota of project:", err)
+			return nil, err
+		}
+	}
+
 	var prfID = in.GetProfileId()
 
 	prf, err := c.SelectProfile(prfID)
 	if err != nil {
-		log.Error("when search profiles in db:", err)
+		logger.Error("when search profiles in db:", err)
 		return nil, err
 	}
 
 	// Select the storage tag according to the lifecycle flag.
 	c.policyController = policy.NewController(prf)
-	c.policyController.Setup(CREATE_LIFECIRCLE_FLAG)
+	if err = c.policyController.Setup(CREATE_LIFECIRCLE_FLAG); err != nil {
+		logger.Error("When parse storage tags of profile:", err)
+		return nil, err
+	}
 
 	polInfo, err := c.SelectSupportedPool(c.policyController.StorageTag().GetSyncTag())
 	if err != nil {
-		log.Error("When search supported pool resource:", err)
+		logger.Error("When search supported pool resource:", err)
 		return nil, err
 	}
 	dockInfo, err := c.SelectDock(polInfo)
 	if err != nil {
-		log.Error("When search supported dock resource:", err)
+		logger.Error("When search supported dock resource:", err)
 		return nil, err
 	}
 	c.policyController.SetDock(dockInfo)
@@ -91,23 +105,49 @@ func (c *Controller) CreateVolume(in *model.VolumeSpec) (*model.VolumeSpec, erro
 		PoolId:           polInfo.GetId(),
 		DockId:           dockInfo.GetId(),
 		DriverName:       dockInfo.GetDriverName(),
+		Multiattach:      in.GetMultiattach(),
+		ProjectId:        in.GetProjectId(),
 	}
-	result, err := c.volumeController.CreateVolume(opt)
-	if err != nil {
+	// Apply the synchronous policies such as qos before creating volume.
+	if err = c.policyController.ExecuteSyncPolicy(opt); err != nil {
+		logger.Error("When execute sync policy:", err)
 		return nil, err
 	}
+	// The operation policies such as intervalSnapshot are run by the leader
+	// of osdslet replicas, see TakeIntervalSnapshots.
+	return c.volumeController.CreateVolume(ctx, opt)
+}
 
-	var errChan = make(chan error, 1)
-	volBody, _ := json.Marshal(result)
-	go c.policyController.ExecuteAsyncPolicy(opt, string(volBody), errChan)
+func (c *Controller) DeleteVolume(ctx context.Context, in *model.VolumeSpec) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
 
-	return result, nil
-}
+	// The volume which is attached to hosts can't be deleted.
+	vol, err := db.C.GetVolume(in.GetId())
+	if err != nil {
+		logger.Error("When get volume in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	if vol.GetStatus() == model.VolumeInUse {
+		logger.Errorf("Volume %s is in use", in.GetId())
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprintf("Volume %s is in use, please detach it first", in.GetId()),
+		}
+	}
+	if vol.GetStatus() == model.VolumeAwaitingTransfer {
+		logger.Errorf("Volume %s is awaiting transfer", in.GetId())
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprintf("Volume %s is awaiting transfer, please cancel the transfer first", in.GetId()),
+		}
+	}
 
-func (c *Controller) DeleteVolume(in *model.VolumeSpec) *model.Response {
 	prf, err := c.SelectProfile(in.GetProfileId())
 	if err != nil {
-		log.Error("when search profiles in db:", err)
+		logger.Error("when search profiles in db:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
@@ -116,11 +156,17 @@ func (c *Controller) DeleteVolume(in *model.VolumeSpec) *model.Response {
 
 	// Select the storage tag according to the lifecycle flag.
 	c.policyController = policy.NewController(prf)
-	c.policyController.Setup(DELETE_LIFECIRCLE_FLAG)
+	if err = c.policyController.Setup(DELETE_LIFECIRCLE_FLAG); err != nil {
+		logger.Error("When parse storage tags of profile:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
 
 	dockInfo, err := c.SelectDock(in.GetId())
 	if err != nil {
-		log.Error("When search supported dock resource:", err)
+		logger.Error("When search supported dock resource:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
@@ -140,25 +186,33 @@ func (c *Controller) DeleteVolume(in *model.VolumeSpec) *model.Response {
 	go c.policyController.ExecuteAsyncPolicy(opt, "", errChan)
 
 	if err := <-errChan; err != nil {
-		log.Error("When execute async policy:", err)
+		logger.Error("When execute async policy:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
 		}
 	}
 
-	return c.volumeController.DeleteVolume(opt)
+	return c.volumeController.DeleteVolume(ctx, opt)
 }
 
-func (c *Controller) CreateVolumeAttachment(in *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+func (c *Controller) CreateVolumeAttachment(ctx context.Context, in *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if err := checkAttachable(in.GetVolumeId()); err != nil {
+		logger.Error("When check volume attachable:", err)
+		return nil, err
+	}
+
 	dockInfo, err := c.SelectDock(in.GetVolumeId())
 	if err != nil {
-		log.Error("When search supported dock resource:", err)
+		logger.Error("When search supported dock resource:", err)
 		return nil, err
 	}
 	c.volumeController.SetDock(dockInfo)
 
 	return c.volumeController.CreateVolumeAttachment(
+		ctx,
 		&pb.CreateAttachmentOpts{
 			Id:       in.GetId(),
 			VolumeId: in.GetVolumeId(),
@@ -176,14 +230,75 @@ func (c *Controller) CreateVolumeAttachment(in *model.VolumeAttachmentSpec) (*mo
 	)
 }
 
-func (c *Controller) UpdateVolumeAttachment(in *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
-	return nil, errors.New("Not implemented!")
+// UpdateVolumeAttachment records the device path or mountpoint reported by
+// the host after connection. The request will be dispatched to the dock to
+// re-export the volume only if the initiator of the host is changed.
+func (c *Controller) UpdateVolumeAttachment(ctx context.Context, in *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	atc, err := db.C.GetVolumeAttachment(in.GetVolumeId(), in.GetId())
+	if err != nil {
+		logger.Error("When get volume attachment in db:", err)
+		return nil, err
+	}
+	in.VolumeId = atc.GetVolumeId()
+	// The volume shouldn't be re-exported for a stale view of the attachment.
+	if v := in.GetResourceVersion(); v != 0 && v != atc.GetResourceVersion() {
+		return nil, utils.ErrConflict
+	}
+
+	if in.HostInfo == nil || in.GetInitiator() == "" ||
+		(atc.HostInfo != nil && in.GetInitiator() == atc.GetInitiator()) {
+		return db.C.UpdateVolumeAttachment(in.GetVolumeId(), in.GetId(), in)
+	}
+
+	dockInfo, err := c.SelectDock(in.GetVolumeId())
+	if err != nil {
+		logger.Error("When search supported dock resource:", err)
+		return nil, err
+	}
+	c.volumeController.SetDock(dockInfo)
+
+	return c.volumeController.UpdateVolumeAttachment(
+		ctx,
+		&pb.UpdateAttachmentOpts{
+			Id:         in.GetId(),
+			VolumeId:   in.GetVolumeId(),
+			Mountpoint: in.GetMountpoint(),
+			HostInfo: &pb.HostInfo{
+				Platform:  in.GetPlatform(),
+				OsType:    in.GetOsType(),
+				Ip:        in.GetIp(),
+				Host:      in.GetHost(),
+				Initiator: in.GetInitiator(),
+			},
+			Metadata:   in.GetMetadata(),
+			DockId:     dockInfo.GetId(),
+			DriverName: dockInfo.GetDriverName(),
+		},
+	)
 }
 
-func (c *Controller) DeleteVolumeAttachment(in *model.VolumeAttachmentSpec) *model.Response {
+func (c *Controller) DeleteVolumeAttachment(ctx context.Context, in *model.VolumeAttachmentSpec) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
+	// Look up the volume which the attachment belongs to if it's not
+	// specified.
+	if in.GetVolumeId() == "" {
+		atc, err := db.C.GetVolumeAttachment("", in.GetId())
+		if err != nil {
+			logger.Error("When get volume attachment in db:", err)
+			return &model.Response{
+				Status: "Failure",
+				Error:  fmt.Sprint(err),
+			}
+		}
+		in.VolumeId = atc.GetVolumeId()
+	}
+
 	dockInfo, err := c.SelectDock(in.GetVolumeId())
 	if err != nil {
-		log.Error("When search supported dock resource:", err)
+		logger.Error("When search supported dock resource:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
@@ -192,6 +307,7 @@ func (c *Controller) DeleteVolumeAttachment(in *model.VolumeAttachmentSpec) *mod
 	c.volumeController.SetDock(dockInfo)
 
 	return c.volumeController.DeleteVolumeAttachment(
+		ctx,
 		&pb.DeleteAttachmentOpts{
 			Id:       in.GetId(),
 			VolumeId: in.GetVolumeId(),
@@ -209,15 +325,18 @@ func (c *Controller) DeleteVolumeAttachment(in *model.VolumeAttachmentSpec) *mod
 	)
 }
 
-func (c *Controller) CreateVolumeSnapshot(in *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error) {
+func (c *Controller) CreateVolumeSnapshot(ctx context.Context, in *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	dockInfo, err := c.SelectDock(in.GetVolumeId())
 	if err != nil {
-		log.Error("When search supported dock resource:", err)
+		logger.Error("When search supported dock resource:", err)
 		return nil, err
 	}
 	c.volumeController.SetDock(dockInfo)
 
 	return c.volumeController.CreateVolumeSnapshot(
+		ctx,
 		&pb.CreateVolumeSnapshotOpts{
 			Id:          in.GetId(),
 			Name:        in.GetName(),
@@ -225,14 +344,18 @@ func (c *Controller) CreateVolumeSnapshot(in *model.VolumeSnapshotSpec) (*model.
 			Size:        in.GetSize(),
 			VolumeId:    in.GetVolumeId(),
 			Metadata:    in.GetMetadata(),
+			DockId:      dockInfo.GetId(),
+			DriverName:  dockInfo.GetDriverName(),
 		},
 	)
 }
 
-func (c *Controller) DeleteVolumeSnapshot(in *model.VolumeSnapshotSpec) *model.Response {
+func (c *Controller) DeleteVolumeSnapshot(ctx context.Context, in *model.VolumeSnapshotSpec) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
 	dockInfo, err := c.SelectDock(in.GetVolumeId())
 	if err != nil {
-		log.Error("When search supported dock resource:", err)
+		logger.Error("When search supported dock resource:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
@@ -241,10 +364,32 @@ func (c *Controller) DeleteVolumeSnapshot(in *model.VolumeSnapshotSpec) *model.R
 	c.volumeController.SetDock(dockInfo)
 
 	return c.volumeController.DeleteVolumeSnapshot(
+		ctx,
 		&pb.DeleteVolumeSnapshotOpts{
-			Id:       in.GetId(),
-			VolumeId: in.GetVolumeId(),
-			Metadata: in.GetMetadata(),
+			Id:         in.GetId(),
+			VolumeId:   in.GetVolumeId(),
+			Metadata:   in.GetMetadata(),
+			DockId:     dockInfo.GetId(),
+			DriverName: dockInfo.GetDriverName(),
 		},
 	)
 }
+
+// checkAttachable checks whether the volume could be attached once more, the
+// volume which has been attached could only be attached again if it supports
+// multiattach. It fails the request early, db module enforces it again when
+// the attachment is stored since the connection is initialized in between.
+func checkAttachable(volID string) error {
+	vol, err := db.C.GetVolume(volID)
+	if err != nil {
+		return err
+	}
+	atcs, err := db.C.ListVolumeAttachments(volID, nil)
+	if err != nil {
+		return err
+	}
+	if len(atcs) != 0 && !vol.GetMultiattach() {
+		return utils.MultiattachError(volID)
+	}
+	return nil
+}
@@ -21,8 +21,12 @@ import (
 	"github.com/opensds/opensds/pkg/controller/policy"
 	"github.com/opensds/opensds/pkg/controller/selector"
 	"github.com/opensds/opensds/pkg/controller/volume"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
 )
 
 func NewFakeVolumeController() volume.Controller {
@@ -32,27 +36,39 @@ func NewFakeVolumeController() volume.Controller {
 type fakeVolumeController struct {
 }
 
-func (fvc *fakeVolumeController) CreateVolume(*pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+func (fvc *fakeVolumeController) CreateVolume(context.Context, *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
 	return &sampleVolume, nil
 }
 
-func (fvc *fakeVolumeController) DeleteVolume(*pb.DeleteVolumeOpts) *model.Response {
+func (fvc *fakeVolumeController) DeleteVolume(context.Context, *pb.DeleteVolumeOpts) *model.Response {
 	return &model.Response{Status: "Success"}
 }
 
-func (fvc *fakeVolumeController) CreateVolumeAttachment(*pb.CreateAttachmentOpts) (*model.VolumeAttachmentSpec, error) {
+func (fvc *fakeVolumeController) ManageVolume(context.Context, *pb.ManageVolumeOpts) (*model.VolumeSpec, error) {
+	return &sampleVolume, nil
+}
+
+func (fvc *fakeVolumeController) ManageVolumeSnapshot(context.Context, *pb.ManageVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	return &sampleSnapshot, nil
+}
+
+func (fvc *fakeVolumeController) CreateVolumeAttachment(context.Context, *pb.CreateAttachmentOpts) (*model.VolumeAttachmentSpec, error) {
 	return &sampleAttachment, nil
 }
 
-func (fvc *fakeVolumeController) DeleteVolumeAttachment(*pb.DeleteAttachmentOpts) *model.Response {
+func (fvc *fakeVolumeController) DeleteVolumeAttachment(context.Context, *pb.DeleteAttachmentOpts) *model.Response {
 	return &model.Response{Status: "Success"}
 }
 
-func (fvc *fakeVolumeController) CreateVolumeSnapshot(*pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+func (fvc *fakeVolumeController) UpdateVolumeAttachment(context.Context, *pb.UpdateAttachmentOpts) (*model.VolumeAttachmentSpec, error) {
+	return &sampleModifiedAttachment, nil
+}
+
+func (fvc *fakeVolumeController) CreateVolumeSnapshot(context.Context, *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
 	return &sampleSnapshot, nil
 }
 
-func (fvc *fakeVolumeController) DeleteVolumeSnapshot(*pb.DeleteVolumeSnapshotOpts) *model.Response {
+func (fvc *fakeVolumeController) DeleteVolumeSnapshot(context.Context, *pb.DeleteVolumeSnapshotOpts) *model.Response {
 	return &model.Response{Status: "Success"}
 }
 
@@ -73,7 +89,7 @@ func TestCreateVolume(t *testing.T) {
 	}
 	var expected = &sampleVolume
 
-	result, err := c.CreateVolume(req)
+	result, err := c.CreateVolume(context.Background(), req)
 	if err != nil {
 		t.Errorf("Failed to create volume, err is %v\n", err)
 	}
@@ -88,6 +104,10 @@ func TestDeleteVolume(t *testing.T) {
 			Id: "9193c3ec-771f-11e7-8ca3-d32c0a8b2725",
 		},
 	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", req.Id).Return(&sampleVolume, nil)
+	db.C = mockClient
+
 	var c = &Controller{
 		Selector:         selector.NewFakeSelector(),
 		volumeController: NewFakeVolumeController(),
@@ -95,25 +115,55 @@ func TestDeleteVolume(t *testing.T) {
 	}
 	var expected = &model.Response{Status: "Success"}
 
-	result := c.DeleteVolume(req)
+	result := c.DeleteVolume(context.Background(), req)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Expected %v, got %v\n", expected, result)
 	}
 }
 
+func TestDeleteVolumeInUse(t *testing.T) {
+	var req = &model.VolumeSpec{
+		BaseModel: &model.BaseModel{
+			Id: "9193c3ec-771f-11e7-8ca3-d32c0a8b2725",
+		},
+	}
+	var vol = sampleVolume
+	vol.Status = model.VolumeInUse
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", req.Id).Return(&vol, nil)
+	db.C = mockClient
+
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+		policyController: policy.NewController(&sampleProfile),
+	}
+
+	result := c.DeleteVolume(context.Background(), req)
+	if result.Status != "Failure" {
+		t.Errorf("Expected delete in-use volume failed, got %v\n", result)
+	}
+}
+
 func TestCreateVolumeAttachment(t *testing.T) {
 	var req = &model.VolumeAttachmentSpec{
 		BaseModel: &model.BaseModel{},
 		VolumeId:  "9193c3ec-771f-11e7-8ca3-d32c0a8b2725",
 		HostInfo:  &model.HostInfo{},
 	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", req.VolumeId).Return(&sampleVolume, nil)
+	mockClient.On("ListVolumeAttachments", req.VolumeId, (*model.ListOptions)(nil)).
+		Return([]*model.VolumeAttachmentSpec{}, nil)
+	db.C = mockClient
+
 	var c = &Controller{
 		Selector:         selector.NewFakeSelector(),
 		volumeController: NewFakeVolumeController(),
 	}
 	var expected = &sampleAttachment
 
-	result, err := c.CreateVolumeAttachment(req)
+	result, err := c.CreateVolumeAttachment(context.Background(), req)
 	if err != nil {
 		t.Errorf("Failed to create volume attachment, err is %v\n", err)
 	}
@@ -122,6 +172,36 @@ func TestCreateVolumeAttachment(t *testing.T) {
 	}
 }
 
+func TestCreateVolumeAttachmentMultiattach(t *testing.T) {
+	var req = &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{},
+		VolumeId:  "9193c3ec-771f-11e7-8ca3-d32c0a8b2725",
+		HostInfo:  &model.HostInfo{},
+	}
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+	}
+
+	for _, multiattach := range []bool{false, true} {
+		var vol = sampleVolume
+		vol.Status, vol.Multiattach = model.VolumeInUse, multiattach
+		mockClient := new(dbtest.MockClient)
+		mockClient.On("GetVolume", req.VolumeId).Return(&vol, nil)
+		mockClient.On("ListVolumeAttachments", req.VolumeId, (*model.ListOptions)(nil)).
+			Return([]*model.VolumeAttachmentSpec{&sampleAttachment}, nil)
+		db.C = mockClient
+
+		_, err := c.CreateVolumeAttachment(context.Background(), req)
+		if multiattach && err != nil {
+			t.Errorf("Failed to attach multiattach volume again, err is %v\n", err)
+		}
+		if !multiattach && err == nil {
+			t.Error("Expected attaching attached volume without multiattach failed")
+		}
+	}
+}
+
 func TestDeleteVolumeAttachment(t *testing.T) {
 	var req = &model.VolumeAttachmentSpec{
 		BaseModel: &model.BaseModel{
@@ -129,18 +209,88 @@ func TestDeleteVolumeAttachment(t *testing.T) {
 		},
 		HostInfo: &model.HostInfo{},
 	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeAttachment", "", req.Id).Return(&sampleAttachment, nil)
+	db.C = mockClient
+
 	var c = &Controller{
 		Selector:         selector.NewFakeSelector(),
 		volumeController: NewFakeVolumeController(),
 	}
 	var expected = &model.Response{Status: "Success"}
 
-	result := c.DeleteVolumeAttachment(req)
+	result := c.DeleteVolumeAttachment(context.Background(), req)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Expected %v, got %v\n", expected, result)
 	}
 }
 
+func TestUpdateVolumeAttachment(t *testing.T) {
+	var atc = sampleAttachment
+	atc.HostInfo = &model.HostInfo{Initiator: "iqn.2017-10.io.opensds:host1"}
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+	}
+
+	// The attachment is updated in db directly if the initiator isn't changed.
+	var req = &model.VolumeAttachmentSpec{
+		BaseModel:  &model.BaseModel{Id: atc.Id},
+		Mountpoint: "/mnt/opensds",
+		HostInfo:   &model.HostInfo{Initiator: "iqn.2017-10.io.opensds:host1"},
+	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeAttachment", "", atc.Id).Return(&atc, nil)
+	mockClient.On("UpdateVolumeAttachment", atc.VolumeId, atc.Id, req).Return(&atc, nil)
+	db.C = mockClient
+
+	result, err := c.UpdateVolumeAttachment(context.Background(), req)
+	if err != nil {
+		t.Errorf("Failed to update volume attachment, err is %v\n", err)
+	}
+	if !reflect.DeepEqual(result, &atc) {
+		t.Errorf("Expected %v, got %v\n", &atc, result)
+	}
+	mockClient.AssertExpectations(t)
+
+	// The volume is re-exported by the dock if the initiator is changed.
+	req = &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: atc.Id},
+		HostInfo:  &model.HostInfo{Initiator: "iqn.2017-10.io.opensds:host2"},
+	}
+	result, err = c.UpdateVolumeAttachment(context.Background(), req)
+	if err != nil {
+		t.Errorf("Failed to update volume attachment, err is %v\n", err)
+	}
+	if !reflect.DeepEqual(result, &sampleModifiedAttachment) {
+		t.Errorf("Expected %v, got %v\n", &sampleModifiedAttachment, result)
+	}
+}
+
+func TestUpdateVolumeAttachmentWithStaleVersion(t *testing.T) {
+	var atc = &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: sampleAttachment.Id, ResourceVersion: 12},
+		VolumeId:  sampleAttachment.VolumeId,
+		HostInfo:  &model.HostInfo{Initiator: "iqn.2017-10.io.opensds:host1"},
+	}
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+	}
+
+	var req = &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: atc.Id, ResourceVersion: 11},
+		HostInfo:  &model.HostInfo{Initiator: "iqn.2017-10.io.opensds:host2"},
+	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeAttachment", "", atc.Id).Return(atc, nil)
+	db.C = mockClient
+
+	if _, err := c.UpdateVolumeAttachment(context.Background(), req); err != utils.ErrConflict {
+		t.Errorf("Expected conflict, got %v\n", err)
+	}
+}
+
 func TestCreateVolumeSnapshot(t *testing.T) {
 	var req = &model.VolumeSnapshotSpec{
 		BaseModel:   &model.BaseModel{},
@@ -155,7 +305,7 @@ func TestCreateVolumeSnapshot(t *testing.T) {
 	}
 	var expected = &sampleSnapshot
 
-	result, err := c.CreateVolumeSnapshot(req)
+	result, err := c.CreateVolumeSnapshot(context.Background(), req)
 	if err != nil {
 		t.Errorf("Failed to create volume snapshot, err is %v\n", err)
 	}
@@ -176,12 +326,59 @@ func TestDeleteVolumeSnapshot(t *testing.T) {
 	}
 	var expected = &model.Response{Status: "Success"}
 
-	result := c.DeleteVolumeSnapshot(req)
+	result := c.DeleteVolumeSnapshot(context.Background(), req)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Expected %v, got %v\n", expected, result)
 	}
 }
 
+// routingVolumeController records the docks which the snapshot requests are
+// sent to.
+type routingVolumeController struct {
+	fakeVolumeController
+	docks []string
+}
+
+func (rvc *routingVolumeController) CreateVolumeSnapshot(_ context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	rvc.docks = append(rvc.docks, opt.GetDockId()+":"+opt.GetDriverName())
+	return &sampleSnapshot, nil
+}
+
+func (rvc *routingVolumeController) DeleteVolumeSnapshot(_ context.Context, opt *pb.DeleteVolumeSnapshotOpts) *model.Response {
+	rvc.docks = append(rvc.docks, opt.GetDockId()+":"+opt.GetDriverName())
+	return &model.Response{Status: "Success"}
+}
+
+func TestVolumeSnapshotRouting(t *testing.T) {
+	var req = &model.VolumeSnapshotSpec{
+		BaseModel: &model.BaseModel{
+			Id: "3769855c-a102-11e7-b772-17b880d2f537",
+		},
+		VolumeId: "9193c3ec-771f-11e7-8ca3-d32c0a8b2725",
+		Name:     "fake-volumesnapshot",
+		Size:     int64(1),
+	}
+	var rvc = &routingVolumeController{}
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: rvc,
+	}
+
+	if _, err := c.CreateVolumeSnapshot(context.Background(), req); err != nil {
+		t.Errorf("Failed to create volume snapshot, err is %v\n", err)
+	}
+	c.DeleteVolumeSnapshot(context.Background(), req)
+
+	// The requests are sent to the dock of the volume.
+	var expected = []string{
+		"b7602e18-771e-11e7-8f38-dbd6d291f4e0:sample",
+		"b7602e18-771e-11e7-8f38-dbd6d291f4e0:sample",
+	}
+	if !reflect.DeepEqual(rvc.docks, expected) {
+		t.Errorf("Expected %v, got %v\n", expected, rvc.docks)
+	}
+}
+
 var (
 	sampleVolume = model.VolumeSpec{
 		BaseModel: &model.BaseModel{
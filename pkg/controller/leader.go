@@ -0,0 +1,174 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+
+	osdsctx "github.com/opensds/opensds/pkg/context"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/utils/config"
+)
+
+// LeaderJob is a background loop which must run only once among the replicas
+// of osdslet, it should return soon after stop is closed.
+type LeaderJob func(stop <-chan struct{})
+
+// PeriodicJob returns the job which calls fn every interval.
+func PeriodicJob(interval time.Duration, fn func()) LeaderJob {
+	return func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-stop:
+				return
+			}
+		}
+	}
+}
+
+// NewOsdsletRunner returns the runner of the background jobs of osdslet
+// configured in conf, which are run by Brain on the leader of the osdslet
+// replicas. The jobs work out what to do from the database, so that the new
+// leader goes on with them after a failover.
+func NewOsdsletRunner(conf *config.OsdsLet) *LeaderRunner {
+	host, _ := os.Hostname()
+	r := NewLeaderRunner("osdslet", host+"/"+conf.ApiEndpoint)
+
+	interval := time.Duration(conf.CleanupInterval) * time.Second
+	if expiration := conf.TransferExpiration; expiration > 0 && interval > 0 {
+		r.Register("expire-transfers", PeriodicJob(interval, func() {
+			ctx := osdsctx.NewRequestContext(osdsctx.NewRequestId())
+			n, err := Brain.ExpireVolumeTransfers(ctx, time.Duration(expiration)*time.Second)
+			if err != nil {
+				log.Error("When expire volume transfers:", err)
+				return
+			}
+			if n > 0 {
+				log.Infof("%d volume transfers expired\n", n)
+			}
+		}))
+	}
+	if interval := time.Duration(conf.SnapshotPolicyInterval) * time.Second; interval > 0 {
+		r.Register("interval-snapshots", PeriodicJob(interval, func() {
+			ctx := osdsctx.NewRequestContext(osdsctx.NewRequestId())
+			n, err := Brain.TakeIntervalSnapshots(ctx, time.Now())
+			if err != nil {
+				log.Error("When take interval snapshots:", err)
+				return
+			}
+			if n > 0 {
+				log.Infof("%d interval snapshots taken\n", n)
+			}
+		}))
+	}
+	return r
+}
+
+func NewLeaderRunner(election, id string) *LeaderRunner {
+	return &LeaderRunner{
+		Election:      election,
+		Id:            id,
+		RetryInterval: 5 * time.Second,
+		jobs:          make(map[string]LeaderJob),
+	}
+}
+
+// LeaderRunner runs the background jobs only while this replica is the leader
+// of the election. The jobs are stopped once the leadership is lost, and the
+// replica campaigns again for taking over later.
+type LeaderRunner struct {
+	// Election is the name of the election which the replicas campaign for.
+	Election string
+	// Id identifies this replica in the election.
+	Id string
+	// RetryInterval is the time waited before campaigning again if the
+	// election fails.
+	RetryInterval time.Duration
+
+	jobs map[string]LeaderJob
+}
+
+// Register adds the job which is started whenever this replica becomes the
+// leader, it must be called before Run.
+func (r *LeaderRunner) Register(name string, job LeaderJob) {
+	r.jobs[name] = job
+}
+
+// Run campaigns for the leadership and runs the jobs while being the leader
+// until stop is closed. The leadership is resigned after all jobs return, so
+// that another replica takes over at once.
+func (r *LeaderRunner) Run(stop <-chan struct{}) {
+	for {
+		lost, resign, err := db.C.Elect(r.Election, r.Id, stop)
+		if err != nil {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			log.Errorf("When campaign for leader of %s: %v\n", r.Election, err)
+			select {
+			case <-stop:
+				return
+			case <-time.After(r.RetryInterval):
+			}
+			continue
+		}
+
+		log.Infof("%s becomes the leader of %s\n", r.Id, r.Election)
+		if r.lead(lost, stop) {
+			if err := resign(); err != nil {
+				log.Errorf("When resign leader of %s: %v\n", r.Election, err)
+			}
+			return
+		}
+		log.Warningf("%s lost the leadership of %s\n", r.Id, r.Election)
+	}
+}
+
+// lead runs all jobs until the leadership is lost or stop is closed, and
+// returns true if it's stopped. It doesn't return until all jobs return.
+func (r *LeaderRunner) lead(lost, stop <-chan struct{}) bool {
+	jobStop := make(chan struct{})
+	var wg sync.WaitGroup
+	for name, job := range r.jobs {
+		wg.Add(1)
+		go func(name string, job LeaderJob) {
+			defer wg.Done()
+			log.Infof("Start leader job %s\n", name)
+			job(jobStop)
+			log.Infof("Leader job %s stopped\n", name)
+		}(name, job)
+	}
+
+	var stopped bool
+	select {
+	case <-lost:
+	case <-stop:
+		stopped = true
+	}
+	close(jobStop)
+	wg.Wait()
+	return stopped
+}
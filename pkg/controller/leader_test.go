@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLeaderRunner(t *testing.T) {
+	var lost = make(chan struct{})
+	var resigned = make(chan struct{})
+	resign := func() error {
+		close(resigned)
+		return nil
+	}
+
+	// The first campaign fails, then the replica becomes the leader twice
+	// since the first leadership is lost.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("Elect", "osdslet", "replica-1", mock.Anything).Return(nil, nil, errors.New("etcd is unavailable")).Once()
+	mockClient.On("Elect", "osdslet", "replica-1", mock.Anything).Return((<-chan struct{})(lost), resign, nil).Once()
+	mockClient.On("Elect", "osdslet", "replica-1", mock.Anything).Return((<-chan struct{})(make(chan struct{})), resign, nil).Once()
+	db.C = mockClient
+
+	var started = make(chan struct{}, 2)
+	var stopped = make(chan struct{}, 2)
+	r := NewLeaderRunner("osdslet", "replica-1")
+	r.RetryInterval = time.Millisecond
+	r.Register("test", func(stop <-chan struct{}) {
+		started <- struct{}{}
+		<-stop
+		stopped <- struct{}{}
+	})
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		r.Run(stop)
+		close(done)
+	}()
+
+	<-started
+	// The job is stopped once the leadership is lost, and started again
+	// after the replica becomes the leader again.
+	close(lost)
+	<-stopped
+	<-started
+
+	close(stop)
+	<-stopped
+	<-done
+	select {
+	case <-resigned:
+	default:
+		t.Error("Expected leadership resigned after stopped")
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestPeriodicJob(t *testing.T) {
+	var calls = make(chan struct{}, 10)
+	job := PeriodicJob(time.Millisecond, func() {
+		calls <- struct{}{}
+	})
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		job(stop)
+		close(done)
+	}()
+	<-calls
+	<-calls
+	close(stop)
+	<-done
+}
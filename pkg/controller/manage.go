@@ -0,0 +1,192 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	osdsctx "github.com/opensds/opensds/pkg/context"
+	"github.com/opensds/opensds/pkg/db"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"golang.org/x/net/context"
+)
+
+// ManageVolume imports an existing volume of the backend on the pool into
+// opensds through the dock which the pool belongs to.
+func (c *Controller) ManageVolume(ctx context.Context, in *model.VolumeManageSpec) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if in.Identifier == "" || in.PoolId == "" {
+		return nil, errors.New("Both identifier and pool id are required to manage volume")
+	}
+	if in.ProjectId != "" {
+		if err := checkQuota(in.ProjectId, 1, 0); err != nil {
+			logger.Error("When check quota of project:", err)
+			return nil, err
+		}
+	}
+
+	prf, err := c.SelectProfile(in.ProfileId)
+	if err != nil {
+		logger.Error("when search profiles in db:", err)
+		return nil, err
+	}
+	polInfo, err := db.C.GetPool(in.PoolId)
+	if err != nil {
+		logger.Error("When get pool in db:", err)
+		return nil, err
+	}
+	dockInfo, err := c.SelectDock(polInfo)
+	if err != nil {
+		logger.Error("When search supported dock resource:", err)
+		return nil, err
+	}
+	if in.DockId != "" && in.DockId != dockInfo.GetId() {
+		return nil, fmt.Errorf("Pool %s doesn't belong to dock %s", in.PoolId, in.DockId)
+	}
+	c.volumeController.SetDock(dockInfo)
+
+	vol, err := c.volumeController.ManageVolume(
+		ctx,
+		&pb.ManageVolumeOpts{
+			Identifier:  in.Identifier,
+			Name:        in.Name,
+			Description: in.Description,
+			PoolId:      polInfo.GetId(),
+			ProfileId:   prf.GetId(),
+			ProjectId:   in.ProjectId,
+			Metadata:    in.Metadata,
+			DockId:      dockInfo.GetId(),
+			DriverName:  dockInfo.GetDriverName(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The size of volume is known only after it's pulled from the backend, so
+	// the volume is unmanaged again if it exceeds the quota of gigabytes.
+	if in.ProjectId != "" {
+		if err = checkQuota(in.ProjectId, 0, 0); err != nil {
+			logger.Error("When check quota of project:", err)
+			if err := db.C.DeleteVolume(vol.GetId()); err != nil {
+				logger.Errorf("When unmanage volume %s: %v\n", vol.GetId(), err)
+			}
+			return nil, err
+		}
+	}
+	return vol, nil
+}
+
+// UnmanageVolume removes the volume from opensds but keeps the data of it in
+// the backend, the volume which is attached, awaiting transfer or has
+// snapshots can't be unmanaged.
+func (c *Controller) UnmanageVolume(ctx context.Context, in *model.VolumeSpec) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
+	vol, err := db.C.GetVolume(in.GetId())
+	if err != nil {
+		logger.Error("When get volume in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	if status := vol.GetStatus(); status == model.VolumeInUse || status == model.VolumeAwaitingTransfer {
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprintf("Volume %s is %s and can't be unmanaged", vol.GetId(), status),
+		}
+	}
+	snps, err := db.C.ListVolumeSnapshots(&model.ListOptions{
+		Filters: map[string]string{"volumeId": vol.GetId()},
+	})
+	if err != nil {
+		logger.Error("When list volume snapshots in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	if len(snps) != 0 {
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprintf("Volume %s has snapshots, please unmanage them first", vol.GetId()),
+		}
+	}
+
+	if err = db.C.DeleteVolume(vol.GetId()); err != nil {
+		logger.Error("When delete volume in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	return &model.Response{Status: "Success"}
+}
+
+// ManageVolumeSnapshot imports an existing snapshot of the backend into
+// opensds through the dock which its volume belongs to.
+func (c *Controller) ManageVolumeSnapshot(ctx context.Context, in *model.VolumeSnapshotManageSpec) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if in.Identifier == "" || in.VolumeId == "" {
+		return nil, errors.New("Both identifier and volume id are required to manage snapshot")
+	}
+
+	dockInfo, err := c.SelectDock(in.VolumeId)
+	if err != nil {
+		logger.Error("When search supported dock resource:", err)
+		return nil, err
+	}
+	c.volumeController.SetDock(dockInfo)
+
+	return c.volumeController.ManageVolumeSnapshot(
+		ctx,
+		&pb.ManageVolumeSnapshotOpts{
+			Identifier:  in.Identifier,
+			Name:        in.Name,
+			Description: in.Description,
+			VolumeId:    in.VolumeId,
+			Metadata:    in.Metadata,
+			DockId:      dockInfo.GetId(),
+			DriverName:  dockInfo.GetDriverName(),
+		},
+	)
+}
+
+// UnmanageVolumeSnapshot removes the snapshot from opensds but keeps the data
+// of it in the backend.
+func (c *Controller) UnmanageVolumeSnapshot(ctx context.Context, in *model.VolumeSnapshotSpec) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
+	if _, err := db.C.GetVolumeSnapshot(in.GetId()); err != nil {
+		logger.Error("When get volume snapshot in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	if err := db.C.DeleteVolumeSnapshot(in.GetId()); err != nil {
+		logger.Error("When delete volume snapshot in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	return &model.Response{Status: "Success"}
+}
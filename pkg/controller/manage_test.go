@@ -0,0 +1,202 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/controller/policy"
+	"github.com/opensds/opensds/pkg/controller/selector"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
+)
+
+func TestManageVolume(t *testing.T) {
+	var pol = &model.StoragePoolSpec{
+		BaseModel: &model.BaseModel{
+			Id: "084bf71e-a102-11e7-88a8-e31fe6d52248",
+		},
+		DockId: "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
+	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetPool", pol.Id).Return(pol, nil)
+	db.C = mockClient
+
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+		policyController: policy.NewController(&sampleProfile),
+	}
+
+	if _, err := c.ManageVolume(context.Background(), &model.VolumeManageSpec{
+		PoolId: pol.Id,
+	}); err == nil {
+		t.Error("Expected managing volume without identifier failed")
+	}
+	if _, err := c.ManageVolume(context.Background(), &model.VolumeManageSpec{
+		Identifier: "volume-existing",
+		PoolId:     pol.Id,
+		DockId:     "another-dock",
+	}); err == nil {
+		t.Error("Expected managing volume with mismatched dock failed")
+	}
+
+	result, err := c.ManageVolume(context.Background(), &model.VolumeManageSpec{
+		Identifier: "volume-existing",
+		PoolId:     pol.Id,
+	})
+	if err != nil {
+		t.Errorf("Failed to manage volume, err is %v\n", err)
+	}
+	if !reflect.DeepEqual(result, &sampleVolume) {
+		t.Errorf("Expected %v, got %v\n", &sampleVolume, result)
+	}
+}
+
+func TestManageVolumeOverQuota(t *testing.T) {
+	var pol = &model.StoragePoolSpec{
+		BaseModel: &model.BaseModel{
+			Id: "084bf71e-a102-11e7-88a8-e31fe6d52248",
+		},
+		DockId: "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
+	}
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+		policyController: policy.NewController(&sampleProfile),
+	}
+
+	// The project has run out of volumes.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetQuota", "project-a").Return(&model.QuotaSpec{
+		BaseModel:    &model.BaseModel{Id: "project-a"},
+		ResourceList: map[string]int64{model.QuotaVolumes: 1},
+	}, nil)
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{
+		{BaseModel: &model.BaseModel{}, Size: 1, ProjectId: "project-a"},
+	}, nil)
+	db.C = mockClient
+	if _, err := c.ManageVolume(context.Background(), &model.VolumeManageSpec{
+		Identifier: "volume-existing",
+		PoolId:     pol.Id,
+		ProjectId:  "project-a",
+	}); err == nil {
+		t.Error("Expected managing volume over quota of volumes failed")
+	}
+	mockClient.AssertNotCalled(t, "GetPool", pol.Id)
+
+	// The managed volume exceeds the quota of gigabytes, so it's unmanaged
+	// again.
+	mockClient = new(dbtest.MockClient)
+	mockClient.On("GetQuota", "project-a").Return(&model.QuotaSpec{
+		BaseModel:    &model.BaseModel{Id: "project-a"},
+		ResourceList: map[string]int64{model.QuotaGigabytes: 4},
+	}, nil)
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{
+		{BaseModel: &model.BaseModel{}, Size: 4, ProjectId: "project-a"},
+	}, nil).Once()
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{
+		{BaseModel: &model.BaseModel{}, Size: 4, ProjectId: "project-a"},
+		&sampleVolume,
+	}, nil)
+	mockClient.On("GetPool", pol.Id).Return(pol, nil)
+	mockClient.On("DeleteVolume", sampleVolume.Id).Return(nil)
+	db.C = mockClient
+	if _, err := c.ManageVolume(context.Background(), &model.VolumeManageSpec{
+		Identifier: "volume-existing",
+		PoolId:     pol.Id,
+		ProjectId:  "project-a",
+	}); err == nil {
+		t.Error("Expected managing volume over quota of gigabytes failed")
+	}
+	mockClient.AssertCalled(t, "DeleteVolume", sampleVolume.Id)
+}
+
+func TestUnmanageVolume(t *testing.T) {
+	var vol = sampleVolume
+	vol.Status = model.VolumeAvailable
+	var c = &Controller{}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	mockClient.On("ListVolumeSnapshots", mock.Anything).
+		Return([]*model.VolumeSnapshotSpec{&sampleSnapshot}, nil).Once()
+	mockClient.On("ListVolumeSnapshots", mock.Anything).
+		Return([]*model.VolumeSnapshotSpec{}, nil)
+	mockClient.On("DeleteVolume", vol.Id).Return(nil)
+	db.C = mockClient
+
+	// The volume which still has snapshots can't be unmanaged.
+	if result := c.UnmanageVolume(context.Background(), &vol); result.Status != "Failure" {
+		t.Errorf("Expected unmanage volume with snapshots failed, got %v\n", result)
+	}
+	if result := c.UnmanageVolume(context.Background(), &vol); result.Status != "Success" {
+		t.Errorf("Expected unmanage volume succeeded, got %v\n", result)
+	}
+	// The backend data should be kept, so only db record is removed.
+	mockClient.AssertCalled(t, "DeleteVolume", vol.Id)
+}
+
+func TestUnmanageVolumeInUse(t *testing.T) {
+	var vol = sampleVolume
+	vol.Status = model.VolumeInUse
+	var c = &Controller{}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	db.C = mockClient
+
+	if result := c.UnmanageVolume(context.Background(), &vol); result.Status != "Failure" {
+		t.Errorf("Expected unmanage in-use volume failed, got %v\n", result)
+	}
+	mockClient.AssertNotCalled(t, "DeleteVolume", vol.Id)
+}
+
+func TestManageVolumeSnapshot(t *testing.T) {
+	var c = &Controller{
+		Selector:         selector.NewFakeSelector(),
+		volumeController: NewFakeVolumeController(),
+		policyController: policy.NewController(&sampleProfile),
+	}
+
+	result, err := c.ManageVolumeSnapshot(context.Background(), &model.VolumeSnapshotManageSpec{
+		Identifier: "snapshot-existing",
+		VolumeId:   "bd5b12a8-a101-11e7-941e-d77981b584d8",
+	})
+	if err != nil {
+		t.Errorf("Failed to manage volume snapshot, err is %v\n", err)
+	}
+	if !reflect.DeepEqual(result, &sampleSnapshot) {
+		t.Errorf("Expected %v, got %v\n", &sampleSnapshot, result)
+	}
+}
+
+func TestUnmanageVolumeSnapshot(t *testing.T) {
+	var c = &Controller{}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeSnapshot", sampleSnapshot.Id).Return(&sampleSnapshot, nil)
+	mockClient.On("DeleteVolumeSnapshot", sampleSnapshot.Id).Return(nil)
+	db.C = mockClient
+
+	if result := c.UnmanageVolumeSnapshot(context.Background(), &sampleSnapshot); result.Status != "Success" {
+		t.Errorf("Expected unmanage volume snapshot succeeded, got %v\n", result)
+	}
+	mockClient.AssertCalled(t, "DeleteVolumeSnapshot", sampleSnapshot.Id)
+}
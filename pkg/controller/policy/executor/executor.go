@@ -23,7 +23,6 @@ package executor
 import (
 	"errors"
 
-	log "github.com/golang/glog"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
 )
@@ -33,48 +32,67 @@ type AsynchronizedExecutor interface {
 	Asynchronized() error
 }
 
+// AsynchronizedExecutorFactory builds the executor of an operation policy
+// with the request and the policy value configured in profile.
+type AsynchronizedExecutorFactory func(
+	req interface{},
+	value string,
+	dockInfo *model.DockSpec) (
+	AsynchronizedExecutor, error)
+
 type AsynchronizedWorkflow map[string]AsynchronizedExecutor
 
-func RegisterAsynchronizedWorkflow(
+func NewIntervalSnapshotExecutor(
 	req interface{},
-	tags map[string]string,
-	dockInfo *model.DockSpec,
-	in string) (
-	AsynchronizedWorkflow, error) {
-
-	var asynWorkflow = AsynchronizedWorkflow{}
-	for key := range tags {
-		switch key {
-		case "intervalSnapshot":
-			ise := &IntervalSnapshotExecutor{
-				Request:  req.(*pb.CreateVolumeSnapshotOpts),
-				Interval: tags[key],
-				DockInfo: dockInfo,
-			}
-
-			if err := ise.Init(in); err != nil {
-				log.Errorf("When register async policy %s: %v\n", key, err)
-				return asynWorkflow, err
-			} else {
-				asynWorkflow[key] = ise
-			}
-		case "deleteSnapshotPolicy":
-			ise := &DeleteSnapshotExecutor{
-				Request:  req.(*pb.DeleteVolumeSnapshotOpts),
-				DockInfo: dockInfo,
-			}
-
-			if err := ise.Init(in); err != nil {
-				log.Errorf("When register async policy %s: %v\n", key, err)
-				return asynWorkflow, err
-			} else {
-				asynWorkflow[key] = ise
-			}
+	value string,
+	dockInfo *model.DockSpec) (
+	AsynchronizedExecutor, error) {
+
+	var opt *pb.CreateVolumeSnapshotOpts
+	switch req.(type) {
+	case *pb.CreateVolumeSnapshotOpts:
+		opt = req.(*pb.CreateVolumeSnapshotOpts)
+	case *pb.CreateVolumeOpts:
+		// Snapshots will be taken periodically on the volume created.
+		opt = &pb.CreateVolumeSnapshotOpts{
+			DockId:     req.(*pb.CreateVolumeOpts).GetDockId(),
+			DriverName: req.(*pb.CreateVolumeOpts).GetDriverName(),
 		}
+	default:
+		return nil, errors.New("Policy intervalSnapshot only supports volume or snapshot creation")
 	}
+	return &IntervalSnapshotExecutor{
+		Request:  opt,
+		Interval: value,
+		DockInfo: dockInfo,
+	}, nil
+}
 
-	log.Info("Register asynchronized work flow success, awf =", asynWorkflow)
-	return asynWorkflow, nil
+func NewDeleteSnapshotExecutor(
+	req interface{},
+	value string,
+	dockInfo *model.DockSpec) (
+	AsynchronizedExecutor, error) {
+
+	var opt *pb.DeleteVolumeSnapshotOpts
+	switch req.(type) {
+	case *pb.DeleteVolumeSnapshotOpts:
+		opt = req.(*pb.DeleteVolumeSnapshotOpts)
+	case *pb.DeleteVolumeOpts:
+		// The remaining snapshots of the volume will be deleted.
+		opt = &pb.DeleteVolumeSnapshotOpts{
+			VolumeId:   req.(*pb.DeleteVolumeOpts).GetId(),
+			DockId:     req.(*pb.DeleteVolumeOpts).GetDockId(),
+			DriverName: req.(*pb.DeleteVolumeOpts).GetDriverName(),
+		}
+	default:
+		return nil, errors.New("Policy deleteSnapshotPolicy only supports volume or snapshot deletion")
+	}
+	return &DeleteSnapshotExecutor{
+		VolumeId: opt.GetVolumeId(),
+		Request:  opt,
+		DockInfo: dockInfo,
+	}, nil
 }
 
 func ExecuteAsynchronizedWorkflow(asynWorkflow AsynchronizedWorkflow) error {
@@ -92,11 +110,11 @@ type SynchronizedExecutor interface {
 	Synchronized() error
 }
 
-type SynchronizedWorkflow map[string]SynchronizedExecutor
+// SynchronizedExecutorFactory builds the executor of a feature policy with
+// the request and the policy value configured in profile.
+type SynchronizedExecutorFactory func(req interface{}, value interface{}) (SynchronizedExecutor, error)
 
-func RegisterSynchronizedWorkflow(req interface{}, tags map[string]interface{}) (SynchronizedWorkflow, error) {
-	return SynchronizedWorkflow{}, nil
-}
+type SynchronizedWorkflow map[string]SynchronizedExecutor
 
 func ExecuteSynchronizedWorkflow(synWorkflow SynchronizedWorkflow) error {
 	for key := range synWorkflow {
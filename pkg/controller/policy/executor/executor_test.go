@@ -13,3 +13,51 @@
 //    under the License.
 
 package executor
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+)
+
+func TestQosExecutor(t *testing.T) {
+	var req = &pb.CreateVolumeOpts{
+		Name: "sample-volume",
+		Size: 1,
+	}
+
+	ie, err := NewIopsExecutor(req, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	be, err := NewBandwidthExecutor(req, float64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var swf = SynchronizedWorkflow{"iops": ie, "bandwidth": be}
+	for _, sye := range swf {
+		if err = sye.Init(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = ExecuteSynchronizedWorkflow(swf); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedQos = &pb.Qos{MaxIOPS: 1000, MaxBandwidth: 100}
+	if !reflect.DeepEqual(expectedQos, req.GetQos()) {
+		t.Fatalf("Expected %v, got %v", expectedQos, req.GetQos())
+	}
+
+	// Test if the executor would fail when an invalid qos value assigned.
+	ie, _ = NewIopsExecutor(req, "-1")
+	if err = ie.Init(); err == nil {
+		t.Fatal("Expected an error when initializing invalid iops")
+	}
+
+	// Test if the factory would fail when qos is assigned to other requests.
+	if _, err = NewIopsExecutor(&pb.DeleteVolumeOpts{}, 1000); err == nil {
+		t.Fatal("Expected an error when building iops executor on volume deletion")
+	}
+}
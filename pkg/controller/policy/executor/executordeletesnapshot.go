@@ -48,11 +48,14 @@ type DeleteSnapshotExecutor struct {
 }
 
 func (dse *DeleteSnapshotExecutor) Init(in string) (err error) {
-	var volumeResponse model.VolumeSpec
-	if err = json.Unmarshal([]byte(in), &volumeResponse); err != nil {
-		return err
+	// The volume id may be already known from the deletion request.
+	if in != "" {
+		var volumeResponse model.VolumeSpec
+		if err = json.Unmarshal([]byte(in), &volumeResponse); err != nil {
+			return err
+		}
+		dse.VolumeId = volumeResponse.Id
 	}
-	dse.VolumeId = volumeResponse.Id
 	dse.Client = client.NewClient()
 	dse.Client.Update(dse.DockInfo)
 
@@ -96,16 +99,15 @@ func CheckSnapshotDeleted(volumeId string) bool {
 
 func findRemainingSnapshot(volumeId string) ([]string, error) {
 	var remainingSnapshots = []string{}
-	snapshots, err := db.C.ListVolumeSnapshots()
+	snapshots, err := db.C.ListVolumeSnapshots(&model.ListOptions{
+		Filters: map[string]string{"volumeId": volumeId},
+	})
 	if err != nil {
 		log.Error("When list volume snapshots:", err)
 		return remainingSnapshots, err
 	}
 
 	for _, snap := range snapshots {
-		if snap.VolumeId != volumeId {
-			continue
-		}
 		remainingSnapshots = append(remainingSnapshots, snap.Id)
 	}
 	return remainingSnapshots, nil
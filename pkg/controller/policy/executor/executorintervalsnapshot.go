@@ -35,6 +35,16 @@ import (
 	"golang.org/x/net/context"
 )
 
+// IntervalSnapshotTotal is the number of snapshots taken by the interval
+// snapshot policy of a volume.
+const IntervalSnapshotTotal = 3
+
+// IntervalSnapshotName returns the name of the snapshots taken by the interval
+// snapshot policy of the volume.
+func IntervalSnapshotName(volID string) string {
+	return "snapshot-" + volID
+}
+
 type IntervalSnapshotExecutor struct {
 	client.Client
 
@@ -51,7 +61,7 @@ func (ise *IntervalSnapshotExecutor) Init(in string) (err error) {
 	}
 
 	ise.Request.VolumeId = volumeResponse.Id
-	ise.Request.Name = "snapshot-" + volumeResponse.Id
+	ise.Request.Name = IntervalSnapshotName(volumeResponse.Id)
 	ise.Request.Size = volumeResponse.Size
 	ise.Client = client.NewClient()
 	ise.Client.Update(ise.DockInfo)
@@ -61,7 +71,7 @@ func (ise *IntervalSnapshotExecutor) Init(in string) (err error) {
 
 func (ise *IntervalSnapshotExecutor) Asynchronized() error {
 	if ise.TotalNum == 0 {
-		ise.TotalNum = 3
+		ise.TotalNum = IntervalSnapshotTotal
 	}
 	num, err := ParseInterval(ise.Interval)
 	if err != nil {
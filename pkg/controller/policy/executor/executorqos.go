@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the executors of qos policies, which stamp the iops
+and bandwidth limits configured in profile on the volume creation request.
+
+*/
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/golang/glog"
+
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+// parseQosLimit converts the qos value configured in profile into a positive
+// limit.
+func parseQosLimit(name string, value interface{}) (int64, error) {
+	limit, err := utils.ToInt64(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s value %v: %v", name, value, err)
+	}
+	if limit <= 0 {
+		return 0, fmt.Errorf("Invalid %s value %v: must be positive", name, value)
+	}
+	return limit, nil
+}
+
+func NewIopsExecutor(req interface{}, value interface{}) (SynchronizedExecutor, error) {
+	opt, ok := req.(*pb.CreateVolumeOpts)
+	if !ok {
+		return nil, errors.New("Policy iops only supports volume creation")
+	}
+	return &IopsExecutor{
+		Request: opt,
+		Iops:    value,
+	}, nil
+}
+
+// IopsExecutor stamps the max iops limit on the volume creation request, the
+// limit will be enforced by the storage driver when creating the volume.
+type IopsExecutor struct {
+	Request *pb.CreateVolumeOpts
+	Iops    interface{}
+
+	limit int64
+}
+
+func (ie *IopsExecutor) Init() (err error) {
+	ie.limit, err = parseQosLimit("iops", ie.Iops)
+	return err
+}
+
+func (ie *IopsExecutor) Synchronized() error {
+	if ie.Request.Qos == nil {
+		ie.Request.Qos = &pb.Qos{}
+	}
+	ie.Request.Qos.MaxIOPS = ie.limit
+
+	log.Infof("Set max iops of volume %s to %d", ie.Request.GetName(), ie.limit)
+	return nil
+}
+
+func NewBandwidthExecutor(req interface{}, value interface{}) (SynchronizedExecutor, error) {
+	opt, ok := req.(*pb.CreateVolumeOpts)
+	if !ok {
+		return nil, errors.New("Policy bandwidth only supports volume creation")
+	}
+	return &BandwidthExecutor{
+		Request:   opt,
+		Bandwidth: value,
+	}, nil
+}
+
+// BandwidthExecutor stamps the max bandwidth limit (MB/s) on the volume
+// creation request, the limit will be enforced by the storage driver when
+// creating the volume.
+type BandwidthExecutor struct {
+	Request   *pb.CreateVolumeOpts
+	Bandwidth interface{}
+
+	limit int64
+}
+
+func (be *BandwidthExecutor) Init() (err error) {
+	be.limit, err = parseQosLimit("bandwidth", be.Bandwidth)
+	return err
+}
+
+func (be *BandwidthExecutor) Synchronized() error {
+	if be.Request.Qos == nil {
+		be.Request.Qos = &pb.Qos{}
+	}
+	be.Request.Qos.MaxBandwidth = be.limit
+
+	log.Infof("Set max bandwidth of volume %s to %d MB/s", be.Request.GetName(), be.limit)
+	return nil
+}
@@ -21,12 +21,28 @@ profiles configured by admin.
 package policy
 
 import (
+	"fmt"
+
+	log "github.com/golang/glog"
+
 	"github.com/opensds/opensds/pkg/controller/policy/executor"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils/metrics"
 )
 
+// asyncPolicyQueueDepth is exposed as opensds_async_policy_queue_depth, which
+// counts the asynchronous policy workflows that are waiting or running.
+var asyncPolicyQueueDepth = metrics.NewGaugeVec(
+	"opensds_async_policy_queue_depth",
+	"Number of asynchronous policy workflows which are not finished yet.",
+)
+
+func init() {
+	metrics.MustRegister(asyncPolicyQueueDepth)
+}
+
 type Controller interface {
-	Setup(flag int)
+	Setup(flag int) error
 
 	StorageTag() *StorageTag
 
@@ -49,8 +65,9 @@ type controller struct {
 	Tag      *StorageTag
 }
 
-func (c *controller) Setup(flag int) {
-	c.Tag = NewStorageTag(c.Profile.Extra, flag)
+func (c *controller) Setup(flag int) (err error) {
+	c.Tag, err = NewStorageTag(c.Profile.Extra, flag)
+	return err
 }
 
 func (c *controller) StorageTag() *StorageTag {
@@ -58,7 +75,7 @@ func (c *controller) StorageTag() *StorageTag {
 }
 
 func (c *controller) ExecuteSyncPolicy(req interface{}) error {
-	swf, err := executor.RegisterSynchronizedWorkflow(req, c.Tag.syncTag)
+	swf, err := buildSynchronizedWorkflow(req, c.Tag.syncTag)
 	if err != nil {
 		return err
 	}
@@ -70,15 +87,19 @@ func (c *controller) ExecuteSyncPolicy(req interface{}) error {
 }
 
 func (c *controller) ExecuteAsyncPolicy(req interface{}, in string, errChan chan error) {
-	awf, err := executor.RegisterAsynchronizedWorkflow(req, c.Tag.asyncTag, c.DockInfo, in)
+	asyncPolicyQueueDepth.Inc()
+	defer asyncPolicyQueueDepth.Dec()
+	defer close(errChan)
+
+	awf, err := buildAsynchronizedWorkflow(req, c.Tag.asyncTag, c.DockInfo, in)
 	if err != nil {
 		errChan <- err
+		return
 	}
 
-	defer close(errChan)
-
 	if err = executor.ExecuteAsynchronizedWorkflow(awf); err != nil {
 		errChan <- err
+		return
 	}
 	errChan <- nil
 }
@@ -86,3 +107,63 @@ func (c *controller) ExecuteAsyncPolicy(req interface{}, in string, errChan chan
 func (c *controller) SetDock(dockInfo *model.DockSpec) {
 	c.DockInfo = dockInfo
 }
+
+// buildSynchronizedWorkflow builds the executors of feature policies through
+// the executor factories registered.
+func buildSynchronizedWorkflow(req interface{}, tags map[string]interface{}) (executor.SynchronizedWorkflow, error) {
+	var synWorkflow = executor.SynchronizedWorkflow{}
+	for key := range tags {
+		p, ok := GetPolicy(key)
+		if !ok {
+			return synWorkflow, fmt.Errorf("The policy type of %s not supported", key)
+		}
+		// Some features are only used when selecting pools.
+		if p.SyncExecutorFactory == nil {
+			continue
+		}
+
+		sye, err := p.SyncExecutorFactory(req, tags[key])
+		if err == nil {
+			err = sye.Init()
+		}
+		if err != nil {
+			log.Errorf("When register sync policy %s: %v\n", key, err)
+			return synWorkflow, err
+		}
+		synWorkflow[key] = sye
+	}
+
+	log.Info("Register synchronized work flow success, swf =", synWorkflow)
+	return synWorkflow, nil
+}
+
+// buildAsynchronizedWorkflow builds the executors of operation policies
+// through the executor factories registered.
+func buildAsynchronizedWorkflow(
+	req interface{},
+	tags map[string]string,
+	dockInfo *model.DockSpec,
+	in string) (
+	executor.AsynchronizedWorkflow, error) {
+
+	var asynWorkflow = executor.AsynchronizedWorkflow{}
+	for key := range tags {
+		p, ok := GetPolicy(key)
+		if !ok || p.AsyncExecutorFactory == nil {
+			return asynWorkflow, fmt.Errorf("The policy type of %s not supported", key)
+		}
+
+		ase, err := p.AsyncExecutorFactory(req, tags[key], dockInfo)
+		if err == nil {
+			err = ase.Init(in)
+		}
+		if err != nil {
+			log.Errorf("When register async policy %s: %v\n", key, err)
+			return asynWorkflow, err
+		}
+		asynWorkflow[key] = ase
+	}
+
+	log.Info("Register asynchronized work flow success, awf =", asynWorkflow)
+	return asynWorkflow, nil
+}
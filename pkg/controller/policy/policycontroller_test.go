@@ -13,3 +13,122 @@
 //    under the License.
 
 package policy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/controller/policy/executor"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+)
+
+type fakeSyncExecutor struct {
+	Request *pb.CreateVolumeOpts
+	Value   interface{}
+}
+
+func (fse *fakeSyncExecutor) Init() error { return nil }
+
+func (fse *fakeSyncExecutor) Synchronized() error {
+	if fse.Request.Metadata == nil {
+		fse.Request.Metadata = make(map[string]string)
+	}
+	fse.Request.Metadata["fakeFeature"] = fse.Value.(string)
+	return nil
+}
+
+func init() {
+	// Register a third-party policy to test the registry.
+	RegisterPolicy(&Policy{
+		Name:       "fakeFeature",
+		Type:       FeaturePolicy,
+		Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+		Schema:     &model.ExtraPropertySchema{Type: model.ExtraTypeString},
+		SyncExecutorFactory: func(req interface{}, value interface{}) (executor.SynchronizedExecutor, error) {
+			opt, ok := req.(*pb.CreateVolumeOpts)
+			if !ok {
+				return nil, errors.New("unexpected request")
+			}
+			return &fakeSyncExecutor{Request: opt, Value: value}, nil
+		},
+	})
+}
+
+func TestRegisterPolicy(t *testing.T) {
+	// Test if the registered policy is published in profile extras schema.
+	if _, ok := model.ProfileExtraSchema.Properties["fakeFeature"]; !ok {
+		t.Error("Expected fakeFeature published in profile extras schema")
+	}
+
+	// Test if the method would fail when registering a duplicated policy.
+	if err := RegisterPolicy(&Policy{
+		Name:       "iops",
+		Type:       FeaturePolicy,
+		Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+		Schema:     &model.ExtraPropertySchema{Type: model.ExtraTypeInteger},
+	}); err == nil {
+		t.Error("Expected an error when registering duplicated policy")
+	}
+
+	// Test if the method would fail when operation policy has no executor.
+	if err := RegisterPolicy(&Policy{
+		Name:   "fakeOperation",
+		Type:   OperationPolicy,
+		Schema: &model.ExtraPropertySchema{Type: model.ExtraTypeString},
+	}); err == nil {
+		t.Error("Expected an error when registering policy without executor")
+	}
+
+	// Test if the method would fail when the schema is invalid.
+	for _, schema := range []*model.ExtraPropertySchema{
+		{Type: model.ExtraTypeString, Pattern: "^[0-9+$"},
+		{Type: "float"},
+	} {
+		p := &Policy{Name: "fakeInvalid", Type: FeaturePolicy, Schema: schema}
+		if err := RegisterPolicy(p); err == nil {
+			t.Errorf("Expected an error when registering schema %+v", schema)
+		}
+		if _, ok := GetPolicy(p.Name); ok {
+			t.Errorf("Expected policy with schema %+v not registered", schema)
+		}
+	}
+
+	// Test if the compiled pattern of registered policy is validated.
+	p, _ := GetPolicy("intervalSnapshot")
+	if err := p.Schema.Validate("30m"); err != nil {
+		t.Error(err)
+	}
+	if err := p.Schema.Validate("30x"); err == nil {
+		t.Error("Expected an error when validating value mismatching pattern")
+	}
+}
+
+func TestExecuteSyncPolicy(t *testing.T) {
+	c := NewController(&model.ProfileSpec{
+		BaseModel: &model.BaseModel{},
+		Extra: model.ExtraSpec{
+			"diskType":         "SSD",
+			"iops":             float64(1000),
+			"fakeFeature":      "enabled",
+			"intervalSnapshot": "1d",
+		},
+	})
+	if err := c.Setup(CREATE_LIFECIRCLE_FLAG); err != nil {
+		t.Fatal(err)
+	}
+
+	var req = &pb.CreateVolumeOpts{Name: "sample-volume"}
+	if err := c.ExecuteSyncPolicy(req); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedQos = &pb.Qos{MaxIOPS: 1000}
+	if !reflect.DeepEqual(expectedQos, req.GetQos()) {
+		t.Errorf("Expected %v, got %v", expectedQos, req.GetQos())
+	}
+	if req.GetMetadata()["fakeFeature"] != "enabled" {
+		t.Errorf("Expected fakeFeature executed, got %v", req.GetMetadata())
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the registry of storage policies, which records the
+type, lifecycles, schema and executors of every policy configured in profile
+extras.
+
+*/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/opensds/opensds/pkg/controller/policy/executor"
+	"github.com/opensds/opensds/pkg/model"
+)
+
+const (
+	// FeaturePolicy is the type of policies describing what the volume is,
+	// they are used for selecting pools and executed synchronously.
+	FeaturePolicy = "feature"
+	// OperationPolicy is the type of policies describing what should be done
+	// on the volume, they are executed asynchronously.
+	OperationPolicy = "operation"
+)
+
+// The lifecycle phases of a volume which policies could be applied to.
+const (
+	CREATE_LIFECIRCLE_FLAG = iota + 1
+	GET_LIFECIRCLE_FLAG
+	LIST_LIFECIRCLE_FLAG
+	DELETE_LIFECIRCLE_FLAG
+	ATTACH_LIFECIRCLE_FLAG
+	SNAPSHOT_LIFECIRCLE_FLAG
+)
+
+// Policy describes a storage policy which could be configured in profile
+// extras.
+type Policy struct {
+	// Name is the key of the policy in profile extras.
+	Name string
+	// Type is either FeaturePolicy or OperationPolicy.
+	Type string
+	// Lifecycles are the lifecycle phases the policy applies to.
+	Lifecycles []int
+	// Schema describes the value type and allowed range of the policy.
+	Schema *model.ExtraPropertySchema
+
+	// SyncExecutorFactory builds the executor of a feature policy, it could
+	// be nil if the policy is only used for selecting pools.
+	SyncExecutorFactory executor.SynchronizedExecutorFactory
+	// AsyncExecutorFactory builds the executor of an operation policy.
+	AsyncExecutorFactory executor.AsynchronizedExecutorFactory
+}
+
+// IsAppliedTo checks if the policy applies to the lifecycle phase.
+func (p *Policy) IsAppliedTo(flag int) bool {
+	for _, lc := range p.Lifecycles {
+		if lc == flag {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]*Policy)
+)
+
+// RegisterPolicy adds a policy into the registry and publishes its schema in
+// profile extras schema, so that the policy could be configured in profiles.
+// It is expected to be called in the init function of the package providing
+// the policy.
+func RegisterPolicy(p *Policy) error {
+	if p == nil || p.Name == "" {
+		return errors.New("Policy name could not be empty")
+	}
+	if p.Schema == nil {
+		return fmt.Errorf("Schema of policy %s could not be empty", p.Name)
+	}
+	if err := p.Schema.Compile(); err != nil {
+		return fmt.Errorf("Schema of policy %s is invalid: %v", p.Name, err)
+	}
+	switch p.Type {
+	case FeaturePolicy:
+	case OperationPolicy:
+		if p.AsyncExecutorFactory == nil {
+			return fmt.Errorf("Async executor of policy %s could not be empty", p.Name)
+		}
+	default:
+		return fmt.Errorf("Type %s of policy %s is not supported", p.Type, p.Name)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[p.Name]; ok {
+		return fmt.Errorf("Policy %s has already been registered", p.Name)
+	}
+	registry[p.Name] = p
+	model.ProfileExtraSchema.Properties[p.Name] = p.Schema
+	return nil
+}
+
+// GetPolicy returns the policy registered with the name.
+func GetPolicy(name string) (*Policy, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	p, ok := registry[name]
+	return p, ok
+}
+
+// ListPolicies returns all policies registered, sorted by name.
+func ListPolicies() []*Policy {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var pols []*Policy
+	for _, p := range registry {
+		pols = append(pols, p)
+	}
+	sort.Slice(pols, func(i, j int) bool { return pols[i].Name < pols[j].Name })
+	return pols
+}
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func init() {
+	var builtinPolicies = []*Policy{
+		{
+			Name:       "diskType",
+			Type:       FeaturePolicy,
+			Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeString,
+				Description: "The type of disks backing the storage pool.",
+				Enum:        []string{"SSD", "SAS", "SATA", "NL-SAS"},
+			},
+		},
+		{
+			Name:       "iops",
+			Type:       FeaturePolicy,
+			Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeInteger,
+				Description: "The max io operations per second of the volume.",
+				Unit:        "IOPS",
+				Minimum:     int64Ptr(1),
+			},
+			SyncExecutorFactory: executor.NewIopsExecutor,
+		},
+		{
+			Name:       "bandwidth",
+			Type:       FeaturePolicy,
+			Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeInteger,
+				Description: "The max throughput of the volume.",
+				Unit:        "MB/s",
+				Minimum:     int64Ptr(1),
+			},
+			SyncExecutorFactory: executor.NewBandwidthExecutor,
+		},
+		{
+			Name:       "thinProvision",
+			Type:       FeaturePolicy,
+			Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeBoolean,
+				Description: "Whether the volume is thin provisioned.",
+			},
+		},
+		{
+			Name:       "highAvailability",
+			Type:       FeaturePolicy,
+			Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeBoolean,
+				Description: "Whether the volume is highly available.",
+			},
+		},
+		{
+			Name:       "intervalSnapshot",
+			Type:       OperationPolicy,
+			Lifecycles: []int{CREATE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeString,
+				Description: "The interval of taking snapshots of the volume, such as 30m or 1d.",
+				Pattern:     "^[1-9][0-9]*[smhdSMHD]$",
+			},
+			AsyncExecutorFactory: executor.NewIntervalSnapshotExecutor,
+		},
+		{
+			Name:       "deleteSnapshotPolicy",
+			Type:       OperationPolicy,
+			Lifecycles: []int{DELETE_LIFECIRCLE_FLAG},
+			Schema: &model.ExtraPropertySchema{
+				Type:        model.ExtraTypeBoolean,
+				Description: "Whether to delete the snapshots when deleting the volume.",
+			},
+			AsyncExecutorFactory: executor.NewDeleteSnapshotExecutor,
+		},
+	}
+
+	for _, p := range builtinPolicies {
+		if err := RegisterPolicy(p); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -21,47 +21,15 @@ profiles configured by admin.
 package policy
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 
 	log "github.com/golang/glog"
-
-	"github.com/opensds/opensds/pkg/utils"
-)
-
-const (
-	POLICY_TYPE_MAPPING_TABLE = `{
-		"iops": "feature",
-		"bandwidth": "feature",
-		"diskType": "feature",
-		"thinProvision": "feature",
-		"highAvailability": "feature",
-		"intervalSnapshot": "operation",
-		"deleteSnapshotPolicy": "operation"
-	}`
-	POLICY_LIFECIRCLE_TABLE = `{
-		"iops": 1,
-		"bandwidth": 1,
-		"iops": 1,
-		"thinProvision": 1,
-		"highAvailability": 1,
-		"intervalSnapshot": 1,
-		"deleteSnapshotPolicy": 4
-	}`
 )
 
-var PolicyTypeMappingTable map[string]string
-var PolicyLifecircleTable map[string]int
-
-func init() {
-	json.Unmarshal([]byte(POLICY_TYPE_MAPPING_TABLE), &PolicyTypeMappingTable)
-	json.Unmarshal([]byte(POLICY_LIFECIRCLE_TABLE), &PolicyLifecircleTable)
-}
-
+// IsStorageTagSupported checks if all the storage tags are operation policies.
 func IsStorageTagSupported(tags map[string]string) bool {
 	for key := range tags {
-		if PolicyTypeMappingTable[key] != "operation" {
+		if p, ok := GetPolicy(key); !ok || p.Type != OperationPolicy {
 			return false
 		}
 	}
@@ -69,11 +37,12 @@ func IsStorageTagSupported(tags map[string]string) bool {
 }
 
 func FindPolicyType(policy string) (string, error) {
-	if !utils.Contained(policy, PolicyTypeMappingTable) {
-		return "", errors.New("The policy type of " + policy + " not supported")
+	p, ok := GetPolicy(policy)
+	if !ok {
+		return "", fmt.Errorf("The policy type of %s not supported", policy)
 	}
 
-	return PolicyTypeMappingTable[policy], nil
+	return p.Type, nil
 }
 
 type StorageTag struct {
@@ -81,32 +50,43 @@ type StorageTag struct {
 	asyncTag map[string]string
 }
 
-func NewStorageTag(tags map[string]interface{}, flag int) *StorageTag {
+// NewStorageTag divides the storage tags matching the lifecycle flag into sync
+// and async part. The tags are validated against the profile extras schema when
+// the profile is written, the ones which are not supported any more, such as
+// the tags of legacy profiles, are skipped here. The input tags will not be
+// modified.
+func NewStorageTag(tags map[string]interface{}, flag int) (*StorageTag, error) {
 	var st = &StorageTag{
 		syncTag:  make(map[string]interface{}),
 		asyncTag: make(map[string]string),
 	}
 
-	// Screen storage tags through life circle flag
 	for key := range tags {
-		if flag != PolicyLifecircleTable[key] {
-			delete(tags, key)
+		p, ok := GetPolicy(key)
+		if !ok {
+			log.Warningf("The policy type of %s not supported, skip it", key)
+			continue
 		}
-	}
-	// Devide all tags into sync and async part
-	for key := range tags {
-		pType, err := FindPolicyType(key)
-		if err != nil {
+		// Screen storage tags through life circle flag
+		if !p.IsAppliedTo(flag) {
+			continue
+		}
+		// The executors rely on the value of tag in the type of schema.
+		if err := p.Schema.Validate(tags[key]); err != nil {
+			err = fmt.Errorf("Storage tag %s %v", key, err)
 			log.Error("When parse storage tag:", err)
+			return nil, err
 		}
-		switch pType {
-		case "feature":
+
+		// Devide all tags into sync and async part
+		switch p.Type {
+		case FeaturePolicy:
 			st.syncTag[key] = tags[key]
-		case "operation":
+		case OperationPolicy:
 			st.asyncTag[key] = fmt.Sprint(tags[key])
 		}
 	}
-	return st
+	return st, nil
 }
 
 func (st *StorageTag) GetSyncTag() map[string]interface{} {
@@ -26,13 +26,13 @@ func TestIsStorageTagSupported(t *testing.T) {
 	}
 
 	if !IsStorageTagSupported(tags) {
-		t.Errorf("tags %v are not supported by %v\n", tags)
+		t.Errorf("tags %v are not supported\n", tags)
 	}
 }
 
 func TestFindPolicyType(t *testing.T) {
 	var policys = []string{"iops", "thinProvision", "intervalSnapshot"}
-	var expectedTypes = []string{"feature", "feature", "operation"}
+	var expectedTypes = []string{FeaturePolicy, FeaturePolicy, OperationPolicy}
 
 	for i, policy := range policys {
 		pType, err := FindPolicyType(policy)
@@ -61,13 +61,33 @@ func TestNewStorageTag(t *testing.T) {
 			"highAvailability": false,
 		},
 		asyncTag: map[string]string{
-			"intervalSnapshot":     "1d",
-			"deleteSnapshotPolicy": "true",
+			"intervalSnapshot": "1d",
 		},
 	}
 
-	st := NewStorageTag(tags, 1)
-	if reflect.DeepEqual(st, expectedSt) {
+	st, err := NewStorageTag(tags, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(st, expectedSt) {
 		t.Errorf("Expected %v, got %v\n", expectedSt, st)
 	}
+	// Test if the input tags are kept untouched after filtering.
+	if len(tags) != 5 {
+		t.Errorf("Expected input tags unchanged, got %v\n", tags)
+	}
+
+	// Test if the unknown tag of legacy profile is skipped.
+	tags["unknownTag"] = "value"
+	if st, err = NewStorageTag(tags, 1); err != nil {
+		t.Errorf("Expected unknown storage tag skipped, got %v", err)
+	} else if !reflect.DeepEqual(st, expectedSt) {
+		t.Errorf("Expected %v, got %v\n", expectedSt, st)
+	}
+
+	// Test if the method would fail when the value of tag is invalid.
+	tags["intervalSnapshot"] = "daily"
+	if _, err = NewStorageTag(tags, 1); err == nil {
+		t.Error("Expected an error when parsing invalid storage tag")
+	}
 }
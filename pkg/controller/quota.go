@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// checkQuota checks whether the project could own the additional volumes
+// and gigabytes. The usage of the project is counted from the volumes it
+// owns, so it's released automatically once a volume is deleted or handed
+// to another project.
+func checkQuota(prjID string, volumes, gigabytes int64) error {
+	quota, err := db.C.GetQuota(prjID)
+	if err != nil {
+		return err
+	}
+	limits := quota.GetResourceList()
+	if len(limits) == 0 {
+		return nil
+	}
+
+	vols, err := db.C.ListVolumes(&model.ListOptions{
+		Filters: map[string]string{"projectId": prjID},
+	})
+	if err != nil {
+		return err
+	}
+	var usage = map[string]int64{
+		model.QuotaVolumes:   volumes + int64(len(vols)),
+		model.QuotaGigabytes: gigabytes,
+	}
+	for _, vol := range vols {
+		usage[model.QuotaGigabytes] += vol.GetSize()
+	}
+
+	for _, res := range []string{model.QuotaVolumes, model.QuotaGigabytes} {
+		if limit, ok := limits[res]; ok && usage[res] > limit {
+			return fmt.Errorf("Quota of %s exceeded for project %s, limit is %d, requested usage is %d",
+				res, prjID, limit, usage[res])
+		}
+	}
+	return nil
+}
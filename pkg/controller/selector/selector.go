@@ -22,12 +22,14 @@ package selector
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	log "github.com/golang/glog"
 
 	"github.com/opensds/opensds/pkg/db"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
 )
 
 type Selector interface {
@@ -58,7 +60,7 @@ func (s *selector) SelectProfile(prfID string) (*model.ProfileSpec, error) {
 	// If a user doesn't specify profile id, then a default profile will be
 	// automatically assigned.
 	if prfID == "" {
-		prfs, err := s.storBox.ListProfiles()
+		prfs, err := s.storBox.ListProfiles(nil)
 		if err != nil {
 			log.Error("When list profiles:", err)
 			return nil, err
@@ -85,32 +87,7 @@ func (s *selector) SelectSupportedPool(tags map[string]interface{}) (*model.Stor
 
 	// Find if the desired storage tags are contained in any profile
 	for _, pol := range pols {
-		var isSupported = true
-
-		for k := range tags {
-			// Find if the desired feature is contained in pool parameters.
-			p, ok := pol.Parameters[k]
-			if !ok {
-				isSupported = false
-				break
-			}
-
-			// Find if all tag are supported by pool.
-			switch strings.ToLower(k) {
-			case "diskType":
-				if tags[k].(string) != p.(string) {
-					isSupported = false
-					break
-				}
-			case "iops", "latency":
-				if tags[k].(int) > p.(int) {
-					isSupported = false
-					break
-				}
-			}
-		}
-
-		if isSupported {
+		if isPoolSupported(pol, tags) {
 			return pol, nil
 		}
 	}
@@ -118,6 +95,42 @@ func (s *selector) SelectSupportedPool(tags map[string]interface{}) (*model.Stor
 	return nil, errors.New("No pool resource supported!")
 }
 
+func isPoolSupported(pol *model.StoragePoolSpec, tags map[string]interface{}) bool {
+	for k := range tags {
+		// Find if the desired feature is contained in pool parameters.
+		p, ok := pol.Parameters[k]
+		if !ok {
+			return false
+		}
+
+		// Find if all tag are supported by pool.
+		switch k {
+		case "diskType":
+			if !strings.EqualFold(fmt.Sprint(tags[k]), fmt.Sprint(p)) {
+				return false
+			}
+		case "iops", "bandwidth":
+			// QoS limits could only be applied to the pools whose backend
+			// is capable of enforcing them.
+			if qos, _ := pol.Parameters[model.QosSupportedKey].(bool); !qos {
+				return false
+			}
+			fallthrough
+		case "latency":
+			want, err := utils.ToInt64(tags[k])
+			if err != nil {
+				log.Errorf("When parse storage tag %s: %v\n", k, err)
+				return false
+			}
+			have, err := utils.ToInt64(p)
+			if err != nil || want > have {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (s *selector) SelectDock(input interface{}) (*model.DockSpec, error) {
 	dcks, err := s.storBox.ListDocks()
 	if err != nil {
@@ -88,9 +88,10 @@ func TestSelectSupportedPool(t *testing.T) {
 		FreeCapacity:  int64(90),
 		DockId:        "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
 		Parameters: map[string]interface{}{
-			"diskType":  "SSD",
-			"iops":      1000,
-			"bandwidth": 1000,
+			"diskType":     "SSD",
+			"iops":         1000,
+			"bandwidth":    1000,
+			"qosSupported": true,
 		},
 	}
 	var inputTag = map[string]interface{}{
@@ -107,6 +108,34 @@ func TestSelectSupportedPool(t *testing.T) {
 	if !reflect.DeepEqual(expectedPool, pol) {
 		t.Fatalf("Expected %v, get %v", expectedPool, pol)
 	}
+
+}
+
+func TestIsPoolSupportedWithQos(t *testing.T) {
+	var pol = &model.StoragePoolSpec{
+		BaseModel: &model.BaseModel{},
+		Parameters: map[string]interface{}{
+			"diskType":  "SAS",
+			"iops":      1000,
+			"bandwidth": 1000,
+		},
+	}
+	var inputTag = map[string]interface{}{
+		"diskType":  "SAS",
+		"iops":      float64(900),
+		"bandwidth": float64(900),
+	}
+
+	// Test if the pool matching all storage tags is rejected when it
+	// couldn't enforce the qos limits.
+	if isPoolSupported(pol, inputTag) {
+		t.Error("Expected pool without qos support rejected")
+	}
+
+	pol.Parameters[model.QosSupportedKey] = true
+	if !isPoolSupported(pol, inputTag) {
+		t.Error("Expected pool with qos support selected")
+	}
 }
 
 func TestSelectDock(t *testing.T) {
@@ -122,9 +151,10 @@ func TestSelectDock(t *testing.T) {
 		FreeCapacity:  int64(90),
 		DockId:        "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
 		Parameters: map[string]interface{}{
-			"diskType":  "SSD",
-			"iops":      1000,
-			"bandwidth": 1000,
+			"diskType":     "SSD",
+			"iops":         1000,
+			"bandwidth":    1000,
+			"qosSupported": true,
 		},
 	}
 	var expectedDock = &model.DockSpec{
@@ -0,0 +1,124 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"time"
+
+	osdsctx "github.com/opensds/opensds/pkg/context"
+	"github.com/opensds/opensds/pkg/controller/policy"
+	"github.com/opensds/opensds/pkg/controller/policy/executor"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
+)
+
+// TakeIntervalSnapshots takes the snapshots due by the intervalSnapshot policy
+// in the profiles of volumes, and returns the number of snapshots taken. When
+// a snapshot is due is worked out from the snapshots stored, so the policies
+// go on wherever it's called.
+func (c *Controller) TakeIntervalSnapshots(ctx context.Context, now time.Time) (int, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	vols, err := db.C.ListVolumes(nil)
+	if err != nil {
+		logger.Error("When list volumes in db:", err)
+		return 0, err
+	}
+	snps, err := db.C.ListVolumeSnapshots(nil)
+	if err != nil {
+		logger.Error("When list volume snapshots in db:", err)
+		return 0, err
+	}
+	// The time of the last snapshot taken by the policy and the number of
+	// them, indexed by volume id.
+	var lastTaken = map[string]string{}
+	var taken = map[string]int{}
+	for _, snp := range snps {
+		volID := snp.GetVolumeId()
+		if snp.GetName() != executor.IntervalSnapshotName(volID) {
+			continue
+		}
+		taken[volID]++
+		if snp.GetCreatedTime() > lastTaken[volID] {
+			lastTaken[volID] = snp.GetCreatedTime()
+		}
+	}
+
+	var n int
+	var intervals = map[string]time.Duration{}
+	for _, vol := range vols {
+		if vol.GetStatus() != model.VolumeAvailable && vol.GetStatus() != model.VolumeInUse {
+			continue
+		}
+		interval, ok := intervals[vol.GetProfileId()]
+		if !ok {
+			interval = c.snapshotInterval(ctx, vol.GetProfileId())
+			intervals[vol.GetProfileId()] = interval
+		}
+		if interval == 0 || taken[vol.GetId()] >= executor.IntervalSnapshotTotal {
+			continue
+		}
+
+		last := vol.GetCreatedTime()
+		if t, ok := lastTaken[vol.GetId()]; ok {
+			last = t
+		}
+		lastAt, err := time.ParseInLocation(utils.TimeFormat, last, time.Local)
+		if err != nil || now.Before(lastAt.Add(interval)) {
+			continue
+		}
+
+		if _, err = c.CreateVolumeSnapshot(ctx, &model.VolumeSnapshotSpec{
+			BaseModel: &model.BaseModel{},
+			Name:      executor.IntervalSnapshotName(vol.GetId()),
+			Size:      vol.GetSize(),
+			VolumeId:  vol.GetId(),
+		}); err != nil {
+			logger.Errorf("When take interval snapshot of volume %s: %v", vol.GetId(), err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// snapshotInterval returns the interval of the intervalSnapshot policy in the
+// profile, it's zero if the policy isn't configured.
+func (c *Controller) snapshotInterval(ctx context.Context, prfID string) time.Duration {
+	logger := osdsctx.GetLogger(ctx)
+
+	prf, err := c.SelectProfile(prfID)
+	if err != nil {
+		logger.Error("When search profiles in db:", err)
+		return 0
+	}
+	tag, err := policy.NewStorageTag(prf.Extra, policy.CREATE_LIFECIRCLE_FLAG)
+	if err != nil {
+		logger.Error("When parse storage tags of profile:", err)
+		return 0
+	}
+	value, ok := tag.GetAsyncTag()["intervalSnapshot"]
+	if !ok {
+		return 0
+	}
+	secs, err := executor.ParseInterval(value)
+	if err != nil {
+		logger.Error("When parse snapshot interval:", err)
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
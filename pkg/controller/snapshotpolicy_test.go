@@ -0,0 +1,113 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opensds/opensds/pkg/controller/policy/executor"
+	"github.com/opensds/opensds/pkg/controller/selector"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
+)
+
+// policySelector selects the profile with the interval snapshot policy for
+// all volumes.
+type policySelector struct {
+	selector.Selector
+}
+
+func (*policySelector) SelectProfile(string) (*model.ProfileSpec, error) {
+	return &model.ProfileSpec{
+		BaseModel: &model.BaseModel{},
+		Extra:     model.ExtraSpec{"intervalSnapshot": "1h"},
+	}, nil
+}
+
+func (*policySelector) SelectDock(interface{}) (*model.DockSpec, error) {
+	return &model.DockSpec{BaseModel: &model.BaseModel{}}, nil
+}
+
+// snapshotRecorder records the volumes whose snapshots are taken.
+type snapshotRecorder struct {
+	fakeVolumeController
+	volIDs []string
+}
+
+func (r *snapshotRecorder) CreateVolumeSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	r.volIDs = append(r.volIDs, opt.GetVolumeId())
+	return &sampleSnapshot, nil
+}
+
+func TestTakeIntervalSnapshots(t *testing.T) {
+	var now = time.Now()
+	var ago = func(d time.Duration) string { return now.Add(-d).Format(utils.TimeFormat) }
+	var newVolume = func(id, status, createdAt string) *model.VolumeSpec {
+		return &model.VolumeSpec{BaseModel: &model.BaseModel{Id: id, CreatedAt: createdAt}, Status: status}
+	}
+	var newSnapshot = func(volID, createdAt string) *model.VolumeSnapshotSpec {
+		return &model.VolumeSnapshotSpec{
+			BaseModel: &model.BaseModel{CreatedAt: createdAt},
+			Name:      executor.IntervalSnapshotName(volID),
+			VolumeId:  volID,
+		}
+	}
+	var vols = []*model.VolumeSpec{
+		// The first snapshot is due an interval after the volume is created.
+		newVolume("volume-due", model.VolumeAvailable, ago(2*time.Hour)),
+		newVolume("volume-new", model.VolumeAvailable, ago(time.Minute)),
+		// The next snapshot is due an interval after the last one.
+		newVolume("volume-waiting", model.VolumeInUse, ago(3*time.Hour)),
+		newVolume("volume-next", model.VolumeInUse, ago(3*time.Hour)),
+		// All snapshots of the policy have been taken.
+		newVolume("volume-done", model.VolumeAvailable, ago(5*time.Hour)),
+		newVolume("volume-error", model.VolumeError, ago(2*time.Hour)),
+	}
+	var snps = []*model.VolumeSnapshotSpec{
+		newSnapshot("volume-waiting", ago(10*time.Minute)),
+		newSnapshot("volume-next", ago(2*time.Hour)),
+		newSnapshot("volume-done", ago(4*time.Hour)),
+		newSnapshot("volume-done", ago(3*time.Hour)),
+		newSnapshot("volume-done", ago(2*time.Hour)),
+		// The snapshots taken by users don't count.
+		{BaseModel: &model.BaseModel{CreatedAt: ago(time.Minute)}, Name: "manual", VolumeId: "volume-next"},
+	}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumes", mock.Anything).Return(vols, nil)
+	mockClient.On("ListVolumeSnapshots", mock.Anything).Return(snps, nil)
+	db.C = mockClient
+
+	var recorder = &snapshotRecorder{}
+	var c = &Controller{
+		Selector:         &policySelector{},
+		volumeController: recorder,
+	}
+	n, err := c.TakeIntervalSnapshots(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"volume-due", "volume-next"}
+	if n != 2 || !reflect.DeepEqual(recorder.volIDs, expected) {
+		t.Errorf("Expected snapshots of %v taken, got %d of %v", expected, n, recorder.volIDs)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	osdsctx "github.com/opensds/opensds/pkg/context"
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
+)
+
+// CreateVolumeTransfer creates a transfer of the volume owned by the project,
+// the volume is awaiting transfer until the transfer is accepted or deleted.
+// The auth key is only returned here, it should be sent to the project which
+// the volume is transferred to.
+func (c *Controller) CreateVolumeTransfer(ctx context.Context, in *model.VolumeTransferSpec) (*model.VolumeTransferSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	vol, err := db.C.GetVolume(in.GetVolumeId())
+	if err != nil {
+		logger.Error("When get volume in db:", err)
+		return nil, err
+	}
+	if vol.GetStatus() != model.VolumeAvailable {
+		return nil, fmt.Errorf("Volume %s is %s, only available volume could be transferred",
+			vol.GetId(), vol.GetStatus())
+	}
+	if in.GetProjectId() != "" && in.GetProjectId() != vol.GetProjectId() {
+		return nil, fmt.Errorf("Volume %s isn't owned by project %s", vol.GetId(), in.GetProjectId())
+	}
+	in.ProjectId = vol.GetProjectId()
+
+	authKey, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	if in.Salt, err = randomHex(8); err != nil {
+		return nil, err
+	}
+	in.AuthKey, in.AuthKeyHash = "", hashAuthKey(in.Salt, authKey)
+	if err = utils.ValidateData(in, utils.S); err != nil {
+		logger.Error("When validate volume transfer data:", err)
+		return nil, err
+	}
+
+	// The volume is updated in the version checked above, so only one of
+	// the transfers of the volume created at the same time is kept.
+	if err = db.C.CreateVolumeTransfer(in); err != nil {
+		logger.Error("When create volume transfer in db:", err)
+		return nil, err
+	}
+	if _, err = db.C.UpdateVolume(vol.GetId(), &model.VolumeSpec{
+		BaseModel: &model.BaseModel{ResourceVersion: vol.GetResourceVersion()},
+		Status:    model.VolumeAwaitingTransfer,
+	}); err != nil {
+		logger.Error("When update volume status in db:", err)
+		if err := db.C.DeleteVolumeTransfer(in.GetId()); err != nil {
+			logger.Error("When delete volume transfer in db:", err)
+		}
+		return nil, err
+	}
+
+	var result = *in
+	result.AuthKey, result.Salt, result.AuthKeyHash = authKey, "", ""
+	return &result, nil
+}
+
+// AcceptVolumeTransfer hands the volume to the project which accepts the
+// transfer with the right auth key, if its quota allows.
+func (c *Controller) AcceptVolumeTransfer(ctx context.Context, trID string, in *model.VolumeTransferAcceptSpec) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if in.GetProjectId() == "" {
+		return nil, fmt.Errorf("The project which accepts the transfer is required")
+	}
+	tr, err := db.C.GetVolumeTransfer(trID)
+	if err != nil {
+		logger.Error("When get volume transfer in db:", err)
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAuthKey(tr.Salt, in.GetAuthKey())), []byte(tr.AuthKeyHash)) != 1 {
+		return nil, fmt.Errorf("Invalid auth key of volume transfer %s", trID)
+	}
+	if in.GetProjectId() == tr.GetProjectId() {
+		return nil, fmt.Errorf("Volume %s is already owned by project %s", tr.GetVolumeId(), in.GetProjectId())
+	}
+
+	vol, err := db.C.GetVolume(tr.GetVolumeId())
+	if err != nil {
+		logger.Error("When get volume in db:", err)
+		return nil, err
+	}
+	if err = checkQuota(in.GetProjectId(), 1, vol.GetSize()); err != nil {
+		logger.Error("When check quota of project:", err)
+		return nil, err
+	}
+
+	return db.C.AcceptVolumeTransfer(trID, in.GetProjectId())
+}
+
+// DeleteVolumeTransfer cancels the transfer, which is only allowed for the
+// project owning the volume.
+func (c *Controller) DeleteVolumeTransfer(ctx context.Context, in *model.VolumeTransferSpec) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
+	tr, err := db.C.GetVolumeTransfer(in.GetId())
+	if err != nil {
+		logger.Error("When get volume transfer in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	if in.GetProjectId() != "" && in.GetProjectId() != tr.GetProjectId() {
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprintf("Volume transfer %s isn't owned by project %s", tr.GetId(), in.GetProjectId()),
+		}
+	}
+
+	if err = db.C.DeleteVolumeTransfer(tr.GetId()); err != nil {
+		logger.Error("When delete volume transfer in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+	if err = releaseVolume(tr.GetVolumeId()); err != nil {
+		logger.Error("When update volume status in db:", err)
+		return &model.Response{
+			Status: "Failure",
+			Error:  fmt.Sprint(err),
+		}
+	}
+
+	return &model.Response{Status: "Success"}
+}
+
+// releaseVolume makes the volume awaiting transfer available again after its
+// transfer is deleted. The volume is updated in the version read, so that the
+// volume accepted by another project meanwhile is left as it is.
+func releaseVolume(volID string) error {
+	for {
+		vol, err := db.C.GetVolume(volID)
+		if err != nil {
+			return err
+		}
+		if vol.GetStatus() != model.VolumeAwaitingTransfer {
+			return nil
+		}
+		_, err = db.C.UpdateVolume(volID, &model.VolumeSpec{
+			BaseModel: &model.BaseModel{ResourceVersion: vol.GetResourceVersion()},
+			Status:    model.VolumeAvailable,
+		})
+		if err != utils.ErrConflict {
+			return err
+		}
+	}
+}
+
+// ExpireVolumeTransfers deletes the transfers which are created longer than
+// expiration ago, so that the volumes awaiting transfer become available
+// again. The number of deleted transfers is returned.
+func (c *Controller) ExpireVolumeTransfers(ctx context.Context, expiration time.Duration) (int, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	trs, err := db.C.ListVolumeTransfers(nil)
+	if err != nil {
+		logger.Error("When list volume transfers in db:", err)
+		return 0, err
+	}
+
+	var expired int
+	deadline := time.Now().Add(-expiration)
+	for _, tr := range trs {
+		createdAt, err := time.ParseInLocation(utils.TimeFormat, tr.GetCreatedTime(), time.Local)
+		if err != nil || createdAt.After(deadline) {
+			continue
+		}
+		result := c.DeleteVolumeTransfer(ctx, &model.VolumeTransferSpec{BaseModel: &model.BaseModel{Id: tr.GetId()}})
+		if result.Status != "Success" {
+			logger.Errorf("When expire volume transfer %s: %s", tr.GetId(), result.GetError())
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func randomHex(n int) (string, error) {
+	var buf = make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashAuthKey(salt, authKey string) string {
+	sum := sha256.Sum256([]byte(salt + authKey))
+	return hex.EncodeToString(sum[:])
+}
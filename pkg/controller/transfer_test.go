@@ -0,0 +1,249 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
+)
+
+func TestVolumeTransfer(t *testing.T) {
+	var vol = sampleVolume
+	vol.Status, vol.ProjectId = model.VolumeAvailable, "project-a"
+	var c = &Controller{}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	mockClient.On("CreateVolumeTransfer", mock.Anything).Return(nil)
+	mockClient.On("UpdateVolume", vol.Id, mock.Anything).Return(&vol, nil)
+	db.C = mockClient
+
+	// Only the owner of the volume could transfer it.
+	if _, err := c.CreateVolumeTransfer(context.Background(), &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{},
+		VolumeId:  vol.Id,
+		ProjectId: "project-b",
+	}); err == nil {
+		t.Error("Expected creating transfer by other project failed")
+	}
+
+	var in = &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{},
+		VolumeId:  vol.Id,
+		ProjectId: "project-a",
+	}
+	tr, err := c.CreateVolumeTransfer(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Failed to create volume transfer, err is %v\n", err)
+	}
+	if tr.GetAuthKey() == "" || tr.AuthKeyHash != "" || tr.Salt != "" {
+		t.Errorf("Expected only auth key returned, got %v\n", tr)
+	}
+	// The auth key itself isn't recorded in db.
+	if in.GetAuthKey() != "" || in.AuthKeyHash == "" {
+		t.Errorf("Expected only hash of auth key recorded, got %v\n", in)
+	}
+
+	mockClient.On("GetVolumeTransfer", tr.Id).Return(in, nil)
+	mockClient.On("GetQuota", "project-b").Return(&model.QuotaSpec{
+		BaseModel:    &model.BaseModel{Id: "project-b"},
+		ResourceList: map[string]int64{model.QuotaGigabytes: 10},
+	}, nil)
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{}, nil)
+	mockClient.On("AcceptVolumeTransfer", tr.Id, "project-b").Return(&vol, nil)
+
+	if _, err = c.AcceptVolumeTransfer(context.Background(), tr.Id, &model.VolumeTransferAcceptSpec{
+		AuthKey:   "wrong-key",
+		ProjectId: "project-b",
+	}); err == nil {
+		t.Error("Expected accepting transfer with wrong auth key failed")
+	}
+	if _, err = c.AcceptVolumeTransfer(context.Background(), tr.Id, &model.VolumeTransferAcceptSpec{
+		AuthKey:   tr.GetAuthKey(),
+		ProjectId: "project-b",
+	}); err != nil {
+		t.Errorf("Failed to accept volume transfer, err is %v\n", err)
+	}
+	mockClient.AssertCalled(t, "AcceptVolumeTransfer", tr.Id, "project-b")
+}
+
+func TestAcceptVolumeTransferOverQuota(t *testing.T) {
+	var vol = sampleVolume
+	vol.Size = 8
+	var tr = &model.VolumeTransferSpec{
+		BaseModel:   &model.BaseModel{Id: "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e"},
+		VolumeId:    vol.Id,
+		ProjectId:   "project-a",
+		Salt:        "salt",
+		AuthKeyHash: hashAuthKey("salt", "key"),
+	}
+	var c = &Controller{}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeTransfer", tr.Id).Return(tr, nil)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	mockClient.On("GetQuota", "project-b").Return(&model.QuotaSpec{
+		BaseModel:    &model.BaseModel{Id: "project-b"},
+		ResourceList: map[string]int64{model.QuotaGigabytes: 10},
+	}, nil)
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{
+		{BaseModel: &model.BaseModel{}, Size: 4, ProjectId: "project-b"},
+	}, nil)
+	db.C = mockClient
+
+	if _, err := c.AcceptVolumeTransfer(context.Background(), tr.Id, &model.VolumeTransferAcceptSpec{
+		AuthKey:   "key",
+		ProjectId: "project-b",
+	}); err == nil {
+		t.Error("Expected accepting transfer over quota failed")
+	}
+	mockClient.AssertNotCalled(t, "AcceptVolumeTransfer", tr.Id, "project-b")
+}
+
+func TestDeleteVolumeTransfer(t *testing.T) {
+	var tr = &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{Id: "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e"},
+		VolumeId:  sampleVolume.Id,
+		ProjectId: "project-a",
+	}
+	var vol = sampleVolume
+	vol.Status = model.VolumeAwaitingTransfer
+	vol.BaseModel = &model.BaseModel{Id: sampleVolume.Id, ResourceVersion: 7}
+	var c = &Controller{}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeTransfer", tr.Id).Return(tr, nil)
+	mockClient.On("DeleteVolumeTransfer", tr.Id).Return(nil)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	mockClient.On("UpdateVolume", tr.VolumeId, &model.VolumeSpec{
+		BaseModel: &model.BaseModel{ResourceVersion: 7},
+		Status:    model.VolumeAvailable,
+	}).Return(&sampleVolume, nil)
+	db.C = mockClient
+
+	result := c.DeleteVolumeTransfer(context.Background(), &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{Id: tr.Id},
+		ProjectId: "project-b",
+	})
+	if result.Status != "Failure" {
+		t.Errorf("Expected deleting transfer by other project failed, got %v\n", result)
+	}
+
+	result = c.DeleteVolumeTransfer(context.Background(), &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{Id: tr.Id},
+		ProjectId: "project-a",
+	})
+	if result.Status != "Success" {
+		t.Errorf("Failed to delete volume transfer, got %v\n", result)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestExpireVolumeTransfers(t *testing.T) {
+	var c = &Controller{}
+	var now = time.Now()
+	var stale = &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{
+			Id:        "3c5e8a86-c0c5-11e7-8a0e-5b8f1e0c8b7d",
+			CreatedAt: now.Add(-2 * time.Hour).Format(utils.TimeFormat),
+		},
+		VolumeId: "bd5b12a8-a101-11e7-941e-d77981b584d8",
+	}
+	var fresh = &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{
+			Id:        "4a7b2f1e-c0c5-11e7-9d3b-1f6a0c3e5d2b",
+			CreatedAt: now.Format(utils.TimeFormat),
+		},
+		VolumeId: "9193c3ec-771f-11e7-8ca3-d32c0a8b2725",
+	}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumeTransfers", mock.Anything).Return([]*model.VolumeTransferSpec{stale, fresh}, nil)
+	mockClient.On("GetVolumeTransfer", stale.Id).Return(stale, nil)
+	mockClient.On("DeleteVolumeTransfer", stale.Id).Return(nil)
+	mockClient.On("GetVolume", stale.VolumeId).Return(&model.VolumeSpec{
+		BaseModel: &model.BaseModel{Id: stale.VolumeId},
+		Status:    model.VolumeAwaitingTransfer,
+	}, nil)
+	mockClient.On("UpdateVolume", stale.VolumeId, mock.Anything).Return(&model.VolumeSpec{}, nil)
+	db.C = mockClient
+
+	n, err := c.ExpireVolumeTransfers(context.Background(), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to expire volume transfers, err is %v\n", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 transfer expired, got %d\n", n)
+	}
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "DeleteVolumeTransfer", fresh.Id)
+}
+
+func TestCreateVolumeTransferConcurrently(t *testing.T) {
+	var vol = sampleVolume
+	vol.Status, vol.ProjectId = model.VolumeAvailable, "project-a"
+	vol.BaseModel = &model.BaseModel{Id: sampleVolume.Id, ResourceVersion: 7}
+	var c = &Controller{}
+
+	// Another transfer of the volume has been created since the volume is
+	// read, so the transfer is deleted.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	mockClient.On("CreateVolumeTransfer", mock.Anything).Return(nil)
+	mockClient.On("UpdateVolume", vol.Id, &model.VolumeSpec{
+		BaseModel: &model.BaseModel{ResourceVersion: 7},
+		Status:    model.VolumeAwaitingTransfer,
+	}).Return(nil, utils.ErrConflict)
+	mockClient.On("DeleteVolumeTransfer", mock.Anything).Return(nil)
+	db.C = mockClient
+
+	if _, err := c.CreateVolumeTransfer(context.Background(), &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{},
+		VolumeId:  vol.Id,
+	}); err != utils.ErrConflict {
+		t.Errorf("Expected conflict, got %v\n", err)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteVolumeTransferAccepted(t *testing.T) {
+	var tr = &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{Id: "e8f3d2c4-bc3e-11e7-8f4b-0f0a3b3c2d1e"},
+		VolumeId:  sampleVolume.Id,
+	}
+	var vol = sampleVolume
+	vol.Status, vol.ProjectId = model.VolumeAvailable, "project-b"
+	var c = &Controller{}
+
+	// The volume accepted meanwhile is left as it is.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolumeTransfer", tr.Id).Return(tr, nil)
+	mockClient.On("DeleteVolumeTransfer", tr.Id).Return(nil)
+	mockClient.On("GetVolume", vol.Id).Return(&vol, nil)
+	db.C = mockClient
+
+	result := c.DeleteVolumeTransfer(context.Background(), &model.VolumeTransferSpec{BaseModel: &model.BaseModel{Id: tr.Id}})
+	if result.Status != "Success" {
+		t.Errorf("Failed to delete volume transfer, got %v\n", result)
+	}
+	mockClient.AssertNotCalled(t, "UpdateVolume", mock.Anything, mock.Anything)
+}
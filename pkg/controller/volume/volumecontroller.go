@@ -23,8 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 
-	log "github.com/golang/glog"
-
+	osdsctx "github.com/opensds/opensds/pkg/context"
 	"github.com/opensds/opensds/pkg/dock/client"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
@@ -32,17 +31,23 @@ import (
 )
 
 type Controller interface {
-	CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error)
+	CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error)
+
+	DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) *model.Response
+
+	ManageVolume(ctx context.Context, opt *pb.ManageVolumeOpts) (*model.VolumeSpec, error)
 
-	DeleteVolume(opt *pb.DeleteVolumeOpts) *model.Response
+	CreateVolumeAttachment(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.VolumeAttachmentSpec, error)
 
-	CreateVolumeAttachment(opt *pb.CreateAttachmentOpts) (*model.VolumeAttachmentSpec, error)
+	DeleteVolumeAttachment(ctx context.Context, opt *pb.DeleteAttachmentOpts) *model.Response
 
-	DeleteVolumeAttachment(opt *pb.DeleteAttachmentOpts) *model.Response
+	UpdateVolumeAttachment(ctx context.Context, opt *pb.UpdateAttachmentOpts) (*model.VolumeAttachmentSpec, error)
 
-	CreateVolumeSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error)
+	CreateVolumeSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error)
 
-	DeleteVolumeSnapshot(opt *pb.DeleteVolumeSnapshotOpts) *model.Response
+	ManageVolumeSnapshot(ctx context.Context, opt *pb.ManageVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error)
+
+	DeleteVolumeSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) *model.Response
 
 	SetDock(dockInfo *model.DockSpec)
 }
@@ -58,15 +63,17 @@ type controller struct {
 	DockInfo *model.DockSpec
 }
 
-func (c *controller) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+func (c *controller) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := c.Client.Update(c.DockInfo); err != nil {
-		log.Error("When parsing dock info:", err)
+		logger.Error("When parsing dock info:", err)
 		return nil, err
 	}
 
-	response, err := c.Client.CreateVolume(context.Background(), opt)
+	response, err := c.Client.CreateVolume(ctx, opt)
 	if err != nil {
-		log.Error("create volume failed in volume controller:", err)
+		logger.Error("create volume failed in volume controller:", err)
 		return nil, err
 	}
 	defer c.Client.Close()
@@ -79,7 +86,7 @@ func (c *controller) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec,
 
 	var vol = &model.VolumeSpec{}
 	if err = json.Unmarshal([]byte(response.GetResult().GetMessage()), vol); err != nil {
-		log.Error("create volume failed in volume controller:", err)
+		logger.Error("create volume failed in volume controller:", err)
 		return nil, err
 	}
 
@@ -87,15 +94,17 @@ func (c *controller) CreateVolume(opt *pb.CreateVolumeOpts) (*model.VolumeSpec,
 
 }
 
-func (c *controller) DeleteVolume(opt *pb.DeleteVolumeOpts) *model.Response {
+func (c *controller) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := c.Client.Update(c.DockInfo); err != nil {
-		log.Error("When parsing dock info:", err)
+		logger.Error("When parsing dock info:", err)
 		return nil
 	}
 
-	response, err := c.Client.DeleteVolume(context.Background(), opt)
+	response, err := c.Client.DeleteVolume(ctx, opt)
 	if err != nil {
-		log.Error("Delete volume failed in volume controller:", err)
+		logger.Error("Delete volume failed in volume controller:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
@@ -116,15 +125,47 @@ func (c *controller) DeleteVolume(opt *pb.DeleteVolumeOpts) *model.Response {
 	}
 }
 
-func (c *controller) CreateVolumeAttachment(opt *pb.CreateAttachmentOpts) (*model.VolumeAttachmentSpec, error) {
+func (c *controller) ManageVolume(ctx context.Context, opt *pb.ManageVolumeOpts) (*model.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if err := c.Client.Update(c.DockInfo); err != nil {
+		logger.Error("When parsing dock info:", err)
+		return nil, err
+	}
+
+	response, err := c.Client.ManageVolume(ctx, opt)
+	if err != nil {
+		logger.Error("manage volume failed in volume controller:", err)
+		return nil, err
+	}
+	defer c.Client.Close()
+
+	if errorMsg := response.GetError(); errorMsg != nil {
+		return nil,
+			fmt.Errorf("failed to manage volume in volume controller, code: %v, message: %v",
+				errorMsg.GetCode(), errorMsg.GetDescription())
+	}
+
+	var vol = &model.VolumeSpec{}
+	if err = json.Unmarshal([]byte(response.GetResult().GetMessage()), vol); err != nil {
+		logger.Error("manage volume failed in volume controller:", err)
+		return nil, err
+	}
+
+	return vol, nil
+}
+
+func (c *controller) CreateVolumeAttachment(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.VolumeAttachmentSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := c.Client.Update(c.DockInfo); err != nil {
-		log.Error("When parsing dock info:", err)
+		logger.Error("When parsing dock info:", err)
 		return nil, err
 	}
 
-	response, err := c.Client.CreateAttachment(context.Background(), opt)
+	response, err := c.Client.CreateAttachment(ctx, opt)
 	if err != nil {
-		log.Error("Create volume failed in volume controller:", err)
+		logger.Error("Create volume failed in volume controller:", err)
 		return nil, err
 	}
 	defer c.Client.Close()
@@ -137,22 +178,24 @@ func (c *controller) CreateVolumeAttachment(opt *pb.CreateAttachmentOpts) (*mode
 
 	var atc = &model.VolumeAttachmentSpec{}
 	if err = json.Unmarshal([]byte(response.GetResult().GetMessage()), atc); err != nil {
-		log.Error("create volume attachment failed in volume controller:", err)
+		logger.Error("create volume attachment failed in volume controller:", err)
 		return nil, err
 	}
 
 	return atc, nil
 }
 
-func (c *controller) DeleteVolumeAttachment(opt *pb.DeleteAttachmentOpts) *model.Response {
+func (c *controller) DeleteVolumeAttachment(ctx context.Context, opt *pb.DeleteAttachmentOpts) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := c.Client.Update(c.DockInfo); err != nil {
-		log.Error("When parsing dock info:", err)
+		logger.Error("When parsing dock info:", err)
 		return nil
 	}
 
-	response, err := c.Client.DeleteAttachment(context.Background(), opt)
+	response, err := c.Client.DeleteAttachment(ctx, opt)
 	if err != nil {
-		log.Error("Delete volume attachment failed in volume controller:", err)
+		logger.Error("Delete volume attachment failed in volume controller:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
@@ -173,15 +216,47 @@ func (c *controller) DeleteVolumeAttachment(opt *pb.DeleteAttachmentOpts) *model
 	}
 }
 
-func (c *controller) CreateVolumeSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+func (c *controller) UpdateVolumeAttachment(ctx context.Context, opt *pb.UpdateAttachmentOpts) (*model.VolumeAttachmentSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := c.Client.Update(c.DockInfo); err != nil {
-		log.Error("When parsing dock info:", err)
+		logger.Error("When parsing dock info:", err)
 		return nil, err
 	}
 
-	response, err := c.Client.CreateVolumeSnapshot(context.Background(), opt)
+	response, err := c.Client.UpdateAttachment(ctx, opt)
 	if err != nil {
-		log.Error("Create volume snapshot failed in volume controller:", err)
+		logger.Error("Update volume attachment failed in volume controller:", err)
+		return nil, err
+	}
+	defer c.Client.Close()
+
+	if errorMsg := response.GetError(); errorMsg != nil {
+		return nil,
+			fmt.Errorf("failed to update volume attachment in volume controller, code: %v, message: %v",
+				errorMsg.GetCode(), errorMsg.GetDescription())
+	}
+
+	var atc = &model.VolumeAttachmentSpec{}
+	if err = json.Unmarshal([]byte(response.GetResult().GetMessage()), atc); err != nil {
+		logger.Error("update volume attachment failed in volume controller:", err)
+		return nil, err
+	}
+
+	return atc, nil
+}
+
+func (c *controller) CreateVolumeSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if err := c.Client.Update(c.DockInfo); err != nil {
+		logger.Error("When parsing dock info:", err)
+		return nil, err
+	}
+
+	response, err := c.Client.CreateVolumeSnapshot(ctx, opt)
+	if err != nil {
+		logger.Error("Create volume snapshot failed in volume controller:", err)
 		return nil, err
 	}
 	defer c.Client.Close()
@@ -194,22 +269,54 @@ func (c *controller) CreateVolumeSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*mo
 
 	var snp = &model.VolumeSnapshotSpec{}
 	if err = json.Unmarshal([]byte(response.GetResult().GetMessage()), snp); err != nil {
-		log.Error("create volume snapshot failed in volume controller:", err)
+		logger.Error("create volume snapshot failed in volume controller:", err)
 		return nil, err
 	}
 
 	return snp, nil
 }
 
-func (c *controller) DeleteVolumeSnapshot(opt *pb.DeleteVolumeSnapshotOpts) *model.Response {
+func (c *controller) ManageVolumeSnapshot(ctx context.Context, opt *pb.ManageVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	if err := c.Client.Update(c.DockInfo); err != nil {
+		logger.Error("When parsing dock info:", err)
+		return nil, err
+	}
+
+	response, err := c.Client.ManageVolumeSnapshot(ctx, opt)
+	if err != nil {
+		logger.Error("manage volume snapshot failed in volume controller:", err)
+		return nil, err
+	}
+	defer c.Client.Close()
+
+	if errorMsg := response.GetError(); errorMsg != nil {
+		return nil,
+			fmt.Errorf("failed to manage volume snapshot in volume controller, code: %v, message: %v",
+				errorMsg.GetCode(), errorMsg.GetDescription())
+	}
+
+	var snp = &model.VolumeSnapshotSpec{}
+	if err = json.Unmarshal([]byte(response.GetResult().GetMessage()), snp); err != nil {
+		logger.Error("manage volume snapshot failed in volume controller:", err)
+		return nil, err
+	}
+
+	return snp, nil
+}
+
+func (c *controller) DeleteVolumeSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) *model.Response {
+	logger := osdsctx.GetLogger(ctx)
+
 	if err := c.Client.Update(c.DockInfo); err != nil {
-		log.Error("When parsing dock info:", err)
+		logger.Error("When parsing dock info:", err)
 		return nil
 	}
 
-	response, err := c.Client.DeleteVolumeSnapshot(context.Background(), opt)
+	response, err := c.Client.DeleteVolumeSnapshot(ctx, opt)
 	if err != nil {
-		log.Error("Delete volume snapshot failed in volume controller:", err)
+		logger.Error("Delete volume snapshot failed in volume controller:", err)
 		return &model.Response{
 			Status: "Failure",
 			Error:  fmt.Sprint(err),
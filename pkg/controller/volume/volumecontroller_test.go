@@ -66,6 +66,32 @@ func (fc *fakeClient) DeleteVolume(ctx context.Context, in *pb.DeleteVolumeOpts,
 	}, nil
 }
 
+// Manage a volume
+func (fc *fakeClient) ManageVolume(ctx context.Context, in *pb.ManageVolumeOpts, opts ...grpc.CallOption) (*pb.GenericResponse, error) {
+	volBody, _ := json.Marshal(&sampleVolume)
+
+	return &pb.GenericResponse{
+		Reply: &pb.GenericResponse_Result_{
+			Result: &pb.GenericResponse_Result{
+				Message: string(volBody),
+			},
+		},
+	}, nil
+}
+
+// Manage a volume snapshot
+func (fc *fakeClient) ManageVolumeSnapshot(ctx context.Context, in *pb.ManageVolumeSnapshotOpts, opts ...grpc.CallOption) (*pb.GenericResponse, error) {
+	snpBody, _ := json.Marshal(&sampleSnapshot)
+
+	return &pb.GenericResponse{
+		Reply: &pb.GenericResponse_Result_{
+			Result: &pb.GenericResponse_Result{
+				Message: string(snpBody),
+			},
+		},
+	}, nil
+}
+
 // Create a volume attachment
 func (fc *fakeClient) CreateAttachment(ctx context.Context, in *pb.CreateAttachmentOpts, opts ...grpc.CallOption) (*pb.GenericResponse, error) {
 	volBody, _ := json.Marshal(&sampleAttachment)
@@ -87,6 +113,19 @@ func (fc *fakeClient) DeleteAttachment(ctx context.Context, in *pb.DeleteAttachm
 	}, nil
 }
 
+// Update a volume attachment
+func (fc *fakeClient) UpdateAttachment(ctx context.Context, in *pb.UpdateAttachmentOpts, opts ...grpc.CallOption) (*pb.GenericResponse, error) {
+	atcBody, _ := json.Marshal(&sampleModifiedAttachment)
+
+	return &pb.GenericResponse{
+		Reply: &pb.GenericResponse_Result_{
+			Result: &pb.GenericResponse_Result{
+				Message: string(atcBody),
+			},
+		},
+	}, nil
+}
+
 // Create a volume snapshot
 func (fc *fakeClient) CreateVolumeSnapshot(ctx context.Context, in *pb.CreateVolumeSnapshotOpts, opts ...grpc.CallOption) (*pb.GenericResponse, error) {
 	volBody, _ := json.Marshal(&sampleSnapshot)
@@ -120,7 +159,7 @@ func TestCreateVolume(t *testing.T) {
 	fc := NewFakeController( /*&pb.DockRequest{}*/ )
 	var expected = &sampleVolume
 
-	result, err := fc.CreateVolume(&pb.CreateVolumeOpts{})
+	result, err := fc.CreateVolume(context.Background(), &pb.CreateVolumeOpts{})
 	if err != nil {
 		t.Errorf("Failed to create volume, err is %v\n", err)
 	}
@@ -134,7 +173,7 @@ func TestDeleteVolume(t *testing.T) {
 	fc := NewFakeController( /*&pb.DockRequest{}*/ )
 	var expected = &model.Response{Status: "Success"}
 
-	result := fc.DeleteVolume(&pb.DeleteVolumeOpts{})
+	result := fc.DeleteVolume(context.Background(), &pb.DeleteVolumeOpts{})
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Expected %v, got %v\n", expected, result)
 	}
@@ -144,7 +183,7 @@ func TestCreateVolumeAttachment(t *testing.T) {
 	fc := NewFakeController( /*&pb.DockRequest{}*/ )
 	var expected = &sampleAttachment
 
-	result, err := fc.CreateVolumeAttachment(&pb.CreateAttachmentOpts{})
+	result, err := fc.CreateVolumeAttachment(context.Background(), &pb.CreateAttachmentOpts{})
 	if err != nil {
 		t.Errorf("Failed to create volume attachment, err is %v\n", err)
 	}
@@ -158,7 +197,20 @@ func TestDeleteVolumeAttachment(t *testing.T) {
 	fc := NewFakeController( /*&pb.DockRequest{}*/ )
 	var expected = &model.Response{Status: "Success"}
 
-	result := fc.DeleteVolumeAttachment(&pb.DeleteAttachmentOpts{})
+	result := fc.DeleteVolumeAttachment(context.Background(), &pb.DeleteAttachmentOpts{})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v\n", expected, result)
+	}
+}
+
+func TestUpdateVolumeAttachment(t *testing.T) {
+	fc := NewFakeController()
+	var expected = &sampleModifiedAttachment
+
+	result, err := fc.UpdateVolumeAttachment(context.Background(), &pb.UpdateAttachmentOpts{})
+	if err != nil {
+		t.Errorf("Failed to update volume attachment, err is %v\n", err)
+	}
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Expected %v, got %v\n", expected, result)
 	}
@@ -168,7 +220,7 @@ func TestCreateVolumeSnapshot(t *testing.T) {
 	fc := NewFakeController( /*&pb.DockRequest{}*/ )
 	var expected = &sampleSnapshot
 
-	result, err := fc.CreateVolumeSnapshot(&pb.CreateVolumeSnapshotOpts{})
+	result, err := fc.CreateVolumeSnapshot(context.Background(), &pb.CreateVolumeSnapshotOpts{})
 	if err != nil {
 		t.Errorf("Failed to create volume snapshot, err is %v\n", err)
 	}
@@ -178,11 +230,37 @@ func TestCreateVolumeSnapshot(t *testing.T) {
 	}
 }
 
+func TestManageVolume(t *testing.T) {
+	fc := NewFakeController()
+	var expected = &sampleVolume
+
+	result, err := fc.ManageVolume(context.Background(), &pb.ManageVolumeOpts{})
+	if err != nil {
+		t.Errorf("Failed to manage volume, err is %v\n", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v\n", expected, result)
+	}
+}
+
+func TestManageVolumeSnapshot(t *testing.T) {
+	fc := NewFakeController()
+	var expected = &sampleSnapshot
+
+	result, err := fc.ManageVolumeSnapshot(context.Background(), &pb.ManageVolumeSnapshotOpts{})
+	if err != nil {
+		t.Errorf("Failed to manage volume snapshot, err is %v\n", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v\n", expected, result)
+	}
+}
+
 func TestDeleteVolumeSnapshot(t *testing.T) {
 	fc := NewFakeController( /*&pb.DockRequest{}*/ )
 	var expected = &model.Response{Status: "Success"}
 
-	result := fc.DeleteVolumeSnapshot(&pb.DeleteVolumeSnapshotOpts{})
+	result := fc.DeleteVolumeSnapshot(context.Background(), &pb.DeleteVolumeSnapshotOpts{})
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Expected %v, got %v\n", expected, result)
 	}
@@ -0,0 +1,191 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the backup of OpenSDS metadata, which exports every
+resource through the db client into a bundle and imports it into any db
+driver, so that it's independent of the storage of the database.
+
+*/
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+// BundleVersion is the version of the bundle format written by Export.
+const BundleVersion = 1
+
+// Bundle contains all resources of OpenSDS. The idempotency keys are left
+// out since they expire soon.
+type Bundle struct {
+	Version     int                           `json:"version"`
+	ExportedAt  string                        `json:"exportedAt,omitempty"`
+	Docks       []*model.DockSpec             `json:"docks,omitempty"`
+	Pools       []*model.StoragePoolSpec      `json:"pools,omitempty"`
+	Profiles    []*model.ProfileSpec          `json:"profiles,omitempty"`
+	Volumes     []*model.VolumeSpec           `json:"volumes,omitempty"`
+	Attachments []*model.VolumeAttachmentSpec `json:"attachments,omitempty"`
+	Snapshots   []*model.VolumeSnapshotSpec   `json:"snapshots,omitempty"`
+	Transfers   []*model.VolumeTransferSpec   `json:"transfers,omitempty"`
+	Quotas      []*model.QuotaSpec            `json:"quotas,omitempty"`
+	Events      []*model.EventSpec            `json:"events,omitempty"`
+}
+
+// Summary tells the number of resources of each kind in the bundle.
+func (b *Bundle) Summary() string {
+	return fmt.Sprintf("%d docks, %d pools, %d profiles, %d volumes, %d attachments, "+
+		"%d snapshots, %d transfers, %d quotas, %d events",
+		len(b.Docks), len(b.Pools), len(b.Profiles), len(b.Volumes), len(b.Attachments),
+		len(b.Snapshots), len(b.Transfers), len(b.Quotas), len(b.Events))
+}
+
+// Export reads all resources from the database. The resource versions are
+// cleared since they only make sense to the database they come from.
+func Export(c db.Client) (*Bundle, error) {
+	var b = &Bundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now().Format(utils.TimeFormat),
+	}
+	var err error
+
+	if b.Docks, err = c.ListDocks(); err != nil {
+		return nil, fmt.Errorf("Export docks failed: %v", err)
+	}
+	if b.Pools, err = c.ListPools(); err != nil {
+		return nil, fmt.Errorf("Export pools failed: %v", err)
+	}
+	if b.Profiles, err = c.ListProfiles(nil); err != nil {
+		return nil, fmt.Errorf("Export profiles failed: %v", err)
+	}
+	if b.Volumes, err = c.ListVolumes(nil); err != nil {
+		return nil, fmt.Errorf("Export volumes failed: %v", err)
+	}
+	if b.Attachments, err = c.ListVolumeAttachments("", nil); err != nil {
+		return nil, fmt.Errorf("Export volume attachments failed: %v", err)
+	}
+	if b.Snapshots, err = c.ListVolumeSnapshots(nil); err != nil {
+		return nil, fmt.Errorf("Export volume snapshots failed: %v", err)
+	}
+	if b.Transfers, err = c.ListVolumeTransfers(nil); err != nil {
+		return nil, fmt.Errorf("Export volume transfers failed: %v", err)
+	}
+	if b.Quotas, err = c.ListQuotas(); err != nil {
+		return nil, fmt.Errorf("Export quotas failed: %v", err)
+	}
+	if b.Events, err = c.ListEvents(nil); err != nil {
+		return nil, fmt.Errorf("Export events failed: %v", err)
+	}
+
+	for _, m := range b.models() {
+		if m.GetResourceVersion() != 0 {
+			m.SetResourceVersion(0)
+		}
+	}
+	return b, nil
+}
+
+// Import creates all resources of the bundle in the database, the resources
+// keep their ids so it's meant to restore into an empty database. It stops
+// at the first failure, and the resources imported before are kept.
+func Import(c db.Client, b *Bundle) error {
+	if b.Version != BundleVersion {
+		return fmt.Errorf("Unsupported bundle version %d, expected %d", b.Version, BundleVersion)
+	}
+
+	for _, dck := range b.Docks {
+		if err := c.CreateDock(dck); err != nil {
+			return fmt.Errorf("Import dock %s failed: %v", dck.GetId(), err)
+		}
+	}
+	for _, pol := range b.Pools {
+		if err := c.CreatePool(pol); err != nil {
+			return fmt.Errorf("Import pool %s failed: %v", pol.GetId(), err)
+		}
+	}
+	for _, prf := range b.Profiles {
+		if err := c.CreateProfile(prf); err != nil {
+			return fmt.Errorf("Import profile %s failed: %v", prf.GetId(), err)
+		}
+	}
+	for _, vol := range b.Volumes {
+		if err := c.CreateVolume(vol); err != nil {
+			return fmt.Errorf("Import volume %s failed: %v", vol.GetId(), err)
+		}
+	}
+	for _, atc := range b.Attachments {
+		if err := c.CreateVolumeAttachment(atc.VolumeId, atc); err != nil {
+			return fmt.Errorf("Import volume attachment %s failed: %v", atc.GetId(), err)
+		}
+	}
+	for _, snp := range b.Snapshots {
+		if err := c.CreateVolumeSnapshot(snp); err != nil {
+			return fmt.Errorf("Import volume snapshot %s failed: %v", snp.GetId(), err)
+		}
+	}
+	for _, tr := range b.Transfers {
+		if err := c.CreateVolumeTransfer(tr); err != nil {
+			return fmt.Errorf("Import volume transfer %s failed: %v", tr.GetId(), err)
+		}
+	}
+	for _, quota := range b.Quotas {
+		if _, err := c.UpdateQuota(quota.GetId(), quota); err != nil {
+			return fmt.Errorf("Import quota %s failed: %v", quota.GetId(), err)
+		}
+	}
+	for _, evt := range b.Events {
+		if err := c.CreateEvent(evt); err != nil {
+			return fmt.Errorf("Import event %s failed: %v", evt.GetId(), err)
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) models() []model.Modeler {
+	var ms []model.Modeler
+	for _, m := range b.Docks {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Pools {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Profiles {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Volumes {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Attachments {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Snapshots {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Transfers {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Quotas {
+		ms = append(ms, m)
+	}
+	for _, m := range b.Events {
+		ms = append(ms, m)
+	}
+	return ms
+}
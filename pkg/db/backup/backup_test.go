@@ -0,0 +1,150 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/db"
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// recordingClient keeps the resources imported into it, which are listed
+// back by export.
+type recordingClient struct {
+	*db.FakeDbClient
+	Bundle
+}
+
+func (c *recordingClient) CreateDock(dck *model.DockSpec) error {
+	c.Docks = append(c.Docks, dck)
+	return nil
+}
+
+func (c *recordingClient) ListDocks() ([]*model.DockSpec, error) { return c.Docks, nil }
+
+func (c *recordingClient) CreatePool(pol *model.StoragePoolSpec) error {
+	c.Pools = append(c.Pools, pol)
+	return nil
+}
+
+func (c *recordingClient) ListPools() ([]*model.StoragePoolSpec, error) { return c.Pools, nil }
+
+func (c *recordingClient) CreateProfile(prf *model.ProfileSpec) error {
+	c.Profiles = append(c.Profiles, prf)
+	return nil
+}
+
+func (c *recordingClient) ListProfiles(opts *model.ListOptions) ([]*model.ProfileSpec, error) {
+	return c.Profiles, nil
+}
+
+func (c *recordingClient) CreateVolume(vol *model.VolumeSpec) error {
+	c.Volumes = append(c.Volumes, vol)
+	return nil
+}
+
+func (c *recordingClient) ListVolumes(opts *model.ListOptions) ([]*model.VolumeSpec, error) {
+	return c.Volumes, nil
+}
+
+func (c *recordingClient) CreateVolumeAttachment(volID string, atc *model.VolumeAttachmentSpec) error {
+	c.Attachments = append(c.Attachments, atc)
+	return nil
+}
+
+func (c *recordingClient) ListVolumeAttachments(volID string, opts *model.ListOptions) ([]*model.VolumeAttachmentSpec, error) {
+	return c.Attachments, nil
+}
+
+func (c *recordingClient) CreateVolumeSnapshot(snp *model.VolumeSnapshotSpec) error {
+	c.Snapshots = append(c.Snapshots, snp)
+	return nil
+}
+
+func (c *recordingClient) ListVolumeSnapshots(opts *model.ListOptions) ([]*model.VolumeSnapshotSpec, error) {
+	return c.Snapshots, nil
+}
+
+func (c *recordingClient) CreateVolumeTransfer(tr *model.VolumeTransferSpec) error {
+	c.Transfers = append(c.Transfers, tr)
+	return nil
+}
+
+func (c *recordingClient) ListVolumeTransfers(opts *model.ListOptions) ([]*model.VolumeTransferSpec, error) {
+	return c.Transfers, nil
+}
+
+func (c *recordingClient) UpdateQuota(projectID string, quota *model.QuotaSpec) (*model.QuotaSpec, error) {
+	c.Quotas = append(c.Quotas, quota)
+	return quota, nil
+}
+
+func (c *recordingClient) ListQuotas() ([]*model.QuotaSpec, error) { return c.Quotas, nil }
+
+func (c *recordingClient) CreateEvent(evt *model.EventSpec) error {
+	c.Events = append(c.Events, evt)
+	return nil
+}
+
+func (c *recordingClient) ListEvents(opts *model.ListOptions) ([]*model.EventSpec, error) {
+	return c.Events, nil
+}
+
+func TestExportImport(t *testing.T) {
+	expected, err := Export(db.NewFakeDbClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expected.Docks) == 0 || len(expected.Volumes) == 0 || len(expected.Events) == 0 {
+		t.Fatalf("Expected resources of fake db exported, got %s", expected.Summary())
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		var buf bytes.Buffer
+		if err = Encode(&buf, expected, format); err != nil {
+			t.Fatal(err)
+		}
+		b, err := Decode(&buf, format)
+		if err != nil {
+			t.Fatalf("Decode %s bundle failed: %v", format, err)
+		}
+
+		c := &recordingClient{FakeDbClient: &db.FakeDbClient{}}
+		if err = Import(c, b); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := Export(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual.ExportedAt = expected.ExportedAt
+
+		expectedBody, _ := json.Marshal(expected)
+		actualBody, _ := json.Marshal(actual)
+		if !bytes.Equal(expectedBody, actualBody) {
+			t.Errorf("Expected %s round trip to keep\n%s\ngot\n%s", format, expectedBody, actualBody)
+		}
+	}
+}
+
+func TestImportWithUnsupportedVersion(t *testing.T) {
+	c := &recordingClient{FakeDbClient: &db.FakeDbClient{}}
+	if err := Import(c, &Bundle{Version: BundleVersion + 1}); err == nil {
+		t.Error("Expected error for unsupported bundle version")
+	}
+}
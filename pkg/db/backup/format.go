@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The formats of bundle files.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// FormatOf returns the format of the bundle file by its extension, json is
+// the default one.
+func FormatOf(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatJSON
+	}
+}
+
+// Encode writes the bundle in the format. The yaml one is converted from
+// json, so that both of them follow the json tags of models.
+func Encode(w io.Writer, b *Bundle, format string) error {
+	body, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		body = append(body, '\n')
+	case FormatYAML:
+		var doc yaml.MapSlice
+		if err = yaml.Unmarshal(body, &doc); err != nil {
+			return err
+		}
+		if body, err = yaml.Marshal(doc); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unsupported bundle format %s", format)
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// Decode reads the bundle in the format.
+func Decode(r io.Reader, format string) (*Bundle, error) {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON:
+	case FormatYAML:
+		var doc interface{}
+		if err = yaml.Unmarshal(body, &doc); err != nil {
+			return nil, err
+		}
+		if body, err = json.Marshal(toJSONValue(doc)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported bundle format %s", format)
+	}
+
+	var b = &Bundle{}
+	if err = json.Unmarshal(body, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// toJSONValue converts the maps decoded from yaml, whose keys could be any
+// type, to the ones which could be encoded into json.
+func toJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = toJSONValue(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = toJSONValue(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	log "github.com/golang/glog"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// broadcaster pushes the changes of resources to the watchers in memory. The
+// zero value is ready to use.
+type broadcaster struct {
+	lock     sync.Mutex
+	watchers map[*watcher]bool
+}
+
+type watcher struct {
+	resource string
+	events   chan *model.WatchEventSpec
+	stop     <-chan struct{}
+}
+
+func (b *broadcaster) Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error) {
+	if !model.IsWatchResource(resource) {
+		return nil, fmt.Errorf("resource %s can't be watched", resource)
+	}
+
+	w := &watcher{
+		resource: resource,
+		events:   make(chan *model.WatchEventSpec),
+		stop:     stop,
+	}
+	b.lock.Lock()
+	if b.watchers == nil {
+		b.watchers = make(map[*watcher]bool)
+	}
+	b.watchers[w] = true
+	b.lock.Unlock()
+
+	go func() {
+		<-stop
+		b.lock.Lock()
+		delete(b.watchers, w)
+		b.lock.Unlock()
+		close(w.events)
+	}()
+	return w.events, nil
+}
+
+// publish pushes the change of obj to all watchers of the resource, it blocks
+// until every watcher receives the event or stops watching.
+func (b *broadcaster) publish(resource, typ string, obj interface{}) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		log.Error("When marshal watch event:", err)
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for w := range b.watchers {
+		if w.resource != resource {
+			continue
+		}
+		select {
+		case w.events <- &model.WatchEventSpec{Type: typ, Resource: resource, Object: body}:
+		case <-w.stop:
+		}
+	}
+}
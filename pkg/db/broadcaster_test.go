@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package db
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+func TestFakeDbClientWatch(t *testing.T) {
+	fc := &FakeDbClient{}
+	stop := make(chan struct{})
+
+	vols, err := fc.Watch(model.WatchResourceVolumes, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snps, err := fc.Watch(model.WatchResourceSnapshots, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		fc.CreateVolume(&model.VolumeSpec{BaseModel: &model.BaseModel{Id: "bd5b12a8-a101-11e7-941e-d77981b584d8"}})
+		fc.DeleteVolumeSnapshot("3769855c-a102-11e7-b772-17b880d2f537")
+	}()
+
+	evt := <-vols
+	var vol model.VolumeSpec
+	if err := json.Unmarshal(evt.Object, &vol); err != nil {
+		t.Fatal(err)
+	}
+	if evt.Type != model.WatchEventCreated || vol.Id != "bd5b12a8-a101-11e7-941e-d77981b584d8" {
+		t.Errorf("Expected volume created, got %s %+v", evt.Type, vol)
+	}
+	if evt = <-snps; evt.Type != model.WatchEventDeleted || evt.Resource != model.WatchResourceSnapshots {
+		t.Errorf("Expected snapshot deleted, got %+v", evt)
+	}
+
+	// The channels are closed once the watchers stop.
+	close(stop)
+	if _, ok := <-vols; ok {
+		t.Error("Expected volume events closed")
+	}
+	if _, ok := <-snps; ok {
+		t.Error("Expected snapshot events closed")
+	}
+	// Nobody is watching, so the change is dropped without blocking.
+	fc.DeleteVolume("bd5b12a8-a101-11e7-941e-d77981b584d8")
+
+	if _, err := fc.Watch("pools", nil); err == nil {
+		t.Error("Expected pools can't be watched")
+	}
+}
@@ -22,9 +22,15 @@ package db
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
+	"time"
+
+	log "github.com/golang/glog"
 
 	"github.com/opensds/opensds/pkg/db/drivers/etcd"
+	"github.com/opensds/opensds/pkg/db/drivers/memory"
 	_ "github.com/opensds/opensds/pkg/db/drivers/mysql"
 	"github.com/opensds/opensds/pkg/model"
 	. "github.com/opensds/opensds/pkg/utils/config"
@@ -33,17 +39,42 @@ import (
 var C Client
 
 func Init(db *Database) {
+	eventTTL := time.Duration(db.EventRetention) * time.Hour
 	switch db.Driver {
 	case "mysql":
 		// C = mysql.Init(db.Driver, db.Crendential)
-		fmt.Errorf("mysql is not implemented right now!")
+		log.Error("mysql is not implemented right now!")
 	case "etcd":
-		C = etcd.Init(strings.Split(db.Endpoint, ","))
+		C = etcd.Init(strings.Split(db.Endpoint, ","), eventTTL)
+	case "memory":
+		// The endpoint is the file the data is persisted into.
+		file, err := memoryDataFile(db.Endpoint)
+		if err != nil {
+			panic(err)
+		}
+		C = memory.Init(file, eventTTL)
 	case "fake":
 		C = NewFakeDbClient()
 	default:
-		fmt.Errorf("Can't find database driver %s!\n", db.Driver)
+		log.Errorf("Can't find database driver %s!", db.Driver)
+	}
+}
+
+// memoryDataFile returns the data file of memory database configured by the
+// endpoint. The endpoint left as default is the hosts of etcd, which is taken
+// as the default data file, and the other hosts are rejected.
+func memoryDataFile(endpoint string) (string, error) {
+	if endpoint == GetDefaultConfig().Database.Endpoint {
+		return memory.DefaultDataFile, nil
+	}
+	for _, host := range strings.Split(endpoint, ",") {
+		if _, port, err := net.SplitHostPort(host); err == nil && !strings.Contains(host, "/") {
+			if _, err = strconv.Atoi(port); err == nil {
+				return "", fmt.Errorf("Endpoint %s of memory database is hosts, not a data file", endpoint)
+			}
+		}
 	}
+	return endpoint, nil
 }
 
 type Client interface {
@@ -71,7 +102,7 @@ type Client interface {
 
 	GetProfile(prfID string) (*model.ProfileSpec, error)
 
-	ListProfiles() ([]*model.ProfileSpec, error)
+	ListProfiles(opts *model.ListOptions) ([]*model.ProfileSpec, error)
 
 	UpdateProfile(prfID string, input *model.ProfileSpec) (*model.ProfileSpec, error)
 
@@ -87,17 +118,23 @@ type Client interface {
 
 	GetVolume(volID string) (*model.VolumeSpec, error)
 
-	ListVolumes() ([]*model.VolumeSpec, error)
+	ListVolumes(opts *model.ListOptions) ([]*model.VolumeSpec, error)
+
+	UpdateVolume(volID string, input *model.VolumeSpec) (*model.VolumeSpec, error)
 
 	DeleteVolume(volID string) error
 
 	CreateVolumeAttachment(volID string, atc *model.VolumeAttachmentSpec) error
 
+	// GetVolumeAttachment and ListVolumeAttachments look up the attachments
+	// across all volumes if volID is empty.
 	GetVolumeAttachment(volID, attachmentID string) (*model.VolumeAttachmentSpec, error)
 
-	ListVolumeAttachments(volID string) ([]*model.VolumeAttachmentSpec, error)
+	ListVolumeAttachments(volID string, opts *model.ListOptions) ([]*model.VolumeAttachmentSpec, error)
 
-	UpdateVolumeAttachment(volID, attachmentID, mountpoint string, hostInfo *model.HostInfo) (*model.VolumeAttachmentSpec, error)
+	// UpdateVolumeAttachment updates the mountpoint, host info, connection
+	// info and metadata of the attachment if they are specified in input.
+	UpdateVolumeAttachment(volID, attachmentID string, input *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error)
 
 	DeleteVolumeAttachment(volID, attachmentID string) error
 
@@ -105,7 +142,60 @@ type Client interface {
 
 	GetVolumeSnapshot(snapshotID string) (*model.VolumeSnapshotSpec, error)
 
-	ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error)
+	ListVolumeSnapshots(opts *model.ListOptions) ([]*model.VolumeSnapshotSpec, error)
+
+	UpdateVolumeSnapshot(snapshotID string, input *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error)
 
 	DeleteVolumeSnapshot(snapshotID string) error
+
+	CreateVolumeTransfer(tr *model.VolumeTransferSpec) error
+
+	GetVolumeTransfer(trID string) (*model.VolumeTransferSpec, error)
+
+	ListVolumeTransfers(opts *model.ListOptions) ([]*model.VolumeTransferSpec, error)
+
+	DeleteVolumeTransfer(trID string) error
+
+	// AcceptVolumeTransfer hands the volume of the transfer to the project
+	// and removes the transfer in one atomic operation, the updated volume
+	// is returned.
+	AcceptVolumeTransfer(trID, projectID string) (*model.VolumeSpec, error)
+
+	// GetQuota returns the quota of the project, a quota without any limit
+	// is returned if it's not set.
+	GetQuota(projectID string) (*model.QuotaSpec, error)
+
+	UpdateQuota(projectID string, quota *model.QuotaSpec) (*model.QuotaSpec, error)
+
+	// ListQuotas returns the quotas which have been set.
+	ListQuotas() ([]*model.QuotaSpec, error)
+
+	// CreateIdempotencyKey stores the key which expires after ttl if it
+	// doesn't exist, otherwise the existing one is returned and nothing is
+	// stored.
+	CreateIdempotencyKey(key *model.IdempotencyKeySpec, ttl time.Duration) (*model.IdempotencyKeySpec, error)
+
+	GetIdempotencyKey(key string) (*model.IdempotencyKeySpec, error)
+
+	// UpdateIdempotencyKey updates the key without changing its expiration.
+	UpdateIdempotencyKey(key *model.IdempotencyKeySpec) error
+
+	DeleteIdempotencyKey(key string) error
+
+	// CreateEvent stores the event which expires after the event retention
+	// of database.
+	CreateEvent(evt *model.EventSpec) error
+
+	ListEvents(opts *model.ListOptions) ([]*model.EventSpec, error)
+
+	// Watch pushes the changes of the resource, which must be one of
+	// model.WatchResources, until stop is closed. The returned channel is
+	// closed when the watch ends.
+	Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error)
+
+	// Elect blocks until the node given by id becomes the leader of the
+	// election or stop is closed. The returned lost channel is closed when
+	// the leadership is lost, and resign gives it up so that another node
+	// takes over at once.
+	Elect(election, id string, stop <-chan struct{}) (lost <-chan struct{}, resign func() error, err error)
 }
@@ -13,3 +13,34 @@
 //    under the License.
 
 package db
+
+import (
+	"testing"
+
+	"github.com/opensds/opensds/pkg/db/drivers/memory"
+)
+
+func TestMemoryDataFile(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint, file string
+		fail           bool
+	}{
+		{endpoint: "localhost:2379,localhost:2380", file: memory.DefaultDataFile},
+		{endpoint: "/tmp/opensds.db", file: "/tmp/opensds.db"},
+		{endpoint: "opensds.db", file: "opensds.db"},
+		{endpoint: "", file: ""},
+		{endpoint: "127.0.0.1:2379", fail: true},
+		{endpoint: "etcd-0:2379,etcd-1:2379", fail: true},
+	} {
+		file, err := memoryDataFile(tc.endpoint)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("Expected error of endpoint %q, got file %q", tc.endpoint, file)
+			}
+			continue
+		}
+		if err != nil || file != tc.file {
+			t.Errorf("Expected file %q of endpoint %q, got %q and %v", tc.file, tc.endpoint, file, err)
+		}
+	}
+}
@@ -15,33 +15,48 @@
 package etcd
 
 import (
+	"errors"
+
 	log "github.com/golang/glog"
 
 	"golang.org/x/net/context"
 
 	"github.com/coreos/etcd/clientv3"
+	"github.com/opensds/opensds/pkg/utils"
 )
 
 type Request struct {
 	Url        string `json:"url"`
 	Content    string `json:"content"`
 	NewContent string `json:"newContent"`
+	// Revision is the mod revision of the key which Update expects, the
+	// update fails with the status Conflict if the key has been modified
+	// since then. Zero skips the check.
+	Revision int64 `json:"revision"`
 }
 
 type Response struct {
 	Status  string   `json:"status"`
 	Message []string `json:"message"`
-	Error   string   `json:"error"`
+	// Revisions are the mod revisions of the keys of Message.
+	Revisions []int64 `json:"revisions"`
+	Error     string  `json:"error"`
+}
+
+// Err returns the error of the failed response, utils.ErrConflict is returned
+// if the key has been modified by others.
+func (r *Response) Err() error {
+	if r.Status == "Conflict" {
+		return utils.ErrConflict
+	}
+	return errors.New(r.Error)
 }
 
 func (c *client) Create(req *Request) *Response {
 	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
 	defer cancel()
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
-	_, err := c.cli.Put(ctx, req.Url, req.Content)
+	resp, err := c.cli.Put(ctx, req.Url, req.Content)
 	if err != nil {
 		log.Error("When create db request:", err)
 		return &Response{
@@ -51,8 +66,9 @@ func (c *client) Create(req *Request) *Response {
 	}
 
 	return &Response{
-		Status:  "Success",
-		Message: []string{req.Content},
+		Status:    "Success",
+		Message:   []string{req.Content},
+		Revisions: []int64{resp.Header.Revision},
 	}
 }
 
@@ -60,9 +76,6 @@ func (c *client) Get(req *Request) *Response {
 	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
 	defer cancel()
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
 	resp, err := c.cli.Get(ctx, req.Url)
 	if err != nil {
 		log.Error("When get db request:", err)
@@ -79,8 +92,9 @@ func (c *client) Get(req *Request) *Response {
 		}
 	}
 	return &Response{
-		Status:  "Success",
-		Message: []string{string(resp.Kvs[0].Value)},
+		Status:    "Success",
+		Message:   []string{string(resp.Kvs[0].Value)},
+		Revisions: []int64{resp.Kvs[0].ModRevision},
 	}
 }
 
@@ -88,9 +102,6 @@ func (c *client) List(req *Request) *Response {
 	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
 	defer cancel()
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
 	resp, err := c.cli.Get(ctx, req.Url, clientv3.WithPrefix())
 	if err != nil {
 		log.Error("When get db request:", err)
@@ -101,12 +112,15 @@ func (c *client) List(req *Request) *Response {
 	}
 
 	var message = []string{}
+	var revisions = []int64{}
 	for _, v := range resp.Kvs {
 		message = append(message, string(v.Value))
+		revisions = append(revisions, v.ModRevision)
 	}
 	return &Response{
-		Status:  "Success",
-		Message: message,
+		Status:    "Success",
+		Message:   message,
+		Revisions: revisions,
 	}
 }
 
@@ -114,10 +128,15 @@ func (c *client) Update(req *Request) *Response {
 	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
 	defer cancel()
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
-	_, err := c.cli.Put(ctx, req.Url, req.NewContent)
+	// The key is put only if it hasn't been modified since the revision
+	// the caller read.
+	var cmps []clientv3.Cmp
+	if req.Revision != 0 {
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(req.Url), "=", req.Revision))
+	}
+	resp, err := c.cli.Txn(ctx).If(cmps...).Then(
+		clientv3.OpPut(req.Url, req.NewContent),
+	).Commit()
 	if err != nil {
 		log.Error("When update db request:", err)
 		return &Response{
@@ -125,10 +144,17 @@ func (c *client) Update(req *Request) *Response {
 			Error:  err.Error(),
 		}
 	}
+	if !resp.Succeeded {
+		return &Response{
+			Status: "Conflict",
+			Error:  utils.ErrConflict.Error(),
+		}
+	}
 
 	return &Response{
-		Status:  "Success",
-		Message: []string{req.NewContent},
+		Status:    "Success",
+		Message:   []string{req.NewContent},
+		Revisions: []int64{resp.Header.Revision},
 	}
 }
 
@@ -136,9 +162,6 @@ func (c *client) Delete(req *Request) *Response {
 	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
 	defer cancel()
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
 	_, err := c.cli.Delete(ctx, req.Url)
 	if err != nil {
 		log.Error("When delete db request:", err)
@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package etcd
+
+import (
+	log "github.com/golang/glog"
+
+	"github.com/coreos/etcd/clientv3/concurrency"
+	"golang.org/x/net/context"
+)
+
+// electionTTL is the time in seconds which the leadership is kept after the
+// leader goes away without resigning, such as crashing or losing connection.
+const electionTTL = 15
+
+func (c *client) Elect(election, id string, stop <-chan struct{}) (<-chan struct{}, func() error, error) {
+	// The leadership is bound to the lease of the session, which is kept
+	// alive as long as the node is alive.
+	sess, err := concurrency.NewSession(c.cli, concurrency.WithTTL(electionTTL))
+	if err != nil {
+		log.Error("When create election session in db:", err)
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	e := concurrency.NewElection(sess, GenerateUrl(prefix, "elections", election))
+	if err = e.Campaign(ctx, id); err != nil {
+		sess.Close()
+		return nil, nil, err
+	}
+
+	resign := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+		defer cancel()
+		defer sess.Close()
+
+		return e.Resign(ctx)
+	}
+	return sess.Done(), resign, nil
+}
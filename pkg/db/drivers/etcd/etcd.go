@@ -23,13 +23,15 @@ package etcd
 import (
 	"encoding/json"
 	"errors"
-	"sync"
+	"fmt"
 	"time"
 
 	log "github.com/golang/glog"
 
 	"github.com/coreos/etcd/clientv3"
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -39,7 +41,9 @@ const (
 
 var c = &client{}
 
-func Init(edps []string) *client {
+// Init connects to the etcd endpoints, the events stored expire after eventTTL
+// and zero keeps them forever.
+func Init(edps []string, eventTTL time.Duration) *client {
 	cli, err := clientv3.New(clientv3.Config{
 		Endpoints:   edps,
 		DialTimeout: timeOut,
@@ -49,13 +53,48 @@ func Init(edps []string) *client {
 		panic(err)
 	}
 
-	c.cli = cli
+	c.cli, c.eventTTL = cli, eventTTL
 	return c
 }
 
 type client struct {
-	cli  *clientv3.Client
-	lock sync.Mutex
+	cli      *clientv3.Client
+	eventTTL time.Duration
+}
+
+// maxUpdateRetries is the times a read-modify-write is retried when the key
+// is modified by others between the read and the write.
+const maxUpdateRetries = 3
+
+// retryOnConflict runs the read-modify-write update again if the key is
+// modified by others in the meantime. The conflict is returned instead if
+// the caller asks for a specific version, since its view is out of date.
+func retryOnConflict(version int64, update func() error) error {
+	var err error
+	for i := 0; i <= maxUpdateRetries; i++ {
+		if err = update(); err != utils.ErrConflict || version != 0 {
+			break
+		}
+	}
+	return err
+}
+
+// checkVersion returns the conflict error if the resource isn't in the version
+// expected by the caller, zero expects any version.
+func checkVersion(expected int64, m model.Modeler) error {
+	if expected != 0 && expected != m.GetResourceVersion() {
+		return utils.ErrConflict
+	}
+	return nil
+}
+
+// marshal encodes the resource to be stored. The resource version isn't
+// stored since it's the mod revision of the key.
+func marshal(m model.Modeler) ([]byte, error) {
+	version := m.GetResourceVersion()
+	m.SetResourceVersion(0)
+	defer m.SetResourceVersion(version)
+	return json.Marshal(m)
 }
 
 func (c *client) CreateDock(dck *model.DockSpec) error {
@@ -92,6 +131,7 @@ func (c *client) GetDock(dckID string) (*model.DockSpec, error) {
 		log.Error("When parsing dock in db:", dbRes.Error)
 		return nil, errors.New(dbRes.Error)
 	}
+	dck.SetResourceVersion(dbRes.Revisions[0])
 	return dck, nil
 }
 
@@ -109,42 +149,52 @@ func (c *client) ListDocks() ([]*model.DockSpec, error) {
 	if len(dbRes.Message) == 0 {
 		return dcks, nil
 	}
-	for _, msg := range dbRes.Message {
+	for i, msg := range dbRes.Message {
 		var dck = &model.DockSpec{}
 		if err := json.Unmarshal([]byte(msg), dck); err != nil {
 			log.Error("When parsing dock in db:", dbRes.Error)
 			return nil, errors.New(dbRes.Error)
 		}
+		dck.SetResourceVersion(dbRes.Revisions[i])
 		dcks = append(dcks, dck)
 	}
 	return dcks, nil
 }
 
 func (c *client) UpdateDock(dckID, name, desp string) (*model.DockSpec, error) {
-	dck, err := c.GetDock(dckID)
-	if err != nil {
-		return nil, err
-	}
-	if name != "" {
-		dck.Name = name
-	}
-	if desp != "" {
-		dck.Description = desp
-	}
-	dckBody, err := json.Marshal(dck)
+	var dck *model.DockSpec
+	err := retryOnConflict(0, func() error {
+		var err error
+		if dck, err = c.GetDock(dckID); err != nil {
+			return err
+		}
+		if name != "" {
+			dck.Name = name
+		}
+		if desp != "" {
+			dck.Description = desp
+		}
+		dckBody, err := marshal(dck)
+		if err != nil {
+			return err
+		}
+
+		dbReq := &Request{
+			Url:        GenerateUrl(prefix, "docks", dckID),
+			NewContent: string(dckBody),
+			Revision:   dck.GetResourceVersion(),
+		}
+		dbRes := c.Update(dbReq)
+		if dbRes.Status != "Success" {
+			log.Error("When update dock in db:", dbRes.Error)
+			return dbRes.Err()
+		}
+		dck.SetResourceVersion(dbRes.Revisions[0])
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	dbReq := &Request{
-		Url:        GenerateUrl(prefix, "docks", dckID),
-		NewContent: string(dckBody),
-	}
-	dbRes := c.Update(dbReq)
-	if dbRes.Status != "Success" {
-		log.Error("When update dock in db:", dbRes.Error)
-		return nil, errors.New(dbRes.Error)
-	}
 	return dck, nil
 }
 
@@ -194,6 +244,7 @@ func (c *client) GetPool(polID string) (*model.StoragePoolSpec, error) {
 		log.Error("When parsing pool in db:", dbRes.Error)
 		return nil, errors.New(dbRes.Error)
 	}
+	pol.SetResourceVersion(dbRes.Revisions[0])
 	return pol, nil
 }
 
@@ -211,42 +262,52 @@ func (c *client) ListPools() ([]*model.StoragePoolSpec, error) {
 	if len(dbRes.Message) == 0 {
 		return pols, nil
 	}
-	for _, msg := range dbRes.Message {
+	for i, msg := range dbRes.Message {
 		var pol = &model.StoragePoolSpec{}
 		if err := json.Unmarshal([]byte(msg), pol); err != nil {
 			log.Error("When parsing pool in db:", dbRes.Error)
 			return nil, errors.New(dbRes.Error)
 		}
+		pol.SetResourceVersion(dbRes.Revisions[i])
 		pols = append(pols, pol)
 	}
 	return pols, nil
 }
 
 func (c *client) UpdatePool(polID, name, desp string, usedCapacity int64, used bool) (*model.StoragePoolSpec, error) {
-	pol, err := c.GetPool(polID)
-	if err != nil {
-		return nil, err
-	}
-	if name != "" {
-		pol.Name = name
-	}
-	if desp != "" {
-		pol.Description = desp
-	}
-	polBody, err := json.Marshal(pol)
+	var pol *model.StoragePoolSpec
+	err := retryOnConflict(0, func() error {
+		var err error
+		if pol, err = c.GetPool(polID); err != nil {
+			return err
+		}
+		if name != "" {
+			pol.Name = name
+		}
+		if desp != "" {
+			pol.Description = desp
+		}
+		polBody, err := marshal(pol)
+		if err != nil {
+			return err
+		}
+
+		dbReq := &Request{
+			Url:        GenerateUrl(prefix, "pools", polID),
+			NewContent: string(polBody),
+			Revision:   pol.GetResourceVersion(),
+		}
+		dbRes := c.Update(dbReq)
+		if dbRes.Status != "Success" {
+			log.Error("When update pool in db:", dbRes.Error)
+			return dbRes.Err()
+		}
+		pol.SetResourceVersion(dbRes.Revisions[0])
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	dbReq := &Request{
-		Url:        GenerateUrl(prefix, "pools", polID),
-		NewContent: string(polBody),
-	}
-	dbRes := c.Update(dbReq)
-	if dbRes.Status != "Success" {
-		log.Error("When update pool in db:", dbRes.Error)
-		return nil, errors.New(dbRes.Error)
-	}
 	return pol, nil
 }
 
@@ -296,10 +357,11 @@ func (c *client) GetProfile(prfID string) (*model.ProfileSpec, error) {
 		log.Error("When parsing profile in db:", dbRes.Error)
 		return nil, errors.New(dbRes.Error)
 	}
+	prf.SetResourceVersion(dbRes.Revisions[0])
 	return prf, nil
 }
 
-func (c *client) ListProfiles() ([]*model.ProfileSpec, error) {
+func (c *client) ListProfiles(opts *model.ListOptions) ([]*model.ProfileSpec, error) {
 	dbReq := &Request{
 		Url: GenerateUrl(prefix, "profiles"),
 	}
@@ -313,46 +375,64 @@ func (c *client) ListProfiles() ([]*model.ProfileSpec, error) {
 	if len(dbRes.Message) == 0 {
 		return prfs, nil
 	}
-	for _, msg := range dbRes.Message {
+	for i, msg := range dbRes.Message {
 		var prf = &model.ProfileSpec{}
 		if err := json.Unmarshal([]byte(msg), prf); err != nil {
 			log.Error("When parsing profile in db:", dbRes.Error)
 			return nil, errors.New(dbRes.Error)
 		}
+		prf.SetResourceVersion(dbRes.Revisions[i])
 		prfs = append(prfs, prf)
 	}
-	return prfs, nil
-}
-
-func (c *client) UpdateProfile(prfID string, input *model.ProfileSpec) (*model.ProfileSpec, error) {
-	prf, err := c.GetProfile(prfID)
+	items, err := utils.SelectItems(prfs, opts)
 	if err != nil {
+		log.Error("When select profiles in db:", err)
 		return nil, err
 	}
-	if name := input.GetName(); name != "" {
-		prf.Name = name
-	}
-	if desp := input.GetDescription(); desp != "" {
-		prf.Description = desp
-	}
-	if props := input.Extra; len(props) != 0 {
-		return nil, errors.New("Failed to update extra properties!")
-	}
+	return items.([]*model.ProfileSpec), nil
+}
 
-	prfBody, err := json.Marshal(prf)
+func (c *client) UpdateProfile(prfID string, input *model.ProfileSpec) (*model.ProfileSpec, error) {
+	var prf *model.ProfileSpec
+	err := retryOnConflict(input.GetResourceVersion(), func() error {
+		var err error
+		if prf, err = c.GetProfile(prfID); err != nil {
+			return err
+		}
+		if err = checkVersion(input.GetResourceVersion(), prf); err != nil {
+			return err
+		}
+		if name := input.GetName(); name != "" {
+			prf.Name = name
+		}
+		if desp := input.GetDescription(); desp != "" {
+			prf.Description = desp
+		}
+		if props := input.Extra; len(props) != 0 {
+			return errors.New("Failed to update extra properties!")
+		}
+
+		prfBody, err := marshal(prf)
+		if err != nil {
+			return err
+		}
+
+		dbReq := &Request{
+			Url:        GenerateUrl(prefix, "profiles", prfID),
+			NewContent: string(prfBody),
+			Revision:   prf.GetResourceVersion(),
+		}
+		dbRes := c.Update(dbReq)
+		if dbRes.Status != "Success" {
+			log.Error("When update profile in db:", dbRes.Error)
+			return dbRes.Err()
+		}
+		prf.SetResourceVersion(dbRes.Revisions[0])
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	dbReq := &Request{
-		Url:        GenerateUrl(prefix, "profiles", prfID),
-		NewContent: string(prfBody),
-	}
-	dbRes := c.Update(dbReq)
-	if dbRes.Status != "Success" {
-		log.Error("When update profile in db:", dbRes.Error)
-		return nil, errors.New(dbRes.Error)
-	}
 	return prf, nil
 }
 
@@ -369,16 +449,19 @@ func (c *client) DeleteProfile(prfID string) error {
 }
 
 func (c *client) AddExtraProperty(prfID string, ext model.ExtraSpec) (*model.ExtraSpec, error) {
-	prf, err := c.GetProfile(prfID)
-	if err != nil {
-		return nil, err
-	}
-
-	for k, v := range ext {
-		prf.Extra[k] = v
-	}
+	var prf *model.ProfileSpec
+	err := retryOnConflict(0, func() error {
+		var err error
+		if prf, err = c.GetProfile(prfID); err != nil {
+			return err
+		}
 
-	if err = c.CreateProfile(prf); err != nil {
+		for k, v := range ext {
+			prf.Extra[k] = v
+		}
+		return c.putProfile(prf)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &prf.Extra, nil
@@ -393,15 +476,36 @@ func (c *client) ListExtraProperties(prfID string) (*model.ExtraSpec, error) {
 }
 
 func (c *client) RemoveExtraProperty(prfID, extraKey string) error {
-	prf, err := c.GetProfile(prfID)
+	return retryOnConflict(0, func() error {
+		prf, err := c.GetProfile(prfID)
+		if err != nil {
+			return err
+		}
+
+		delete(prf.Extra, extraKey)
+		return c.putProfile(prf)
+	})
+}
+
+// putProfile stores the profile read from db, which fails with a conflict if
+// the profile has been modified since then.
+func (c *client) putProfile(prf *model.ProfileSpec) error {
+	prfBody, err := marshal(prf)
 	if err != nil {
 		return err
 	}
 
-	delete(prf.Extra, extraKey)
-	if err = c.CreateProfile(prf); err != nil {
-		return err
+	dbReq := &Request{
+		Url:        GenerateUrl(prefix, "profiles", prf.GetId()),
+		NewContent: string(prfBody),
+		Revision:   prf.GetResourceVersion(),
 	}
+	dbRes := c.Update(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When update profile in db:", dbRes.Error)
+		return dbRes.Err()
+	}
+	prf.SetResourceVersion(dbRes.Revisions[0])
 	return nil
 }
 
@@ -439,10 +543,11 @@ func (c *client) GetVolume(volID string) (*model.VolumeSpec, error) {
 		log.Error("When parsing volume in db:", dbRes.Error)
 		return nil, errors.New(dbRes.Error)
 	}
+	vol.SetResourceVersion(dbRes.Revisions[0])
 	return vol, nil
 }
 
-func (c *client) ListVolumes() ([]*model.VolumeSpec, error) {
+func (c *client) ListVolumes(opts *model.ListOptions) ([]*model.VolumeSpec, error) {
 	dbReq := &Request{
 		Url: GenerateUrl(prefix, "volumes"),
 	}
@@ -456,15 +561,73 @@ func (c *client) ListVolumes() ([]*model.VolumeSpec, error) {
 	if len(dbRes.Message) == 0 {
 		return vols, nil
 	}
-	for _, msg := range dbRes.Message {
+	for i, msg := range dbRes.Message {
 		var vol = &model.VolumeSpec{}
 		if err := json.Unmarshal([]byte(msg), vol); err != nil {
 			log.Error("When parsing volume in db:", dbRes.Error)
 			return nil, errors.New(dbRes.Error)
 		}
+		vol.SetResourceVersion(dbRes.Revisions[i])
 		vols = append(vols, vol)
 	}
-	return vols, nil
+	items, err := utils.SelectItems(vols, opts)
+	if err != nil {
+		log.Error("When select volumes in db:", err)
+		return nil, err
+	}
+	return items.([]*model.VolumeSpec), nil
+}
+
+func (c *client) UpdateVolume(volID string, input *model.VolumeSpec) (*model.VolumeSpec, error) {
+	var vol *model.VolumeSpec
+	err := retryOnConflict(input.GetResourceVersion(), func() error {
+		var err error
+		if vol, err = c.GetVolume(volID); err != nil {
+			return err
+		}
+		if err = checkVersion(input.GetResourceVersion(), vol); err != nil {
+			return err
+		}
+		if name := input.GetName(); name != "" {
+			vol.Name = name
+		}
+		if desp := input.GetDescription(); desp != "" {
+			vol.Description = desp
+		}
+		if status := input.GetStatus(); status != "" {
+			vol.Status = status
+		}
+		if meta := input.GetMetadata(); len(meta) != 0 {
+			if vol.Metadata == nil {
+				vol.Metadata = make(map[string]string)
+			}
+			for k, v := range meta {
+				vol.Metadata[k] = v
+			}
+		}
+
+		volBody, err := marshal(vol)
+		if err != nil {
+			return err
+		}
+
+		dbReq := &Request{
+			Url:        GenerateUrl(prefix, "volumes", volID),
+			NewContent: string(volBody),
+			Revision:   vol.GetResourceVersion(),
+		}
+		dbRes := c.Update(dbReq)
+		if dbRes.Status != "Success" {
+			log.Error("When update volume in db:", dbRes.Error)
+			return dbRes.Err()
+		}
+		vol.SetResourceVersion(dbRes.Revisions[0])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vol, nil
 }
 
 func (c *client) DeleteVolume(volID string) error {
@@ -479,26 +642,83 @@ func (c *client) DeleteVolume(volID string) error {
 	return nil
 }
 
+// CreateVolumeAttachment stores the attachment only if the volume supports
+// multiattach or has no other attachment. The volume is put again along with
+// the attachment in a transaction which expects the volume unmodified since it
+// was read, so that concurrent attachments of the volume can't both pass.
 func (c *client) CreateVolumeAttachment(volID string, atc *model.VolumeAttachmentSpec) error {
 	atcBody, err := json.Marshal(atc)
 	if err != nil {
 		return err
 	}
 
-	dbReq := &Request{
-		Url:     GenerateUrl(prefix, "volume", volID, "attachments", atc.GetId()),
-		Content: string(atcBody),
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	atcKey := GenerateUrl(prefix, "volume", volID, "attachments", atc.GetId())
+	volKey := GenerateUrl(prefix, "volumes", volID)
+	volRes, err := c.cli.Get(ctx, volKey)
+	if err != nil {
+		log.Error("When get volume in db:", err)
+		return err
 	}
-	dbRes := c.Create(dbReq)
-	if dbRes.Status != "Success" {
-		log.Error("When create volume attachment in db:", dbRes.Error)
-		return errors.New(dbRes.Error)
+	var vol = &model.VolumeSpec{}
+	if len(volRes.Kvs) != 0 {
+		if err = json.Unmarshal(volRes.Kvs[0].Value, vol); err != nil {
+			log.Error("When parsing volume in db:", err)
+			return err
+		}
+	}
+	// The attachment of volume which isn't stored, such as the one restored
+	// before its volume, is stored without check.
+	if len(volRes.Kvs) == 0 || vol.GetMultiattach() {
+		dbRes := c.Create(&Request{Url: atcKey, Content: string(atcBody)})
+		if dbRes.Status != "Success" {
+			log.Error("When create volume attachment in db:", dbRes.Error)
+			return errors.New(dbRes.Error)
+		}
+		return nil
 	}
 
+	atcs, err := c.listVolumeAttachments(volID)
+	if err != nil {
+		return err
+	}
+	for _, a := range atcs {
+		if a.GetId() != atc.GetId() {
+			return utils.MultiattachError(volID)
+		}
+	}
+	txnRes, err := c.cli.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(volKey), "=", volRes.Kvs[0].ModRevision),
+	).Then(
+		clientv3.OpPut(atcKey, string(atcBody)),
+		clientv3.OpPut(volKey, string(volRes.Kvs[0].Value)),
+	).Commit()
+	if err != nil {
+		log.Error("When create volume attachment in db:", err)
+		return err
+	}
+	if !txnRes.Succeeded {
+		return utils.ErrConflict
+	}
 	return nil
 }
 
 func (c *client) GetVolumeAttachment(volID, atcID string) (*model.VolumeAttachmentSpec, error) {
+	if volID == "" {
+		atcs, err := c.listAllVolumeAttachments()
+		if err != nil {
+			return nil, err
+		}
+		for _, atc := range atcs {
+			if atc.GetId() == atcID {
+				return atc, nil
+			}
+		}
+		return nil, fmt.Errorf("Can't find volume attachment %s", atcID)
+	}
+
 	dbReq := &Request{
 		Url: GenerateUrl(prefix, "volume", volID, "attachments", atcID),
 	}
@@ -513,10 +733,50 @@ func (c *client) GetVolumeAttachment(volID, atcID string) (*model.VolumeAttachme
 		log.Error("When parsing volume attachment in db:", dbRes.Error)
 		return nil, errors.New(dbRes.Error)
 	}
+	atc.SetResourceVersion(dbRes.Revisions[0])
 	return atc, nil
 }
 
-func (c *client) ListVolumeAttachments(volID string) ([]*model.VolumeAttachmentSpec, error) {
+func (c *client) ListVolumeAttachments(volID string, opts *model.ListOptions) ([]*model.VolumeAttachmentSpec, error) {
+	var atcs []*model.VolumeAttachmentSpec
+	var err error
+	if volID == "" {
+		atcs, err = c.listAllVolumeAttachments()
+	} else {
+		atcs, err = c.listVolumeAttachments(volID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := utils.SelectItems(atcs, opts)
+	if err != nil {
+		log.Error("When select volume attachments in db:", err)
+		return nil, err
+	}
+	return items.([]*model.VolumeAttachmentSpec), nil
+}
+
+// listAllVolumeAttachments lists the attachments of all volumes, since the
+// attachments are stored under the volumes they belong to.
+func (c *client) listAllVolumeAttachments() ([]*model.VolumeAttachmentSpec, error) {
+	vols, err := c.ListVolumes(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var atcs = []*model.VolumeAttachmentSpec{}
+	for _, vol := range vols {
+		volAtcs, err := c.listVolumeAttachments(vol.GetId())
+		if err != nil {
+			return nil, err
+		}
+		atcs = append(atcs, volAtcs...)
+	}
+	return atcs, nil
+}
+
+func (c *client) listVolumeAttachments(volID string) ([]*model.VolumeAttachmentSpec, error) {
 	dbReq := &Request{
 		Url: GenerateUrl(prefix, "volume", volID, "attachments"),
 	}
@@ -530,39 +790,67 @@ func (c *client) ListVolumeAttachments(volID string) ([]*model.VolumeAttachmentS
 	if len(dbRes.Message) == 0 {
 		return atcs, nil
 	}
-	for _, msg := range dbRes.Message {
+	for i, msg := range dbRes.Message {
 		var atc = &model.VolumeAttachmentSpec{}
 		if err := json.Unmarshal([]byte(msg), atc); err != nil {
 			log.Error("When parsing volume attachment in db:", dbRes.Error)
 			return nil, errors.New(dbRes.Error)
 		}
+		atc.SetResourceVersion(dbRes.Revisions[i])
 		atcs = append(atcs, atc)
 	}
 	return atcs, nil
 }
 
-func (c *client) UpdateVolumeAttachment(volID, atcID, mountpoint string, hostInfo *model.HostInfo) (*model.VolumeAttachmentSpec, error) {
-	atc, err := c.GetVolumeAttachment(volID, atcID)
-	if err != nil {
-		return nil, err
-	}
+func (c *client) UpdateVolumeAttachment(volID, atcID string, input *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+	var atc *model.VolumeAttachmentSpec
+	err := retryOnConflict(input.GetResourceVersion(), func() error {
+		var err error
+		if atc, err = c.GetVolumeAttachment(volID, atcID); err != nil {
+			return err
+		}
+		if err = checkVersion(input.GetResourceVersion(), atc); err != nil {
+			return err
+		}
 
-	atc.HostInfo = hostInfo
-	atc.Mountpoint = mountpoint
-	atcBody, err := json.Marshal(atc)
+		if mp := input.GetMountpoint(); mp != "" {
+			atc.Mountpoint = mp
+		}
+		if input.HostInfo != nil {
+			atc.HostInfo = input.HostInfo
+		}
+		if input.ConnectionInfo != nil {
+			atc.ConnectionInfo = input.ConnectionInfo
+		}
+		if meta := input.GetMetadata(); len(meta) != 0 {
+			if atc.Metadata == nil {
+				atc.Metadata = make(map[string]string)
+			}
+			for k, v := range meta {
+				atc.Metadata[k] = v
+			}
+		}
+		atcBody, err := marshal(atc)
+		if err != nil {
+			return err
+		}
+
+		dbReq := &Request{
+			Url:        GenerateUrl(prefix, "volume", volID, "attachments", atcID),
+			NewContent: string(atcBody),
+			Revision:   atc.GetResourceVersion(),
+		}
+		dbRes := c.Update(dbReq)
+		if dbRes.Status != "Success" {
+			log.Error("When update volume attachment in db:", dbRes.Error)
+			return dbRes.Err()
+		}
+		atc.SetResourceVersion(dbRes.Revisions[0])
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	dbReq := &Request{
-		Url:        GenerateUrl(prefix, "volume", volID, "attachments", atcID),
-		NewContent: string(atcBody),
-	}
-	dbRes := c.Update(dbReq)
-	if dbRes.Status != "Success" {
-		log.Error("When update volume attachment in db:", dbRes.Error)
-		return nil, errors.New(dbRes.Error)
-	}
 	return atc, nil
 }
 
@@ -612,10 +900,11 @@ func (c *client) GetVolumeSnapshot(snpID string) (*model.VolumeSnapshotSpec, err
 		log.Error("When parsing volume snapshot in db:", dbRes.Error)
 		return nil, errors.New(dbRes.Error)
 	}
+	vs.SetResourceVersion(dbRes.Revisions[0])
 	return vs, nil
 }
 
-func (c *client) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+func (c *client) ListVolumeSnapshots(opts *model.ListOptions) ([]*model.VolumeSnapshotSpec, error) {
 	dbReq := &Request{
 		Url: GenerateUrl(prefix, "volume", "snapshots"),
 	}
@@ -629,15 +918,73 @@ func (c *client) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error) {
 	if len(dbRes.Message) == 0 {
 		return vss, nil
 	}
-	for _, msg := range dbRes.Message {
+	for i, msg := range dbRes.Message {
 		var vs = &model.VolumeSnapshotSpec{}
 		if err := json.Unmarshal([]byte(msg), vs); err != nil {
 			log.Error("When parsing volume snapshot in db:", dbRes.Error)
 			return nil, errors.New(dbRes.Error)
 		}
+		vs.SetResourceVersion(dbRes.Revisions[i])
 		vss = append(vss, vs)
 	}
-	return vss, nil
+	items, err := utils.SelectItems(vss, opts)
+	if err != nil {
+		log.Error("When select volume snapshots in db:", err)
+		return nil, err
+	}
+	return items.([]*model.VolumeSnapshotSpec), nil
+}
+
+func (c *client) UpdateVolumeSnapshot(snpID string, input *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error) {
+	var vs *model.VolumeSnapshotSpec
+	err := retryOnConflict(input.GetResourceVersion(), func() error {
+		var err error
+		if vs, err = c.GetVolumeSnapshot(snpID); err != nil {
+			return err
+		}
+		if err = checkVersion(input.GetResourceVersion(), vs); err != nil {
+			return err
+		}
+		if name := input.GetName(); name != "" {
+			vs.Name = name
+		}
+		if desp := input.GetDescription(); desp != "" {
+			vs.Description = desp
+		}
+		if status := input.GetStatus(); status != "" {
+			vs.Status = status
+		}
+		if meta := input.GetMetadata(); len(meta) != 0 {
+			if vs.Metadata == nil {
+				vs.Metadata = make(map[string]string)
+			}
+			for k, v := range meta {
+				vs.Metadata[k] = v
+			}
+		}
+
+		vsBody, err := marshal(vs)
+		if err != nil {
+			return err
+		}
+
+		dbReq := &Request{
+			Url:        GenerateUrl(prefix, "volume", "snapshots", snpID),
+			NewContent: string(vsBody),
+			Revision:   vs.GetResourceVersion(),
+		}
+		dbRes := c.Update(dbReq)
+		if dbRes.Status != "Success" {
+			log.Error("When update volume snapshot in db:", dbRes.Error)
+			return dbRes.Err()
+		}
+		vs.SetResourceVersion(dbRes.Revisions[0])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vs, nil
 }
 
 func (c *client) DeleteVolumeSnapshot(snpID string) error {
@@ -651,3 +998,354 @@ func (c *client) DeleteVolumeSnapshot(snpID string) error {
 	}
 	return nil
 }
+
+func (c *client) CreateVolumeTransfer(tr *model.VolumeTransferSpec) error {
+	trBody, err := json.Marshal(tr)
+	if err != nil {
+		return err
+	}
+
+	dbReq := &Request{
+		Url:     GenerateUrl(prefix, "transfers", tr.GetId()),
+		Content: string(trBody),
+	}
+	dbRes := c.Create(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When create volume transfer in db:", dbRes.Error)
+		return errors.New(dbRes.Error)
+	}
+
+	return nil
+}
+
+func (c *client) GetVolumeTransfer(trID string) (*model.VolumeTransferSpec, error) {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "transfers", trID),
+	}
+	dbRes := c.Get(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When get volume transfer in db:", dbRes.Error)
+		return nil, errors.New(dbRes.Error)
+	}
+
+	var tr = &model.VolumeTransferSpec{}
+	if err := json.Unmarshal([]byte(dbRes.Message[0]), tr); err != nil {
+		log.Error("When parsing volume transfer in db:", dbRes.Error)
+		return nil, errors.New(dbRes.Error)
+	}
+	tr.SetResourceVersion(dbRes.Revisions[0])
+	return tr, nil
+}
+
+func (c *client) ListVolumeTransfers(opts *model.ListOptions) ([]*model.VolumeTransferSpec, error) {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "transfers"),
+	}
+	dbRes := c.List(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When list volume transfers in db:", dbRes.Error)
+		return nil, errors.New(dbRes.Error)
+	}
+
+	var trs = []*model.VolumeTransferSpec{}
+	for i, msg := range dbRes.Message {
+		var tr = &model.VolumeTransferSpec{}
+		if err := json.Unmarshal([]byte(msg), tr); err != nil {
+			log.Error("When parsing volume transfer in db:", dbRes.Error)
+			return nil, errors.New(dbRes.Error)
+		}
+		tr.SetResourceVersion(dbRes.Revisions[i])
+		trs = append(trs, tr)
+	}
+	items, err := utils.SelectItems(trs, opts)
+	if err != nil {
+		log.Error("When select volume transfers in db:", err)
+		return nil, err
+	}
+	return items.([]*model.VolumeTransferSpec), nil
+}
+
+func (c *client) DeleteVolumeTransfer(trID string) error {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "transfers", trID),
+	}
+	dbRes := c.Delete(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When delete volume transfer in db:", dbRes.Error)
+		return errors.New(dbRes.Error)
+	}
+	return nil
+}
+
+func (c *client) AcceptVolumeTransfer(trID, projectID string) (*model.VolumeSpec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	trKey := GenerateUrl(prefix, "transfers", trID)
+	trRes, err := c.cli.Get(ctx, trKey)
+	if err != nil {
+		log.Error("When get volume transfer in db:", err)
+		return nil, err
+	}
+	if len(trRes.Kvs) == 0 {
+		return nil, fmt.Errorf("Volume transfer %s doesn't exist", trID)
+	}
+	var tr = &model.VolumeTransferSpec{}
+	if err = json.Unmarshal(trRes.Kvs[0].Value, tr); err != nil {
+		log.Error("When parsing volume transfer in db:", err)
+		return nil, err
+	}
+
+	volKey := GenerateUrl(prefix, "volumes", tr.GetVolumeId())
+	volRes, err := c.cli.Get(ctx, volKey)
+	if err != nil {
+		log.Error("When get volume in db:", err)
+		return nil, err
+	}
+	if len(volRes.Kvs) == 0 {
+		return nil, fmt.Errorf("Volume %s doesn't exist", tr.GetVolumeId())
+	}
+	var vol = &model.VolumeSpec{}
+	if err = json.Unmarshal(volRes.Kvs[0].Value, vol); err != nil {
+		log.Error("When parsing volume in db:", err)
+		return nil, err
+	}
+
+	vol.ProjectId, vol.Status = projectID, model.VolumeAvailable
+	volBody, err := marshal(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner of the volume is changed and the transfer is removed only if
+	// neither of them has been modified since they were read.
+	txnRes, err := c.cli.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(trKey), "=", trRes.Kvs[0].ModRevision),
+		clientv3.Compare(clientv3.ModRevision(volKey), "=", volRes.Kvs[0].ModRevision),
+	).Then(
+		clientv3.OpPut(volKey, string(volBody)),
+		clientv3.OpDelete(trKey),
+	).Commit()
+	if err != nil {
+		log.Error("When accept volume transfer in db:", err)
+		return nil, err
+	}
+	if !txnRes.Succeeded {
+		return nil, utils.ErrConflict
+	}
+	vol.SetResourceVersion(txnRes.Header.Revision)
+	return vol, nil
+}
+
+func (c *client) GetQuota(projectID string) (*model.QuotaSpec, error) {
+	quotas, err := c.ListQuotas()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, quota := range quotas {
+		if quota.GetId() == projectID {
+			return quota, nil
+		}
+	}
+	return &model.QuotaSpec{
+		BaseModel:    &model.BaseModel{Id: projectID},
+		ResourceList: map[string]int64{},
+	}, nil
+}
+
+func (c *client) ListQuotas() ([]*model.QuotaSpec, error) {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "quotas"),
+	}
+	dbRes := c.List(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When list quotas in db:", dbRes.Error)
+		return nil, errors.New(dbRes.Error)
+	}
+
+	var quotas []*model.QuotaSpec
+	for i, msg := range dbRes.Message {
+		var quota = &model.QuotaSpec{}
+		if err := json.Unmarshal([]byte(msg), quota); err != nil {
+			log.Error("When parsing quota in db:", dbRes.Error)
+			return nil, errors.New(dbRes.Error)
+		}
+		quota.SetResourceVersion(dbRes.Revisions[i])
+		quotas = append(quotas, quota)
+	}
+	return quotas, nil
+}
+
+func (c *client) UpdateQuota(projectID string, quota *model.QuotaSpec) (*model.QuotaSpec, error) {
+	if quota.BaseModel == nil {
+		quota.BaseModel = &model.BaseModel{}
+	}
+	quota.Id = projectID
+	quotaBody, err := marshal(quota)
+	if err != nil {
+		return nil, err
+	}
+
+	// The quota is replaced as a whole, so it's checked only if the caller
+	// asks for a specific version.
+	dbReq := &Request{
+		Url:        GenerateUrl(prefix, "quotas", projectID),
+		NewContent: string(quotaBody),
+		Revision:   quota.GetResourceVersion(),
+	}
+	dbRes := c.Update(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When update quota in db:", dbRes.Error)
+		return nil, dbRes.Err()
+	}
+	quota.SetResourceVersion(dbRes.Revisions[0])
+	return quota, nil
+}
+
+func (c *client) CreateIdempotencyKey(key *model.IdempotencyKeySpec, ttl time.Duration) (*model.IdempotencyKeySpec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	keyBody, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key is removed by etcd once the lease expires.
+	lease, err := c.cli.Grant(ctx, int64(ttl/time.Second))
+	if err != nil {
+		log.Error("When grant lease of idempotency key in db:", err)
+		return nil, err
+	}
+
+	url := GenerateUrl(prefix, "idempotency", key.GetId())
+	txnRes, err := c.cli.Txn(ctx).If(
+		clientv3.Compare(clientv3.CreateRevision(url), "=", 0),
+	).Then(
+		clientv3.OpPut(url, string(keyBody), clientv3.WithLease(lease.ID)),
+	).Else(
+		clientv3.OpGet(url),
+	).Commit()
+	if err != nil {
+		log.Error("When create idempotency key in db:", err)
+		return nil, err
+	}
+	if txnRes.Succeeded {
+		return nil, nil
+	}
+
+	kvs := txnRes.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 {
+		return nil, fmt.Errorf("Idempotency key %s was removed concurrently", key.GetId())
+	}
+	var existing = &model.IdempotencyKeySpec{}
+	if err = json.Unmarshal(kvs[0].Value, existing); err != nil {
+		log.Error("When parsing idempotency key in db:", err)
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (c *client) GetIdempotencyKey(key string) (*model.IdempotencyKeySpec, error) {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "idempotency", key),
+	}
+	dbRes := c.Get(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When get idempotency key in db:", dbRes.Error)
+		return nil, errors.New(dbRes.Error)
+	}
+
+	var res = &model.IdempotencyKeySpec{}
+	if err := json.Unmarshal([]byte(dbRes.Message[0]), res); err != nil {
+		log.Error("When parsing idempotency key in db:", err)
+		return nil, err
+	}
+	res.SetResourceVersion(dbRes.Revisions[0])
+	return res, nil
+}
+
+func (c *client) UpdateIdempotencyKey(key *model.IdempotencyKeySpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	keyBody, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	url := GenerateUrl(prefix, "idempotency", key.GetId())
+	if _, err = c.cli.Put(ctx, url, string(keyBody), clientv3.WithIgnoreLease()); err != nil {
+		log.Error("When update idempotency key in db:", err)
+		return err
+	}
+	return nil
+}
+
+func (c *client) DeleteIdempotencyKey(key string) error {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "idempotency", key),
+	}
+	dbRes := c.Delete(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When delete idempotency key in db:", dbRes.Error)
+		return errors.New(dbRes.Error)
+	}
+	return nil
+}
+
+func (c *client) CreateEvent(evt *model.EventSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	evtBody, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	// The event is removed by etcd once the lease expires, so that the
+	// events don't pile up.
+	var opts []clientv3.OpOption
+	if c.eventTTL > 0 {
+		lease, err := c.cli.Grant(ctx, int64(c.eventTTL/time.Second))
+		if err != nil {
+			log.Error("When grant lease of event in db:", err)
+			return err
+		}
+		opts = append(opts, clientv3.WithLease(lease.ID))
+	}
+	url := GenerateUrl(prefix, "events", evt.GetId())
+	if _, err = c.cli.Put(ctx, url, string(evtBody), opts...); err != nil {
+		log.Error("When create event in db:", err)
+		return err
+	}
+	return nil
+}
+
+func (c *client) ListEvents(opts *model.ListOptions) ([]*model.EventSpec, error) {
+	dbReq := &Request{
+		Url: GenerateUrl(prefix, "events"),
+	}
+	dbRes := c.List(dbReq)
+	if dbRes.Status != "Success" {
+		log.Error("When list events in db:", dbRes.Error)
+		return nil, errors.New(dbRes.Error)
+	}
+
+	var evts = []*model.EventSpec{}
+	for i, msg := range dbRes.Message {
+		var evt = &model.EventSpec{}
+		if err := json.Unmarshal([]byte(msg), evt); err != nil {
+			log.Error("When parsing event in db:", dbRes.Error)
+			return nil, errors.New(dbRes.Error)
+		}
+		evt.SetResourceVersion(dbRes.Revisions[i])
+		evts = append(evts, evt)
+	}
+	items, err := utils.SelectItems(evts, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.EventSpec), nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package etcd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	var calls int
+	conflict := func() error {
+		calls++
+		return utils.ErrConflict
+	}
+
+	// The update is retried if the caller doesn't ask for a version.
+	if err := retryOnConflict(0, conflict); err != utils.ErrConflict {
+		t.Errorf("Expected conflict, got %v", err)
+	}
+	if calls != maxUpdateRetries+1 {
+		t.Errorf("Expected %d calls, got %d", maxUpdateRetries+1, calls)
+	}
+
+	calls = 0
+	if err := retryOnConflict(5, conflict); err != utils.ErrConflict || calls != 1 {
+		t.Errorf("Expected conflict without retry, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err := retryOnConflict(0, func() error {
+		if calls++; calls < 2 {
+			return utils.ErrConflict
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Expected success on retry, got %v after %d calls", err, calls)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	vol := &model.VolumeSpec{BaseModel: &model.BaseModel{ResourceVersion: 7}}
+
+	for _, v := range []int64{0, 7} {
+		if err := checkVersion(v, vol); err != nil {
+			t.Errorf("Expected version %d matched, got %v", v, err)
+		}
+	}
+	if err := checkVersion(6, vol); err != utils.ErrConflict {
+		t.Errorf("Expected conflict, got %v", err)
+	}
+}
+
+func TestMarshalWithoutVersion(t *testing.T) {
+	vol := &model.VolumeSpec{BaseModel: &model.BaseModel{Id: "bd5b12a8-a101-11e7-941e-d77981b584d8", ResourceVersion: 7}}
+
+	body, err := marshal(vol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "resourceVersion") {
+		t.Errorf("Expected resource version not stored, got %s", body)
+	}
+	if vol.GetResourceVersion() != 7 {
+		t.Errorf("Expected resource version kept, got %d", vol.GetResourceVersion())
+	}
+}
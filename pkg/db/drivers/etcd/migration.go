@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package etcd
+
+import (
+	"encoding/json"
+	"strings"
+
+	log "github.com/golang/glog"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/opensds/opensds/pkg/db/migration"
+	"golang.org/x/net/context"
+)
+
+var _ migration.Store = &client{}
+
+// schemaVersion is the record of the version of schema which the data is in.
+type schemaVersion struct {
+	Version int `json:"version"`
+}
+
+var schemaVersionKey = GenerateUrl(prefix, "schema_version")
+
+func (c *client) GetSchemaVersion() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	resp, err := c.cli.Get(ctx, schemaVersionKey)
+	if err != nil {
+		log.Error("When get schema version from db:", err)
+		return 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+
+	var ver schemaVersion
+	if err = json.Unmarshal(resp.Kvs[0].Value, &ver); err != nil {
+		return 0, err
+	}
+	return ver.Version, nil
+}
+
+func (c *client) SetSchemaVersion(version int) error {
+	body, err := json.Marshal(&schemaVersion{Version: version})
+	if err != nil {
+		return err
+	}
+	return c.PutKey("schema_version", string(body))
+}
+
+func (c *client) Scan(pfx string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	root := prefix + "/"
+	resp, err := c.cli.Get(ctx, root+pfx, clientv3.WithPrefix())
+	if err != nil {
+		log.Error("When scan db:", err)
+		return nil, err
+	}
+
+	var kvs = map[string]string{}
+	for _, kv := range resp.Kvs {
+		kvs[strings.TrimPrefix(string(kv.Key), root)] = string(kv.Value)
+	}
+	return kvs, nil
+}
+
+func (c *client) PutKey(key, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	if _, err := c.cli.Put(ctx, GenerateUrl(prefix, key), value); err != nil {
+		log.Error("When put key to db:", err)
+		return err
+	}
+	return nil
+}
+
+func (c *client) DeleteKey(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeOut)
+	defer cancel()
+
+	if _, err := c.cli.Delete(ctx, GenerateUrl(prefix, key)); err != nil {
+		log.Error("When delete key from db:", err)
+		return err
+	}
+	return nil
+}
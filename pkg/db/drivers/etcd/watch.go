@@ -0,0 +1,124 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/golang/glog"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/opensds/opensds/pkg/model"
+	"golang.org/x/net/context"
+)
+
+// watchPrefixes maps the resources which could be watched to the prefix of
+// their keys. Attachments are stored under the volumes they belong to, so
+// they are picked out of all keys under the volumes.
+var watchPrefixes = map[string]string{
+	model.WatchResourceVolumes:     GenerateUrl(prefix, "volumes", ""),
+	model.WatchResourceAttachments: GenerateUrl(prefix, "volume", ""),
+	model.WatchResourceSnapshots:   GenerateUrl(prefix, "volume", "snapshots", ""),
+	model.WatchResourceTransfers:   GenerateUrl(prefix, "transfers", ""),
+}
+
+func (c *client) Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error) {
+	pfx, ok := watchPrefixes[resource]
+	if !ok {
+		return nil, fmt.Errorf("resource %s can't be watched", resource)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wch := c.cli.Watch(ctx, pfx, clientv3.WithPrefix(), clientv3.WithPrevKV())
+	events := make(chan *model.WatchEventSpec)
+
+	go func() {
+		defer close(events)
+		defer cancel()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case resp, ok := <-wch:
+				if !ok {
+					return
+				}
+				if err := resp.Err(); err != nil {
+					log.Error("When watch resource in db:", err)
+					return
+				}
+				for _, ev := range resp.Events {
+					evt := toWatchEvent(resource, ev)
+					if evt == nil {
+						continue
+					}
+					select {
+					case events <- evt:
+					case <-stop:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// toWatchEvent converts the change of a key to the event of the resource, nil
+// is returned if the key doesn't belong to the resource.
+func toWatchEvent(resource string, ev *clientv3.Event) *model.WatchEventSpec {
+	if resource == model.WatchResourceAttachments &&
+		!strings.Contains(string(ev.Kv.Key), "/attachments/") {
+		return nil
+	}
+
+	evt := &model.WatchEventSpec{
+		Resource: resource,
+		Object:   withResourceVersion(ev.Kv.Value, ev.Kv.ModRevision),
+	}
+	switch {
+	case ev.Type == clientv3.EventTypeDelete:
+		evt.Type = model.WatchEventDeleted
+		// The value of a deleted key is empty, the previous one is the
+		// last state of the resource.
+		evt.Object = nil
+		if ev.PrevKv != nil {
+			evt.Object = withResourceVersion(ev.PrevKv.Value, ev.PrevKv.ModRevision)
+		}
+	case ev.IsCreate():
+		evt.Type = model.WatchEventCreated
+	default:
+		evt.Type = model.WatchEventUpdated
+	}
+	return evt
+}
+
+// withResourceVersion sets the resource version of the stored resource to the
+// mod revision of its key, as the resource read from db does.
+func withResourceVersion(value []byte, version int64) json.RawMessage {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(value, &obj); err != nil {
+		return value
+	}
+	obj["resourceVersion"], _ = json.Marshal(version)
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return value
+	}
+	return body
+}
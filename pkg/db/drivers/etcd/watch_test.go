@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package etcd
+
+import (
+	"testing"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/opensds/opensds/pkg/model"
+)
+
+func TestToWatchEvent(t *testing.T) {
+	var volKey = []byte("/v1alpha/block/volumes/bd5b12a8-a101-11e7-941e-d77981b584d8")
+	var atcKey = []byte("/v1alpha/block/volume/bd5b12a8-a101-11e7-941e-d77981b584d8/attachments/f2dda3d2-bf79-11e7-8665-f750b088f63e")
+
+	testCases := []struct {
+		resource     string
+		ev           *clientv3.Event
+		expectedType string
+		expectedObj  string
+	}{
+		{
+			resource: model.WatchResourceVolumes,
+			ev: &clientv3.Event{
+				Type: clientv3.EventTypePut,
+				Kv:   &mvccpb.KeyValue{Key: volKey, Value: []byte(`{"status":"creating"}`), CreateRevision: 5, ModRevision: 5},
+			},
+			expectedType: model.WatchEventCreated,
+			expectedObj:  `{"resourceVersion":5,"status":"creating"}`,
+		},
+		{
+			resource: model.WatchResourceVolumes,
+			ev: &clientv3.Event{
+				Type: clientv3.EventTypePut,
+				Kv:   &mvccpb.KeyValue{Key: volKey, Value: []byte(`{"status":"available"}`), CreateRevision: 5, ModRevision: 6},
+			},
+			expectedType: model.WatchEventUpdated,
+			expectedObj:  `{"resourceVersion":6,"status":"available"}`,
+		},
+		{
+			resource: model.WatchResourceVolumes,
+			ev: &clientv3.Event{
+				Type:   clientv3.EventTypeDelete,
+				Kv:     &mvccpb.KeyValue{Key: volKey, ModRevision: 7},
+				PrevKv: &mvccpb.KeyValue{Key: volKey, Value: []byte(`{"status":"deleting"}`), ModRevision: 6},
+			},
+			expectedType: model.WatchEventDeleted,
+			expectedObj:  `{"resourceVersion":6,"status":"deleting"}`,
+		},
+		{
+			resource: model.WatchResourceAttachments,
+			ev: &clientv3.Event{
+				Type: clientv3.EventTypePut,
+				Kv:   &mvccpb.KeyValue{Key: atcKey, Value: []byte(`{"status":"available"}`), CreateRevision: 8, ModRevision: 8},
+			},
+			expectedType: model.WatchEventCreated,
+			expectedObj:  `{"resourceVersion":8,"status":"available"}`,
+		},
+	}
+
+	for _, c := range testCases {
+		evt := toWatchEvent(c.resource, c.ev)
+		if evt == nil {
+			t.Fatalf("Expected event of %s, got nil", c.ev.Kv.Key)
+		}
+		if evt.Type != c.expectedType || evt.Resource != c.resource || string(evt.Object) != c.expectedObj {
+			t.Errorf("Expected %s event of %s, got %+v", c.expectedType, c.expectedObj, evt)
+		}
+	}
+
+	// The keys of snapshots are under the volumes too, but they aren't
+	// attachments.
+	snpEv := &clientv3.Event{
+		Type: clientv3.EventTypePut,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/v1alpha/block/volume/snapshots/3769855c-a102-11e7-b772-17b880d2f537")},
+	}
+	if evt := toWatchEvent(model.WatchResourceAttachments, snpEv); evt != nil {
+		t.Errorf("Expected snapshot key filtered, got %+v", evt)
+	}
+}
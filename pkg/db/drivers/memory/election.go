@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package memory
+
+import (
+	"errors"
+	"sync"
+)
+
+// Elect makes the node the leader once the one before it resigns. The store
+// is only shared by the nodes in one process, so the leadership is never lost
+// until it's given up.
+func (c *client) Elect(election, id string, stop <-chan struct{}) (<-chan struct{}, func() error, error) {
+	for {
+		c.lock.Lock()
+		held, ok := c.leaders[election]
+		if !ok {
+			resigned := make(chan struct{})
+			c.leaders[election] = resigned
+			c.lock.Unlock()
+
+			var once sync.Once
+			resign := func() error {
+				once.Do(func() {
+					c.lock.Lock()
+					delete(c.leaders, election)
+					c.lock.Unlock()
+					close(resigned)
+				})
+				return nil
+			}
+			return make(chan struct{}), resign, nil
+		}
+		c.lock.Unlock()
+
+		select {
+		case <-held:
+		case <-stop:
+			return nil, nil, errors.New("The election is stopped")
+		}
+	}
+}
@@ -0,0 +1,657 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+func dockKey(id string) string       { return "docks/" + id }
+func poolKey(id string) string       { return "pools/" + id }
+func profileKey(id string) string    { return "profiles/" + id }
+func volumeKey(id string) string     { return "volumes/" + id }
+func snapshotKey(id string) string   { return "volume/snapshots/" + id }
+func transferKey(id string) string   { return "transfers/" + id }
+func quotaKey(id string) string      { return "quotas/" + id }
+func idempotencyKey(k string) string { return "idempotency/" + k }
+func eventKey(id string) string      { return "events/" + id }
+
+func attachmentKey(volID, id string) string {
+	return "volume/" + volID + "/attachments/" + id
+}
+
+func (c *client) CreateDock(dck *model.DockSpec) error {
+	return c.create(dockKey(dck.GetId()), dck)
+}
+
+func (c *client) GetDock(dckID string) (*model.DockSpec, error) {
+	var dck = &model.DockSpec{}
+	if err := c.load(dockKey(dckID), dck); err != nil {
+		return nil, err
+	}
+	return dck, nil
+}
+
+func (c *client) ListDocks() ([]*model.DockSpec, error) {
+	var dcks = []*model.DockSpec{}
+	err := c.loadAll(dockKey(""), func() model.Modeler {
+		dck := &model.DockSpec{}
+		dcks = append(dcks, dck)
+		return dck
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dcks, nil
+}
+
+func (c *client) UpdateDock(dckID, name, desp string) (*model.DockSpec, error) {
+	var dck = &model.DockSpec{}
+	err := c.update(dockKey(dckID), 0, dck, func() error {
+		if name != "" {
+			dck.Name = name
+		}
+		if desp != "" {
+			dck.Description = desp
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dck, nil
+}
+
+func (c *client) DeleteDock(dckID string) error {
+	return c.remove(dockKey(dckID))
+}
+
+func (c *client) CreatePool(pol *model.StoragePoolSpec) error {
+	return c.create(poolKey(pol.GetId()), pol)
+}
+
+func (c *client) GetPool(polID string) (*model.StoragePoolSpec, error) {
+	var pol = &model.StoragePoolSpec{}
+	if err := c.load(poolKey(polID), pol); err != nil {
+		return nil, err
+	}
+	return pol, nil
+}
+
+func (c *client) ListPools() ([]*model.StoragePoolSpec, error) {
+	var pols = []*model.StoragePoolSpec{}
+	err := c.loadAll(poolKey(""), func() model.Modeler {
+		pol := &model.StoragePoolSpec{}
+		pols = append(pols, pol)
+		return pol
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pols, nil
+}
+
+func (c *client) UpdatePool(polID, name, desp string, usedCapacity int64, used bool) (*model.StoragePoolSpec, error) {
+	var pol = &model.StoragePoolSpec{}
+	err := c.update(poolKey(polID), 0, pol, func() error {
+		if name != "" {
+			pol.Name = name
+		}
+		if desp != "" {
+			pol.Description = desp
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pol, nil
+}
+
+func (c *client) DeletePool(polID string) error {
+	return c.remove(poolKey(polID))
+}
+
+func (c *client) CreateProfile(prf *model.ProfileSpec) error {
+	return c.create(profileKey(prf.GetId()), prf)
+}
+
+func (c *client) GetProfile(prfID string) (*model.ProfileSpec, error) {
+	var prf = &model.ProfileSpec{}
+	if err := c.load(profileKey(prfID), prf); err != nil {
+		return nil, err
+	}
+	return prf, nil
+}
+
+func (c *client) ListProfiles(opts *model.ListOptions) ([]*model.ProfileSpec, error) {
+	var prfs = []*model.ProfileSpec{}
+	err := c.loadAll(profileKey(""), func() model.Modeler {
+		prf := &model.ProfileSpec{}
+		prfs = append(prfs, prf)
+		return prf
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, err := utils.SelectItems(prfs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.ProfileSpec), nil
+}
+
+func (c *client) UpdateProfile(prfID string, input *model.ProfileSpec) (*model.ProfileSpec, error) {
+	var prf = &model.ProfileSpec{}
+	err := c.update(profileKey(prfID), input.GetResourceVersion(), prf, func() error {
+		if name := input.GetName(); name != "" {
+			prf.Name = name
+		}
+		if desp := input.GetDescription(); desp != "" {
+			prf.Description = desp
+		}
+		if props := input.Extra; len(props) != 0 {
+			return fmt.Errorf("Failed to update extra properties!")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prf, nil
+}
+
+func (c *client) DeleteProfile(prfID string) error {
+	return c.remove(profileKey(prfID))
+}
+
+func (c *client) AddExtraProperty(prfID string, ext model.ExtraSpec) (*model.ExtraSpec, error) {
+	var prf = &model.ProfileSpec{}
+	err := c.update(profileKey(prfID), 0, prf, func() error {
+		if prf.Extra == nil {
+			prf.Extra = model.ExtraSpec{}
+		}
+		for k, v := range ext {
+			prf.Extra[k] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prf.Extra, nil
+}
+
+func (c *client) ListExtraProperties(prfID string) (*model.ExtraSpec, error) {
+	prf, err := c.GetProfile(prfID)
+	if err != nil {
+		return nil, err
+	}
+	return &prf.Extra, nil
+}
+
+func (c *client) RemoveExtraProperty(prfID, extraKey string) error {
+	var prf = &model.ProfileSpec{}
+	return c.update(profileKey(prfID), 0, prf, func() error {
+		delete(prf.Extra, extraKey)
+		return nil
+	})
+}
+
+func (c *client) CreateVolume(vol *model.VolumeSpec) error {
+	return c.create(volumeKey(vol.GetId()), vol)
+}
+
+func (c *client) GetVolume(volID string) (*model.VolumeSpec, error) {
+	var vol = &model.VolumeSpec{}
+	if err := c.load(volumeKey(volID), vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func (c *client) ListVolumes(opts *model.ListOptions) ([]*model.VolumeSpec, error) {
+	var vols = []*model.VolumeSpec{}
+	err := c.loadAll(volumeKey(""), func() model.Modeler {
+		vol := &model.VolumeSpec{}
+		vols = append(vols, vol)
+		return vol
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, err := utils.SelectItems(vols, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeSpec), nil
+}
+
+func (c *client) UpdateVolume(volID string, input *model.VolumeSpec) (*model.VolumeSpec, error) {
+	var vol = &model.VolumeSpec{}
+	err := c.update(volumeKey(volID), input.GetResourceVersion(), vol, func() error {
+		if name := input.GetName(); name != "" {
+			vol.Name = name
+		}
+		if desp := input.GetDescription(); desp != "" {
+			vol.Description = desp
+		}
+		if status := input.GetStatus(); status != "" {
+			vol.Status = status
+		}
+		if meta := input.GetMetadata(); len(meta) != 0 {
+			if vol.Metadata == nil {
+				vol.Metadata = make(map[string]string)
+			}
+			for k, v := range meta {
+				vol.Metadata[k] = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func (c *client) DeleteVolume(volID string) error {
+	return c.remove(volumeKey(volID))
+}
+
+// CreateVolumeAttachment stores the attachment only if the volume supports
+// multiattach or has no other attachment, which is checked under the lock of
+// store so that concurrent attachments can't both pass.
+func (c *client) CreateVolumeAttachment(volID string, atc *model.VolumeAttachmentSpec) error {
+	value, err := marshal(atc)
+	if err != nil {
+		return err
+	}
+
+	key := attachmentKey(volID, atc.GetId())
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var vol = &model.VolumeSpec{}
+	if err = c.loadLocked(volumeKey(volID), vol); err == nil && !vol.GetMultiattach() {
+		for _, k := range c.scan(attachmentKey(volID, "")) {
+			if k != key {
+				return utils.MultiattachError(volID)
+			}
+		}
+	}
+	_, err = c.put(key, value, 0, 0)
+	return err
+}
+
+func (c *client) GetVolumeAttachment(volID, atcID string) (*model.VolumeAttachmentSpec, error) {
+	if volID == "" {
+		atcs, err := c.ListVolumeAttachments("", nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, atc := range atcs {
+			if atc.GetId() == atcID {
+				return atc, nil
+			}
+		}
+		return nil, fmt.Errorf("Can't find volume attachment %s", atcID)
+	}
+
+	var atc = &model.VolumeAttachmentSpec{}
+	if err := c.load(attachmentKey(volID, atcID), atc); err != nil {
+		return nil, err
+	}
+	return atc, nil
+}
+
+func (c *client) ListVolumeAttachments(volID string, opts *model.ListOptions) ([]*model.VolumeAttachmentSpec, error) {
+	// The attachments of all volumes are picked out of the keys under the
+	// volumes, which include the snapshots as well.
+	pfx := attachmentKey(volID, "")
+	if volID == "" {
+		pfx = "volume/"
+	}
+
+	var atcs = []*model.VolumeAttachmentSpec{}
+	c.lock.Lock()
+	for _, key := range c.scan(pfx) {
+		if !strings.Contains(key, "/attachments/") {
+			continue
+		}
+		atc := &model.VolumeAttachmentSpec{}
+		if err := c.loadLocked(key, atc); err != nil {
+			c.lock.Unlock()
+			return nil, err
+		}
+		atcs = append(atcs, atc)
+	}
+	c.lock.Unlock()
+
+	items, err := utils.SelectItems(atcs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeAttachmentSpec), nil
+}
+
+func (c *client) UpdateVolumeAttachment(volID, atcID string, input *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+	if volID == "" {
+		atc, err := c.GetVolumeAttachment("", atcID)
+		if err != nil {
+			return nil, err
+		}
+		volID = atc.VolumeId
+	}
+
+	var atc = &model.VolumeAttachmentSpec{}
+	err := c.update(attachmentKey(volID, atcID), input.GetResourceVersion(), atc, func() error {
+		if mp := input.GetMountpoint(); mp != "" {
+			atc.Mountpoint = mp
+		}
+		if input.HostInfo != nil {
+			atc.HostInfo = input.HostInfo
+		}
+		if input.ConnectionInfo != nil {
+			atc.ConnectionInfo = input.ConnectionInfo
+		}
+		if meta := input.GetMetadata(); len(meta) != 0 {
+			if atc.Metadata == nil {
+				atc.Metadata = make(map[string]string)
+			}
+			for k, v := range meta {
+				atc.Metadata[k] = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return atc, nil
+}
+
+func (c *client) DeleteVolumeAttachment(volID, atcID string) error {
+	return c.remove(attachmentKey(volID, atcID))
+}
+
+func (c *client) CreateVolumeSnapshot(snp *model.VolumeSnapshotSpec) error {
+	return c.create(snapshotKey(snp.GetId()), snp)
+}
+
+func (c *client) GetVolumeSnapshot(snpID string) (*model.VolumeSnapshotSpec, error) {
+	var snp = &model.VolumeSnapshotSpec{}
+	if err := c.load(snapshotKey(snpID), snp); err != nil {
+		return nil, err
+	}
+	return snp, nil
+}
+
+func (c *client) ListVolumeSnapshots(opts *model.ListOptions) ([]*model.VolumeSnapshotSpec, error) {
+	var snps = []*model.VolumeSnapshotSpec{}
+	err := c.loadAll(snapshotKey(""), func() model.Modeler {
+		snp := &model.VolumeSnapshotSpec{}
+		snps = append(snps, snp)
+		return snp
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, err := utils.SelectItems(snps, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeSnapshotSpec), nil
+}
+
+func (c *client) UpdateVolumeSnapshot(snpID string, input *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error) {
+	var snp = &model.VolumeSnapshotSpec{}
+	err := c.update(snapshotKey(snpID), input.GetResourceVersion(), snp, func() error {
+		if name := input.GetName(); name != "" {
+			snp.Name = name
+		}
+		if desp := input.GetDescription(); desp != "" {
+			snp.Description = desp
+		}
+		if status := input.GetStatus(); status != "" {
+			snp.Status = status
+		}
+		if meta := input.GetMetadata(); len(meta) != 0 {
+			if snp.Metadata == nil {
+				snp.Metadata = make(map[string]string)
+			}
+			for k, v := range meta {
+				snp.Metadata[k] = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snp, nil
+}
+
+func (c *client) DeleteVolumeSnapshot(snpID string) error {
+	return c.remove(snapshotKey(snpID))
+}
+
+func (c *client) CreateVolumeTransfer(tr *model.VolumeTransferSpec) error {
+	return c.create(transferKey(tr.GetId()), tr)
+}
+
+func (c *client) GetVolumeTransfer(trID string) (*model.VolumeTransferSpec, error) {
+	var tr = &model.VolumeTransferSpec{}
+	if err := c.load(transferKey(trID), tr); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func (c *client) ListVolumeTransfers(opts *model.ListOptions) ([]*model.VolumeTransferSpec, error) {
+	var trs = []*model.VolumeTransferSpec{}
+	err := c.loadAll(transferKey(""), func() model.Modeler {
+		tr := &model.VolumeTransferSpec{}
+		trs = append(trs, tr)
+		return tr
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, err := utils.SelectItems(trs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeTransferSpec), nil
+}
+
+func (c *client) DeleteVolumeTransfer(trID string) error {
+	return c.remove(transferKey(trID))
+}
+
+func (c *client) AcceptVolumeTransfer(trID, projectID string) (*model.VolumeSpec, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var tr = &model.VolumeTransferSpec{}
+	if _, ok := c.get(transferKey(trID)); !ok {
+		return nil, fmt.Errorf("Volume transfer %s doesn't exist", trID)
+	}
+	if err := c.loadLocked(transferKey(trID), tr); err != nil {
+		return nil, err
+	}
+	var vol = &model.VolumeSpec{}
+	if _, ok := c.get(volumeKey(tr.GetVolumeId())); !ok {
+		return nil, fmt.Errorf("Volume %s doesn't exist", tr.GetVolumeId())
+	}
+	if err := c.loadLocked(volumeKey(tr.GetVolumeId()), vol); err != nil {
+		return nil, err
+	}
+
+	// Both changes are committed in one record, so nobody sees or persists
+	// one of them without the other.
+	vol.ProjectId, vol.Status = projectID, model.VolumeAvailable
+	value, err := marshal(vol)
+	if err != nil {
+		return nil, err
+	}
+	rev := c.revision + 1
+	if err = c.commit(
+		change{Key: volumeKey(vol.GetId()), Entry: &entry{Value: value, Revision: rev}},
+		change{Key: transferKey(trID)},
+	); err != nil {
+		return nil, err
+	}
+	vol.SetResourceVersion(rev)
+	return vol, nil
+}
+
+func (c *client) GetQuota(projectID string) (*model.QuotaSpec, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.get(quotaKey(projectID)); !ok {
+		return &model.QuotaSpec{
+			BaseModel:    &model.BaseModel{Id: projectID},
+			ResourceList: map[string]int64{},
+		}, nil
+	}
+
+	var quota = &model.QuotaSpec{}
+	if err := c.loadLocked(quotaKey(projectID), quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+func (c *client) UpdateQuota(projectID string, quota *model.QuotaSpec) (*model.QuotaSpec, error) {
+	if quota.BaseModel == nil {
+		quota.BaseModel = &model.BaseModel{}
+	}
+	quota.Id = projectID
+	value, err := marshal(quota)
+	if err != nil {
+		return nil, err
+	}
+
+	// The quota is replaced as a whole, so it's checked only if the caller
+	// asks for a specific version.
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	rev, err := c.put(quotaKey(projectID), value, quota.GetResourceVersion(), 0)
+	if err != nil {
+		return nil, err
+	}
+	quota.SetResourceVersion(rev)
+	return quota, nil
+}
+
+func (c *client) ListQuotas() ([]*model.QuotaSpec, error) {
+	var quotas []*model.QuotaSpec
+	err := c.loadAll(quotaKey(""), func() model.Modeler {
+		quota := &model.QuotaSpec{}
+		quotas = append(quotas, quota)
+		return quota
+	})
+	if err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+func (c *client) CreateIdempotencyKey(key *model.IdempotencyKeySpec, ttl time.Duration) (*model.IdempotencyKeySpec, error) {
+	value, err := marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.get(idempotencyKey(key.GetId())); ok {
+		var existing = &model.IdempotencyKeySpec{}
+		if err = c.loadLocked(idempotencyKey(key.GetId()), existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	if _, err = c.put(idempotencyKey(key.GetId()), value, 0, time.Now().Add(ttl).Unix()); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *client) GetIdempotencyKey(key string) (*model.IdempotencyKeySpec, error) {
+	var res = &model.IdempotencyKeySpec{}
+	if err := c.load(idempotencyKey(key), res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *client) UpdateIdempotencyKey(key *model.IdempotencyKeySpec) error {
+	value, err := marshal(key)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	prev, ok := c.get(idempotencyKey(key.GetId()))
+	if !ok {
+		return fmt.Errorf("Idempotency key %s doesn't exist", key.GetId())
+	}
+	_, err = c.put(idempotencyKey(key.GetId()), value, 0, prev.Expiry)
+	return err
+}
+
+func (c *client) DeleteIdempotencyKey(key string) error {
+	return c.remove(idempotencyKey(key))
+}
+
+func (c *client) CreateEvent(evt *model.EventSpec) error {
+	value, err := marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	var expiry int64
+	if c.eventTTL > 0 {
+		expiry = time.Now().Add(c.eventTTL).Unix()
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, err = c.put(eventKey(evt.GetId()), value, 0, expiry)
+	return err
+}
+
+func (c *client) ListEvents(opts *model.ListOptions) ([]*model.EventSpec, error) {
+	var evts = []*model.EventSpec{}
+	err := c.loadAll(eventKey(""), func() model.Modeler {
+		evt := &model.EventSpec{}
+		evts = append(evts, evt)
+		return evt
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, err := utils.SelectItems(evts, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.EventSpec), nil
+}
@@ -0,0 +1,398 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opensds/opensds/pkg/db/migration"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+var sampleVolume = &model.VolumeSpec{
+	BaseModel: &model.BaseModel{Id: "bd5b12a8-a101-11e7-941e-d77981b584d8"},
+	Name:      "sample-volume",
+	Size:      1,
+	Status:    "available",
+}
+
+func newVolume() *model.VolumeSpec {
+	vol := *sampleVolume
+	base := *sampleVolume.BaseModel
+	vol.BaseModel = &base
+	return &vol
+}
+
+func TestUpdateVolumeWithVersion(t *testing.T) {
+	c, _ := open("")
+	if err := c.CreateVolume(newVolume()); err != nil {
+		t.Fatal(err)
+	}
+	vol, err := c.GetVolume(sampleVolume.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.Name != sampleVolume.Name || vol.GetResourceVersion() == 0 {
+		t.Errorf("Unexpected volume %+v", vol)
+	}
+
+	input := &model.VolumeSpec{BaseModel: &model.BaseModel{ResourceVersion: vol.GetResourceVersion()}, Name: "renamed"}
+	updated, err := c.UpdateVolume(vol.Id, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "renamed" || updated.GetResourceVersion() <= vol.GetResourceVersion() {
+		t.Errorf("Unexpected updated volume %+v", updated)
+	}
+	// The version read before the update is stale now.
+	if _, err = c.UpdateVolume(vol.Id, input); err != utils.ErrConflict {
+		t.Errorf("Expected conflict, got %v", err)
+	}
+
+	if err = c.DeleteVolume(vol.Id); err != nil {
+		t.Fatal(err)
+	}
+	if vols, _ := c.ListVolumes(nil); len(vols) != 0 {
+		t.Errorf("Expected no volume, got %d", len(vols))
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opensds-memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "opensds.db")
+
+	c, err := open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.CreateVolume(newVolume()); err != nil {
+		t.Fatal(err)
+	}
+	atc := &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: "f2dda3d2-bf79-11e7-8665-f750b088f63e"},
+		VolumeId:  sampleVolume.Id,
+	}
+	if err = c.CreateVolumeAttachment(sampleVolume.Id, atc); err != nil {
+		t.Fatal(err)
+	}
+	vol, _ := c.GetVolume(sampleVolume.Id)
+
+	c, err = open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := c.GetVolume(sampleVolume.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Name != vol.Name || reopened.GetResourceVersion() != vol.GetResourceVersion() {
+		t.Errorf("Expected %+v, got %+v", vol, reopened)
+	}
+	if atcs, _ := c.ListVolumeAttachments("", nil); len(atcs) != 1 || atcs[0].Id != atc.Id {
+		t.Errorf("Expected the attachment kept, got %+v", atcs)
+	}
+	if err = migration.CheckVersion(c); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPersistenceLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opensds-memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "opensds.db")
+
+	c, err := open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.CreateVolume(newVolume()); err != nil {
+		t.Fatal(err)
+	}
+	// The file is compacted once the records outnumber the entries.
+	for i := 0; i < minCompaction; i++ {
+		input := &model.VolumeSpec{BaseModel: &model.BaseModel{}, Name: fmt.Sprint("volume-", i)}
+		if _, err = c.UpdateVolume(sampleVolume.Id, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.records >= minCompaction {
+		t.Errorf("Expected the file compacted, got %d records", c.records)
+	}
+	vol, _ := c.GetVolume(sampleVolume.Id)
+
+	// A record broken by a crash while appending it is dropped.
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"key":"volumes/`)
+	f.Close()
+
+	c, err = open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := c.GetVolume(sampleVolume.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Name != vol.Name || reopened.GetResourceVersion() != vol.GetResourceVersion() {
+		t.Errorf("Expected %+v, got %+v", vol, reopened)
+	}
+	if err = c.DeleteVolume(sampleVolume.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetVolume(sampleVolume.Id); err == nil {
+		t.Error("Expected the volume deleted")
+	}
+}
+
+func TestPersistenceFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opensds-memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "opensds.db")
+
+	c, err := open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	vols, err := c.Watch(model.WatchResourceVolumes, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A change failed to be persisted leaves no trace.
+	rev := c.revision
+	c.log.Close()
+	if err = c.CreateVolume(newVolume()); err == nil {
+		t.Fatal("Expected the volume failed to be persisted")
+	}
+	if _, err = c.GetVolume(sampleVolume.Id); err == nil || c.revision != rev {
+		t.Errorf("Expected no volume in revision %d, got revision %d", rev, c.revision)
+	}
+	select {
+	case evt := <-vols:
+		t.Errorf("Expected no event, got %+v", evt)
+	default:
+	}
+
+	// The file is compacted along with the next change.
+	if err = c.CreateVolume(newVolume()); err != nil {
+		t.Fatal(err)
+	}
+	if evt := <-vols; evt.Type != model.WatchEventCreated {
+		t.Errorf("Expected volume created, got %+v", evt)
+	}
+	if c, err = open(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetVolume(sampleVolume.Id); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAcceptVolumeTransfer(t *testing.T) {
+	c, _ := open("")
+	c.CreateVolume(newVolume())
+	tr := &model.VolumeTransferSpec{
+		BaseModel: &model.BaseModel{Id: "3769855c-a102-11e7-b772-17b880d2f537"},
+		VolumeId:  sampleVolume.Id,
+	}
+	c.CreateVolumeTransfer(tr)
+
+	vol, err := c.AcceptVolumeTransfer(tr.Id, "project-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.ProjectId != "project-b" || vol.Status != model.VolumeAvailable {
+		t.Errorf("Unexpected accepted volume %+v", vol)
+	}
+	if _, err = c.GetVolumeTransfer(tr.Id); err == nil {
+		t.Error("Expected the transfer removed")
+	}
+	if _, err = c.AcceptVolumeTransfer(tr.Id, "project-c"); err == nil {
+		t.Error("Expected error for accepted transfer")
+	}
+}
+
+func TestCreateVolumeAttachmentConcurrently(t *testing.T) {
+	c, _ := open("")
+	c.CreateVolume(newVolume())
+
+	// Only one of the concurrent attachments is stored if the volume doesn't
+	// support multiattach.
+	var errs = make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		go func(i int) {
+			errs <- c.CreateVolumeAttachment(sampleVolume.Id, &model.VolumeAttachmentSpec{
+				BaseModel: &model.BaseModel{Id: fmt.Sprintf("atc-%d", i)},
+				VolumeId:  sampleVolume.Id,
+			})
+		}(i)
+	}
+	var failed int
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			if _, ok := err.(utils.MultiattachError); !ok {
+				t.Errorf("Unexpected error %v", err)
+			}
+			failed++
+		}
+	}
+	if failed != cap(errs)-1 {
+		t.Errorf("Expected one attachment stored, got %d failed", failed)
+	}
+	if atcs, _ := c.ListVolumeAttachments(sampleVolume.Id, nil); len(atcs) != 1 {
+		t.Errorf("Expected one attachment, got %+v", atcs)
+	}
+
+	vol := newVolume()
+	vol.Multiattach = true
+	c.CreateVolume(vol)
+	if err := c.CreateVolumeAttachment(vol.Id, &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: "atc-multiattach"},
+		VolumeId:  vol.Id,
+	}); err != nil {
+		t.Errorf("Expected multiattach volume attached again, got %v", err)
+	}
+}
+
+func TestIdempotencyKeyExpiry(t *testing.T) {
+	c, _ := open("")
+	key := &model.IdempotencyKeySpec{BaseModel: &model.BaseModel{Id: "c0ffee"}}
+
+	if existing, err := c.CreateIdempotencyKey(key, time.Hour); err != nil || existing != nil {
+		t.Fatalf("Expected key created, got %+v, %v", existing, err)
+	}
+	if existing, err := c.CreateIdempotencyKey(key, time.Hour); err != nil || existing == nil {
+		t.Errorf("Expected existing key returned, got %+v, %v", existing, err)
+	}
+
+	expired := &model.IdempotencyKeySpec{BaseModel: &model.BaseModel{Id: "deadbeef"}}
+	c.CreateIdempotencyKey(expired, -time.Second)
+	if _, err := c.GetIdempotencyKey(expired.Id); err == nil {
+		t.Error("Expected expired key to be gone")
+	}
+}
+
+func TestEventRetention(t *testing.T) {
+	c, _ := open("")
+	c.eventTTL = time.Hour
+	for _, evt := range []*model.EventSpec{
+		{BaseModel: &model.BaseModel{Id: "evt-1"}, ResourceType: "volume"},
+		{BaseModel: &model.BaseModel{Id: "evt-2"}, ResourceType: "profile"},
+		{BaseModel: &model.BaseModel{Id: "evt-3"}, ResourceType: "volume"},
+	} {
+		if err := c.CreateEvent(evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, _ := c.get(eventKey("evt-1"))
+	if e.Expiry <= time.Now().Unix() {
+		t.Errorf("Expected event expires in an hour, got %d", e.Expiry)
+	}
+
+	// The expired event is gone and the others are filtered by db.
+	c.entries[eventKey("evt-3")].Expiry = time.Now().Add(-time.Second).Unix()
+	evts, err := c.ListEvents(&model.ListOptions{
+		Filters: map[string]string{"resourceType": "volume"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evts) != 1 || evts[0].Id != "evt-1" {
+		t.Errorf("Expected event evt-1, got %+v", evts)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	c, _ := open("")
+	stop := make(chan struct{})
+	defer close(stop)
+
+	atcs, err := c.Watch(model.WatchResourceAttachments, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.CreateVolume(newVolume())
+	c.CreateVolumeSnapshot(&model.VolumeSnapshotSpec{BaseModel: &model.BaseModel{Id: "3bfaf2cc-a102-11e7-8ecb-63aea739d755"}})
+	c.CreateVolumeAttachment(sampleVolume.Id, &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: "f2dda3d2-bf79-11e7-8665-f750b088f63e"},
+	})
+	c.DeleteVolumeAttachment(sampleVolume.Id, "f2dda3d2-bf79-11e7-8665-f750b088f63e")
+
+	for _, typ := range []string{model.WatchEventCreated, model.WatchEventDeleted} {
+		evt := <-atcs
+		var atc model.VolumeAttachmentSpec
+		if err = json.Unmarshal(evt.Object, &atc); err != nil {
+			t.Fatal(err)
+		}
+		if evt.Type != typ || atc.Id != "f2dda3d2-bf79-11e7-8665-f750b088f63e" || atc.GetResourceVersion() == 0 {
+			t.Errorf("Expected attachment %s, got %s %+v", typ, evt.Type, atc)
+		}
+	}
+}
+
+func TestElect(t *testing.T) {
+	c, _ := open("")
+	stop := make(chan struct{})
+
+	_, resign, err := c.Elect("osdslet", "node-a", stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elected := make(chan struct{})
+	go func() {
+		if _, _, err := c.Elect("osdslet", "node-b", stop); err == nil {
+			close(elected)
+		}
+	}()
+
+	select {
+	case <-elected:
+		t.Fatal("Expected node-b to wait for node-a")
+	case <-time.After(50 * time.Millisecond):
+	}
+	resign()
+	select {
+	case <-elected:
+	case <-time.After(time.Second):
+		t.Fatal("Expected node-b to be elected after node-a resigned")
+	}
+}
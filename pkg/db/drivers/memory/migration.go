@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package memory
+
+import (
+	"encoding/json"
+
+	"github.com/opensds/opensds/pkg/db/migration"
+)
+
+var _ migration.Store = &client{}
+
+// schemaVersion is the record of the version of schema which the data is in.
+type schemaVersion struct {
+	Version int `json:"version"`
+}
+
+const schemaVersionKey = "schema_version"
+
+func (c *client) GetSchemaVersion() (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.get(schemaVersionKey)
+	if !ok {
+		return 0, nil
+	}
+	var ver schemaVersion
+	if err := json.Unmarshal([]byte(e.Value), &ver); err != nil {
+		return 0, err
+	}
+	return ver.Version, nil
+}
+
+func (c *client) SetSchemaVersion(version int) error {
+	body, err := json.Marshal(&schemaVersion{Version: version})
+	if err != nil {
+		return err
+	}
+	return c.PutKey(schemaVersionKey, string(body))
+}
+
+func (c *client) Scan(pfx string) (map[string]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var kvs = map[string]string{}
+	for _, key := range c.scan(pfx) {
+		kvs[key] = c.entries[key].Value
+	}
+	return kvs, nil
+}
+
+// PutKey keeps the expiry of the key if it exists.
+func (c *client) PutKey(key, value string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var expiry int64
+	if prev, ok := c.get(key); ok {
+		expiry = prev.Expiry
+	}
+	_, err := c.put(key, value, 0, expiry)
+	return err
+}
+
+func (c *client) DeleteKey(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.delete(key)
+}
@@ -0,0 +1,457 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements an embedded database which keeps the data in memory
+and persists it into a local file, so that OpenSDS could run on a single node
+without etcd. The keys and values are laid out as the ones of etcd driver.
+
+The file starts with a snapshot of all entries, which is followed by a record
+appended for every change. The file is rewritten as a new snapshot once the
+records outnumber the entries.
+
+*/
+
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+
+	"github.com/opensds/opensds/pkg/db/migration"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+)
+
+type entry struct {
+	Value string `json:"value"`
+	// Revision is the revision of the store when the entry was last put,
+	// which is the resource version of the resource stored in it.
+	Revision int64 `json:"revision"`
+	// Expiry is the unix time after which the entry is gone, zero means it
+	// never expires.
+	Expiry int64 `json:"expiry,omitempty"`
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return e.Expiry != 0 && now.Unix() >= e.Expiry
+}
+
+// dataFile is the snapshot the file the store is persisted into starts with.
+type dataFile struct {
+	Revision int64             `json:"revision"`
+	Entries  map[string]*entry `json:"entries"`
+}
+
+// change puts the entry of the key, or deletes the key if the entry is nil.
+type change struct {
+	Key   string `json:"key"`
+	Entry *entry `json:"entry,omitempty"`
+}
+
+// record is the changes made in one go, which are appended to the file as a
+// whole.
+type record struct {
+	Revision int64    `json:"revision"`
+	Changes  []change `json:"changes"`
+}
+
+// minCompaction is the number of records appended below which the file is
+// never compacted, so that a small store isn't rewritten all the time.
+const minCompaction = 1000
+
+// DefaultDataFile is the file the store is persisted into unless another one
+// is configured.
+const DefaultDataFile = "/var/lib/opensds/opensds.db"
+
+// Init opens the store persisted in file, the data is only kept in memory if
+// file is empty. A new store is recorded in the latest schema version. The
+// events stored expire after eventTTL, and zero keeps them forever.
+func Init(file string, eventTTL time.Duration) *client {
+	c, err := open(file)
+	if err != nil {
+		panic(err)
+	}
+	c.eventTTL = eventTTL
+	return c
+}
+
+func open(file string) (*client, error) {
+	c := &client{
+		file:     file,
+		entries:  map[string]*entry{},
+		watchers: map[*watcher]bool{},
+		leaders:  map[string]chan struct{}{},
+	}
+	if file != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadFile(file)
+		switch {
+		case os.IsNotExist(err):
+			// The snapshot is written before any record is appended.
+			c.failed = true
+		case err != nil:
+			return nil, err
+		default:
+			if err = c.replay(body); err != nil {
+				return nil, fmt.Errorf("Parse data file %s failed: %v", file, err)
+			}
+		}
+		if c.failed {
+			if err = c.compact(); err != nil {
+				return nil, err
+			}
+		} else if c.log, err = os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.entries) == 0 {
+		if err := c.SetSchemaVersion(migration.LatestVersion()); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// replay loads the snapshot and the records of the file body. A broken record
+// at the end, which is left by a crash while appending it, is dropped and the
+// file is compacted afterwards.
+func (c *client) replay(body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	var data dataFile
+	if err := dec.Decode(&data); err != nil {
+		return err
+	}
+	c.revision = data.Revision
+	for k, e := range data.Entries {
+		c.entries[k] = e
+	}
+	for {
+		var rec record
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Warningf("Drop the broken records of data file %s: %v", c.file, err)
+			c.failed = true
+			break
+		}
+		c.revision = rec.Revision
+		for _, ch := range rec.Changes {
+			if ch.Entry != nil {
+				c.entries[ch.Key] = ch.Entry
+			} else {
+				delete(c.entries, ch.Key)
+			}
+		}
+		c.records++
+	}
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if e.expired(now) {
+			delete(c.entries, k)
+		}
+	}
+	return nil
+}
+
+type client struct {
+	lock     sync.Mutex
+	file     string
+	revision int64
+	entries  map[string]*entry
+	watchers map[*watcher]bool
+	leaders  map[string]chan struct{}
+	eventTTL time.Duration
+
+	// log is the file opened for appending records, records is the number of
+	// records appended to it since the snapshot. The file is compacted along
+	// with the next change if failed is set.
+	log     *os.File
+	records int
+	failed  bool
+}
+
+// The operations below are called with the lock held.
+
+func (c *client) get(key string) (*entry, bool) {
+	e, ok := c.entries[key]
+	if !ok || e.expired(time.Now()) {
+		return nil, false
+	}
+	return e, true
+}
+
+// scan returns the keys with the prefix in order, as etcd does.
+func (c *client) scan(pfx string) []string {
+	var keys []string
+	now := time.Now()
+	for k, e := range c.entries {
+		if strings.HasPrefix(k, pfx) && !e.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// put stores the value of the key if the key is in the expected revision,
+// zero expects any revision. The new revision of the key is returned.
+func (c *client) put(key, value string, expected int64, expiry int64) (int64, error) {
+	prev, ok := c.get(key)
+	if expected != 0 && (!ok || prev.Revision != expected) {
+		return 0, utils.ErrConflict
+	}
+
+	rev := c.revision + 1
+	e := &entry{Value: value, Revision: rev, Expiry: expiry}
+	if err := c.commit(change{Key: key, Entry: e}); err != nil {
+		return 0, err
+	}
+	return rev, nil
+}
+
+func (c *client) delete(key string) error {
+	if _, ok := c.get(key); !ok {
+		return nil
+	}
+	return c.commit(change{Key: key})
+}
+
+// commit makes the changes in the next revision, the entries put by them are
+// expected to be in that revision. The changes are persisted before watchers
+// see them, and they're reverted if they fail to be persisted.
+func (c *client) commit(changes ...change) error {
+	prevs := make([]*entry, len(changes))
+	c.revision++
+	for i, ch := range changes {
+		prevs[i], _ = c.get(ch.Key)
+		if ch.Entry != nil {
+			c.entries[ch.Key] = ch.Entry
+		} else {
+			delete(c.entries, ch.Key)
+		}
+	}
+
+	if err := c.persist(&record{Revision: c.revision, Changes: changes}); err != nil {
+		for i := len(changes) - 1; i >= 0; i-- {
+			if prevs[i] != nil {
+				c.entries[changes[i].Key] = prevs[i]
+			} else {
+				delete(c.entries, changes[i].Key)
+			}
+		}
+		c.revision--
+		return err
+	}
+
+	for i, ch := range changes {
+		switch {
+		case ch.Entry == nil:
+			if prevs[i] != nil {
+				c.publish(ch.Key, model.WatchEventDeleted, prevs[i])
+			}
+		case prevs[i] != nil:
+			c.publish(ch.Key, model.WatchEventUpdated, ch.Entry)
+		default:
+			c.publish(ch.Key, model.WatchEventCreated, ch.Entry)
+		}
+	}
+	return nil
+}
+
+// persist appends the record to the data file, or compacts the file if the
+// records outnumber the entries or the last change failed to be persisted.
+func (c *client) persist(rec *record) error {
+	if c.file == "" {
+		return nil
+	}
+	if c.failed || (c.records >= minCompaction && c.records > len(c.entries)) {
+		return c.compact()
+	}
+
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err = c.log.Write(append(body, '\n')); err != nil {
+		c.failed = true
+		log.Error("When persist db into file:", err)
+		return err
+	}
+	c.records++
+	return nil
+}
+
+// compact writes all entries into the data file as a new snapshot, the file
+// is replaced as a whole so that it's never left half written.
+func (c *client) compact() error {
+	c.failed = true
+	now := time.Now()
+	for k, e := range c.entries {
+		if e.expired(now) {
+			delete(c.entries, k)
+		}
+	}
+
+	body, err := json.Marshal(&dataFile{Revision: c.revision, Entries: c.entries})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(c.file), filepath.Base(c.file)+".tmp")
+	if err != nil {
+		log.Error("When persist db into file:", err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(body); err != nil {
+		tmp.Close()
+		log.Error("When persist db into file:", err)
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		log.Error("When persist db into file:", err)
+		return err
+	}
+	if err = os.Rename(tmp.Name(), c.file); err != nil {
+		log.Error("When persist db into file:", err)
+		return err
+	}
+
+	if c.log != nil {
+		c.log.Close()
+	}
+	if c.log, err = os.OpenFile(c.file, os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		log.Error("When persist db into file:", err)
+		return err
+	}
+	c.records, c.failed = 0, false
+	return nil
+}
+
+// The helpers below store and load the resources, the resource version of a
+// resource is the revision of its entry.
+
+// marshal encodes the resource to be stored without its resource version.
+func marshal(m model.Modeler) (string, error) {
+	version := m.GetResourceVersion()
+	if version != 0 {
+		m.SetResourceVersion(0)
+		defer m.SetResourceVersion(version)
+	}
+	body, err := json.Marshal(m)
+	return string(body), err
+}
+
+func (c *client) create(key string, m model.Modeler) error {
+	value, err := marshal(m)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, err = c.put(key, value, 0, 0); err != nil {
+		log.Error("When create resource in db:", err)
+		return err
+	}
+	return nil
+}
+
+func (c *client) load(key string, m model.Modeler) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.loadLocked(key, m)
+}
+
+func (c *client) loadLocked(key string, m model.Modeler) error {
+	e, ok := c.get(key)
+	if !ok {
+		return fmt.Errorf("Can't find %s in db", key)
+	}
+	if err := json.Unmarshal([]byte(e.Value), m); err != nil {
+		log.Error("When parsing resource in db:", err)
+		return err
+	}
+	m.SetResourceVersion(e.Revision)
+	return nil
+}
+
+// loadAll decodes the resources with the prefix of key by newItem, which
+// returns the resource to decode into.
+func (c *client) loadAll(pfx string, newItem func() model.Modeler) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, key := range c.scan(pfx) {
+		if err := c.loadLocked(key, newItem()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update loads the resource of key into m, applies modify to it and stores it
+// back in one go. The conflict error is returned if the resource isn't in the
+// expected version, zero expects any version.
+func (c *client) update(key string, expected int64, m model.Modeler, modify func() error) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.loadLocked(key, m); err != nil {
+		return err
+	}
+	if expected != 0 && expected != m.GetResourceVersion() {
+		return utils.ErrConflict
+	}
+	if err := modify(); err != nil {
+		return err
+	}
+	value, err := marshal(m)
+	if err != nil {
+		return err
+	}
+	rev, err := c.put(key, value, 0, 0)
+	if err != nil {
+		log.Error("When update resource in db:", err)
+		return err
+	}
+	m.SetResourceVersion(rev)
+	return nil
+}
+
+func (c *client) remove(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.delete(key); err != nil {
+		log.Error("When delete resource in db:", err)
+		return err
+	}
+	return nil
+}
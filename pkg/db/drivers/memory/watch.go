@@ -0,0 +1,124 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// watchPrefixes maps the resources which could be watched to the prefix of
+// their keys, the same as etcd driver.
+var watchPrefixes = map[string]string{
+	model.WatchResourceVolumes:     volumeKey(""),
+	model.WatchResourceAttachments: "volume/",
+	model.WatchResourceSnapshots:   snapshotKey(""),
+	model.WatchResourceTransfers:   transferKey(""),
+}
+
+// watcher queues the events of the resource, so that the changes of store are
+// never blocked by the watchers which are slow to receive them.
+type watcher struct {
+	resource string
+	lock     sync.Mutex
+	queue    []*model.WatchEventSpec
+	notify   chan struct{}
+}
+
+func (c *client) Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error) {
+	if _, ok := watchPrefixes[resource]; !ok {
+		return nil, fmt.Errorf("resource %s can't be watched", resource)
+	}
+
+	w := &watcher{resource: resource, notify: make(chan struct{}, 1)}
+	c.lock.Lock()
+	c.watchers[w] = true
+	c.lock.Unlock()
+
+	events := make(chan *model.WatchEventSpec)
+	go func() {
+		defer close(events)
+		defer func() {
+			c.lock.Lock()
+			delete(c.watchers, w)
+			c.lock.Unlock()
+		}()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-w.notify:
+			}
+
+			w.lock.Lock()
+			queue := w.queue
+			w.queue = nil
+			w.lock.Unlock()
+			for _, evt := range queue {
+				select {
+				case events <- evt:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// publish queues the change of the key to the watchers of the resource it
+// belongs to, it's called with the lock of store held.
+func (c *client) publish(key, typ string, e *entry) {
+	var obj json.RawMessage
+	for w := range c.watchers {
+		if !strings.HasPrefix(key, watchPrefixes[w.resource]) {
+			continue
+		}
+		if w.resource == model.WatchResourceAttachments && !strings.Contains(key, "/attachments/") {
+			continue
+		}
+		if obj == nil {
+			obj = withResourceVersion(e.Value, e.Revision)
+		}
+
+		w.lock.Lock()
+		w.queue = append(w.queue, &model.WatchEventSpec{Type: typ, Resource: w.resource, Object: obj})
+		w.lock.Unlock()
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// withResourceVersion sets the resource version of the stored resource to the
+// revision of its entry, as the resource read from db does.
+func withResourceVersion(value string, version int64) json.RawMessage {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &obj); err != nil {
+		return json.RawMessage(value)
+	}
+	obj["resourceVersion"], _ = json.Marshal(version)
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return json.RawMessage(value)
+	}
+	return body
+}
@@ -16,11 +16,16 @@ package db
 
 import (
 	"errors"
+	"time"
 
 	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
 )
 
-type FakeDbClient struct{}
+type FakeDbClient struct {
+	// watchers receives the changes of resources stored by the client.
+	watchers broadcaster
+}
 
 func NewFakeDbClient() Client {
 	return &FakeDbClient{}
@@ -102,13 +107,17 @@ func (fc *FakeDbClient) GetProfile(prfID string) (*model.ProfileSpec, error) {
 	return nil, errors.New("Can't find this profile resource!")
 }
 
-func (fc *FakeDbClient) ListProfiles() ([]*model.ProfileSpec, error) {
+func (fc *FakeDbClient) ListProfiles(opts *model.ListOptions) ([]*model.ProfileSpec, error) {
 	var prfs []*model.ProfileSpec
 
 	for i := range sampleProfiles {
 		prfs = append(prfs, &sampleProfiles[i])
 	}
-	return prfs, nil
+	items, err := utils.SelectItems(prfs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.ProfileSpec), nil
 }
 
 func (fc *FakeDbClient) UpdateProfile(prfID string, input *model.ProfileSpec) (*model.ProfileSpec, error) {
@@ -134,6 +143,7 @@ func (fc *FakeDbClient) RemoveExtraProperty(prfID, extraKey string) error {
 }
 
 func (fc *FakeDbClient) CreateVolume(vol *model.VolumeSpec) error {
+	fc.watchers.publish(model.WatchResourceVolumes, model.WatchEventCreated, vol)
 	return nil
 }
 
@@ -141,18 +151,30 @@ func (fc *FakeDbClient) GetVolume(volID string) (*model.VolumeSpec, error) {
 	return &sampleVolumes[0], nil
 }
 
-func (fc *FakeDbClient) ListVolumes() ([]*model.VolumeSpec, error) {
+func (fc *FakeDbClient) ListVolumes(opts *model.ListOptions) ([]*model.VolumeSpec, error) {
 	var vols []*model.VolumeSpec
 
 	vols = append(vols, &sampleVolumes[0])
-	return vols, nil
+	items, err := utils.SelectItems(vols, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeSpec), nil
+}
+
+func (fc *FakeDbClient) UpdateVolume(volID string, input *model.VolumeSpec) (*model.VolumeSpec, error) {
+	fc.watchers.publish(model.WatchResourceVolumes, model.WatchEventUpdated, input)
+	return nil, nil
 }
 
 func (fc *FakeDbClient) DeleteVolume(volID string) error {
+	fc.watchers.publish(model.WatchResourceVolumes, model.WatchEventDeleted,
+		&model.VolumeSpec{BaseModel: &model.BaseModel{Id: volID}})
 	return nil
 }
 
 func (fc *FakeDbClient) CreateVolumeAttachment(volID string, atc *model.VolumeAttachmentSpec) error {
+	fc.watchers.publish(model.WatchResourceAttachments, model.WatchEventCreated, atc)
 	return nil
 }
 
@@ -160,22 +182,30 @@ func (fc *FakeDbClient) GetVolumeAttachment(volID, attachmentID string) (*model.
 	return &sampleAttachments[0], nil
 }
 
-func (fc *FakeDbClient) ListVolumeAttachments(volID string) ([]*model.VolumeAttachmentSpec, error) {
+func (fc *FakeDbClient) ListVolumeAttachments(volID string, opts *model.ListOptions) ([]*model.VolumeAttachmentSpec, error) {
 	var atcs []*model.VolumeAttachmentSpec
 
 	atcs = append(atcs, &sampleAttachments[0])
-	return atcs, nil
+	items, err := utils.SelectItems(atcs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeAttachmentSpec), nil
 }
 
-func (fc *FakeDbClient) UpdateVolumeAttachment(volID, attachmentID, mountpoint string, hostInfo *model.HostInfo) (*model.VolumeAttachmentSpec, error) {
+func (fc *FakeDbClient) UpdateVolumeAttachment(volID, attachmentID string, input *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+	fc.watchers.publish(model.WatchResourceAttachments, model.WatchEventUpdated, input)
 	return nil, nil
 }
 
 func (fc *FakeDbClient) DeleteVolumeAttachment(volID, attachmentID string) error {
+	fc.watchers.publish(model.WatchResourceAttachments, model.WatchEventDeleted,
+		&model.VolumeAttachmentSpec{BaseModel: &model.BaseModel{Id: attachmentID}, VolumeId: volID})
 	return nil
 }
 
 func (fc *FakeDbClient) CreateVolumeSnapshot(vs *model.VolumeSnapshotSpec) error {
+	fc.watchers.publish(model.WatchResourceSnapshots, model.WatchEventCreated, vs)
 	return nil
 }
 
@@ -183,17 +213,106 @@ func (fc *FakeDbClient) GetVolumeSnapshot(snapshotID string) (*model.VolumeSnaps
 	return &sampleSnapshots[0], nil
 }
 
-func (fc *FakeDbClient) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error) {
+func (fc *FakeDbClient) ListVolumeSnapshots(opts *model.ListOptions) ([]*model.VolumeSnapshotSpec, error) {
 	var snps []*model.VolumeSnapshotSpec
 
 	snps = append(snps, &sampleSnapshots[0], &sampleSnapshots[1])
-	return snps, nil
+	items, err := utils.SelectItems(snps, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.VolumeSnapshotSpec), nil
+}
+
+func (fc *FakeDbClient) UpdateVolumeSnapshot(snapshotID string, input *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error) {
+	fc.watchers.publish(model.WatchResourceSnapshots, model.WatchEventUpdated, input)
+	return nil, nil
 }
 
 func (fc *FakeDbClient) DeleteVolumeSnapshot(snapshotID string) error {
+	fc.watchers.publish(model.WatchResourceSnapshots, model.WatchEventDeleted,
+		&model.VolumeSnapshotSpec{BaseModel: &model.BaseModel{Id: snapshotID}})
+	return nil
+}
+
+func (fc *FakeDbClient) CreateVolumeTransfer(tr *model.VolumeTransferSpec) error {
+	fc.watchers.publish(model.WatchResourceTransfers, model.WatchEventCreated, tr)
+	return nil
+}
+
+func (fc *FakeDbClient) GetVolumeTransfer(trID string) (*model.VolumeTransferSpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) ListVolumeTransfers(opts *model.ListOptions) ([]*model.VolumeTransferSpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) DeleteVolumeTransfer(trID string) error {
+	fc.watchers.publish(model.WatchResourceTransfers, model.WatchEventDeleted,
+		&model.VolumeTransferSpec{BaseModel: &model.BaseModel{Id: trID}})
+	return nil
+}
+
+func (fc *FakeDbClient) AcceptVolumeTransfer(trID, projectID string) (*model.VolumeSpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) GetQuota(projectID string) (*model.QuotaSpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) UpdateQuota(projectID string, quota *model.QuotaSpec) (*model.QuotaSpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) ListQuotas() ([]*model.QuotaSpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) CreateIdempotencyKey(key *model.IdempotencyKeySpec, ttl time.Duration) (*model.IdempotencyKeySpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) GetIdempotencyKey(key string) (*model.IdempotencyKeySpec, error) {
+	return nil, nil
+}
+
+func (fc *FakeDbClient) UpdateIdempotencyKey(key *model.IdempotencyKeySpec) error {
+	return nil
+}
+
+func (fc *FakeDbClient) DeleteIdempotencyKey(key string) error {
+	return nil
+}
+
+func (fc *FakeDbClient) CreateEvent(evt *model.EventSpec) error {
 	return nil
 }
 
+func (fc *FakeDbClient) ListEvents(opts *model.ListOptions) ([]*model.EventSpec, error) {
+	var evts []*model.EventSpec
+
+	for i := range sampleEvents {
+		evts = append(evts, &sampleEvents[i])
+	}
+	items, err := utils.SelectItems(evts, opts)
+	if err != nil {
+		return nil, err
+	}
+	return items.([]*model.EventSpec), nil
+}
+
+func (fc *FakeDbClient) Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error) {
+	return fc.watchers.Watch(resource, stop)
+}
+
+// Elect makes every node the leader at once, since the fake client is only
+// used by a single node.
+func (fc *FakeDbClient) Elect(election, id string, stop <-chan struct{}) (<-chan struct{}, func() error, error) {
+	return make(chan struct{}), func() error { return nil }, nil
+}
+
 var (
 	sampleProfiles = []model.ProfileSpec{
 		{
@@ -241,9 +360,10 @@ var (
 			FreeCapacity:  int64(90),
 			DockId:        "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
 			Parameters: map[string]interface{}{
-				"diskType":  "SSD",
-				"iops":      1000,
-				"bandwidth": 1000,
+				"diskType":     "SSD",
+				"iops":         1000,
+				"bandwidth":    1000,
+				"qosSupported": true,
 			},
 		},
 		{
@@ -256,9 +376,10 @@ var (
 			FreeCapacity:  int64(170),
 			DockId:        "b7602e18-771e-11e7-8f38-dbd6d291f4e0",
 			Parameters: map[string]interface{}{
-				"diskType":  "SAS",
-				"iops":      800,
-				"bandwidth": 800,
+				"diskType":     "SAS",
+				"iops":         800,
+				"bandwidth":    800,
+				"qosSupported": true,
 			},
 		},
 	}
@@ -321,4 +442,19 @@ var (
 			VolumeId:    "bd5b12a8-a101-11e7-941e-d77981b584d8",
 		},
 	}
+
+	sampleEvents = []model.EventSpec{
+		{
+			BaseModel: &model.BaseModel{
+				Id:        "fd1e3cf2-b7e3-11e7-a2b8-2f8b0d3bc3c7",
+				CreatedAt: "2017-10-24T16:21:32",
+			},
+			Actor:        "127.0.0.1",
+			Source:       model.EventSourceController,
+			ResourceType: "volume",
+			ResourceId:   "bd5b12a8-a101-11e7-941e-d77981b584d8",
+			Action:       "create",
+			Outcome:      model.EventOutcomeSuccess,
+		},
+	}
 )
@@ -0,0 +1,217 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the migration of data in database between the
+versions of schema, which covers the key layout and the json shapes of
+models.
+
+*/
+
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Store is the raw access to the data in database used by migrations. The
+// keys are relative to the root of opensds data, such as volumes/<id>.
+type Store interface {
+	// GetSchemaVersion returns zero if the version has never been recorded.
+	GetSchemaVersion() (int, error)
+
+	SetSchemaVersion(version int) error
+
+	// Scan returns the values of all keys with the prefix.
+	Scan(prefix string) (map[string]string, error)
+
+	PutKey(key, value string) error
+
+	DeleteKey(key string) error
+}
+
+// Migration upgrades the data from the version before it to its version.
+type Migration struct {
+	Version     int
+	Description string
+	// Migrate must be idempotent, since it's run again if the migration is
+	// interrupted before the version is recorded.
+	Migrate func(s Store) error
+}
+
+// Migrations are all migrations in the order of their versions. A new one
+// must be appended with the next version whenever the key layout or the json
+// shape of models is changed.
+var Migrations = []*Migration{
+	{
+		Version:     1,
+		Description: "Record the initial schema",
+		Migrate:     func(Store) error { return nil },
+	},
+	{
+		Version:     2,
+		Description: "Remove the resource versions stored in resources",
+		Migrate:     removeStoredResourceVersions,
+	},
+}
+
+// LatestVersion returns the version of schema which this release works with.
+func LatestVersion() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// CheckVersion returns an error if the data isn't in the latest version.
+func CheckVersion(s Store) error {
+	current, err := s.GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current != LatestVersion() {
+		return fmt.Errorf("The schema version of database is %d but %d is expected, please run osds-manage db sync",
+			current, LatestVersion())
+	}
+	return nil
+}
+
+// The operations of changes made by migrations.
+const (
+	ChangePut    = "put"
+	ChangeDelete = "delete"
+)
+
+// Change is one change of a key made by a migration.
+type Change struct {
+	Op  string
+	Key string
+}
+
+// Step is the result of one migration.
+type Step struct {
+	Version     int
+	Description string
+	Changes     []Change
+}
+
+// Report tells which migrations are run and what they change.
+type Report struct {
+	From   int
+	To     int
+	DryRun bool
+	Steps  []*Step
+}
+
+// Sync migrates the data up to the target version, or the latest one if it's
+// zero. Nothing is written if dryRun is true, and the changes which would be
+// made are reported instead.
+func Sync(s Store, target int, dryRun bool) (*Report, error) {
+	current, err := s.GetSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	latest := LatestVersion()
+	if target == 0 {
+		target = latest
+	}
+	switch {
+	case current > latest:
+		return nil, fmt.Errorf("The schema version of database %d is newer than %d known by this release", current, latest)
+	case target > latest:
+		return nil, fmt.Errorf("Unknown schema version %d, the latest one is %d", target, latest)
+	case target < current:
+		return nil, fmt.Errorf("Can't downgrade the schema version from %d to %d", current, target)
+	}
+
+	var report = &Report{From: current, To: target, DryRun: dryRun}
+	var rec = &recorder{Store: s, dryRun: dryRun, pending: map[string]*string{}}
+	for _, m := range Migrations {
+		if m.Version <= current || m.Version > target {
+			continue
+		}
+		rec.changes = nil
+		if err := m.Migrate(rec); err != nil {
+			return report, fmt.Errorf("Migration %d failed: %v", m.Version, err)
+		}
+		report.Steps = append(report.Steps, &Step{
+			Version:     m.Version,
+			Description: m.Description,
+			Changes:     rec.changes,
+		})
+		if dryRun {
+			continue
+		}
+		if err := s.SetSchemaVersion(m.Version); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// recorder records the changes made by migrations, which are only applied to
+// the store if it isn't a dry run. In a dry run the changes are kept in memory
+// so that the later migrations see them.
+type recorder struct {
+	Store
+	dryRun  bool
+	pending map[string]*string
+	changes []Change
+}
+
+func (r *recorder) Scan(prefix string) (map[string]string, error) {
+	kvs, err := r.Store.Scan(prefix)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.pending {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if v == nil {
+			delete(kvs, k)
+		} else {
+			kvs[k] = *v
+		}
+	}
+	return kvs, nil
+}
+
+func (r *recorder) PutKey(key, value string) error {
+	r.changes = append(r.changes, Change{Op: ChangePut, Key: key})
+	if r.dryRun {
+		r.pending[key] = &value
+		return nil
+	}
+	return r.Store.PutKey(key, value)
+}
+
+func (r *recorder) DeleteKey(key string) error {
+	r.changes = append(r.changes, Change{Op: ChangeDelete, Key: key})
+	if r.dryRun {
+		r.pending[key] = nil
+		return nil
+	}
+	return r.Store.DeleteKey(key)
+}
+
+// sortedKeys returns the keys in order, so that migrations change them in
+// the same order every time.
+func sortedKeys(kvs map[string]string) []string {
+	var keys []string
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package migration
+
+import (
+	"strings"
+	"testing"
+)
+
+type fakeStore struct {
+	version int
+	kvs     map[string]string
+}
+
+func (s *fakeStore) GetSchemaVersion() (int, error) { return s.version, nil }
+
+func (s *fakeStore) SetSchemaVersion(version int) error {
+	s.version = version
+	return nil
+}
+
+func (s *fakeStore) Scan(prefix string) (map[string]string, error) {
+	var kvs = map[string]string{}
+	for k, v := range s.kvs {
+		if strings.HasPrefix(k, prefix) {
+			kvs[k] = v
+		}
+	}
+	return kvs, nil
+}
+
+func (s *fakeStore) PutKey(key, value string) error {
+	s.kvs[key] = value
+	return nil
+}
+
+func (s *fakeStore) DeleteKey(key string) error {
+	delete(s.kvs, key)
+	return nil
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{kvs: map[string]string{
+		"volumes/bd5b12a8-a101-11e7-941e-d77981b584d8":          `{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8","resourceVersion":7}`,
+		"volume/snapshots/3769855c-a102-11e7-b772-17b880d2f537": `{"id":"3769855c-a102-11e7-b772-17b880d2f537"}`,
+		"idempotency/c0ffee": `{"id":"c0ffee","resourceVersion":3}`,
+	}}
+}
+
+func TestSync(t *testing.T) {
+	s := newFakeStore()
+
+	report, err := Sync(s, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.From != 0 || report.To != LatestVersion() || len(report.Steps) != len(Migrations) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if s.version != LatestVersion() {
+		t.Errorf("Expected version %d, got %d", LatestVersion(), s.version)
+	}
+	if v := s.kvs["volumes/bd5b12a8-a101-11e7-941e-d77981b584d8"]; v != `{"id":"bd5b12a8-a101-11e7-941e-d77981b584d8"}` {
+		t.Errorf("Expected resource version removed, got %s", v)
+	}
+	if v := s.kvs["idempotency/c0ffee"]; !strings.Contains(v, "resourceVersion") {
+		t.Errorf("Expected idempotency key untouched, got %s", v)
+	}
+
+	// Running it again changes nothing.
+	report, err = Sync(s, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Steps) != 0 {
+		t.Errorf("Expected no migration, got %+v", report.Steps)
+	}
+	if err = CheckVersion(s); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSyncDryRun(t *testing.T) {
+	s := newFakeStore()
+	before := s.kvs["volumes/bd5b12a8-a101-11e7-941e-d77981b584d8"]
+
+	report, err := Sync(s, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.version != 0 || s.kvs["volumes/bd5b12a8-a101-11e7-941e-d77981b584d8"] != before {
+		t.Errorf("Expected nothing written in dry run, got version %d", s.version)
+	}
+	last := report.Steps[len(report.Steps)-1]
+	if len(last.Changes) != 1 || last.Changes[0] != (Change{Op: ChangePut, Key: "volumes/bd5b12a8-a101-11e7-941e-d77981b584d8"}) {
+		t.Errorf("Unexpected changes %+v", last.Changes)
+	}
+	if err = CheckVersion(s); err == nil {
+		t.Error("Expected version check to fail before migration")
+	}
+}
+
+func TestSyncVersions(t *testing.T) {
+	s := newFakeStore()
+
+	if _, err := Sync(s, 1, false); err != nil || s.version != 1 {
+		t.Fatalf("Expected version 1, got %d, %v", s.version, err)
+	}
+	if _, err := Sync(s, LatestVersion()+1, false); err == nil {
+		t.Error("Expected error for unknown version")
+	}
+	s.version = LatestVersion() + 1
+	if _, err := Sync(s, 0, false); err == nil {
+		t.Error("Expected error for newer version of database")
+	}
+}
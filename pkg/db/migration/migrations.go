@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package migration
+
+import (
+	"encoding/json"
+)
+
+// resourcePrefixes are the keys of the resources which embed the base model.
+var resourcePrefixes = []string{
+	"docks/", "pools/", "profiles/", "volumes/", "volume/", "transfers/", "quotas/",
+}
+
+// removeStoredResourceVersions removes the resource versions which were
+// stored along with resources. The version is the mod revision of the key,
+// so the stored one is always stale.
+func removeStoredResourceVersions(s Store) error {
+	for _, pfx := range resourcePrefixes {
+		kvs, err := s.Scan(pfx)
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(kvs) {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(kvs[key]), &obj); err != nil {
+				continue
+			}
+			if _, ok := obj["resourceVersion"]; !ok {
+				continue
+			}
+			delete(obj, "resourceVersion")
+			body, err := json.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			if err := s.PutKey(key, string(body)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -15,6 +15,8 @@
 package testing
 
 import (
+	"time"
+
 	"github.com/opensds/opensds/pkg/model"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +25,28 @@ type MockClient struct {
 	mock.Mock
 }
 
+func (_m *MockClient) AcceptVolumeTransfer(trID string, projectID string) (*model.VolumeSpec, error) {
+	ret := _m.Called(trID, projectID)
+
+	var r0 *model.VolumeSpec
+	if rf, ok := ret.Get(0).(func(string, string) *model.VolumeSpec); ok {
+		r0 = rf(trID, projectID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VolumeSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(trID, projectID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockClient) AddExtraProperty(prfID string, ext model.ExtraSpec) (*model.ExtraSpec, error) {
 	ret := _m.Called(prfID, ext)
 
@@ -58,6 +82,41 @@ func (_m *MockClient) CreateDock(dck *model.DockSpec) error {
 	return r0
 }
 
+func (_m *MockClient) CreateEvent(evt *model.EventSpec) error {
+	ret := _m.Called(evt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.EventSpec) error); ok {
+		r0 = rf(evt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+func (_m *MockClient) CreateIdempotencyKey(key *model.IdempotencyKeySpec, ttl time.Duration) (*model.IdempotencyKeySpec, error) {
+	ret := _m.Called(key, ttl)
+
+	var r0 *model.IdempotencyKeySpec
+	if rf, ok := ret.Get(0).(func(*model.IdempotencyKeySpec, time.Duration) *model.IdempotencyKeySpec); ok {
+		r0 = rf(key, ttl)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IdempotencyKeySpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.IdempotencyKeySpec, time.Duration) error); ok {
+		r1 = rf(key, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockClient) CreatePool(pol *model.StoragePoolSpec) error {
 	ret := _m.Called(pol)
 
@@ -123,6 +182,19 @@ func (_m *MockClient) CreateVolumeSnapshot(vs *model.VolumeSnapshotSpec) error {
 	return r0
 }
 
+func (_m *MockClient) CreateVolumeTransfer(tr *model.VolumeTransferSpec) error {
+	ret := _m.Called(tr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.VolumeTransferSpec) error); ok {
+		r0 = rf(tr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 func (_m *MockClient) DeleteDock(dckID string) error {
 	ret := _m.Called(dckID)
 
@@ -136,6 +208,19 @@ func (_m *MockClient) DeleteDock(dckID string) error {
 	return r0
 }
 
+func (_m *MockClient) DeleteIdempotencyKey(key string) error {
+	ret := _m.Called(key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 func (_m *MockClient) DeletePool(polID string) error {
 	ret := _m.Called(polID)
 
@@ -201,6 +286,19 @@ func (_m *MockClient) DeleteVolumeSnapshot(snapshotID string) error {
 	return r0
 }
 
+func (_m *MockClient) DeleteVolumeTransfer(trID string) error {
+	ret := _m.Called(trID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(trID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 func (_m *MockClient) GetDock(dckID string) (*model.DockSpec, error) {
 	ret := _m.Called(dckID)
 
@@ -223,6 +321,28 @@ func (_m *MockClient) GetDock(dckID string) (*model.DockSpec, error) {
 	return r0, r1
 }
 
+func (_m *MockClient) GetIdempotencyKey(key string) (*model.IdempotencyKeySpec, error) {
+	ret := _m.Called(key)
+
+	var r0 *model.IdempotencyKeySpec
+	if rf, ok := ret.Get(0).(func(string) *model.IdempotencyKeySpec); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IdempotencyKeySpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockClient) GetPool(polID string) (*model.StoragePoolSpec, error) {
 	ret := _m.Called(polID)
 
@@ -267,6 +387,28 @@ func (_m *MockClient) GetProfile(prfID string) (*model.ProfileSpec, error) {
 	return r0, r1
 }
 
+func (_m *MockClient) GetQuota(projectID string) (*model.QuotaSpec, error) {
+	ret := _m.Called(projectID)
+
+	var r0 *model.QuotaSpec
+	if rf, ok := ret.Get(0).(func(string) *model.QuotaSpec); ok {
+		r0 = rf(projectID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.QuotaSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(projectID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockClient) GetVolume(volID string) (*model.VolumeSpec, error) {
 	ret := _m.Called(volID)
 
@@ -333,6 +475,28 @@ func (_m *MockClient) GetVolumeSnapshot(snapshotID string) (*model.VolumeSnapsho
 	return r0, r1
 }
 
+func (_m *MockClient) GetVolumeTransfer(trID string) (*model.VolumeTransferSpec, error) {
+	ret := _m.Called(trID)
+
+	var r0 *model.VolumeTransferSpec
+	if rf, ok := ret.Get(0).(func(string) *model.VolumeTransferSpec); ok {
+		r0 = rf(trID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VolumeTransferSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(trID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockClient) ListDocks() ([]*model.DockSpec, error) {
 	ret := _m.Called()
 
@@ -355,6 +519,28 @@ func (_m *MockClient) ListDocks() ([]*model.DockSpec, error) {
 	return r0, r1
 }
 
+func (_m *MockClient) ListEvents(opts *model.ListOptions) ([]*model.EventSpec, error) {
+	ret := _m.Called(opts)
+
+	var r0 []*model.EventSpec
+	if rf, ok := ret.Get(0).(func(*model.ListOptions) []*model.EventSpec); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.EventSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.ListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockClient) ListExtraProperties(prfID string) (*model.ExtraSpec, error) {
 	ret := _m.Called(prfID)
 
@@ -399,18 +585,40 @@ func (_m *MockClient) ListPools() ([]*model.StoragePoolSpec, error) {
 	return r0, r1
 }
 
-func (_m *MockClient) ListProfiles() ([]*model.ProfileSpec, error) {
-	ret := _m.Called()
+func (_m *MockClient) ListProfiles(opts *model.ListOptions) ([]*model.ProfileSpec, error) {
+	ret := _m.Called(opts)
 
 	var r0 []*model.ProfileSpec
-	if rf, ok := ret.Get(0).(func() []*model.ProfileSpec); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*model.ListOptions) []*model.ProfileSpec); ok {
+		r0 = rf(opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.ProfileSpec)
 		}
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.ListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockClient) ListQuotas() ([]*model.QuotaSpec, error) {
+	ret := _m.Called()
+
+	var r0 []*model.QuotaSpec
+	if rf, ok := ret.Get(0).(func() []*model.QuotaSpec); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.QuotaSpec)
+		}
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func() error); ok {
 		r1 = rf()
@@ -421,12 +629,12 @@ func (_m *MockClient) ListProfiles() ([]*model.ProfileSpec, error) {
 	return r0, r1
 }
 
-func (_m *MockClient) ListVolumeAttachments(volID string) ([]*model.VolumeAttachmentSpec, error) {
-	ret := _m.Called(volID)
+func (_m *MockClient) ListVolumeAttachments(volID string, opts *model.ListOptions) ([]*model.VolumeAttachmentSpec, error) {
+	ret := _m.Called(volID, opts)
 
 	var r0 []*model.VolumeAttachmentSpec
-	if rf, ok := ret.Get(0).(func(string) []*model.VolumeAttachmentSpec); ok {
-		r0 = rf(volID)
+	if rf, ok := ret.Get(0).(func(string, *model.ListOptions) []*model.VolumeAttachmentSpec); ok {
+		r0 = rf(volID, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.VolumeAttachmentSpec)
@@ -434,8 +642,8 @@ func (_m *MockClient) ListVolumeAttachments(volID string) ([]*model.VolumeAttach
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(volID)
+	if rf, ok := ret.Get(1).(func(string, *model.ListOptions) error); ok {
+		r1 = rf(volID, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -443,12 +651,12 @@ func (_m *MockClient) ListVolumeAttachments(volID string) ([]*model.VolumeAttach
 	return r0, r1
 }
 
-func (_m *MockClient) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error) {
-	ret := _m.Called()
+func (_m *MockClient) ListVolumeSnapshots(opts *model.ListOptions) ([]*model.VolumeSnapshotSpec, error) {
+	ret := _m.Called(opts)
 
 	var r0 []*model.VolumeSnapshotSpec
-	if rf, ok := ret.Get(0).(func() []*model.VolumeSnapshotSpec); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*model.ListOptions) []*model.VolumeSnapshotSpec); ok {
+		r0 = rf(opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.VolumeSnapshotSpec)
@@ -456,8 +664,8 @@ func (_m *MockClient) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(*model.ListOptions) error); ok {
+		r1 = rf(opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -465,12 +673,34 @@ func (_m *MockClient) ListVolumeSnapshots() ([]*model.VolumeSnapshotSpec, error)
 	return r0, r1
 }
 
-func (_m *MockClient) ListVolumes() ([]*model.VolumeSpec, error) {
-	ret := _m.Called()
+func (_m *MockClient) ListVolumeTransfers(opts *model.ListOptions) ([]*model.VolumeTransferSpec, error) {
+	ret := _m.Called(opts)
+
+	var r0 []*model.VolumeTransferSpec
+	if rf, ok := ret.Get(0).(func(*model.ListOptions) []*model.VolumeTransferSpec); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.VolumeTransferSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.ListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockClient) ListVolumes(opts *model.ListOptions) ([]*model.VolumeSpec, error) {
+	ret := _m.Called(opts)
 
 	var r0 []*model.VolumeSpec
-	if rf, ok := ret.Get(0).(func() []*model.VolumeSpec); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*model.ListOptions) []*model.VolumeSpec); ok {
+		r0 = rf(opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.VolumeSpec)
@@ -478,8 +708,8 @@ func (_m *MockClient) ListVolumes() ([]*model.VolumeSpec, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(*model.ListOptions) error); ok {
+		r1 = rf(opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -522,6 +752,19 @@ func (_m *MockClient) UpdateDock(dckID string, name string, desp string) (*model
 	return r0, r1
 }
 
+func (_m *MockClient) UpdateIdempotencyKey(key *model.IdempotencyKeySpec) error {
+	ret := _m.Called(key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.IdempotencyKeySpec) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 func (_m *MockClient) UpdatePool(polID string, name string, desp string, usedCapacity int64, used bool) (*model.StoragePoolSpec, error) {
 	ret := _m.Called(polID, name, desp, usedCapacity, used)
 
@@ -566,12 +809,56 @@ func (_m *MockClient) UpdateProfile(prfID string, input *model.ProfileSpec) (*mo
 	return r0, r1
 }
 
-func (_m *MockClient) UpdateVolumeAttachment(volID string, attachmentID string, mountpoint string, hostInfo *model.HostInfo) (*model.VolumeAttachmentSpec, error) {
-	ret := _m.Called(volID, attachmentID, mountpoint, hostInfo)
+func (_m *MockClient) UpdateQuota(projectID string, quota *model.QuotaSpec) (*model.QuotaSpec, error) {
+	ret := _m.Called(projectID, quota)
+
+	var r0 *model.QuotaSpec
+	if rf, ok := ret.Get(0).(func(string, *model.QuotaSpec) *model.QuotaSpec); ok {
+		r0 = rf(projectID, quota)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.QuotaSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *model.QuotaSpec) error); ok {
+		r1 = rf(projectID, quota)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockClient) UpdateVolume(volID string, input *model.VolumeSpec) (*model.VolumeSpec, error) {
+	ret := _m.Called(volID, input)
+
+	var r0 *model.VolumeSpec
+	if rf, ok := ret.Get(0).(func(string, *model.VolumeSpec) *model.VolumeSpec); ok {
+		r0 = rf(volID, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VolumeSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *model.VolumeSpec) error); ok {
+		r1 = rf(volID, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockClient) UpdateVolumeAttachment(volID string, attachmentID string, input *model.VolumeAttachmentSpec) (*model.VolumeAttachmentSpec, error) {
+	ret := _m.Called(volID, attachmentID, input)
 
 	var r0 *model.VolumeAttachmentSpec
-	if rf, ok := ret.Get(0).(func(string, string, string, *model.HostInfo) *model.VolumeAttachmentSpec); ok {
-		r0 = rf(volID, attachmentID, mountpoint, hostInfo)
+	if rf, ok := ret.Get(0).(func(string, string, *model.VolumeAttachmentSpec) *model.VolumeAttachmentSpec); ok {
+		r0 = rf(volID, attachmentID, input)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.VolumeAttachmentSpec)
@@ -579,11 +866,82 @@ func (_m *MockClient) UpdateVolumeAttachment(volID string, attachmentID string,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string, string, *model.HostInfo) error); ok {
-		r1 = rf(volID, attachmentID, mountpoint, hostInfo)
+	if rf, ok := ret.Get(1).(func(string, string, *model.VolumeAttachmentSpec) error); ok {
+		r1 = rf(volID, attachmentID, input)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+func (_m *MockClient) UpdateVolumeSnapshot(snapshotID string, input *model.VolumeSnapshotSpec) (*model.VolumeSnapshotSpec, error) {
+	ret := _m.Called(snapshotID, input)
+
+	var r0 *model.VolumeSnapshotSpec
+	if rf, ok := ret.Get(0).(func(string, *model.VolumeSnapshotSpec) *model.VolumeSnapshotSpec); ok {
+		r0 = rf(snapshotID, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VolumeSnapshotSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *model.VolumeSnapshotSpec) error); ok {
+		r1 = rf(snapshotID, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockClient) Watch(resource string, stop <-chan struct{}) (<-chan *model.WatchEventSpec, error) {
+	ret := _m.Called(resource, stop)
+
+	var r0 <-chan *model.WatchEventSpec
+	if rf, ok := ret.Get(0).(func(string, <-chan struct{}) <-chan *model.WatchEventSpec); ok {
+		r0 = rf(resource, stop)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *model.WatchEventSpec)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, <-chan struct{}) error); ok {
+		r1 = rf(resource, stop)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockClient) Elect(election string, id string, stop <-chan struct{}) (<-chan struct{}, func() error, error) {
+	ret := _m.Called(election, id, stop)
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func(string, string, <-chan struct{}) <-chan struct{}); ok {
+		r0 = rf(election, id, stop)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	var r1 func() error
+	if ret.Get(1) != nil {
+		r1 = ret.Get(1).(func() error)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string, <-chan struct{}) error); ok {
+		r2 = rf(election, id, stop)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package dock
+
+import (
+	"os"
+	"sync"
+
+	log "github.com/golang/glog"
+	"github.com/opensds/opensds/contrib/drivers"
+	"github.com/opensds/opensds/pkg/utils/config"
+	"github.com/satori/go.uuid"
+)
+
+// backend keeps the volume driver of a backend for the lifetime of process,
+// which is shared by all the requests and reconcilers of the backend.
+type backend struct {
+	driver drivers.VolumeDriver
+	// slots limits the concurrent requests to the backend, it's nil if the
+	// requests are unlimited.
+	slots chan struct{}
+}
+
+var (
+	backendsLock sync.Mutex
+	backends     = map[string]*backend{}
+)
+
+// SetupBackends initializes the drivers of enabled backends keyed by their
+// names when the dock starts, they are kept until UnsetBackends is called on
+// shutdown. Every backend is served as a dock whose id is given by DockId.
+func SetupBackends(bes map[string]config.BackendProperties) error {
+	host, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	for name, b := range bes {
+		if _, err = setupBackend(DockId(host, name), b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DockId returns the id of the dock serving the named backend on the host, so
+// that the backends of the same driver are told apart.
+func DockId(host, backendName string) string {
+	return uuid.NewV5(uuid.NamespaceOID, host+":"+backendName).String()
+}
+
+// setupBackend returns the backend of the key, which is initialized with the
+// backend properties first if it hasn't been.
+func setupBackend(key string, b config.BackendProperties) (*backend, error) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	if be, ok := backends[key]; ok {
+		return be, nil
+	}
+	d, err := drivers.InitBackend(b)
+	if err != nil {
+		return nil, err
+	}
+	be := &backend{driver: d}
+	if b.MaxConcurrency > 0 {
+		be.slots = make(chan struct{}, b.MaxConcurrency)
+	}
+	backends[key] = be
+	return be, nil
+}
+
+// getBackend returns the backend serving the dock. The requests to the docks
+// which aren't set up when the dock starts are served by the backend of the
+// resource type, which is initialized with no limit.
+func getBackend(dockId, resourceType string) (*backend, error) {
+	backendsLock.Lock()
+	be, ok := backends[dockId]
+	backendsLock.Unlock()
+	if ok {
+		return be, nil
+	}
+	return setupBackend(resourceType, config.BackendProperties{Name: resourceType, DriverName: resourceType})
+}
+
+// UnsetBackends unsets the drivers of all backends when the dock shuts down.
+func UnsetBackends() {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	for key, be := range backends {
+		if err := be.driver.Unset(); err != nil {
+			log.Errorf("When unset driver of backend %s: %v\n", key, err)
+		}
+		delete(backends, key)
+	}
+}
+
+// acquire waits until the backend has a free slot for the request.
+func (be *backend) acquire() {
+	if be != nil && be.slots != nil {
+		be.slots <- struct{}{}
+	}
+}
+
+func (be *backend) release() {
+	if be != nil && be.slots != nil {
+		<-be.slots
+	}
+}
+
+// volumeLock is the lock of a volume, which is removed once it's neither
+// held nor waited for.
+type volumeLock struct {
+	sync.Mutex
+	refs int
+}
+
+var (
+	volumeLocksLock sync.Mutex
+	volumeLocks     = map[string]*volumeLock{}
+)
+
+// lockVolume serializes the operations on the same volume, it returns the
+// function to unlock the volume.
+func lockVolume(volID string) func() {
+	if volID == "" {
+		return func() {}
+	}
+
+	volumeLocksLock.Lock()
+	l, ok := volumeLocks[volID]
+	if !ok {
+		l = &volumeLock{}
+		volumeLocks[volID] = l
+	}
+	l.refs++
+	volumeLocksLock.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		volumeLocksLock.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(volumeLocks, volID)
+		}
+		volumeLocksLock.Unlock()
+	}
+}
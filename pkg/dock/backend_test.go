@@ -0,0 +1,161 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package dock
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensds/opensds/contrib/drivers/sample"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils/config"
+)
+
+func TestSetupBackends(t *testing.T) {
+	defer UnsetBackends()
+	err := SetupBackends(map[string]config.BackendProperties{
+		"sample-1": {Name: "sample", DriverName: "sample", MaxConcurrency: 2},
+		"sample-2": {Name: "sample", DriverName: "sample", MaxConcurrency: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+
+	// The driver is shared by all requests to the backend.
+	d1, d2 := NewDockHub(DockId(host, "sample-1"), "sample"), NewDockHub(DockId(host, "sample-1"), "sample")
+	d1.ListPools()
+	d2.ListPools()
+	if d1.Driver != d2.Driver || d1.backend != d2.backend {
+		t.Error("Expected driver of backend shared by requests")
+	}
+	if cap(d1.backend.slots) != 2 {
+		t.Errorf("Expected 2 concurrent requests, got %d", cap(d1.backend.slots))
+	}
+
+	// The requests are routed to the backends of the same driver by dock.
+	d3 := NewDockHub(DockId(host, "sample-2"), "sample")
+	d3.ListPools()
+	if d3.backend == d1.backend || cap(d3.backend.slots) != 4 {
+		t.Error("Expected requests routed to backend of dock")
+	}
+
+	// The requests to the docks not set up are served by the driver.
+	d4 := NewDockHub("not-exist", "sample")
+	d4.ListPools()
+	if d4.backend == d1.backend || d4.backend == d3.backend || d4.backend.slots != nil {
+		t.Error("Expected requests to unknown dock served by backend of driver")
+	}
+
+	// The requests to the docks of unknown drivers fail.
+	d5 := NewDockHub("not-exist", "ceph-ssd")
+	if _, err = d5.ListPools(); err == nil {
+		t.Error("Expected requests to unknown driver failed")
+	}
+
+	UnsetBackends()
+	if len(backends) != 0 {
+		t.Errorf("Expected no backend left, got %v", backends)
+	}
+
+	// The dock fails to start if any backend is of unknown driver.
+	err = SetupBackends(map[string]config.BackendProperties{
+		"ceph-ssd": {Name: "ceph-ssd", DriverName: "ceph-ssd"},
+	})
+	if err == nil {
+		t.Error("Expected backend of unknown driver failed to be set up")
+	}
+}
+
+// countingDriver records the maximum of concurrent calls to it.
+type countingDriver struct {
+	sample.Driver
+
+	mu            sync.Mutex
+	running, peak int
+}
+
+func (d *countingDriver) ListPools() ([]*model.StoragePoolSpec, error) {
+	d.mu.Lock()
+	if d.running++; d.running > d.peak {
+		d.peak = d.running
+	}
+	d.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	d.mu.Lock()
+	d.running--
+	d.mu.Unlock()
+	return nil, nil
+}
+
+func TestBackendConcurrency(t *testing.T) {
+	defer UnsetBackends()
+	cd := &countingDriver{}
+	backendsLock.Lock()
+	backends["limited"] = &backend{driver: cd, slots: make(chan struct{}, 2)}
+	backendsLock.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			NewDockHub("limited", "sample").ListPools()
+		}()
+	}
+	wg.Wait()
+
+	if cd.peak != 2 {
+		t.Errorf("Expected at most 2 concurrent calls, got %d", cd.peak)
+	}
+}
+
+func TestLockVolume(t *testing.T) {
+	var mu sync.Mutex
+	var running = map[string]int{}
+	var conflicts int
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		volID := []string{"vol1", "vol2"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer lockVolume(volID)()
+
+			mu.Lock()
+			if running[volID]++; running[volID] > 1 {
+				conflicts++
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running[volID]--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if conflicts != 0 {
+		t.Errorf("Expected operations on the same volume serialized, got %d conflicts", conflicts)
+	}
+	if len(volumeLocks) != 0 {
+		t.Errorf("Expected no volume lock left, got %v", volumeLocks)
+	}
+}
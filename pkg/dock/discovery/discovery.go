@@ -29,7 +29,6 @@ import (
 	. "github.com/opensds/opensds/pkg/utils/config"
 
 	log "github.com/golang/glog"
-	"github.com/satori/go.uuid"
 )
 
 type Discoverer interface {
@@ -52,29 +51,21 @@ func NewDiscover() Discoverer {
 }
 
 func (dd *DockDiscoverer) Init() error {
-	// Load resource from specified file
-	name2Backend := map[string]BackendProperties{
-		"ceph":   BackendProperties(CONF.Ceph),
-		"cinder": BackendProperties(CONF.Cinder),
-		"sample": BackendProperties(CONF.Sample),
-		"lvm":    BackendProperties(CONF.LVM),
-	}
-
 	host, err := os.Hostname()
 	if err != nil {
 		log.Error("When get os hostname:", err)
 		return err
 	}
 
-	for _, v := range CONF.EnableBackends {
-		b := name2Backend[v]
-		if b.Name == "" {
+	// Load resource from specified file, every backend is served as a dock.
+	for _, name := range CONF.EnableBackends {
+		b, ok := CONF.Backends[name]
+		if !ok {
 			continue
 		}
-
 		dck := &api.DockSpec{
 			BaseModel: &api.BaseModel{
-				Id: uuid.NewV5(uuid.NamespaceOID, host+":"+b.DriverName).String(),
+				Id: dockHub.DockId(host, name),
 			},
 			Name:        b.Name,
 			Description: b.Description,
@@ -91,7 +82,7 @@ func (dd *DockDiscoverer) Discovery() error {
 	var err error
 
 	for _, dck := range dd.dcks {
-		pols, err = dockHub.NewDockHub(dck.GetDriverName()).ListPools()
+		pols, err = dockHub.NewDockHub(dck.GetId(), dck.GetDriverName()).ListPools()
 		if err != nil {
 			log.Error("When list pools:", err)
 			return err
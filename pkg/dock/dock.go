@@ -21,97 +21,261 @@ storage plugins.
 package dock
 
 import (
+	"fmt"
+	"time"
+
 	log "github.com/golang/glog"
 
 	"github.com/opensds/opensds/contrib/drivers"
+	osdsctx "github.com/opensds/opensds/pkg/context"
 	"github.com/opensds/opensds/pkg/db"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	api "github.com/opensds/opensds/pkg/model"
 	"github.com/opensds/opensds/pkg/utils"
+	"golang.org/x/net/context"
 )
 
 // A reference to DockHub structure with fields that represent some required
 // parameters for initializing and controlling the volume driver.
 type DockHub struct {
+	// DockId represents the dock which the requests are sent to, and every
+	// backend is served as a dock. This field is used for routing the
+	// requests to the backend.
+	DockId string
 	// ResourceType represents the type of backend resources. This field is used
-	// for initializing the specified volume driver.
+	// for initializing the specified volume driver if the dock isn't set up.
 	ResourceType string
 
 	Driver drivers.VolumeDriver
+
+	// backend is the shared backend of the dock, which limits the concurrent
+	// requests to it.
+	backend *backend
 }
 
-func NewDockHub(resourceType string) *DockHub {
+func NewDockHub(dockId, resourceType string) *DockHub {
 	return &DockHub{
+		DockId:       dockId,
 		ResourceType: resourceType,
 	}
 }
 
-func (d *DockHub) CreateVolume(opt *pb.CreateVolumeOpts) (*api.VolumeSpec, error) {
+// begin initializes the volume driver of the dock unless the driver has been
+// specified, then waits until no one else operates the volume and the backend
+// has a free slot. It returns the function to end the request.
+func (d *DockHub) begin(volID string) (func(), error) {
+	if d.Driver == nil {
+		be, err := getBackend(d.DockId, d.ResourceType)
+		if err != nil {
+			log.Errorf("When initialize driver of dock %s: %v\n", d.DockId, err)
+			return nil, err
+		}
+		d.backend, d.Driver = be, be.driver
+	}
+
+	unlock := lockVolume(volID)
+	d.backend.acquire()
+	return func() {
+		d.backend.release()
+		unlock()
+	}, nil
+}
+
+func (d *DockHub) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*api.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin(opt.GetId())
+	if err != nil {
+		return nil, err
+	}
+	defer end()
 
-	log.Info("Calling volume driver to create volume...")
+	logger.Info("Calling volume driver to create volume...")
 
 	//Call function of StorageDrivers configured by storage drivers.
-	vol, err := d.Driver.CreateVolume(opt)
+	start := time.Now()
+	vol, err := d.Driver.CreateVolume(ctx, opt)
+	observeDriverCall(d.ResourceType, "create_volume", start, err)
 	if err != nil {
-		log.Error("When calling volume driver to create volume:", err)
+		logger.Error("When calling volume driver to create volume:", err)
 		return nil, err
 	}
 	vol.PoolId, vol.ProfileId = opt.GetPoolId(), opt.GetProfileId()
+	vol.Multiattach, vol.ProjectId = opt.GetMultiattach(), opt.GetProjectId()
 
 	// Validate the data.
 	if err = utils.ValidateData(vol, utils.S); err != nil {
-		log.Error("When validate volume data:", err)
+		logger.Error("When validate volume data:", err)
+		d.rollbackVolume(ctx, vol)
 		return nil, err
 	}
 
 	// Store the volume data into database.
 	if err = db.C.CreateVolume(vol); err != nil {
-		log.Error("When create volume in db module:", err)
+		logger.Error("When create volume in db module:", err)
+		d.rollbackVolume(ctx, vol)
 		return nil, err
 	}
 
 	return vol, nil
 }
 
-func (d *DockHub) DeleteVolume(opt *pb.DeleteVolumeOpts) error {
+// rollbackVolume deletes the volume created by driver when it fails to be
+// stored, so that no orphan is left in the backend.
+func (d *DockHub) rollbackVolume(ctx context.Context, vol *api.VolumeSpec) {
+	logger := osdsctx.GetLogger(ctx)
+
+	logger.Info("Calling volume driver to roll back created volume...")
+
+	start := time.Now()
+	err := d.Driver.DeleteVolume(ctx, &pb.DeleteVolumeOpts{
+		Id:       vol.GetId(),
+		Metadata: vol.GetMetadata(),
+	})
+	observeDriverCall(d.ResourceType, "delete_volume", start, err)
+	if err != nil {
+		logger.Errorf("When roll back volume %s, it's left in backend: %v\n", vol.GetId(), err)
+	}
+}
+
+// ManageVolume imports an existing volume of the backend into opensds, the
+// data of the volume is kept untouched.
+func (d *DockHub) ManageVolume(ctx context.Context, opt *pb.ManageVolumeOpts) (*api.VolumeSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	//Get the storage drivers and do some initializations, the volume is
+	//locked by its identifier so that it's managed only once.
+	end, err := d.begin(opt.GetIdentifier())
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	logger.Info("Calling volume driver to pull volume...")
+
+	start := time.Now()
+	vol, err := d.Driver.PullVolume(opt.GetIdentifier())
+	observeDriverCall(d.ResourceType, "pull_volume", start, err)
+	if err != nil {
+		logger.Error("When calling volume driver to pull volume:", err)
+		return nil, err
+	}
+	if vol.BaseModel == nil {
+		vol.BaseModel = &api.BaseModel{}
+	}
+	// The volume which is identified by the backend id could only be managed
+	// once.
+	if vol.GetId() != "" {
+		if _, err := db.C.GetVolume(vol.GetId()); err == nil {
+			return nil, fmt.Errorf("Volume %s has already been managed", vol.GetId())
+		}
+	}
+	// The volume which is identified by the metadata of driver, such as the
+	// path of lvm volume, could only be managed once either.
+	if vol.GetId() == "" && len(vol.GetMetadata()) != 0 {
+		vols, err := db.C.ListVolumes(&api.ListOptions{
+			Filters:  map[string]string{"poolId": opt.GetPoolId()},
+			Metadata: vol.GetMetadata(),
+		})
+		if err != nil {
+			logger.Error("When list volumes in db:", err)
+			return nil, err
+		}
+		if len(vols) != 0 {
+			return nil, fmt.Errorf("Volume %s has already been managed as %s", opt.GetIdentifier(), vols[0].GetId())
+		}
+	}
+
+	if opt.GetName() != "" {
+		vol.Name = opt.GetName()
+	}
+	if opt.GetDescription() != "" {
+		vol.Description = opt.GetDescription()
+	}
+	vol.PoolId, vol.ProfileId = opt.GetPoolId(), opt.GetProfileId()
+	vol.ProjectId, vol.Status = opt.GetProjectId(), api.VolumeAvailable
+	vol.Metadata = mergeMetadata(vol.GetMetadata(), opt.GetMetadata())
+
+	// Validate the data.
+	if err = utils.ValidateData(vol, utils.S); err != nil {
+		logger.Error("When validate volume data:", err)
+		return nil, err
+	}
+
+	// Store the volume data into database.
+	if err = db.C.CreateVolume(vol); err != nil {
+		logger.Error("When create volume in db module:", err)
+		return nil, err
+	}
+
+	return vol, nil
+}
+
+func (d *DockHub) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	var err error
 
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin(opt.GetId())
+	if err != nil {
+		return err
+	}
+	defer end()
 
-	log.Info("Calling volume driver to delete volume...")
+	logger.Info("Calling volume driver to delete volume...")
 
 	//Call function of StorageDrivers configured by storage drivers.
-	if err = d.Driver.DeleteVolume(opt); err != nil {
-		log.Error("When calling volume driver to delete volume:", err)
+	start := time.Now()
+	err = d.Driver.DeleteVolume(ctx, opt)
+	observeDriverCall(d.ResourceType, "delete_volume", start, err)
+	if err != nil {
+		logger.Error("When calling volume driver to delete volume:", err)
 		return err
 	}
 
 	if err = db.C.DeleteVolume(opt.GetId()); err != nil {
-		log.Error("Error occured in dock module when delete volume in db:", err)
+		logger.Error("Error occured in dock module when delete volume in db:", err)
 		return err
 	}
 
 	return nil
 }
 
-func (d *DockHub) CreateVolumeAttachment(opt *pb.CreateAttachmentOpts) (*api.VolumeAttachmentSpec, error) {
+func (d *DockHub) CreateVolumeAttachment(ctx context.Context, opt *pb.CreateAttachmentOpts) (*api.VolumeAttachmentSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin(opt.GetVolumeId())
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	// Pass the volume metadata recorded by the driver when creating volume,
+	// such as device path and qos limits, to the driver.
+	var atcMeta = opt.GetMetadata()
+	if vol, err := db.C.GetVolume(opt.GetVolumeId()); err != nil {
+		logger.Warning("When get volume in db module:", err)
+	} else {
+		opt.Metadata = mergeMetadata(vol.GetMetadata(), atcMeta)
+	}
 
-	log.Info("Calling volume driver to initialize volume connection...")
+	logger.Info("Calling volume driver to initialize volume connection...")
 
 	//Call function of StorageDrivers configured by storage drivers.
-	connInfo, err := d.Driver.InitializeConnection(opt)
+	start := time.Now()
+	connInfo, err := d.Driver.InitializeConnection(ctx, opt)
+	observeDriverCall(d.ResourceType, "initialize_connection", start, err)
 	if err != nil {
-		log.Error("Call driver to initialize volume connection failed:", err)
+		logger.Error("Call driver to initialize volume connection failed:", err)
 		return nil, err
 	}
 
 	var atc = &api.VolumeAttachmentSpec{
 		BaseModel: &api.BaseModel{},
+		VolumeId:  opt.GetVolumeId(),
 		HostInfo: &api.HostInfo{
 			Platform:  opt.HostInfo.GetPlatform(),
 			OsType:    opt.HostInfo.GetOsType(),
@@ -120,85 +284,362 @@ func (d *DockHub) CreateVolumeAttachment(opt *pb.CreateAttachmentOpts) (*api.Vol
 			Initiator: opt.HostInfo.GetInitiator(),
 		},
 		ConnectionInfo: connInfo,
-		Metadata:       opt.GetMetadata(),
+		Metadata:       atcMeta,
 	}
 
 	// Validate the data.
 	if err = utils.ValidateData(atc, utils.S); err != nil {
-		log.Error("When validate volume attachment data:", err)
+		logger.Error("When validate volume attachment data:", err)
+		d.rollbackConnection(ctx, atc, opt.GetMetadata())
 		return nil, err
 	}
 
 	if err = db.C.CreateVolumeAttachment(opt.GetVolumeId(), atc); err != nil {
-		log.Error("Error occured in dock module when create volume attachment in db:", err)
+		logger.Error("Error occured in dock module when create volume attachment in db:", err)
+		d.rollbackConnection(ctx, atc, opt.GetMetadata())
+		return nil, err
+	}
+
+	// The volume turns to be in use once it's attached.
+	if _, err = db.C.UpdateVolume(opt.GetVolumeId(), &api.VolumeSpec{Status: api.VolumeInUse}); err != nil {
+		logger.Error("When update volume status in db:", err)
+		if err := db.C.DeleteVolumeAttachment(opt.GetVolumeId(), atc.GetId()); err != nil {
+			logger.Error("When roll back volume attachment in db:", err)
+		}
+		d.rollbackConnection(ctx, atc, opt.GetMetadata())
 		return nil, err
 	}
 
 	return atc, nil
 }
 
-func (d *DockHub) DeleteVolumeAttachment(opt *pb.DeleteAttachmentOpts) error {
+// rollbackConnection terminates the connection initialized by driver when
+// the attachment fails to be stored.
+func (d *DockHub) rollbackConnection(ctx context.Context, atc *api.VolumeAttachmentSpec, meta map[string]string) {
+	logger := osdsctx.GetLogger(ctx)
+
+	logger.Info("Calling volume driver to roll back initialized connection...")
+
+	start := time.Now()
+	err := d.Driver.TerminateConnection(ctx, &pb.DeleteAttachmentOpts{
+		Id:       atc.GetId(),
+		VolumeId: atc.GetVolumeId(),
+		HostInfo: &pb.HostInfo{
+			Platform:  atc.HostInfo.GetPlatform(),
+			OsType:    atc.HostInfo.GetOsType(),
+			Ip:        atc.HostInfo.GetIp(),
+			Host:      atc.HostInfo.GetHost(),
+			Initiator: atc.HostInfo.GetInitiator(),
+		},
+		Metadata: meta,
+	})
+	observeDriverCall(d.ResourceType, "terminate_connection", start, err)
+	if err != nil {
+		logger.Errorf("When roll back connection of volume %s, it's left exported: %v\n", atc.GetVolumeId(), err)
+	}
+}
+
+func (d *DockHub) DeleteVolumeAttachment(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin(opt.GetVolumeId())
+	if err != nil {
+		return err
+	}
+	defer end()
 
-	log.Info("Calling volume driver to terminate volume connection...")
+	logger.Info("Calling volume driver to terminate volume connection...")
 
 	//Call function of StorageDrivers configured by storage drivers.
-	if err := d.Driver.TerminateConnection(opt); err != nil {
-		log.Error("Call driver to terminate volume connection failed:", err)
+	start := time.Now()
+	err = d.Driver.TerminateConnection(ctx, opt)
+	observeDriverCall(d.ResourceType, "terminate_connection", start, err)
+	if err != nil {
+		logger.Error("Call driver to terminate volume connection failed:", err)
 		return err
 	}
 
-	if err := db.C.DeleteVolumeAttachment(opt.GetVolumeId(), opt.GetId()); err != nil {
-		log.Error("Error occured in dock module when delete volume attachment in db:", err)
+	if err = db.C.DeleteVolumeAttachment(opt.GetVolumeId(), opt.GetId()); err != nil {
+		logger.Error("Error occured in dock module when delete volume attachment in db:", err)
 		return err
 	}
 
+	// The volume turns to be available again once the last attachment of it
+	// is deleted.
+	atcs, err := db.C.ListVolumeAttachments(opt.GetVolumeId(), nil)
+	if err != nil {
+		logger.Error("When list volume attachments in db:", err)
+		return err
+	}
+	if len(atcs) == 0 {
+		if _, err = db.C.UpdateVolume(opt.GetVolumeId(), &api.VolumeSpec{Status: api.VolumeAvailable}); err != nil {
+			logger.Error("When update volume status in db:", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (d *DockHub) CreateSnapshot(opt *pb.CreateVolumeSnapshotOpts) (*api.VolumeSnapshotSpec, error) {
+// UpdateVolumeAttachment records the mountpoint and host info reported by the
+// host, and re-exports the volume to the host if its initiator is changed, so
+// that the access control of the storage target could be updated.
+func (d *DockHub) UpdateVolumeAttachment(ctx context.Context, opt *pb.UpdateAttachmentOpts) (*api.VolumeAttachmentSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	//Get the storage drivers and do some initializations.
+	end, err := d.begin(opt.GetVolumeId())
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	atc, err := db.C.GetVolumeAttachment(opt.GetVolumeId(), opt.GetId())
+	if err != nil {
+		logger.Error("When get volume attachment in db:", err)
+		return nil, err
+	}
+
+	var input = &api.VolumeAttachmentSpec{
+		Mountpoint: opt.GetMountpoint(),
+		Metadata:   opt.GetMetadata(),
+	}
+	if host := opt.GetHostInfo(); host != nil {
+		input.HostInfo = &api.HostInfo{
+			Platform:  host.GetPlatform(),
+			OsType:    host.GetOsType(),
+			Ip:        host.GetIp(),
+			Host:      host.GetHost(),
+			Initiator: host.GetInitiator(),
+		}
+	}
+
+	// The condition is the same as the one the controller dispatches the
+	// request with, the volume exported to all initiators before is
+	// re-exported to the specified one as well.
+	if input.HostInfo != nil && input.GetInitiator() != "" &&
+		(atc.HostInfo == nil || input.GetInitiator() != atc.GetInitiator()) {
+		if input.ConnectionInfo, err = d.reexportVolume(ctx, atc, opt); err != nil {
+			return nil, err
+		}
+	}
+
+	return db.C.UpdateVolumeAttachment(opt.GetVolumeId(), opt.GetId(), input)
+}
+
+// reexportVolume initializes a new connection to the initiator specified in
+// opt and then terminates the connection to the old initiator of the
+// attachment, so that the old host keeps its access to the volume if the
+// volume fails to be re-exported.
+func (d *DockHub) reexportVolume(ctx context.Context, atc *api.VolumeAttachmentSpec, opt *pb.UpdateAttachmentOpts) (*api.ConnectionInfo, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	var meta = atc.GetMetadata()
+	if vol, err := db.C.GetVolume(opt.GetVolumeId()); err != nil {
+		logger.Warning("When get volume in db module:", err)
+	} else {
+		meta = mergeMetadata(vol.GetMetadata(), atc.GetMetadata())
+	}
+	// The volume is exported to all initiators if no host is recorded.
+	var oldHost = &pb.HostInfo{}
+	if atc.HostInfo != nil {
+		oldHost = &pb.HostInfo{
+			Platform:  atc.GetPlatform(),
+			OsType:    atc.GetOsType(),
+			Ip:        atc.GetIp(),
+			Host:      atc.GetHost(),
+			Initiator: atc.GetInitiator(),
+		}
+	}
+
+	logger.Info("Calling volume driver to re-export volume to the new initiator...")
+
+	start := time.Now()
+	connInfo, err := d.Driver.InitializeConnection(ctx, &pb.CreateAttachmentOpts{
+		Id:         atc.GetId(),
+		VolumeId:   opt.GetVolumeId(),
+		HostInfo:   opt.GetHostInfo(),
+		Metadata:   meta,
+		DockId:     opt.GetDockId(),
+		DriverName: opt.GetDriverName(),
+	})
+	observeDriverCall(d.ResourceType, "initialize_connection", start, err)
+	if err != nil {
+		logger.Error("Call driver to initialize volume connection failed:", err)
+		return nil, err
+	}
+
+	start = time.Now()
+	err = d.Driver.TerminateConnection(ctx, &pb.DeleteAttachmentOpts{
+		Id:         atc.GetId(),
+		VolumeId:   opt.GetVolumeId(),
+		HostInfo:   oldHost,
+		Metadata:   meta,
+		DockId:     opt.GetDockId(),
+		DriverName: opt.GetDriverName(),
+	})
+	observeDriverCall(d.ResourceType, "terminate_connection", start, err)
+	if err != nil {
+		logger.Error("Call driver to terminate volume connection failed:", err)
+		// Roll back the new connection, so that the volume is still exported
+		// to the old initiator only.
+		host := opt.GetHostInfo()
+		d.rollbackConnection(ctx, &api.VolumeAttachmentSpec{
+			BaseModel: &api.BaseModel{Id: atc.GetId()},
+			VolumeId:  opt.GetVolumeId(),
+			HostInfo: &api.HostInfo{
+				Platform:  host.GetPlatform(),
+				OsType:    host.GetOsType(),
+				Ip:        host.GetIp(),
+				Host:      host.GetHost(),
+				Initiator: host.GetInitiator(),
+			},
+		}, meta)
+		return nil, err
+	}
+
+	return connInfo, nil
+}
+
+func (d *DockHub) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*api.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin(opt.GetVolumeId())
+	if err != nil {
+		return nil, err
+	}
+	defer end()
 
-	log.Info("Calling volume driver to create snapshot...")
+	logger.Info("Calling volume driver to create snapshot...")
 
 	//Call function of StorageDrivers configured by storage drivers.
-	snp, err := d.Driver.CreateSnapshot(opt)
+	start := time.Now()
+	snp, err := d.Driver.CreateSnapshot(ctx, opt)
+	observeDriverCall(d.ResourceType, "create_snapshot", start, err)
 	if err != nil {
-		log.Error("Call driver to create volume snashot failed:", err)
+		logger.Error("Call driver to create volume snashot failed:", err)
 		return nil, err
 	}
 
 	// Validate the data.
 	if err = utils.ValidateData(snp, utils.S); err != nil {
-		log.Error("When validate volume snapshot data:", err)
+		logger.Error("When validate volume snapshot data:", err)
+		d.rollbackSnapshot(ctx, snp)
+		return nil, err
 	}
 
-	if err := db.C.CreateVolumeSnapshot(snp); err != nil {
-		log.Error("Error occured in dock module when create volume snapshot in db:", err)
+	if err = db.C.CreateVolumeSnapshot(snp); err != nil {
+		logger.Error("Error occured in dock module when create volume snapshot in db:", err)
+		d.rollbackSnapshot(ctx, snp)
 		return nil, err
 	}
 
 	return snp, nil
 }
 
-func (d *DockHub) DeleteSnapshot(opt *pb.DeleteVolumeSnapshotOpts) error {
+// rollbackSnapshot deletes the snapshot created by driver when it fails to
+// be stored.
+func (d *DockHub) rollbackSnapshot(ctx context.Context, snp *api.VolumeSnapshotSpec) {
+	logger := osdsctx.GetLogger(ctx)
+
+	logger.Info("Calling volume driver to roll back created snapshot...")
+
+	start := time.Now()
+	err := d.Driver.DeleteSnapshot(ctx, &pb.DeleteVolumeSnapshotOpts{
+		Id:       snp.GetId(),
+		VolumeId: snp.GetVolumeId(),
+		Metadata: snp.GetMetadata(),
+	})
+	observeDriverCall(d.ResourceType, "delete_snapshot", start, err)
+	if err != nil {
+		logger.Errorf("When roll back snapshot %s, it's left in backend: %v\n", snp.GetId(), err)
+	}
+}
+
+// ManageSnapshot imports an existing snapshot of the backend into opensds,
+// the volume of the snapshot should have been managed.
+func (d *DockHub) ManageSnapshot(ctx context.Context, opt *pb.ManageVolumeSnapshotOpts) (*api.VolumeSnapshotSpec, error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	//Get the storage drivers and do some initializations.
+	end, err := d.begin(opt.GetVolumeId())
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	logger.Info("Calling volume driver to pull snapshot...")
+
+	start := time.Now()
+	snp, err := d.Driver.PullSnapshot(opt.GetIdentifier())
+	observeDriverCall(d.ResourceType, "pull_snapshot", start, err)
+	if err != nil {
+		logger.Error("When calling volume driver to pull snapshot:", err)
+		return nil, err
+	}
+	if snp.BaseModel == nil {
+		snp.BaseModel = &api.BaseModel{}
+	}
+	if snp.GetVolumeId() != "" && snp.GetVolumeId() != opt.GetVolumeId() {
+		return nil, fmt.Errorf("Snapshot %s doesn't belong to volume %s",
+			opt.GetIdentifier(), opt.GetVolumeId())
+	}
+	if snp.GetId() != "" {
+		if _, err := db.C.GetVolumeSnapshot(snp.GetId()); err == nil {
+			return nil, fmt.Errorf("Snapshot %s has already been managed", snp.GetId())
+		}
+	}
+
+	if opt.GetName() != "" {
+		snp.Name = opt.GetName()
+	}
+	if opt.GetDescription() != "" {
+		snp.Description = opt.GetDescription()
+	}
+	snp.VolumeId, snp.Status = opt.GetVolumeId(), "available"
+	snp.Metadata = mergeMetadata(snp.GetMetadata(), opt.GetMetadata())
+
+	// Validate the data.
+	if err = utils.ValidateData(snp, utils.S); err != nil {
+		logger.Error("When validate volume snapshot data:", err)
+		return nil, err
+	}
+
+	if err = db.C.CreateVolumeSnapshot(snp); err != nil {
+		logger.Error("Error occured in dock module when create volume snapshot in db:", err)
+		return nil, err
+	}
+
+	return snp, nil
+}
+
+func (d *DockHub) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error {
+	logger := osdsctx.GetLogger(ctx)
+
 	var err error
 
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin(opt.GetVolumeId())
+	if err != nil {
+		return err
+	}
+	defer end()
 
-	log.Info("Calling volume driver to delete snapshot...")
+	logger.Info("Calling volume driver to delete snapshot...")
 
 	//Call function of StorageDrivers configured by storage drivers.
-	if err = d.Driver.DeleteSnapshot(opt); err != nil {
-		log.Error("When calling volume driver to delete volume:", err)
+	start := time.Now()
+	err = d.Driver.DeleteSnapshot(ctx, opt)
+	observeDriverCall(d.ResourceType, "delete_snapshot", start, err)
+	if err != nil {
+		logger.Error("When calling volume driver to delete volume:", err)
 		return err
 	}
 
 	if err = db.C.DeleteVolumeSnapshot(opt.GetId()); err != nil {
-		log.Error("Error occured in dock module when delete volume snapshot in db:", err)
+		logger.Error("Error occured in dock module when delete volume snapshot in db:", err)
 		return err
 	}
 
@@ -207,12 +648,18 @@ func (d *DockHub) DeleteSnapshot(opt *pb.DeleteVolumeSnapshotOpts) error {
 
 func (d *DockHub) ListPools() ([]*api.StoragePoolSpec, error) {
 	//Get the storage drivers and do some initializations.
-	d.Driver = drivers.Init(d.ResourceType)
+	end, err := d.begin("")
+	if err != nil {
+		return nil, err
+	}
+	defer end()
 
 	log.Info("Calling volume driver to list pools...")
 
 	//Call function of StorageDrivers configured by storage drivers.
+	start := time.Now()
 	pols, err := d.Driver.ListPools()
+	observeDriverCall(d.ResourceType, "list_pools", start, err)
 	if err != nil {
 		log.Error("Call driver to list pools failed:", err)
 		return nil, err
@@ -220,3 +667,15 @@ func (d *DockHub) ListPools() ([]*api.StoragePoolSpec, error) {
 
 	return pols, nil
 }
+
+// mergeMetadata merges the metadata maps in order, the latter one takes
+// precedence when keys conflict.
+func mergeMetadata(metas ...map[string]string) map[string]string {
+	var merged = make(map[string]string)
+	for _, meta := range metas {
+		for k, v := range meta {
+			merged[k] = v
+		}
+	}
+	return merged
+}
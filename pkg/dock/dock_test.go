@@ -15,17 +15,315 @@
 package dock
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	"github.com/opensds/opensds/contrib/drivers/sample"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
 )
 
 var (
-	fd = &DockHub{ResourceType: "default"}
+	fd = &DockHub{DockId: "b7602e18-771e-11e7-8f38-dbd6d291f4e0", ResourceType: "default"}
 )
 
 func TestNewDockHub(t *testing.T) {
-	result := NewDockHub("default")
+	result := NewDockHub("b7602e18-771e-11e7-8f38-dbd6d291f4e0", "default")
 	if !reflect.DeepEqual(result, fd) {
 		t.Errorf("Expected %v, got %v\n", fd, result)
 	}
 }
+
+// faultDriver injects the errors given in the test into driver calls and
+// records the calls which undo the created resources.
+type faultDriver struct {
+	sample.Driver
+
+	createErr, deleteErr, initErr error
+	// pulled is returned by PullVolume if it's set.
+	pulled *model.VolumeSpec
+
+	deletedVolumes   []string
+	deletedSnapshots []string
+	terminated       []string
+	// connections records the initialized and terminated connections in
+	// order, such as "initialize iqn.2017-10.io.opensds:host".
+	connections []string
+}
+
+// createSampleVolume creates a volume in the sample backend directly.
+func createSampleVolume(t *testing.T, d *faultDriver) *model.VolumeSpec {
+	vol, err := d.Driver.CreateVolume(context.Background(), &pb.CreateVolumeOpts{Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vol
+}
+
+func (d *faultDriver) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*model.VolumeSpec, error) {
+	if d.createErr != nil {
+		return nil, d.createErr
+	}
+	return d.Driver.CreateVolume(ctx, opt)
+}
+
+func (d *faultDriver) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) error {
+	d.deletedVolumes = append(d.deletedVolumes, opt.GetId())
+	return d.deleteErr
+}
+
+func (d *faultDriver) CreateSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*model.VolumeSnapshotSpec, error) {
+	if d.createErr != nil {
+		return nil, d.createErr
+	}
+	return d.Driver.CreateSnapshot(ctx, opt)
+}
+
+func (d *faultDriver) DeleteSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) error {
+	d.deletedSnapshots = append(d.deletedSnapshots, opt.GetId())
+	return d.deleteErr
+}
+
+func (d *faultDriver) PullVolume(volIdentifier string) (*model.VolumeSpec, error) {
+	if d.pulled != nil {
+		copied := *d.pulled
+		return &copied, nil
+	}
+	return d.Driver.PullVolume(volIdentifier)
+}
+
+func (d *faultDriver) InitializeConnection(ctx context.Context, opt *pb.CreateAttachmentOpts) (*model.ConnectionInfo, error) {
+	d.connections = append(d.connections, "initialize "+opt.GetHostInfo().GetInitiator())
+	if d.initErr != nil {
+		return nil, d.initErr
+	}
+	return d.Driver.InitializeConnection(ctx, opt)
+}
+
+func (d *faultDriver) TerminateConnection(ctx context.Context, opt *pb.DeleteAttachmentOpts) error {
+	d.terminated = append(d.terminated, opt.GetVolumeId())
+	d.connections = append(d.connections, "terminate "+opt.GetHostInfo().GetInitiator())
+	return d.deleteErr
+}
+
+func TestCreateVolumeRollback(t *testing.T) {
+	var dbErr = errors.New("db is unavailable")
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("CreateVolume", mock.Anything).Return(dbErr)
+	db.C = mockClient
+
+	// The volume is deleted in backend even if the rollback itself fails,
+	// and the error of db is returned.
+	for volID, deleteErr := range map[string]error{
+		"bd5b12a8-a101-11e7-941e-d77981b584d8": nil,
+		"e3d8a4c6-a101-11e7-8bb6-1f2b3d1e5f6a": errors.New("backend is busy"),
+	} {
+		fd := &faultDriver{deleteErr: deleteErr}
+		d := &DockHub{ResourceType: "sample", Driver: fd}
+
+		opt := &pb.CreateVolumeOpts{Id: volID, Size: 1}
+		if _, err := d.CreateVolume(context.Background(), opt); err != dbErr {
+			t.Errorf("Expected %v, got %v", dbErr, err)
+		}
+		if len(fd.deletedVolumes) != 1 || fd.deletedVolumes[0] != volID {
+			t.Errorf("Expected created volume rolled back, got %v", fd.deletedVolumes)
+		}
+	}
+}
+
+func TestCreateVolumeDriverFailed(t *testing.T) {
+	// No db call is expected if the driver fails.
+	db.C = new(dbtest.MockClient)
+
+	fd := &faultDriver{createErr: errors.New("no space left")}
+	d := &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.CreateVolume(context.Background(), &pb.CreateVolumeOpts{}); err == nil {
+		t.Error("Expected create volume failed")
+	}
+	if len(fd.deletedVolumes) != 0 {
+		t.Errorf("Expected nothing rolled back, got %v", fd.deletedVolumes)
+	}
+}
+
+func TestCreateSnapshotRollback(t *testing.T) {
+	var dbErr = errors.New("db is unavailable")
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("CreateVolumeSnapshot", mock.Anything).Return(dbErr)
+	db.C = mockClient
+
+	fd := &faultDriver{}
+	vol := createSampleVolume(t, fd)
+	d := &DockHub{ResourceType: "sample", Driver: fd}
+	opt := &pb.CreateVolumeSnapshotOpts{Id: "3769855c-a102-11e7-b772-17b880d2f537", VolumeId: vol.Id}
+	if _, err := d.CreateSnapshot(context.Background(), opt); err != dbErr {
+		t.Errorf("Expected %v, got %v", dbErr, err)
+	}
+	if len(fd.deletedSnapshots) != 1 || fd.deletedSnapshots[0] != "3769855c-a102-11e7-b772-17b880d2f537" {
+		t.Errorf("Expected created snapshot rolled back, got %v", fd.deletedSnapshots)
+	}
+}
+
+func TestCreateVolumeAttachmentRollback(t *testing.T) {
+	var volID = createSampleVolume(t, &faultDriver{}).Id
+	var opt = &pb.CreateAttachmentOpts{
+		VolumeId: volID,
+		HostInfo: &pb.HostInfo{Host: "localhost", Initiator: "iqn.2017-10.io.opensds:host"},
+	}
+	var dbErr = errors.New("db is unavailable")
+
+	// The connection is terminated if the attachment fails to be stored.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetVolume", volID).Return(&model.VolumeSpec{BaseModel: &model.BaseModel{Id: volID}}, nil)
+	mockClient.On("CreateVolumeAttachment", volID, mock.Anything).Return(dbErr)
+	db.C = mockClient
+
+	fd := &faultDriver{}
+	d := &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.CreateVolumeAttachment(context.Background(), opt); err != dbErr {
+		t.Errorf("Expected %v, got %v", dbErr, err)
+	}
+	if len(fd.terminated) != 1 || fd.terminated[0] != volID {
+		t.Errorf("Expected initialized connection rolled back, got %v", fd.terminated)
+	}
+
+	// The stored attachment is removed as well if the volume status fails
+	// to be updated.
+	mockClient = new(dbtest.MockClient)
+	mockClient.On("GetVolume", volID).Return(&model.VolumeSpec{BaseModel: &model.BaseModel{Id: volID}}, nil)
+	mockClient.On("CreateVolumeAttachment", volID, mock.Anything).Return(nil)
+	mockClient.On("UpdateVolume", volID, mock.Anything).Return(nil, dbErr)
+	mockClient.On("DeleteVolumeAttachment", volID, mock.Anything).Return(nil)
+	db.C = mockClient
+
+	fd = &faultDriver{}
+	d = &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.CreateVolumeAttachment(context.Background(), opt); err != dbErr {
+		t.Errorf("Expected %v, got %v", dbErr, err)
+	}
+	if len(fd.terminated) != 1 {
+		t.Errorf("Expected initialized connection rolled back, got %v", fd.terminated)
+	}
+	mockClient.AssertCalled(t, "DeleteVolumeAttachment", volID, mock.Anything)
+}
+
+func TestManageVolumeOnce(t *testing.T) {
+	var lvPath = "/dev/vg001/volume-001"
+	fd := &faultDriver{pulled: &model.VolumeSpec{
+		BaseModel: &model.BaseModel{},
+		Size:      1,
+		Metadata:  map[string]string{"lvPath": lvPath},
+	}}
+	d := &DockHub{ResourceType: "sample", Driver: fd}
+	opt := &pb.ManageVolumeOpts{Identifier: lvPath, PoolId: "pool-001"}
+
+	// The volume identified by the metadata of driver is rejected if it has
+	// been managed in the same pool.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{
+		{BaseModel: &model.BaseModel{Id: "vol-001"}},
+	}, nil)
+	db.C = mockClient
+	if _, err := d.ManageVolume(context.Background(), opt); err == nil {
+		t.Error("Expected managing a volume twice failed")
+	}
+	mockClient.AssertNotCalled(t, "CreateVolume", mock.Anything)
+	listOpts := mockClient.Calls[0].Arguments.Get(0).(*model.ListOptions)
+	if listOpts.Filters["poolId"] != "pool-001" || listOpts.Metadata["lvPath"] != lvPath {
+		t.Errorf("Unexpected list options %v", listOpts)
+	}
+
+	mockClient = new(dbtest.MockClient)
+	mockClient.On("ListVolumes", mock.Anything).Return([]*model.VolumeSpec{}, nil)
+	mockClient.On("CreateVolume", mock.Anything).Return(nil)
+	db.C = mockClient
+	vol, err := d.ManageVolume(context.Background(), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol.Metadata["lvPath"] != lvPath {
+		t.Errorf("Expected lvPath %s kept in metadata, got %v", lvPath, vol.Metadata)
+	}
+}
+
+func TestUpdateVolumeAttachmentReexport(t *testing.T) {
+	var volID = createSampleVolume(t, &faultDriver{}).Id
+	var atc = &model.VolumeAttachmentSpec{
+		BaseModel: &model.BaseModel{Id: "atc-001"},
+		VolumeId:  volID,
+		HostInfo:  &model.HostInfo{Host: "host-1", Initiator: "iqn.2017-10.io.opensds:host-1"},
+	}
+	var opt = &pb.UpdateAttachmentOpts{
+		Id:       atc.Id,
+		VolumeId: volID,
+		HostInfo: &pb.HostInfo{Host: "host-2", Initiator: "iqn.2017-10.io.opensds:host-2"},
+	}
+	var drvErr = errors.New("driver is unavailable")
+
+	newMockClient := func() *dbtest.MockClient {
+		mockClient := new(dbtest.MockClient)
+		mockClient.On("GetVolumeAttachment", volID, atc.Id).Return(atc, nil)
+		mockClient.On("GetVolume", volID).Return(&model.VolumeSpec{BaseModel: &model.BaseModel{Id: volID}}, nil)
+		mockClient.On("UpdateVolumeAttachment", volID, atc.Id, mock.Anything).Return(atc, nil)
+		return mockClient
+	}
+
+	// The old connection is terminated only after the new one is initialized.
+	db.C = newMockClient()
+	fd := &faultDriver{}
+	d := &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.UpdateVolumeAttachment(context.Background(), opt); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"initialize iqn.2017-10.io.opensds:host-2",
+		"terminate iqn.2017-10.io.opensds:host-1",
+	}
+	if !reflect.DeepEqual(fd.connections, expected) {
+		t.Errorf("Expected %v, got %v", expected, fd.connections)
+	}
+
+	// The old connection is kept if the new one fails to be initialized.
+	mockClient := newMockClient()
+	db.C = mockClient
+	fd = &faultDriver{initErr: drvErr}
+	d = &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.UpdateVolumeAttachment(context.Background(), opt); err != drvErr {
+		t.Errorf("Expected %v, got %v", drvErr, err)
+	}
+	if len(fd.terminated) != 0 {
+		t.Errorf("Expected old connection kept, got %v", fd.connections)
+	}
+	mockClient.AssertNotCalled(t, "UpdateVolumeAttachment", volID, atc.Id, mock.Anything)
+
+	// The new connection is rolled back if the old one fails to be
+	// terminated.
+	db.C = newMockClient()
+	fd = &faultDriver{deleteErr: drvErr}
+	d = &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.UpdateVolumeAttachment(context.Background(), opt); err != drvErr {
+		t.Errorf("Expected %v, got %v", drvErr, err)
+	}
+	expected = append(expected, "terminate iqn.2017-10.io.opensds:host-2")
+	if !reflect.DeepEqual(fd.connections, expected) {
+		t.Errorf("Expected %v, got %v", expected, fd.connections)
+	}
+
+	// The volume exported to all initiators is re-exported to the specified
+	// one, as the controller dispatches it.
+	db.C = newMockClient()
+	atc.HostInfo = nil
+	fd = &faultDriver{}
+	d = &DockHub{ResourceType: "sample", Driver: fd}
+	if _, err := d.UpdateVolumeAttachment(context.Background(), opt); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"initialize iqn.2017-10.io.opensds:host-2", "terminate "}
+	if !reflect.DeepEqual(fd.connections, expected) {
+		t.Errorf("Expected %v, got %v", expected, fd.connections)
+	}
+}
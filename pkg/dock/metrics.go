@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the metrics of volume driver calls made by dock:
+
+  opensds_driver_call_duration_seconds{driver,operation}
+      histogram of the latencies of driver calls.
+  opensds_driver_call_errors_total{driver,operation}
+      counter of the driver calls which return error.
+
+*/
+
+package dock
+
+import (
+	"time"
+
+	"github.com/opensds/opensds/pkg/utils/metrics"
+)
+
+var (
+	driverCallDuration = metrics.NewHistogramVec(
+		"opensds_driver_call_duration_seconds",
+		"Latency of volume driver calls in seconds by driver and operation.",
+		metrics.DefBuckets, "driver", "operation",
+	)
+	driverCallErrors = metrics.NewCounterVec(
+		"opensds_driver_call_errors_total",
+		"Total number of failed volume driver calls by driver and operation.",
+		"driver", "operation",
+	)
+)
+
+func init() {
+	metrics.MustRegister(driverCallDuration, driverCallErrors)
+}
+
+// observeDriverCall records the latency and result of a driver call which
+// started at the given time.
+func observeDriverCall(driver, operation string, start time.Time, err error) {
+	driverCallDuration.Observe(time.Since(start).Seconds(), driver, operation)
+	if err != nil {
+		driverCallErrors.Inc(driver, operation)
+	}
+}
@@ -9,12 +9,18 @@ It is generated from these files:
 
 It has these top-level messages:
 	CreateVolumeOpts
+	Qos
 	DeleteVolumeOpts
+	ManageVolumeOpts
 	CreateVolumeSnapshotOpts
 	DeleteVolumeSnapshotOpts
+	ManageVolumeSnapshotOpts
 	CreateAttachmentOpts
 	DeleteAttachmentOpts
+	UpdateAttachmentOpts
 	HostInfo
+	DriverOpts
+	PullOpts
 	GenericResponse
 */
 package proto
@@ -66,6 +72,12 @@ type CreateVolumeOpts struct {
 	DockId string `protobuf:"bytes,11,opt,name=dockId" json:"dockId,omitempty"`
 	// The storage driver type.
 	DriverName string `protobuf:"bytes,12,opt,name=driverName" json:"driverName,omitempty"`
+	// The qos limits resolved from the profile, optional.
+	Qos *Qos `protobuf:"bytes,13,opt,name=qos" json:"qos,omitempty"`
+	// Whether the volume could be attached to multiple hosts, optional.
+	Multiattach bool `protobuf:"varint,14,opt,name=multiattach" json:"multiattach,omitempty"`
+	// The project which owns the volume, optional.
+	ProjectId string `protobuf:"bytes,15,opt,name=projectId" json:"projectId,omitempty"`
 }
 
 func (m *CreateVolumeOpts) Reset()                    { *m = CreateVolumeOpts{} }
@@ -157,6 +169,55 @@ func (m *CreateVolumeOpts) GetDriverName() string {
 	return ""
 }
 
+func (m *CreateVolumeOpts) GetQos() *Qos {
+	if m != nil {
+		return m.Qos
+	}
+	return nil
+}
+
+func (m *CreateVolumeOpts) GetMultiattach() bool {
+	if m != nil {
+		return m.Multiattach
+	}
+	return false
+}
+
+func (m *CreateVolumeOpts) GetProjectId() string {
+	if m != nil {
+		return m.ProjectId
+	}
+	return ""
+}
+
+// Qos is a structure which indicates the qos limits that the storage
+// backend is expected to enforce on a volume. Zero value means unlimited.
+type Qos struct {
+	// The maximum io operations per second of the volume.
+	MaxIOPS int64 `protobuf:"varint,1,opt,name=maxIOPS" json:"maxIOPS,omitempty"`
+	// The maximum throughput of the volume in MB/s.
+	MaxBandwidth int64 `protobuf:"varint,2,opt,name=maxBandwidth" json:"maxBandwidth,omitempty"`
+}
+
+func (m *Qos) Reset()                    { *m = Qos{} }
+func (m *Qos) String() string            { return proto1.CompactTextString(m) }
+func (*Qos) ProtoMessage()               {}
+func (*Qos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Qos) GetMaxIOPS() int64 {
+	if m != nil {
+		return m.MaxIOPS
+	}
+	return 0
+}
+
+func (m *Qos) GetMaxBandwidth() int64 {
+	if m != nil {
+		return m.MaxBandwidth
+	}
+	return 0
+}
+
 // DeleteVolumeOpts is a structure which indicates all required properties
 // for deleting a volume.
 type DeleteVolumeOpts struct {
@@ -173,7 +234,7 @@ type DeleteVolumeOpts struct {
 func (m *DeleteVolumeOpts) Reset()                    { *m = DeleteVolumeOpts{} }
 func (m *DeleteVolumeOpts) String() string            { return proto1.CompactTextString(m) }
 func (*DeleteVolumeOpts) ProtoMessage()               {}
-func (*DeleteVolumeOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*DeleteVolumeOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 func (m *DeleteVolumeOpts) GetId() string {
 	if m != nil {
@@ -203,6 +264,97 @@ func (m *DeleteVolumeOpts) GetDriverName() string {
 	return ""
 }
 
+// ManageVolumeOpts is a structure which indicates all required properties
+// for managing an existing volume of the backend.
+type ManageVolumeOpts struct {
+	// The identifier of the volume in the backend, required.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
+	// The name of the volume, optional.
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// The description of the volume, optional.
+	Description string `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	// The uuid of the pool which the volume belongs to, required.
+	PoolId string `protobuf:"bytes,4,opt,name=poolId" json:"poolId,omitempty"`
+	// The service level that volume belongs to, optional.
+	ProfileId string `protobuf:"bytes,5,opt,name=profileId" json:"profileId,omitempty"`
+	// The project which owns the volume, optional.
+	ProjectId string `protobuf:"bytes,6,opt,name=projectId" json:"projectId,omitempty"`
+	// The metadata of the volume, optional.
+	Metadata map[string]string `protobuf:"bytes,7,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The dock infomation on which the request will be executed
+	DockId string `protobuf:"bytes,8,opt,name=dockId" json:"dockId,omitempty"`
+	// The storage driver type.
+	DriverName string `protobuf:"bytes,9,opt,name=driverName" json:"driverName,omitempty"`
+}
+
+func (m *ManageVolumeOpts) Reset()                    { *m = ManageVolumeOpts{} }
+func (m *ManageVolumeOpts) String() string            { return proto1.CompactTextString(m) }
+func (*ManageVolumeOpts) ProtoMessage()               {}
+func (*ManageVolumeOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ManageVolumeOpts) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetPoolId() string {
+	if m != nil {
+		return m.PoolId
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetProfileId() string {
+	if m != nil {
+		return m.ProfileId
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetProjectId() string {
+	if m != nil {
+		return m.ProjectId
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *ManageVolumeOpts) GetDockId() string {
+	if m != nil {
+		return m.DockId
+	}
+	return ""
+}
+
+func (m *ManageVolumeOpts) GetDriverName() string {
+	if m != nil {
+		return m.DriverName
+	}
+	return ""
+}
+
 // CreateVolumeSnapshotOpts is a structure which indicates all required
 // properties for creating a volume snapshot.
 type CreateVolumeSnapshotOpts struct {
@@ -227,7 +379,7 @@ type CreateVolumeSnapshotOpts struct {
 func (m *CreateVolumeSnapshotOpts) Reset()                    { *m = CreateVolumeSnapshotOpts{} }
 func (m *CreateVolumeSnapshotOpts) String() string            { return proto1.CompactTextString(m) }
 func (*CreateVolumeSnapshotOpts) ProtoMessage()               {}
-func (*CreateVolumeSnapshotOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*CreateVolumeSnapshotOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *CreateVolumeSnapshotOpts) GetId() string {
 	if m != nil {
@@ -303,7 +455,7 @@ type DeleteVolumeSnapshotOpts struct {
 func (m *DeleteVolumeSnapshotOpts) Reset()                    { *m = DeleteVolumeSnapshotOpts{} }
 func (m *DeleteVolumeSnapshotOpts) String() string            { return proto1.CompactTextString(m) }
 func (*DeleteVolumeSnapshotOpts) ProtoMessage()               {}
-func (*DeleteVolumeSnapshotOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*DeleteVolumeSnapshotOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 func (m *DeleteVolumeSnapshotOpts) GetId() string {
 	if m != nil {
@@ -340,6 +492,79 @@ func (m *DeleteVolumeSnapshotOpts) GetDriverName() string {
 	return ""
 }
 
+// ManageVolumeSnapshotOpts is a structure which indicates all required
+// properties for managing an existing volume snapshot of the backend.
+type ManageVolumeSnapshotOpts struct {
+	// The identifier of the volume snapshot in the backend, required.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
+	// The name of the volume snapshot, optional.
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// The description of the volume snapshot, optional.
+	Description string `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	// The uuid of the volume which the snapshot belongs to, required.
+	VolumeId string `protobuf:"bytes,4,opt,name=volumeId" json:"volumeId,omitempty"`
+	// The metadata of the volume snapshot, optional.
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The dock infomation on which the request will be executed
+	DockId string `protobuf:"bytes,6,opt,name=dockId" json:"dockId,omitempty"`
+	// The storage driver type.
+	DriverName string `protobuf:"bytes,7,opt,name=driverName" json:"driverName,omitempty"`
+}
+
+func (m *ManageVolumeSnapshotOpts) Reset()                    { *m = ManageVolumeSnapshotOpts{} }
+func (m *ManageVolumeSnapshotOpts) String() string            { return proto1.CompactTextString(m) }
+func (*ManageVolumeSnapshotOpts) ProtoMessage()               {}
+func (*ManageVolumeSnapshotOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *ManageVolumeSnapshotOpts) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *ManageVolumeSnapshotOpts) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ManageVolumeSnapshotOpts) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *ManageVolumeSnapshotOpts) GetVolumeId() string {
+	if m != nil {
+		return m.VolumeId
+	}
+	return ""
+}
+
+func (m *ManageVolumeSnapshotOpts) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *ManageVolumeSnapshotOpts) GetDockId() string {
+	if m != nil {
+		return m.DockId
+	}
+	return ""
+}
+
+func (m *ManageVolumeSnapshotOpts) GetDriverName() string {
+	if m != nil {
+		return m.DriverName
+	}
+	return ""
+}
+
 // CreateAttachmentOpts is a structure which indicates all required
 // properties for creating a volume attachment.
 type CreateAttachmentOpts struct {
@@ -364,7 +589,7 @@ type CreateAttachmentOpts struct {
 func (m *CreateAttachmentOpts) Reset()                    { *m = CreateAttachmentOpts{} }
 func (m *CreateAttachmentOpts) String() string            { return proto1.CompactTextString(m) }
 func (*CreateAttachmentOpts) ProtoMessage()               {}
-func (*CreateAttachmentOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*CreateAttachmentOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *CreateAttachmentOpts) GetId() string {
 	if m != nil {
@@ -442,7 +667,7 @@ type DeleteAttachmentOpts struct {
 func (m *DeleteAttachmentOpts) Reset()                    { *m = DeleteAttachmentOpts{} }
 func (m *DeleteAttachmentOpts) String() string            { return proto1.CompactTextString(m) }
 func (*DeleteAttachmentOpts) ProtoMessage()               {}
-func (*DeleteAttachmentOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*DeleteAttachmentOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *DeleteAttachmentOpts) GetId() string {
 	if m != nil {
@@ -486,6 +711,79 @@ func (m *DeleteAttachmentOpts) GetDriverName() string {
 	return ""
 }
 
+// UpdateAttachmentOpts is a structure which indicates all required
+// properties for updating a volume attachment.
+type UpdateAttachmentOpts struct {
+	// The uuid of the volume attachment, required.
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// The uuid of the volume, required.
+	VolumeId string `protobuf:"bytes,2,opt,name=volumeId" json:"volumeId,omitempty"`
+	// The mountpoint of the volume on the host, optional.
+	Mountpoint string `protobuf:"bytes,3,opt,name=mountpoint" json:"mountpoint,omitempty"`
+	// The infomation of the host node on which the volume is attached.
+	HostInfo *HostInfo `protobuf:"bytes,4,opt,name=hostInfo" json:"hostInfo,omitempty"`
+	// The metadata of the volume attachment, optional.
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The dock infomation on which the request will be executed
+	DockId string `protobuf:"bytes,6,opt,name=dockId" json:"dockId,omitempty"`
+	// The storage driver type.
+	DriverName string `protobuf:"bytes,7,opt,name=driverName" json:"driverName,omitempty"`
+}
+
+func (m *UpdateAttachmentOpts) Reset()                    { *m = UpdateAttachmentOpts{} }
+func (m *UpdateAttachmentOpts) String() string            { return proto1.CompactTextString(m) }
+func (*UpdateAttachmentOpts) ProtoMessage()               {}
+func (*UpdateAttachmentOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *UpdateAttachmentOpts) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UpdateAttachmentOpts) GetVolumeId() string {
+	if m != nil {
+		return m.VolumeId
+	}
+	return ""
+}
+
+func (m *UpdateAttachmentOpts) GetMountpoint() string {
+	if m != nil {
+		return m.Mountpoint
+	}
+	return ""
+}
+
+func (m *UpdateAttachmentOpts) GetHostInfo() *HostInfo {
+	if m != nil {
+		return m.HostInfo
+	}
+	return nil
+}
+
+func (m *UpdateAttachmentOpts) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *UpdateAttachmentOpts) GetDockId() string {
+	if m != nil {
+		return m.DockId
+	}
+	return ""
+}
+
+func (m *UpdateAttachmentOpts) GetDriverName() string {
+	if m != nil {
+		return m.DriverName
+	}
+	return ""
+}
+
 type HostInfo struct {
 	// The platform of the host, such as "x86_64"
 	Platform string `protobuf:"bytes,1,opt,name=platform" json:"platform,omitempty"`
@@ -502,7 +800,7 @@ type HostInfo struct {
 func (m *HostInfo) Reset()                    { *m = HostInfo{} }
 func (m *HostInfo) String() string            { return proto1.CompactTextString(m) }
 func (*HostInfo) ProtoMessage()               {}
-func (*HostInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*HostInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *HostInfo) GetPlatform() string {
 	if m != nil {
@@ -543,6 +841,40 @@ func (m *HostInfo) GetInitiator() string {
 // 1. Return result with message when create/update resource successfully.
 // 2. Return result without message when delete resource successfully.
 // 3. Return Error with error code and message when operate unsuccessfully.
+type DriverOpts struct {
+	// The path of the backend's driver configuration file
+	ConfigPath string `protobuf:"bytes,1,opt,name=configPath" json:"configPath,omitempty"`
+}
+
+func (m *DriverOpts) Reset()                    { *m = DriverOpts{} }
+func (m *DriverOpts) String() string            { return proto1.CompactTextString(m) }
+func (*DriverOpts) ProtoMessage()               {}
+func (*DriverOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *DriverOpts) GetConfigPath() string {
+	if m != nil {
+		return m.ConfigPath
+	}
+	return ""
+}
+
+type PullOpts struct {
+	// The identifier of the resource in the backend, required.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier" json:"identifier,omitempty"`
+}
+
+func (m *PullOpts) Reset()                    { *m = PullOpts{} }
+func (m *PullOpts) String() string            { return proto1.CompactTextString(m) }
+func (*PullOpts) ProtoMessage()               {}
+func (*PullOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *PullOpts) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
 type GenericResponse struct {
 	// Types that are valid to be assigned to Reply:
 	//	*GenericResponse_Result_
@@ -553,7 +885,7 @@ type GenericResponse struct {
 func (m *GenericResponse) Reset()                    { *m = GenericResponse{} }
 func (m *GenericResponse) String() string            { return proto1.CompactTextString(m) }
 func (*GenericResponse) ProtoMessage()               {}
-func (*GenericResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*GenericResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 type isGenericResponse_Reply interface {
 	isGenericResponse_Reply()
@@ -671,7 +1003,7 @@ type GenericResponse_Result struct {
 func (m *GenericResponse_Result) Reset()                    { *m = GenericResponse_Result{} }
 func (m *GenericResponse_Result) String() string            { return proto1.CompactTextString(m) }
 func (*GenericResponse_Result) ProtoMessage()               {}
-func (*GenericResponse_Result) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7, 0} }
+func (*GenericResponse_Result) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13, 0} }
 
 func (m *GenericResponse_Result) GetMessage() string {
 	if m != nil {
@@ -688,7 +1020,7 @@ type GenericResponse_Error struct {
 func (m *GenericResponse_Error) Reset()                    { *m = GenericResponse_Error{} }
 func (m *GenericResponse_Error) String() string            { return proto1.CompactTextString(m) }
 func (*GenericResponse_Error) ProtoMessage()               {}
-func (*GenericResponse_Error) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7, 1} }
+func (*GenericResponse_Error) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13, 1} }
 
 func (m *GenericResponse_Error) GetCode() string {
 	if m != nil {
@@ -706,12 +1038,18 @@ func (m *GenericResponse_Error) GetDescription() string {
 
 func init() {
 	proto1.RegisterType((*CreateVolumeOpts)(nil), "proto.CreateVolumeOpts")
+	proto1.RegisterType((*Qos)(nil), "proto.Qos")
 	proto1.RegisterType((*DeleteVolumeOpts)(nil), "proto.DeleteVolumeOpts")
+	proto1.RegisterType((*ManageVolumeOpts)(nil), "proto.ManageVolumeOpts")
 	proto1.RegisterType((*CreateVolumeSnapshotOpts)(nil), "proto.CreateVolumeSnapshotOpts")
 	proto1.RegisterType((*DeleteVolumeSnapshotOpts)(nil), "proto.DeleteVolumeSnapshotOpts")
+	proto1.RegisterType((*ManageVolumeSnapshotOpts)(nil), "proto.ManageVolumeSnapshotOpts")
 	proto1.RegisterType((*CreateAttachmentOpts)(nil), "proto.CreateAttachmentOpts")
 	proto1.RegisterType((*DeleteAttachmentOpts)(nil), "proto.DeleteAttachmentOpts")
+	proto1.RegisterType((*UpdateAttachmentOpts)(nil), "proto.UpdateAttachmentOpts")
 	proto1.RegisterType((*HostInfo)(nil), "proto.HostInfo")
+	proto1.RegisterType((*DriverOpts)(nil), "proto.DriverOpts")
+	proto1.RegisterType((*PullOpts)(nil), "proto.PullOpts")
 	proto1.RegisterType((*GenericResponse)(nil), "proto.GenericResponse")
 	proto1.RegisterType((*GenericResponse_Result)(nil), "proto.GenericResponse.Result")
 	proto1.RegisterType((*GenericResponse_Error)(nil), "proto.GenericResponse.Error")
@@ -740,6 +1078,12 @@ type DockClient interface {
 	CreateAttachment(ctx context.Context, in *CreateAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error)
 	// Delete a volume attachment
 	DeleteAttachment(ctx context.Context, in *DeleteAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Update a volume attachment
+	UpdateAttachment(ctx context.Context, in *UpdateAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Manage an existing volume of the backend
+	ManageVolume(ctx context.Context, in *ManageVolumeOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Manage an existing volume snapshot of the backend
+	ManageVolumeSnapshot(ctx context.Context, in *ManageVolumeSnapshotOpts, opts ...grpc.CallOption) (*GenericResponse, error)
 }
 
 type dockClient struct {
@@ -804,6 +1148,33 @@ func (c *dockClient) DeleteAttachment(ctx context.Context, in *DeleteAttachmentO
 	return out, nil
 }
 
+func (c *dockClient) UpdateAttachment(ctx context.Context, in *UpdateAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Dock/UpdateAttachment", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dockClient) ManageVolume(ctx context.Context, in *ManageVolumeOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Dock/ManageVolume", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dockClient) ManageVolumeSnapshot(ctx context.Context, in *ManageVolumeSnapshotOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Dock/ManageVolumeSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Dock service
 
 type DockServer interface {
@@ -819,6 +1190,12 @@ type DockServer interface {
 	CreateAttachment(context.Context, *CreateAttachmentOpts) (*GenericResponse, error)
 	// Delete a volume attachment
 	DeleteAttachment(context.Context, *DeleteAttachmentOpts) (*GenericResponse, error)
+	// Update a volume attachment
+	UpdateAttachment(context.Context, *UpdateAttachmentOpts) (*GenericResponse, error)
+	// Manage an existing volume of the backend
+	ManageVolume(context.Context, *ManageVolumeOpts) (*GenericResponse, error)
+	// Manage an existing volume snapshot of the backend
+	ManageVolumeSnapshot(context.Context, *ManageVolumeSnapshotOpts) (*GenericResponse, error)
 }
 
 func RegisterDockServer(s *grpc.Server, srv DockServer) {
@@ -933,33 +1310,585 @@ func _Dock_DeleteAttachment_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Dock_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "proto.Dock",
-	HandlerType: (*DockServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "CreateVolume",
-			Handler:    _Dock_CreateVolume_Handler,
-		},
-		{
-			MethodName: "DeleteVolume",
-			Handler:    _Dock_DeleteVolume_Handler,
-		},
-		{
-			MethodName: "CreateVolumeSnapshot",
-			Handler:    _Dock_CreateVolumeSnapshot_Handler,
-		},
-		{
-			MethodName: "DeleteVolumeSnapshot",
-			Handler:    _Dock_DeleteVolumeSnapshot_Handler,
-		},
-		{
-			MethodName: "CreateAttachment",
-			Handler:    _Dock_CreateAttachment_Handler,
-		},
-		{
-			MethodName: "DeleteAttachment",
-			Handler:    _Dock_DeleteAttachment_Handler,
+func _Dock_UpdateAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAttachmentOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DockServer).UpdateAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Dock/UpdateAttachment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DockServer).UpdateAttachment(ctx, req.(*UpdateAttachmentOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dock_ManageVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ManageVolumeOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DockServer).ManageVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Dock/ManageVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DockServer).ManageVolume(ctx, req.(*ManageVolumeOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dock_ManageVolumeSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ManageVolumeSnapshotOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DockServer).ManageVolumeSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Dock/ManageVolumeSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DockServer).ManageVolumeSnapshot(ctx, req.(*ManageVolumeSnapshotOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Dock_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Dock",
+	HandlerType: (*DockServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateVolume",
+			Handler:    _Dock_CreateVolume_Handler,
+		},
+		{
+			MethodName: "DeleteVolume",
+			Handler:    _Dock_DeleteVolume_Handler,
+		},
+		{
+			MethodName: "CreateVolumeSnapshot",
+			Handler:    _Dock_CreateVolumeSnapshot_Handler,
+		},
+		{
+			MethodName: "DeleteVolumeSnapshot",
+			Handler:    _Dock_DeleteVolumeSnapshot_Handler,
+		},
+		{
+			MethodName: "CreateAttachment",
+			Handler:    _Dock_CreateAttachment_Handler,
+		},
+		{
+			MethodName: "DeleteAttachment",
+			Handler:    _Dock_DeleteAttachment_Handler,
+		},
+		{
+			MethodName: "UpdateAttachment",
+			Handler:    _Dock_UpdateAttachment_Handler,
+		},
+		{
+			MethodName: "ManageVolume",
+			Handler:    _Dock_ManageVolume_Handler,
+		},
+		{
+			MethodName: "ManageVolumeSnapshot",
+			Handler:    _Dock_ManageVolumeSnapshot_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dock.proto",
+}
+
+// Client API for Driver service
+
+type DriverClient interface {
+	// Initialize the driver while osdsdock connects to the plugin
+	Setup(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Release the driver while osdsdock disconnects from the plugin
+	Unset(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Create a volume in the backend
+	CreateVolume(ctx context.Context, in *CreateVolumeOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Get a volume from the backend
+	PullVolume(ctx context.Context, in *PullOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Delete a volume from the backend
+	DeleteVolume(ctx context.Context, in *DeleteVolumeOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Export a volume to the host
+	InitializeConnection(ctx context.Context, in *CreateAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Stop exporting a volume to the host
+	TerminateConnection(ctx context.Context, in *DeleteAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Create a volume snapshot in the backend
+	CreateSnapshot(ctx context.Context, in *CreateVolumeSnapshotOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Get a volume snapshot from the backend
+	PullSnapshot(ctx context.Context, in *PullOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// Delete a volume snapshot from the backend
+	DeleteSnapshot(ctx context.Context, in *DeleteVolumeSnapshotOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// List the storage pools of the backend
+	ListPools(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// List the volumes of the backend
+	ListVolumes(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+	// List the volume snapshots of the backend
+	ListSnapshots(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error)
+}
+
+type driverClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDriverClient(cc *grpc.ClientConn) DriverClient {
+	return &driverClient{cc}
+}
+
+func (c *driverClient) Setup(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/Setup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) Unset(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/Unset", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) CreateVolume(ctx context.Context, in *CreateVolumeOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/CreateVolume", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) PullVolume(ctx context.Context, in *PullOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/PullVolume", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) DeleteVolume(ctx context.Context, in *DeleteVolumeOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/DeleteVolume", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) InitializeConnection(ctx context.Context, in *CreateAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/InitializeConnection", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) TerminateConnection(ctx context.Context, in *DeleteAttachmentOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/TerminateConnection", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) CreateSnapshot(ctx context.Context, in *CreateVolumeSnapshotOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/CreateSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) PullSnapshot(ctx context.Context, in *PullOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/PullSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) DeleteSnapshot(ctx context.Context, in *DeleteVolumeSnapshotOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/DeleteSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) ListPools(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/ListPools", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) ListVolumes(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/ListVolumes", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverClient) ListSnapshots(ctx context.Context, in *DriverOpts, opts ...grpc.CallOption) (*GenericResponse, error) {
+	out := new(GenericResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/ListSnapshots", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Driver service
+
+type DriverServer interface {
+	// Initialize the driver while osdsdock connects to the plugin
+	Setup(context.Context, *DriverOpts) (*GenericResponse, error)
+	// Release the driver while osdsdock disconnects from the plugin
+	Unset(context.Context, *DriverOpts) (*GenericResponse, error)
+	// Create a volume in the backend
+	CreateVolume(context.Context, *CreateVolumeOpts) (*GenericResponse, error)
+	// Get a volume from the backend
+	PullVolume(context.Context, *PullOpts) (*GenericResponse, error)
+	// Delete a volume from the backend
+	DeleteVolume(context.Context, *DeleteVolumeOpts) (*GenericResponse, error)
+	// Export a volume to the host
+	InitializeConnection(context.Context, *CreateAttachmentOpts) (*GenericResponse, error)
+	// Stop exporting a volume to the host
+	TerminateConnection(context.Context, *DeleteAttachmentOpts) (*GenericResponse, error)
+	// Create a volume snapshot in the backend
+	CreateSnapshot(context.Context, *CreateVolumeSnapshotOpts) (*GenericResponse, error)
+	// Get a volume snapshot from the backend
+	PullSnapshot(context.Context, *PullOpts) (*GenericResponse, error)
+	// Delete a volume snapshot from the backend
+	DeleteSnapshot(context.Context, *DeleteVolumeSnapshotOpts) (*GenericResponse, error)
+	// List the storage pools of the backend
+	ListPools(context.Context, *DriverOpts) (*GenericResponse, error)
+	// List the volumes of the backend
+	ListVolumes(context.Context, *DriverOpts) (*GenericResponse, error)
+	// List the volume snapshots of the backend
+	ListSnapshots(context.Context, *DriverOpts) (*GenericResponse, error)
+}
+
+func RegisterDriverServer(s *grpc.Server, srv DriverServer) {
+	s.RegisterService(&_Driver_serviceDesc, srv)
+}
+
+func _Driver_Setup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DriverOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).Setup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/Setup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).Setup(ctx, req.(*DriverOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_Unset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DriverOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).Unset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/Unset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).Unset(ctx, req.(*DriverOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_CreateVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVolumeOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).CreateVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/CreateVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).CreateVolume(ctx, req.(*CreateVolumeOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_PullVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PullOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).PullVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/PullVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).PullVolume(ctx, req.(*PullOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_DeleteVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVolumeOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).DeleteVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/DeleteVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).DeleteVolume(ctx, req.(*DeleteVolumeOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_InitializeConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAttachmentOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).InitializeConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/InitializeConnection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).InitializeConnection(ctx, req.(*CreateAttachmentOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_TerminateConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAttachmentOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).TerminateConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/TerminateConnection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).TerminateConnection(ctx, req.(*DeleteAttachmentOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVolumeSnapshotOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).CreateSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/CreateSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).CreateSnapshot(ctx, req.(*CreateVolumeSnapshotOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_PullSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PullOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).PullSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/PullSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).PullSnapshot(ctx, req.(*PullOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVolumeSnapshotOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/DeleteSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).DeleteSnapshot(ctx, req.(*DeleteVolumeSnapshotOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_ListPools_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DriverOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).ListPools(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/ListPools",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).ListPools(ctx, req.(*DriverOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_ListVolumes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DriverOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).ListVolumes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/ListVolumes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).ListVolumes(ctx, req.(*DriverOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Driver_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DriverOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/ListSnapshots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).ListSnapshots(ctx, req.(*DriverOpts))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Driver_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Driver",
+	HandlerType: (*DriverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Setup",
+			Handler:    _Driver_Setup_Handler,
+		},
+		{
+			MethodName: "Unset",
+			Handler:    _Driver_Unset_Handler,
+		},
+		{
+			MethodName: "CreateVolume",
+			Handler:    _Driver_CreateVolume_Handler,
+		},
+		{
+			MethodName: "PullVolume",
+			Handler:    _Driver_PullVolume_Handler,
+		},
+		{
+			MethodName: "DeleteVolume",
+			Handler:    _Driver_DeleteVolume_Handler,
+		},
+		{
+			MethodName: "InitializeConnection",
+			Handler:    _Driver_InitializeConnection_Handler,
+		},
+		{
+			MethodName: "TerminateConnection",
+			Handler:    _Driver_TerminateConnection_Handler,
+		},
+		{
+			MethodName: "CreateSnapshot",
+			Handler:    _Driver_CreateSnapshot_Handler,
+		},
+		{
+			MethodName: "PullSnapshot",
+			Handler:    _Driver_PullSnapshot_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _Driver_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "ListPools",
+			Handler:    _Driver_ListPools_Handler,
+		},
+		{
+			MethodName: "ListVolumes",
+			Handler:    _Driver_ListVolumes_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _Driver_ListSnapshots_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -969,54 +1898,80 @@ var _Dock_serviceDesc = grpc.ServiceDesc{
 func init() { proto1.RegisterFile("dock.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 771 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xcc, 0x96, 0xcf, 0x6f, 0xd3, 0x30,
-	0x14, 0xc7, 0xd7, 0xa4, 0x69, 0xb3, 0xd7, 0x8d, 0x55, 0xd6, 0x34, 0xa2, 0x32, 0xa0, 0xaa, 0x40,
-	0x1a, 0x20, 0x7a, 0x28, 0x48, 0x20, 0x10, 0x87, 0xc1, 0x26, 0x56, 0x89, 0x9f, 0x01, 0x71, 0xe0,
-	0xe6, 0x35, 0x1e, 0xb5, 0x96, 0xc4, 0x91, 0xe3, 0x56, 0x2a, 0x27, 0x4e, 0xfc, 0x33, 0xfc, 0x17,
-	0x9c, 0x38, 0x72, 0xe6, 0x6f, 0x40, 0xe2, 0x5f, 0x40, 0x76, 0x9c, 0x34, 0xe9, 0xda, 0xd0, 0x89,
-	0x22, 0x71, 0xaa, 0xdf, 0xf3, 0xf3, 0xb3, 0xbf, 0x1f, 0x3f, 0xbf, 0x14, 0xc0, 0x63, 0x83, 0xd3,
-	0x6e, 0xc4, 0x99, 0x60, 0xc8, 0x52, 0x3f, 0x9d, 0xaf, 0x26, 0x34, 0x9f, 0x70, 0x82, 0x05, 0x79,
-	0xc7, 0xfc, 0x51, 0x40, 0x5e, 0x46, 0x22, 0x46, 0x17, 0xc0, 0xa0, 0x9e, 0x53, 0x69, 0x57, 0xf6,
-	0xd6, 0x5d, 0x83, 0x7a, 0x08, 0x41, 0x35, 0xc4, 0x01, 0x71, 0x0c, 0xe5, 0x51, 0x63, 0xe9, 0x8b,
-	0xe9, 0x47, 0xe2, 0x98, 0xed, 0xca, 0x9e, 0xe9, 0xaa, 0x31, 0x6a, 0x43, 0xc3, 0x23, 0xf1, 0x80,
-	0xd3, 0x48, 0x50, 0x16, 0x3a, 0x55, 0x15, 0x9e, 0x77, 0xa1, 0x2b, 0x00, 0x71, 0x88, 0xa3, 0x78,
-	0xc8, 0x44, 0xdf, 0x73, 0x2c, 0x15, 0x90, 0xf3, 0xa0, 0x9b, 0xd0, 0xc4, 0x63, 0x4c, 0x7d, 0x7c,
-	0x4c, 0x7d, 0x2a, 0x26, 0xef, 0x59, 0x48, 0x9c, 0x9a, 0x8a, 0x3a, 0xe3, 0x47, 0xbb, 0xb0, 0x1e,
-	0x71, 0x76, 0x42, 0x7d, 0xd2, 0xf7, 0x9c, 0xba, 0x0a, 0x9a, 0x3a, 0xd0, 0x0e, 0xd4, 0x22, 0xc6,
-	0xfc, 0xbe, 0xe7, 0xd8, 0x6a, 0x4a, 0x5b, 0xa8, 0x05, 0xb6, 0x1c, 0xbd, 0x90, 0x7a, 0xd6, 0xd5,
-	0x4c, 0x66, 0xa3, 0x7d, 0xb0, 0x03, 0x22, 0xb0, 0x87, 0x05, 0x76, 0xa0, 0x6d, 0xee, 0x35, 0x7a,
-	0xd7, 0x13, 0x5a, 0xdd, 0x59, 0x44, 0xdd, 0xe7, 0x3a, 0xee, 0x30, 0x14, 0x7c, 0xe2, 0x66, 0xcb,
-	0xe4, 0xb6, 0x12, 0x72, 0xdf, 0x73, 0x1a, 0xc9, 0xb6, 0x89, 0x25, 0x85, 0x7b, 0x9c, 0x8e, 0x09,
-	0x57, 0x1b, 0x6f, 0x24, 0xc2, 0xa7, 0x9e, 0xd6, 0x43, 0xd8, 0x2c, 0xa4, 0x44, 0x4d, 0x30, 0x4f,
-	0xc9, 0x44, 0x5f, 0x82, 0x1c, 0xa2, 0x6d, 0xb0, 0xc6, 0xd8, 0x1f, 0xa5, 0xd7, 0x90, 0x18, 0x0f,
-	0x8c, 0xfb, 0x95, 0xce, 0x8f, 0x0a, 0x34, 0x0f, 0x88, 0x4f, 0x4a, 0x2f, 0x31, 0x2f, 0xce, 0x28,
-	0x88, 0x9b, 0x5d, 0xba, 0x84, 0x38, 0xb3, 0x44, 0x5c, 0x75, 0xb5, 0xe2, 0xbe, 0x1b, 0xe0, 0xe4,
-	0xf1, 0xbf, 0xd1, 0xd5, 0xf2, 0x8f, 0x2b, 0xb5, 0x05, 0xf6, 0x58, 0xed, 0x97, 0xd5, 0x69, 0x66,
-	0xa3, 0x7e, 0x0e, 0x65, 0x4d, 0xa1, 0xbc, 0x3d, 0xa7, 0x4e, 0xf2, 0x07, 0x5d, 0x02, 0x69, 0xbd,
-	0x04, 0xa9, 0xbd, 0x5a, 0xa4, 0x9f, 0x0d, 0x70, 0xf2, 0x97, 0x5e, 0x8a, 0x34, 0x0f, 0xc2, 0x28,
-	0x01, 0x61, 0x16, 0x40, 0x2c, 0x4a, 0xbf, 0x04, 0x88, 0x6a, 0x09, 0x08, 0x6b, 0xb5, 0x20, 0x7e,
-	0x19, 0xb0, 0x9d, 0x5c, 0xd9, 0xbe, 0x10, 0x78, 0x30, 0x0c, 0x48, 0x78, 0x7e, 0x08, 0xd7, 0x60,
-	0xd3, 0x63, 0xcf, 0xd8, 0x00, 0xfb, 0x49, 0x12, 0x55, 0x68, 0xb6, 0x5b, 0x74, 0xca, 0x6e, 0x15,
-	0x8c, 0x7c, 0x41, 0x5f, 0x61, 0x31, 0x54, 0x12, 0x6d, 0x77, 0xea, 0x40, 0xb7, 0xc0, 0x1e, 0xb2,
-	0x58, 0xf4, 0xc3, 0x13, 0xa6, 0x34, 0x36, 0x7a, 0x5b, 0x1a, 0xe4, 0x91, 0x76, 0xbb, 0x59, 0x00,
-	0x3a, 0x3c, 0x53, 0x7e, 0x37, 0x0a, 0xe5, 0x57, 0xd4, 0xf2, 0x7f, 0x95, 0xde, 0x17, 0x03, 0xb6,
-	0x93, 0xda, 0xf8, 0x0b, 0xe2, 0x79, 0x5a, 0xe6, 0x79, 0x68, 0x55, 0x0b, 0xb4, 0xe6, 0x9d, 0x63,
-	0x09, 0x5a, 0x56, 0x09, 0xad, 0xda, 0x6a, 0x69, 0x7d, 0xaa, 0x80, 0x9d, 0x4a, 0x52, 0x5f, 0x2e,
-	0x1f, 0x8b, 0x13, 0xc6, 0x03, 0xbd, 0x3a, 0xb3, 0xe5, 0xe9, 0x58, 0xfc, 0x76, 0x12, 0xa5, 0x39,
-	0xb4, 0x25, 0x7b, 0x9f, 0x04, 0xa1, 0xfb, 0xb5, 0x1a, 0x2b, 0xd2, 0x91, 0x7e, 0x65, 0x06, 0x8d,
-	0x64, 0x65, 0xd2, 0x90, 0x0a, 0x8a, 0x05, 0xe3, 0x5a, 0xdc, 0xd4, 0xd1, 0xf9, 0x59, 0x81, 0xad,
-	0xa7, 0x24, 0x24, 0x9c, 0x0e, 0x5c, 0x12, 0x47, 0x2c, 0x8c, 0x09, 0xba, 0x07, 0x35, 0x4e, 0xe2,
-	0x91, 0x2f, 0xd4, 0x39, 0x1a, 0xbd, 0xcb, 0x1a, 0xe8, 0x4c, 0x5c, 0xd7, 0x55, 0x41, 0x47, 0x6b,
-	0xae, 0x0e, 0x47, 0x77, 0xc1, 0x22, 0x9c, 0x33, 0xae, 0x4e, 0xd9, 0xe8, 0xed, 0x2e, 0x58, 0x77,
-	0x28, 0x63, 0x8e, 0xd6, 0xdc, 0x24, 0xb8, 0xd5, 0x81, 0x5a, 0x92, 0x09, 0x39, 0x50, 0x0f, 0x48,
-	0x1c, 0xe3, 0x0f, 0x44, 0x13, 0x48, 0xcd, 0xd6, 0x23, 0xb0, 0xd4, 0x2a, 0xa9, 0x78, 0xc0, 0xbc,
-	0x74, 0x5e, 0x8d, 0x67, 0xbb, 0xbd, 0x71, 0xa6, 0xdb, 0x3f, 0xae, 0x83, 0xc5, 0x49, 0xe4, 0x4f,
-	0x7a, 0xdf, 0x4c, 0xa8, 0x1e, 0xb0, 0xc1, 0x29, 0xda, 0x87, 0x8d, 0x7c, 0x33, 0x47, 0x17, 0x17,
-	0xfc, 0x13, 0x68, 0xed, 0xcc, 0x17, 0xd1, 0x59, 0x93, 0x29, 0xf2, 0x6d, 0x30, 0x4b, 0x31, 0xfb,
-	0xbd, 0x2d, 0x49, 0xf1, 0x3a, 0xed, 0x4f, 0xc5, 0x4e, 0x8a, 0xae, 0xfe, 0xe1, 0x7b, 0x53, 0x9e,
-	0x72, 0x5e, 0x73, 0xce, 0x52, 0x2e, 0xea, 0xdc, 0x25, 0x29, 0xfb, 0xe9, 0x7f, 0xc8, 0xe9, 0x5b,
-	0x42, 0x97, 0x4a, 0x5a, 0x52, 0x79, 0xaa, 0xd9, 0x67, 0x99, 0xa5, 0x9a, 0xf7, 0x5e, 0x17, 0xa7,
-	0x3a, 0xae, 0xa9, 0x89, 0x3b, 0xbf, 0x03, 0x00, 0x00, 0xff, 0xff, 0x7c, 0xfb, 0xe9, 0xde, 0xf6,
-	0x0a, 0x00, 0x00,
+	// 1186 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x97, 0x4f, 0x73, 0xdb, 0x44,
+	0x14, 0xc0, 0x6b, 0xc9, 0x92, 0x95, 0xe7, 0xfc, 0x63, 0xc9, 0x14, 0x8d, 0x09, 0xc1, 0xe3, 0x81,
+	0x99, 0x50, 0x20, 0x07, 0xd3, 0x99, 0x96, 0x02, 0x87, 0x34, 0xc9, 0x10, 0x4f, 0x13, 0x9a, 0x28,
+	0x2d, 0x07, 0x6e, 0x5b, 0x6b, 0x93, 0x2c, 0x91, 0x76, 0x85, 0xb4, 0x0e, 0x4d, 0x4f, 0x9c, 0xf8,
+	0x1c, 0x5c, 0x19, 0x3e, 0x0a, 0x1c, 0xb8, 0xc2, 0x67, 0x60, 0x86, 0x0b, 0x1f, 0x80, 0xd9, 0xd5,
+	0x1f, 0x4b, 0x8a, 0x2c, 0x6c, 0xe2, 0xcc, 0xf4, 0xe4, 0xdd, 0xa7, 0xf7, 0xde, 0xee, 0xfe, 0xf6,
+	0xbd, 0xb7, 0xcf, 0x00, 0x2e, 0x1f, 0x5e, 0x6c, 0x05, 0x21, 0x17, 0x1c, 0x19, 0xea, 0xa7, 0xf7,
+	0x53, 0x13, 0x56, 0x77, 0x42, 0x82, 0x05, 0xf9, 0x9a, 0x7b, 0x23, 0x9f, 0x3c, 0x0d, 0x44, 0x84,
+	0x96, 0x41, 0xa3, 0xae, 0xdd, 0xe8, 0x36, 0x36, 0x17, 0x1c, 0x8d, 0xba, 0x08, 0x41, 0x93, 0x61,
+	0x9f, 0xd8, 0x9a, 0x92, 0xa8, 0xb1, 0x94, 0x45, 0xf4, 0x15, 0xb1, 0xf5, 0x6e, 0x63, 0x53, 0x77,
+	0xd4, 0x18, 0x75, 0xa1, 0xed, 0x92, 0x68, 0x18, 0xd2, 0x40, 0x50, 0xce, 0xec, 0xa6, 0x52, 0xcf,
+	0x8b, 0xd0, 0x06, 0x40, 0xc4, 0x70, 0x10, 0x9d, 0x73, 0x31, 0x70, 0x6d, 0x43, 0x29, 0xe4, 0x24,
+	0xe8, 0x1e, 0xac, 0xe2, 0x4b, 0x4c, 0x3d, 0xfc, 0x82, 0x7a, 0x54, 0x5c, 0x7d, 0xc3, 0x19, 0xb1,
+	0x4d, 0xa5, 0x75, 0x4d, 0x8e, 0xd6, 0x61, 0x21, 0x08, 0xf9, 0x29, 0xf5, 0xc8, 0xc0, 0xb5, 0x5b,
+	0x4a, 0x69, 0x2c, 0x40, 0x77, 0xc1, 0x0c, 0x38, 0xf7, 0x06, 0xae, 0x6d, 0xa9, 0x4f, 0xc9, 0x0c,
+	0x75, 0xc0, 0x92, 0xa3, 0xaf, 0xe4, 0x79, 0x16, 0xd4, 0x97, 0x6c, 0x8e, 0xb6, 0xc1, 0xf2, 0x89,
+	0xc0, 0x2e, 0x16, 0xd8, 0x86, 0xae, 0xbe, 0xd9, 0xee, 0xbf, 0x1f, 0xd3, 0xda, 0x2a, 0x23, 0xda,
+	0x3a, 0x4c, 0xf4, 0xf6, 0x98, 0x08, 0xaf, 0x9c, 0xcc, 0x4c, 0x2e, 0x2b, 0x21, 0x0f, 0x5c, 0xbb,
+	0x1d, 0x2f, 0x1b, 0xcf, 0xe4, 0xc1, 0xdd, 0x90, 0x5e, 0x92, 0x50, 0x2d, 0xbc, 0x18, 0x1f, 0x7c,
+	0x2c, 0x41, 0xeb, 0xa0, 0x7f, 0xc7, 0x23, 0x7b, 0xa9, 0xdb, 0xd8, 0x6c, 0xf7, 0x21, 0x59, 0xf5,
+	0x98, 0x47, 0x8e, 0x14, 0x4b, 0xb0, 0xfe, 0xc8, 0x13, 0x14, 0x0b, 0x81, 0x87, 0xe7, 0xf6, 0x72,
+	0xb7, 0xb1, 0x69, 0x39, 0x79, 0x51, 0x02, 0xe3, 0x5b, 0x32, 0x94, 0x5c, 0x57, 0x32, 0x18, 0xb1,
+	0xa0, 0xf3, 0x19, 0x2c, 0x15, 0x36, 0x8c, 0x56, 0x41, 0xbf, 0x20, 0x57, 0xc9, 0x15, 0xcb, 0x21,
+	0x5a, 0x03, 0xe3, 0x12, 0x7b, 0xa3, 0xf4, 0x92, 0xe3, 0xc9, 0x23, 0xed, 0x61, 0xa3, 0xb7, 0x03,
+	0xfa, 0x31, 0x8f, 0x90, 0x0d, 0x2d, 0x1f, 0xbf, 0x1c, 0x3c, 0x3d, 0x3a, 0x51, 0x66, 0xba, 0x93,
+	0x4e, 0x51, 0x0f, 0x16, 0x7d, 0xfc, 0xf2, 0x31, 0x66, 0xee, 0xf7, 0xd4, 0x15, 0xe7, 0xca, 0x83,
+	0xee, 0x14, 0x64, 0xbd, 0x3f, 0x1b, 0xb0, 0xba, 0x4b, 0x3c, 0x52, 0x1b, 0x67, 0x79, 0xfe, 0x5a,
+	0x81, 0x7f, 0xd9, 0x74, 0x0a, 0xfe, 0x7a, 0x0d, 0xff, 0x66, 0x99, 0xff, 0xcd, 0x08, 0xfd, 0xa3,
+	0xc1, 0xea, 0x21, 0x66, 0xf8, 0x2c, 0x7f, 0xb8, 0x0d, 0x00, 0xea, 0x12, 0x26, 0xe8, 0x29, 0x25,
+	0x61, 0xe2, 0x27, 0x27, 0xa9, 0x4c, 0xaa, 0x52, 0x02, 0xe9, 0xd7, 0x13, 0x68, 0x1c, 0xd6, 0xcd,
+	0x42, 0x58, 0x17, 0x92, 0xc1, 0x28, 0x27, 0x43, 0x21, 0x3a, 0xcc, 0x52, 0x74, 0x14, 0xb0, 0xb7,
+	0x0a, 0xd8, 0xcb, 0x87, 0x9a, 0x02, 0xbb, 0x55, 0x83, 0x7d, 0x61, 0xbe, 0xd8, 0x7f, 0xd7, 0xc0,
+	0xce, 0x27, 0xe6, 0x49, 0x52, 0x47, 0x6e, 0xb9, 0x86, 0x75, 0xc0, 0xba, 0x54, 0xeb, 0x65, 0xa4,
+	0xb3, 0x39, 0x1a, 0xe4, 0x50, 0x9a, 0x0a, 0xe5, 0xc7, 0x15, 0x15, 0x24, 0xbf, 0xd1, 0x29, 0x90,
+	0xb6, 0x6a, 0x90, 0x5a, 0xf3, 0x45, 0xfa, 0xa3, 0x06, 0x76, 0x3e, 0xd7, 0x6a, 0x91, 0xe6, 0x41,
+	0x68, 0x35, 0x20, 0xf4, 0x02, 0x88, 0x49, 0xee, 0xa7, 0x00, 0xd1, 0xac, 0x01, 0x61, 0xcc, 0x17,
+	0xc4, 0x6f, 0x1a, 0xd8, 0xf9, 0xe8, 0x2f, 0x80, 0xb8, 0x9d, 0xd4, 0xce, 0xe3, 0x6c, 0xd6, 0xe0,
+	0x34, 0x0a, 0x38, 0x27, 0x6d, 0x72, 0x0a, 0x9c, 0x66, 0x0d, 0xce, 0xd6, 0x7c, 0x71, 0xfe, 0xad,
+	0xc1, 0x5a, 0x9c, 0x01, 0xdb, 0xea, 0xbd, 0xf2, 0x09, 0x9b, 0x3d, 0xa6, 0xde, 0x83, 0x25, 0x97,
+	0x1f, 0xf0, 0x21, 0xf6, 0x62, 0x27, 0x0a, 0xa2, 0xe5, 0x14, 0x85, 0xb2, 0xd6, 0xa9, 0x87, 0xf1,
+	0x08, 0x8b, 0x73, 0xc5, 0xd1, 0x72, 0xc6, 0x02, 0xf4, 0x21, 0x58, 0xe7, 0x3c, 0x12, 0x03, 0x76,
+	0xca, 0x55, 0xc8, 0xb4, 0xfb, 0x2b, 0x09, 0xc8, 0xfd, 0x44, 0xec, 0x64, 0x0a, 0x68, 0xef, 0x5a,
+	0x36, 0x7f, 0x50, 0xc8, 0xe6, 0xe2, 0x59, 0x5e, 0xaf, 0x4c, 0xfe, 0x45, 0x83, 0xb5, 0x38, 0xd5,
+	0x6e, 0x40, 0x3c, 0x4f, 0x4b, 0x9f, 0x85, 0x56, 0xb3, 0x40, 0xab, 0x6a, 0x1f, 0x53, 0xd0, 0x32,
+	0x6a, 0x68, 0x99, 0xf3, 0xa5, 0xf5, 0xab, 0x06, 0x6b, 0xcf, 0x03, 0xf7, 0x66, 0xf1, 0xb9, 0x01,
+	0xe0, 0xf3, 0x11, 0x13, 0x01, 0xa7, 0x4c, 0x24, 0x19, 0x9e, 0x93, 0x14, 0x68, 0x36, 0x67, 0xa1,
+	0x69, 0x14, 0x68, 0x56, 0xed, 0xf3, 0xf5, 0xca, 0xf6, 0x1f, 0x1a, 0x60, 0xa5, 0x47, 0x52, 0x0d,
+	0xb7, 0x87, 0xc5, 0x29, 0x0f, 0xfd, 0xc4, 0x3a, 0x9b, 0xcb, 0xdd, 0xf1, 0xe8, 0xd9, 0x55, 0x90,
+	0xfa, 0x48, 0x66, 0xb2, 0x80, 0x4a, 0x10, 0x09, 0x43, 0x35, 0x56, 0x37, 0x11, 0x24, 0x85, 0x51,
+	0xa3, 0x81, 0xcc, 0x73, 0xca, 0xa8, 0xa0, 0x58, 0xf0, 0x30, 0xed, 0x78, 0x32, 0x41, 0xef, 0x23,
+	0x80, 0x5d, 0x75, 0x9a, 0xb4, 0x60, 0x0f, 0x39, 0x3b, 0xa5, 0x67, 0xaa, 0x28, 0x24, 0x05, 0x7b,
+	0x2c, 0xe9, 0xdd, 0x03, 0xeb, 0x68, 0xe4, 0x79, 0xd3, 0x14, 0xf7, 0xde, 0x5f, 0x0d, 0x58, 0xf9,
+	0x92, 0x30, 0x12, 0xd2, 0xa1, 0x43, 0xa2, 0x80, 0xb3, 0x88, 0xa0, 0x07, 0x60, 0x86, 0x24, 0x1a,
+	0x79, 0x42, 0xe9, 0xb7, 0xfb, 0xef, 0x24, 0x57, 0x55, 0xd2, 0xdb, 0x72, 0x94, 0xd2, 0xfe, 0x1d,
+	0x27, 0x51, 0x47, 0xf7, 0xc1, 0x20, 0x61, 0xc8, 0x43, 0x75, 0xfe, 0x76, 0x7f, 0x7d, 0x82, 0xdd,
+	0x9e, 0xd4, 0xd9, 0xbf, 0xe3, 0xc4, 0xca, 0x9d, 0x1e, 0x98, 0xb1, 0x27, 0xd5, 0x94, 0x93, 0x28,
+	0xc2, 0x67, 0x24, 0xd9, 0x69, 0x3a, 0xed, 0x7c, 0x01, 0x86, 0xb2, 0x92, 0x2c, 0x87, 0xdc, 0x4d,
+	0xbf, 0xab, 0x71, 0xf9, 0x31, 0xd2, 0xae, 0x3d, 0x46, 0x8f, 0x5b, 0x60, 0x84, 0x24, 0xf0, 0xae,
+	0xfa, 0x3f, 0x1b, 0xd0, 0xdc, 0xe5, 0xc3, 0x0b, 0xb4, 0x0d, 0x8b, 0xf9, 0x1e, 0x06, 0xbd, 0x35,
+	0xe1, 0xaf, 0x51, 0xe7, 0x6e, 0xf5, 0x21, 0x7a, 0x77, 0xa4, 0x8b, 0xfc, 0xeb, 0x9f, 0xb9, 0x28,
+	0x77, 0xf7, 0x35, 0x2e, 0x8e, 0xd3, 0x77, 0xa4, 0xf8, 0xe2, 0xa1, 0x77, 0xff, 0xa3, 0xcd, 0xaa,
+	0x77, 0x59, 0xd5, 0x93, 0x64, 0x2e, 0x27, 0x35, 0x2c, 0x35, 0x2e, 0x07, 0xe9, 0x9f, 0xea, 0x71,
+	0x96, 0xa2, 0xb7, 0x6b, 0x9e, 0x8e, 0x7a, 0x57, 0xe5, 0xf2, 0x99, 0xb9, 0xaa, 0xaa, 0xab, 0xf5,
+	0xae, 0xca, 0xb5, 0x23, 0x73, 0x55, 0x55, 0x54, 0xea, 0x6f, 0x32, 0xdf, 0x78, 0x64, 0x37, 0x59,
+	0xfe, 0xc3, 0x50, 0x8f, 0xbd, 0xaa, 0x77, 0xc9, 0xb0, 0x4f, 0x6a, 0x6c, 0x26, 0xbb, 0xec, 0xff,
+	0x61, 0x82, 0x19, 0x67, 0xbd, 0x4c, 0xac, 0x13, 0x22, 0x46, 0x01, 0x7a, 0x23, 0x65, 0x95, 0x55,
+	0x83, 0x9a, 0x3d, 0xdd, 0x07, 0xe3, 0x39, 0x8b, 0x88, 0x98, 0xcd, 0x6a, 0x0e, 0x99, 0xf1, 0x00,
+	0x40, 0x16, 0xa0, 0xc4, 0x41, 0xfa, 0x2c, 0xa4, 0x35, 0xe9, 0x76, 0x53, 0xea, 0x10, 0xd6, 0x06,
+	0xaa, 0x6e, 0x7a, 0xf4, 0x15, 0xd9, 0xe1, 0x8c, 0x91, 0xa1, 0xea, 0x47, 0xff, 0x67, 0xc0, 0x1e,
+	0xc0, 0x9b, 0xcf, 0x48, 0xe8, 0x53, 0x86, 0x45, 0x95, 0xb7, 0x19, 0x63, 0xf6, 0x09, 0x2c, 0xc7,
+	0xeb, 0xcf, 0x23, 0xd3, 0x3f, 0x85, 0x45, 0x89, 0x34, 0x73, 0x35, 0x03, 0xe7, 0x27, 0xb0, 0x1c,
+	0xef, 0x7c, 0x1e, 0xe5, 0xe1, 0x21, 0x2c, 0x1c, 0xd0, 0x48, 0x1c, 0x71, 0xee, 0x45, 0xb3, 0x85,
+	0xda, 0x23, 0x68, 0x4b, 0xcb, 0x78, 0xb5, 0x19, 0x6d, 0x3f, 0x87, 0x25, 0x69, 0x9b, 0xee, 0x71,
+	0x36, 0xeb, 0x17, 0xa6, 0xfa, 0xf0, 0xc9, 0xbf, 0x03, 0x00, 0x32, 0xf2, 0xc4, 0xcd, 0x44, 0x14,
+	0x00, 0x00,
 }
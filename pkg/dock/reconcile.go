@@ -0,0 +1,446 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the reconciler which compares the volumes and
+snapshots recorded in database with the ones existing in backend, so that the
+drift between them, such as the volume created by driver but failed to be
+stored in database, could be found and optionally repaired.
+
+*/
+
+package dock
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/opensds/opensds/contrib/drivers"
+	"github.com/opensds/opensds/pkg/db"
+	api "github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
+	"github.com/opensds/opensds/pkg/utils/config"
+	"github.com/opensds/opensds/pkg/utils/metrics"
+)
+
+var reconcileDrift = metrics.NewGaugeVec(
+	"opensds_reconcile_drift",
+	"Number of orphan and missing objects found by the last reconciliation by dock, kind and state.",
+	"dock", "kind", "state",
+)
+
+func init() {
+	metrics.MustRegister(reconcileDrift)
+}
+
+// ReconcileReport describes the drift between database and backend found in
+// one pass of reconciliation. Orphans exist in backend but not in database,
+// and missing ones are recorded in database but no longer exist in backend.
+type ReconcileReport struct {
+	DockId string
+
+	OrphanVolumes    []*api.VolumeSpec
+	MissingVolumes   []*api.VolumeSpec
+	OrphanSnapshots  []*api.VolumeSnapshotSpec
+	MissingSnapshots []*api.VolumeSnapshotSpec
+
+	// The objects which have been repaired in this pass.
+	MarkedVolumes    []*api.VolumeSpec
+	AdoptedVolumes   []*api.VolumeSpec
+	MarkedSnapshots  []*api.VolumeSnapshotSpec
+	AdoptedSnapshots []*api.VolumeSnapshotSpec
+}
+
+// Reconciler reconciles the volumes and snapshots of one dock. Objects are
+// only repaired after they have been found drifted in two passes in a row,
+// so that those in the middle of being created or deleted are left alone.
+type Reconciler struct {
+	Dock *api.DockSpec
+
+	// MarkMissing sets the status of missing volumes and snapshots to error.
+	MarkMissing bool
+	// AdoptOrphans stores the orphan volumes and snapshots into database.
+	AdoptOrphans bool
+
+	Driver drivers.VolumeDriver
+
+	// backend is the shared backend of the dock, which limits the concurrent
+	// requests to it.
+	backend *backend
+
+	mu sync.Mutex
+	// The keys of drifted objects found in last pass.
+	lastDrift map[string]bool
+}
+
+// StartReconcilers runs the reconcilers of the docks set up by SetupBackends
+// in background, every ReconcileInterval seconds. The docks of other hosts are
+// never reconciled, since their backends aren't served here.
+func StartReconcilers(conf *config.OsdsDock) {
+	backendsLock.Lock()
+	var ids []string
+	for id := range backends {
+		ids = append(ids, id)
+	}
+	backendsLock.Unlock()
+
+	interval := time.Duration(conf.ReconcileInterval) * time.Second
+	for _, id := range ids {
+		dck, err := db.C.GetDock(id)
+		if err != nil {
+			log.Errorf("When get dock %s for reconciliation: %v\n", id, err)
+			continue
+		}
+		r := NewReconciler(dck, conf.ReconcileMarkMissing, conf.ReconcileAdoptOrphans)
+		go r.Run(interval, nil)
+	}
+}
+
+func NewReconciler(dck *api.DockSpec, markMissing, adoptOrphans bool) *Reconciler {
+	return &Reconciler{
+		Dock:         dck,
+		MarkMissing:  markMissing,
+		AdoptOrphans: adoptOrphans,
+	}
+}
+
+// Run reconciles the dock every interval until stop is closed.
+func (r *Reconciler) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := r.Reconcile(); err != nil {
+				log.Errorf("When reconcile dock %s: %v\n", r.Dock.GetId(), err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Reconcile runs one pass of reconciliation and returns the report of it.
+func (r *Reconciler) Reconcile() (*ReconcileReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Driver == nil {
+		be, err := getBackend(r.Dock.GetId(), r.Dock.GetDriverName())
+		if err != nil {
+			return nil, err
+		}
+		r.backend, r.Driver = be, be.driver
+	}
+
+	beVols, beSnps, err := r.listBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	pols, err := r.listPools()
+	if err != nil {
+		return nil, err
+	}
+	dbVols, err := db.C.ListVolumes(nil)
+	if err != nil {
+		return nil, err
+	}
+	dbSnps, err := db.C.ListVolumeSnapshots(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rpt = &ReconcileReport{DockId: r.Dock.GetId()}
+
+	// Only the volumes on the pools of this dock are taken into account.
+	var vols []*api.VolumeSpec
+	for _, vol := range dbVols {
+		if pols[vol.GetPoolId()] != nil {
+			vols = append(vols, vol)
+		}
+	}
+	// Map the volume id known by backend to the one recorded in database.
+	var volIds = make(map[string]string)
+	for _, bv := range beVols {
+		v := matchVolume(bv, vols)
+		if v == nil {
+			rpt.OrphanVolumes = append(rpt.OrphanVolumes, bv)
+			continue
+		}
+		if bv.GetId() != "" {
+			volIds[bv.GetId()] = v.GetId()
+		}
+	}
+	for _, vol := range vols {
+		if vol.GetStatus() != api.VolumeError && !containsVolume(beVols, vol) {
+			rpt.MissingVolumes = append(rpt.MissingVolumes, vol)
+		}
+	}
+
+	var snps []*api.VolumeSnapshotSpec
+	var inDock = make(map[string]bool)
+	for _, vol := range vols {
+		inDock[vol.GetId()] = true
+	}
+	for _, snp := range dbSnps {
+		if inDock[snp.GetVolumeId()] {
+			snps = append(snps, snp)
+		}
+	}
+	for _, bs := range beSnps {
+		if matchSnapshot(bs, snps) == nil {
+			rpt.OrphanSnapshots = append(rpt.OrphanSnapshots, bs)
+		}
+	}
+	for _, snp := range snps {
+		if snp.GetStatus() != api.VolumeSnapshotError && !containsSnapshot(beSnps, snp) {
+			rpt.MissingSnapshots = append(rpt.MissingSnapshots, snp)
+		}
+	}
+
+	r.repair(rpt, pols, volIds)
+	r.report(rpt)
+	return rpt, nil
+}
+
+// listBackend lists the volumes and snapshots in backend, which takes one
+// slot of the concurrent requests to the backend.
+func (r *Reconciler) listBackend() ([]*api.VolumeSpec, []*api.VolumeSnapshotSpec, error) {
+	r.backend.acquire()
+	defer r.backend.release()
+	driver := r.Dock.GetDriverName()
+
+	start := time.Now()
+	beVols, err := r.Driver.ListVolumes()
+	observeDriverCall(driver, "list_volumes", start, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	start = time.Now()
+	beSnps, err := r.Driver.ListSnapshots()
+	observeDriverCall(driver, "list_snapshots", start, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beVols, beSnps, nil
+}
+
+// listPools returns the pools of the dock indexed by their ids.
+func (r *Reconciler) listPools() (map[string]*api.StoragePoolSpec, error) {
+	pols, err := db.C.ListPools()
+	if err != nil {
+		return nil, err
+	}
+
+	var res = make(map[string]*api.StoragePoolSpec)
+	for _, pol := range pols {
+		if pol.DockId == r.Dock.GetId() {
+			res[pol.GetId()] = pol
+		}
+	}
+	return res, nil
+}
+
+func (r *Reconciler) repair(rpt *ReconcileReport, pols map[string]*api.StoragePoolSpec, volIds map[string]string) {
+	var drift = make(map[string]bool)
+	var confirmed = func(kind, key string) bool {
+		drift[kind+"/"+key] = true
+		return r.lastDrift[kind+"/"+key]
+	}
+	defer func() { r.lastDrift = drift }()
+
+	for _, vol := range rpt.MissingVolumes {
+		if !confirmed("missing-volume", vol.GetId()) || !r.MarkMissing {
+			continue
+		}
+		if _, err := db.C.UpdateVolume(vol.GetId(), &api.VolumeSpec{Status: api.VolumeError}); err != nil {
+			log.Errorf("When mark missing volume %s as error: %v\n", vol.GetId(), err)
+			continue
+		}
+		r.recordEvent(api.EventResourceVolume, vol.GetId(), "mark_error")
+		rpt.MarkedVolumes = append(rpt.MarkedVolumes, vol)
+	}
+	for _, snp := range rpt.MissingSnapshots {
+		if !confirmed("missing-snapshot", snp.GetId()) || !r.MarkMissing {
+			continue
+		}
+		if _, err := db.C.UpdateVolumeSnapshot(snp.GetId(), &api.VolumeSnapshotSpec{Status: api.VolumeSnapshotError}); err != nil {
+			log.Errorf("When mark missing snapshot %s as error: %v\n", snp.GetId(), err)
+			continue
+		}
+		r.recordEvent(api.EventResourceSnapshot, snp.GetId(), "mark_error")
+		rpt.MarkedSnapshots = append(rpt.MarkedSnapshots, snp)
+	}
+
+	for _, vol := range rpt.OrphanVolumes {
+		if !confirmed("orphan-volume", backendKey(vol.BaseModel, vol.GetMetadata())) || !r.AdoptOrphans {
+			continue
+		}
+		// The pool is unknown if the driver doesn't report it, in which
+		// case the only pool of the dock is the one.
+		if pols[vol.GetPoolId()] == nil {
+			if len(pols) != 1 {
+				log.Warningf("Can't adopt orphan volume %s without knowing its pool\n", vol.GetName())
+				continue
+			}
+			for id := range pols {
+				vol.PoolId = id
+			}
+		}
+		if vol.BaseModel == nil {
+			vol.BaseModel = &api.BaseModel{}
+		}
+		vol.Status = api.VolumeAvailable
+		if err := utils.ValidateData(vol, utils.S); err != nil {
+			log.Error("When validate volume data:", err)
+			continue
+		}
+		if err := db.C.CreateVolume(vol); err != nil {
+			log.Errorf("When adopt orphan volume %s: %v\n", vol.GetId(), err)
+			continue
+		}
+		r.recordEvent(api.EventResourceVolume, vol.GetId(), "adopt")
+		rpt.AdoptedVolumes = append(rpt.AdoptedVolumes, vol)
+		volIds[vol.GetId()] = vol.GetId()
+	}
+	for _, snp := range rpt.OrphanSnapshots {
+		if !confirmed("orphan-snapshot", backendKey(snp.BaseModel, snp.GetMetadata())) || !r.AdoptOrphans {
+			continue
+		}
+		// The snapshot could only be adopted if its volume is managed.
+		volID, ok := volIds[snp.GetVolumeId()]
+		if !ok {
+			log.Warningf("Can't adopt orphan snapshot %s whose volume isn't managed\n", snp.GetName())
+			continue
+		}
+		if snp.BaseModel == nil {
+			snp.BaseModel = &api.BaseModel{}
+		}
+		snp.VolumeId, snp.Status = volID, "available"
+		if err := utils.ValidateData(snp, utils.S); err != nil {
+			log.Error("When validate volume snapshot data:", err)
+			continue
+		}
+		if err := db.C.CreateVolumeSnapshot(snp); err != nil {
+			log.Errorf("When adopt orphan snapshot %s: %v\n", snp.GetId(), err)
+			continue
+		}
+		r.recordEvent(api.EventResourceSnapshot, snp.GetId(), "adopt")
+		rpt.AdoptedSnapshots = append(rpt.AdoptedSnapshots, snp)
+	}
+}
+
+func (r *Reconciler) report(rpt *ReconcileReport) {
+	dck := r.Dock.GetId()
+	reconcileDrift.Set(float64(len(rpt.OrphanVolumes)), dck, "volume", "orphan")
+	reconcileDrift.Set(float64(len(rpt.MissingVolumes)), dck, "volume", "missing")
+	reconcileDrift.Set(float64(len(rpt.OrphanSnapshots)), dck, "snapshot", "orphan")
+	reconcileDrift.Set(float64(len(rpt.MissingSnapshots)), dck, "snapshot", "missing")
+
+	for _, vol := range rpt.OrphanVolumes {
+		log.Warningf("Found orphan volume %s (%s) in backend of dock %s\n", vol.GetName(), vol.GetId(), dck)
+	}
+	for _, vol := range rpt.MissingVolumes {
+		log.Warningf("Found volume %s missing in backend of dock %s\n", vol.GetId(), dck)
+	}
+	for _, snp := range rpt.OrphanSnapshots {
+		log.Warningf("Found orphan snapshot %s (%s) in backend of dock %s\n", snp.GetName(), snp.GetId(), dck)
+	}
+	for _, snp := range rpt.MissingSnapshots {
+		log.Warningf("Found snapshot %s missing in backend of dock %s\n", snp.GetId(), dck)
+	}
+}
+
+func (r *Reconciler) recordEvent(resType, resID, action string) {
+	evt := api.NewEvent(api.EventSourceDock, r.Dock.GetId(), resType, resID, action, nil)
+	if err := utils.ValidateData(evt, utils.S); err != nil {
+		log.Error("When validate event data:", err)
+		return
+	}
+	if err := db.C.CreateEvent(evt); err != nil {
+		log.Error("When create event in db module:", err)
+	}
+}
+
+// matchVolume finds the volume in database which the backend volume refers
+// to. The backend volume is identified by its id if the driver knows it,
+// otherwise by its metadata, such as the path of lvm logic volume.
+func matchVolume(bv *api.VolumeSpec, vols []*api.VolumeSpec) *api.VolumeSpec {
+	for _, vol := range vols {
+		if matchObject(bv.BaseModel, bv.GetMetadata(), vol.GetId(), vol.GetMetadata()) {
+			return vol
+		}
+	}
+	return nil
+}
+
+func containsVolume(beVols []*api.VolumeSpec, vol *api.VolumeSpec) bool {
+	for _, bv := range beVols {
+		if matchObject(bv.BaseModel, bv.GetMetadata(), vol.GetId(), vol.GetMetadata()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSnapshot(bs *api.VolumeSnapshotSpec, snps []*api.VolumeSnapshotSpec) *api.VolumeSnapshotSpec {
+	for _, snp := range snps {
+		if matchObject(bs.BaseModel, bs.GetMetadata(), snp.GetId(), snp.GetMetadata()) {
+			return snp
+		}
+	}
+	return nil
+}
+
+func containsSnapshot(beSnps []*api.VolumeSnapshotSpec, snp *api.VolumeSnapshotSpec) bool {
+	for _, bs := range beSnps {
+		if matchObject(bs.BaseModel, bs.GetMetadata(), snp.GetId(), snp.GetMetadata()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchObject(bm *api.BaseModel, beMeta map[string]string, id string, meta map[string]string) bool {
+	if bm != nil && bm.Id != "" {
+		return bm.Id == id
+	}
+	if len(beMeta) == 0 {
+		return false
+	}
+	for k, v := range beMeta {
+		if meta[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// backendKey identifies the backend object across passes.
+func backendKey(bm *api.BaseModel, meta map[string]string) string {
+	if bm != nil && bm.Id != "" {
+		return bm.Id
+	}
+	var kvs []string
+	for k, v := range meta {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package dock
+
+import (
+	"os"
+	"testing"
+
+	"github.com/opensds/opensds/contrib/drivers/sample"
+	"github.com/opensds/opensds/pkg/db"
+	dbtest "github.com/opensds/opensds/pkg/db/testing"
+	api "github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils/config"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeDriver reports the volumes and snapshots given in the test as the ones
+// existing in backend.
+type fakeDriver struct {
+	sample.Driver
+
+	vols []*api.VolumeSpec
+	snps []*api.VolumeSnapshotSpec
+}
+
+func (d *fakeDriver) ListVolumes() ([]*api.VolumeSpec, error) { return d.vols, nil }
+
+func (d *fakeDriver) ListSnapshots() ([]*api.VolumeSnapshotSpec, error) { return d.snps, nil }
+
+func TestReconcile(t *testing.T) {
+	var dck = &api.DockSpec{
+		BaseModel:  &api.BaseModel{Id: "b7602e18-771e-11e7-8f38-dbd6d291f4e0"},
+		DriverName: "sample",
+	}
+	var pols = []*api.StoragePoolSpec{
+		{BaseModel: &api.BaseModel{Id: "pool-01"}, DockId: dck.Id},
+		{BaseModel: &api.BaseModel{Id: "pool-02"}, DockId: "another-dock"},
+	}
+	var vols = []*api.VolumeSpec{
+		{BaseModel: &api.BaseModel{Id: "volume-a"}, PoolId: "pool-01", Status: api.VolumeAvailable},
+		{BaseModel: &api.BaseModel{Id: "volume-b"}, PoolId: "pool-01", Status: api.VolumeAvailable},
+		// The volume belongs to another dock should be ignored.
+		{BaseModel: &api.BaseModel{Id: "volume-x"}, PoolId: "pool-02", Status: api.VolumeAvailable},
+		// The volume identified by the metadata of backend, such as lvm.
+		{BaseModel: &api.BaseModel{Id: "volume-l"}, PoolId: "pool-01", Status: api.VolumeAvailable,
+			Metadata: map[string]string{"lvPath": "/dev/vg001/volume-l", "maxIOPS": "100"}},
+	}
+	var snps = []*api.VolumeSnapshotSpec{
+		{BaseModel: &api.BaseModel{Id: "snapshot-a"}, VolumeId: "volume-a"},
+	}
+	var d = &fakeDriver{
+		vols: []*api.VolumeSpec{
+			{BaseModel: &api.BaseModel{Id: "volume-a"}},
+			{BaseModel: &api.BaseModel{Id: "volume-c"}, Name: "orphan"},
+			{BaseModel: &api.BaseModel{}, Metadata: map[string]string{"lvPath": "/dev/vg001/volume-l"}},
+		},
+		snps: []*api.VolumeSnapshotSpec{
+			{BaseModel: &api.BaseModel{Id: "snapshot-a"}, VolumeId: "volume-a"},
+			{BaseModel: &api.BaseModel{Id: "snapshot-c"}, VolumeId: "volume-c"},
+		},
+	}
+
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListPools").Return(pols, nil)
+	mockClient.On("ListVolumes", (*api.ListOptions)(nil)).Return(vols, nil)
+	mockClient.On("ListVolumeSnapshots", (*api.ListOptions)(nil)).Return(snps, nil)
+	mockClient.On("UpdateVolume", "volume-b", mock.Anything).Return(vols[1], nil)
+	mockClient.On("CreateVolume", mock.Anything).Return(nil)
+	mockClient.On("CreateVolumeSnapshot", mock.Anything).Return(nil)
+	mockClient.On("CreateEvent", mock.Anything).Return(nil)
+	db.C = mockClient
+
+	r := NewReconciler(dck, true, true)
+	r.Driver = d
+
+	rpt, err := r.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rpt.OrphanVolumes) != 1 || rpt.OrphanVolumes[0].Id != "volume-c" {
+		t.Errorf("Expected orphan volume volume-c, got %v", rpt.OrphanVolumes)
+	}
+	if len(rpt.MissingVolumes) != 1 || rpt.MissingVolumes[0].Id != "volume-b" {
+		t.Errorf("Expected missing volume volume-b, got %v", rpt.MissingVolumes)
+	}
+	if len(rpt.OrphanSnapshots) != 1 || rpt.OrphanSnapshots[0].Id != "snapshot-c" {
+		t.Errorf("Expected orphan snapshot snapshot-c, got %v", rpt.OrphanSnapshots)
+	}
+	if len(rpt.MissingSnapshots) != 0 {
+		t.Errorf("Expected no missing snapshot, got %v", rpt.MissingSnapshots)
+	}
+	// Nothing is repaired until the drift is found again in next pass.
+	if len(rpt.MarkedVolumes)+len(rpt.AdoptedVolumes)+len(rpt.AdoptedSnapshots) != 0 {
+		t.Errorf("Expected nothing repaired in first pass, got %v", rpt)
+	}
+
+	rpt, err = r.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rpt.MarkedVolumes) != 1 || rpt.MarkedVolumes[0].Id != "volume-b" {
+		t.Errorf("Expected volume-b marked as error, got %v", rpt.MarkedVolumes)
+	}
+	if len(rpt.AdoptedVolumes) != 1 || rpt.AdoptedVolumes[0].PoolId != "pool-01" ||
+		rpt.AdoptedVolumes[0].Status != api.VolumeAvailable {
+		t.Errorf("Expected volume-c adopted on pool-01, got %v", rpt.AdoptedVolumes)
+	}
+	if len(rpt.AdoptedSnapshots) != 1 || rpt.AdoptedSnapshots[0].VolumeId != "volume-c" {
+		t.Errorf("Expected snapshot-c adopted, got %v", rpt.AdoptedSnapshots)
+	}
+	mockClient.AssertCalled(t, "UpdateVolume", "volume-b", &api.VolumeSpec{Status: api.VolumeError})
+}
+
+func TestReconcileReportOnly(t *testing.T) {
+	var dck = &api.DockSpec{
+		BaseModel:  &api.BaseModel{Id: "b7602e18-771e-11e7-8f38-dbd6d291f4e0"},
+		DriverName: "sample",
+	}
+	var vols = []*api.VolumeSpec{
+		{BaseModel: &api.BaseModel{Id: "volume-b"}, PoolId: "pool-01", Status: api.VolumeAvailable},
+	}
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("ListPools").Return([]*api.StoragePoolSpec{
+		{BaseModel: &api.BaseModel{Id: "pool-01"}, DockId: dck.Id},
+	}, nil)
+	mockClient.On("ListVolumes", (*api.ListOptions)(nil)).Return(vols, nil)
+	mockClient.On("ListVolumeSnapshots", (*api.ListOptions)(nil)).
+		Return([]*api.VolumeSnapshotSpec{}, nil)
+	db.C = mockClient
+
+	r := NewReconciler(dck, false, false)
+	r.Driver = &fakeDriver{
+		vols: []*api.VolumeSpec{{BaseModel: &api.BaseModel{Id: "volume-c"}}},
+	}
+	for i := 0; i < 2; i++ {
+		rpt, err := r.Reconcile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rpt.OrphanVolumes) != 1 || len(rpt.MissingVolumes) != 1 {
+			t.Errorf("Expected one orphan and one missing volume, got %v", rpt)
+		}
+	}
+	mockClient.AssertNotCalled(t, "UpdateVolume", mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "CreateVolume", mock.Anything)
+}
+
+func TestStartReconcilers(t *testing.T) {
+	defer UnsetBackends()
+	if err := SetupBackends(map[string]config.BackendProperties{
+		"sample": {Name: "sample", DriverName: "sample"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	id := DockId(host, "sample")
+
+	// Only the docks set up here are reconciled, the docks of other hosts
+	// aren't even listed.
+	mockClient := new(dbtest.MockClient)
+	mockClient.On("GetDock", id).Return(&api.DockSpec{BaseModel: &api.BaseModel{Id: id}, DriverName: "sample"}, nil)
+	db.C = mockClient
+
+	StartReconcilers(&config.OsdsDock{ReconcileInterval: 3600})
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the metrics of dock rpc calls, which are exposed on
+/metrics endpoint of dock metrics service together with the driver metrics:
+
+  opensds_dock_rpc_requests_total{rpc,code}
+      counter of the dock rpc calls, code is the grpc status code.
+  opensds_dock_rpc_duration_seconds{rpc}
+      histogram of the latencies of dock rpc calls.
+
+*/
+
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/opensds/opensds/pkg/utils/metrics"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var (
+	rpcRequestsTotal = metrics.NewCounterVec(
+		"opensds_dock_rpc_requests_total",
+		"Total number of dock rpc calls by rpc and grpc status code.",
+		"rpc", "code",
+	)
+	rpcDuration = metrics.NewHistogramVec(
+		"opensds_dock_rpc_duration_seconds",
+		"Latency of dock rpc calls in seconds by rpc.",
+		metrics.DefBuckets, "rpc",
+	)
+)
+
+func init() {
+	metrics.MustRegister(rpcRequestsTotal, rpcDuration)
+}
+
+// metricsInterceptor observes the count and latency of every unary rpc call.
+func metricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	rpc := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	rpcRequestsTotal.Inc(rpc, grpc.Code(err).String())
+	rpcDuration.Observe(time.Since(start).Seconds(), rpc)
+	return resp, err
+}
+
+// ServeMetrics starts the http service exposing /metrics endpoint for
+// prometheus to scrape, it's skipped if the endpoint is empty.
+func ServeMetrics(endpoint string) {
+	if endpoint == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	go func() {
+		log.Info("Dock metrics service start listening on:", endpoint)
+		if err := http.ListenAndServe(endpoint, mux); err != nil {
+			log.Error("Dock metrics service stopped:", err)
+		}
+	}()
+}
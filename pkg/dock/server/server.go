@@ -43,8 +43,12 @@ import (
 	"fmt"
 	"net"
 
+	osdsctx "github.com/opensds/opensds/pkg/context"
+	"github.com/opensds/opensds/pkg/db"
 	"github.com/opensds/opensds/pkg/dock"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
+	"github.com/opensds/opensds/pkg/model"
+	"github.com/opensds/opensds/pkg/utils"
 
 	log "github.com/golang/glog"
 	"golang.org/x/net/context"
@@ -60,7 +64,7 @@ type dockServer struct {
 // NewDockServer returns an dockServer instance.
 func NewDockServer(port string) pb.DockServer {
 	// Construct dock server.
-	gs := grpc.NewServer()
+	gs := grpc.NewServer(grpc.UnaryInterceptor(metricsInterceptor))
 	ds := &dockServer{
 		Server: gs,
 		Port:   port,
@@ -74,30 +78,57 @@ func NewDockServer(port string) pb.DockServer {
 
 // CreateVolume implements opensds.DockServer
 func (ds *dockServer) CreateVolume(ctx context.Context, opt *pb.CreateVolumeOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
 	var res pb.GenericResponse
 
-	log.Info("Dock server receive create volume request, vr =", opt)
+	logger.Info("Dock server receive create volume request, vr =", opt)
 
-	vol, err := dock.NewDockHub(opt.GetDriverName()).CreateVolume(opt)
+	vol, err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).CreateVolume(ctx, opt)
 	if err != nil {
-		log.Error("When create volume in dock module:", err)
+		recordEvent(ctx, opt.GetDockId(), model.EventResourceVolume, opt.GetId(), "create", err)
+		logger.Error("When create volume in dock module:", err)
 
 		res.Reply = GenericResponseError("400", fmt.Sprint(err))
 		return &res, err
 	}
 
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceVolume, vol.GetId(), "create", nil)
+	res.Reply = GenericResponseResult(vol)
+	return &res, nil
+}
+
+// ManageVolume implements opensds.DockServer
+func (ds *dockServer) ManageVolume(ctx context.Context, opt *pb.ManageVolumeOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
+	var res pb.GenericResponse
+
+	logger.Info("Dock server receive manage volume request, vr =", opt)
+
+	vol, err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).ManageVolume(ctx, opt)
+	if err != nil {
+		recordEvent(ctx, opt.GetDockId(), model.EventResourceVolume, opt.GetIdentifier(), "manage", err)
+		logger.Error("When manage volume in dock module:", err)
+
+		res.Reply = GenericResponseError("400", fmt.Sprint(err))
+		return &res, err
+	}
+
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceVolume, vol.GetId(), "manage", nil)
 	res.Reply = GenericResponseResult(vol)
 	return &res, nil
 }
 
 // DeleteVolume implements opensds.DockServer
 func (ds *dockServer) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
 	var res pb.GenericResponse
 
-	log.Info("Dock server receive delete volume request, vr =", opt)
+	logger.Info("Dock server receive delete volume request, vr =", opt)
 
-	if err := dock.NewDockHub(opt.GetDriverName()).DeleteVolume(opt); err != nil {
-		log.Error("Error occured in dock module when delete volume:", err)
+	err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).DeleteVolume(ctx, opt)
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceVolume, opt.GetId(), "delete", err)
+	if err != nil {
+		logger.Error("Error occured in dock module when delete volume:", err)
 
 		res.Reply = GenericResponseError("400", fmt.Sprint(err))
 		return &res, err
@@ -109,30 +140,36 @@ func (ds *dockServer) DeleteVolume(ctx context.Context, opt *pb.DeleteVolumeOpts
 
 // CreateAttachment implements opensds.DockServer
 func (ds *dockServer) CreateAttachment(ctx context.Context, opt *pb.CreateAttachmentOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
 	var res pb.GenericResponse
 
-	log.Info("Dock server receive create volume attachment request, vr =", opt)
+	logger.Info("Dock server receive create volume attachment request, vr =", opt)
 
-	atc, err := dock.NewDockHub(opt.GetDriverName()).CreateVolumeAttachment(opt)
+	atc, err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).CreateVolumeAttachment(ctx, opt)
 	if err != nil {
-		log.Error("Error occured in dock module when create volume attachment:", err)
+		recordEvent(ctx, opt.GetDockId(), model.EventResourceAttachment, opt.GetId(), "create", err)
+		logger.Error("Error occured in dock module when create volume attachment:", err)
 
 		res.Reply = GenericResponseError("400", fmt.Sprint(err))
 		return &res, err
 	}
 
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceAttachment, atc.GetId(), "create", nil)
 	res.Reply = GenericResponseResult(atc)
 	return &res, nil
 }
 
 // DeleteAttachment implements opensds.DockServer
 func (ds *dockServer) DeleteAttachment(ctx context.Context, opt *pb.DeleteAttachmentOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
 	var res pb.GenericResponse
 
-	log.Info("Dock server receive delete volume attachment request, vr =", opt)
+	logger.Info("Dock server receive delete volume attachment request, vr =", opt)
 
-	if err := dock.NewDockHub(opt.GetDriverName()).DeleteVolumeAttachment(opt); err != nil {
-		log.Error("Error occured in dock module when delete volume attachment:", err)
+	err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).DeleteVolumeAttachment(ctx, opt)
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceAttachment, opt.GetId(), "delete", err)
+	if err != nil {
+		logger.Error("Error occured in dock module when delete volume attachment:", err)
 
 		res.Reply = GenericResponseError("400", fmt.Sprint(err))
 		return &res, err
@@ -142,31 +179,77 @@ func (ds *dockServer) DeleteAttachment(ctx context.Context, opt *pb.DeleteAttach
 	return &res, nil
 }
 
+// UpdateAttachment implements opensds.DockServer
+func (ds *dockServer) UpdateAttachment(ctx context.Context, opt *pb.UpdateAttachmentOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
+	var res pb.GenericResponse
+
+	logger.Info("Dock server receive update volume attachment request, vr =", opt)
+
+	atc, err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).UpdateVolumeAttachment(ctx, opt)
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceAttachment, opt.GetId(), "update", err)
+	if err != nil {
+		logger.Error("Error occured in dock module when update volume attachment:", err)
+
+		res.Reply = GenericResponseError("400", fmt.Sprint(err))
+		return &res, err
+	}
+
+	res.Reply = GenericResponseResult(atc)
+	return &res, nil
+}
+
 // CreateVolumeSnapshot implements opensds.DockServer
 func (ds *dockServer) CreateVolumeSnapshot(ctx context.Context, opt *pb.CreateVolumeSnapshotOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
 	var res pb.GenericResponse
 
-	log.Info("Dock server receive create volume snapshot request, vr =", opt)
+	logger.Info("Dock server receive create volume snapshot request, vr =", opt)
 
-	snp, err := dock.NewDockHub(opt.GetDriverName()).CreateSnapshot(opt)
+	snp, err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).CreateSnapshot(ctx, opt)
 	if err != nil {
-		log.Error("Error occured in dock module when create snapshot:", err)
+		recordEvent(ctx, opt.GetDockId(), model.EventResourceSnapshot, opt.GetId(), "create", err)
+		logger.Error("Error occured in dock module when create snapshot:", err)
 		res.Reply = GenericResponseError("400", fmt.Sprint(err))
 		return &res, err
 	}
 
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceSnapshot, snp.GetId(), "create", nil)
+	res.Reply = GenericResponseResult(snp)
+	return &res, nil
+}
+
+// ManageVolumeSnapshot implements opensds.DockServer
+func (ds *dockServer) ManageVolumeSnapshot(ctx context.Context, opt *pb.ManageVolumeSnapshotOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
+	var res pb.GenericResponse
+
+	logger.Info("Dock server receive manage volume snapshot request, vr =", opt)
+
+	snp, err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).ManageSnapshot(ctx, opt)
+	if err != nil {
+		recordEvent(ctx, opt.GetDockId(), model.EventResourceSnapshot, opt.GetIdentifier(), "manage", err)
+		logger.Error("Error occured in dock module when manage snapshot:", err)
+		res.Reply = GenericResponseError("400", fmt.Sprint(err))
+		return &res, err
+	}
+
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceSnapshot, snp.GetId(), "manage", nil)
 	res.Reply = GenericResponseResult(snp)
 	return &res, nil
 }
 
 // DeleteVolumeSnapshot implements opensds.DockServer
 func (ds *dockServer) DeleteVolumeSnapshot(ctx context.Context, opt *pb.DeleteVolumeSnapshotOpts) (*pb.GenericResponse, error) {
+	logger := osdsctx.GetLogger(ctx)
 	var res pb.GenericResponse
 
-	log.Info("Dock server receive delete volume snapshot request, vr =", opt)
+	logger.Info("Dock server receive delete volume snapshot request, vr =", opt)
 
-	if err := dock.NewDockHub(opt.GetDriverName()).DeleteSnapshot(opt); err != nil {
-		log.Error("Error occured in dock module when delete snapshot:", err)
+	err := dock.NewDockHub(opt.GetDockId(), opt.GetDriverName()).DeleteSnapshot(ctx, opt)
+	recordEvent(ctx, opt.GetDockId(), model.EventResourceSnapshot, opt.GetId(), "delete", err)
+	if err != nil {
+		logger.Error("Error occured in dock module when delete snapshot:", err)
 
 		res.Reply = GenericResponseError("400", fmt.Sprint(err))
 		return &res, err
@@ -176,6 +259,22 @@ func (ds *dockServer) DeleteVolumeSnapshot(ctx context.Context, opt *pb.DeleteVo
 	return &res, nil
 }
 
+// recordEvent stores the result of an operation handled by the dock, the
+// failure of storing it is only logged.
+func recordEvent(ctx context.Context, dockID, resType, resID, action string, err error) {
+	logger := osdsctx.GetLogger(ctx)
+
+	evt := model.NewEvent(model.EventSourceDock, dockID, resType, resID, action, err)
+	evt.RequestId = osdsctx.RequestId(ctx)
+	if err := utils.ValidateData(evt, utils.S); err != nil {
+		logger.Error("When validate event data:", err)
+		return
+	}
+	if err := db.C.CreateEvent(evt); err != nil {
+		logger.Error("When create event in db module:", err)
+	}
+}
+
 func ListenAndServe(srv pb.DockServer) {
 	// Find whether the type of input is supported.
 	switch srv.(type) {
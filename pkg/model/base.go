@@ -29,12 +29,20 @@ type Modeler interface {
 	SetCreatedTime(createdAt string)
 
 	SetUpdatedTime(updatedAt string)
+
+	GetResourceVersion() int64
+
+	SetResourceVersion(version int64)
 }
 
 type BaseModel struct {
 	Id        string `json:"id"`
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
+	// ResourceVersion changes whenever the resource is modified. If it's
+	// given in an update request, the update is rejected with a conflict
+	// unless the resource is still in that version.
+	ResourceVersion int64 `json:"resourceVersion,omitempty"`
 }
 
 func (b *BaseModel) GetId() string {
@@ -60,3 +68,16 @@ func (b *BaseModel) SetCreatedTime(createdAt string) {
 func (b *BaseModel) SetUpdatedTime(updatedAt string) {
 	b.UpdatedAt = updatedAt
 }
+
+// GetResourceVersion returns zero if the base model isn't set, which stands
+// for any version.
+func (b *BaseModel) GetResourceVersion() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.ResourceVersion
+}
+
+func (b *BaseModel) SetResourceVersion(version int64) {
+	b.ResourceVersion = version
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+const (
+	// The components which record events.
+	EventSourceController = "controller"
+	EventSourceDock       = "dock"
+
+	// The types of resources which events are recorded for.
+	EventResourceVolume     = "volume"
+	EventResourceAttachment = "attachment"
+	EventResourceSnapshot   = "snapshot"
+	EventResourceProfile    = "profile"
+	EventResourceTransfer   = "transfer"
+
+	// The outcomes of an operation.
+	EventOutcomeSuccess = "Success"
+	EventOutcomeFailure = "Failure"
+)
+
+// EventSpec is an audit record of one operation on a resource, which answers
+// who did what to which resource and how it ended up.
+type EventSpec struct {
+	*BaseModel
+	// Actor is the one who triggered the operation, such as the address of
+	// the api client or the dock id.
+	Actor        string `json:"actor,omitempty"`
+	Source       string `json:"source,omitempty"`
+	ResourceType string `json:"resourceType,omitempty"`
+	ResourceId   string `json:"resourceId,omitempty"`
+	Action       string `json:"action,omitempty"`
+	Outcome      string `json:"outcome,omitempty"`
+	Error        string `json:"error,omitempty"`
+	RequestId    string `json:"requestId,omitempty"`
+}
+
+// NewEvent builds an event with the outcome decided by err.
+func NewEvent(source, actor, resType, resID, action string, err error) *EventSpec {
+	evt := &EventSpec{
+		BaseModel:    &BaseModel{},
+		Actor:        actor,
+		Source:       source,
+		ResourceType: resType,
+		ResourceId:   resID,
+		Action:       action,
+		Outcome:      EventOutcomeSuccess,
+	}
+	if err != nil {
+		evt.Outcome = EventOutcomeFailure
+		evt.Error = err.Error()
+	}
+	return evt
+}
+
+func (evt *EventSpec) GetActor() string {
+	return evt.Actor
+}
+
+func (evt *EventSpec) GetSource() string {
+	return evt.Source
+}
+
+func (evt *EventSpec) GetResourceType() string {
+	return evt.ResourceType
+}
+
+func (evt *EventSpec) GetResourceId() string {
+	return evt.ResourceId
+}
+
+func (evt *EventSpec) GetAction() string {
+	return evt.Action
+}
+
+func (evt *EventSpec) GetOutcome() string {
+	return evt.Outcome
+}
+
+func (evt *EventSpec) GetError() string {
+	return evt.Error
+}
+
+func (evt *EventSpec) GetRequestId() string {
+	return evt.RequestId
+}
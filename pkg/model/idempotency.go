@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// The statuses of idempotency key.
+const (
+	IdempotencyKeyPending   = "pending"
+	IdempotencyKeyCompleted = "completed"
+)
+
+// IdempotencyKeySpec maps the idempotency key given by client, which is the
+// id of it, to the result of the create request. The replays of the request
+// get the original result instead of creating a duplicate resource.
+type IdempotencyKeySpec struct {
+	*BaseModel
+	// Resource is the type of resource created by the request, and the key
+	// can't be reused for creating another type of resource.
+	Resource string `json:"resource"`
+	// Fingerprint is the hash of the method, path and body of the request,
+	// and the key can't be reused for another request.
+	Fingerprint string `json:"fingerprint"`
+	Status      string `json:"status"`
+	// Code and Result are the status code and body of the original response.
+	Code   int             `json:"code,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+func (key *IdempotencyKeySpec) GetResource() string {
+	return key.Resource
+}
+
+func (key *IdempotencyKeySpec) GetStatus() string {
+	return key.Status
+}
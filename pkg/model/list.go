@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+const (
+	SortDirAsc  = "asc"
+	SortDirDesc = "desc"
+
+	// The resources are sorted by the time they are created by default.
+	DefaultSortKey = "createdAt"
+)
+
+// ListOptions represents the pagination, sorting and filtering options of
+// listing resources. The keys of sorting and filtering are the json names of
+// resource fields, such as name, status, poolId and profileId.
+type ListOptions struct {
+	// Limit is the max number of resources returned, 0 means no limit.
+	Limit int
+	// Offset is the number of resources skipped after the marker.
+	Offset int
+	// Marker is the id of the last resource in the previous page, and the
+	// resources after it are returned.
+	Marker string
+
+	SortKey string
+	SortDir string
+
+	// Filters selects the resources whose fields equal to the values.
+	Filters map[string]string
+	// Metadata selects the resources which contain all of the metadata.
+	Metadata map[string]string
+	// CreatedSince and CreatedUntil select the resources created in the time
+	// range, which are in the format of utils.TimeFormat. Either of them
+	// could be empty to leave the range open.
+	CreatedSince string
+	CreatedUntil string
+}
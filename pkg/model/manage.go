@@ -0,0 +1,44 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+// VolumeManageSpec is the request of managing an existing volume of the
+// backend, which is identified by the backend, such as the image name of
+// ceph rbd or the path of lvm logic volume.
+type VolumeManageSpec struct {
+	Identifier  string            `json:"identifier"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	DockId      string            `json:"dockId,omitempty"`
+	PoolId      string            `json:"poolId"`
+	ProfileId   string            `json:"profileId,omitempty"`
+	ProjectId   string            `json:"projectId,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// VolumeSnapshotManageSpec is the request of managing an existing snapshot
+// of the backend, whose volume should have been managed.
+type VolumeSnapshotManageSpec struct {
+	Identifier  string            `json:"identifier"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	VolumeId    string            `json:"volumeId"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
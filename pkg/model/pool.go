@@ -23,6 +23,11 @@ import (
 	"encoding/json"
 )
 
+// QosSupportedKey is the capability flag reported in pool parameters by the
+// storage drivers which are able to enforce qos limits (iops and bandwidth)
+// on a volume.
+const QosSupportedKey = "qosSupported"
+
 type StoragePoolSpec struct {
 	*BaseModel
 	Name             string                 `json:"name,omitempty"`
@@ -19,6 +19,15 @@ This module implements the common data structure.
 
 package model
 
+// The resources limited by the quota of a project.
+const (
+	QuotaVolumes   = "volumes"
+	QuotaGigabytes = "gigabytes"
+)
+
+// QuotaSpec limits the resources a project could own, the id of a quota is
+// the id of the project. The resource without limit in ResourceList is
+// unlimited.
 type QuotaSpec struct {
 	*BaseModel
 	Name         string           `json:"name,omitempty"`
@@ -0,0 +1,187 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+)
+
+// ExtraSchemaVersion is the version of profile extras schema, it should be
+// bumped whenever a property is added, removed or changed incompatibly.
+const ExtraSchemaVersion = "v1"
+
+const (
+	ExtraTypeString  = "string"
+	ExtraTypeInteger = "integer"
+	ExtraTypeBoolean = "boolean"
+)
+
+// ExtraPropertySchema describes the name, value type, unit and allowed range
+// of a profile extra property.
+type ExtraPropertySchema struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	Minimum     *int64   `json:"minimum,omitempty"`
+	Maximum     *int64   `json:"maximum,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+
+	// pattern is compiled from Pattern by Compile.
+	pattern *regexp.Regexp
+}
+
+// Compile checks the type of the property and compiles its pattern, so that
+// values are validated without compiling the pattern every time. It should be
+// called before the schema is published.
+func (p *ExtraPropertySchema) Compile() error {
+	switch p.Type {
+	case ExtraTypeString, ExtraTypeInteger, ExtraTypeBoolean:
+	default:
+		return fmt.Errorf("unknown type %s", p.Type)
+	}
+	if p.Pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %s: %v", p.Pattern, err)
+	}
+	p.pattern = re
+	return nil
+}
+
+// ExtraSchema is the typed and versioned schema of profile extras, which is
+// used for validating profiles and published for rendering profile forms.
+type ExtraSchema struct {
+	Version    string                          `json:"version"`
+	Properties map[string]*ExtraPropertySchema `json:"properties"`
+}
+
+// ProfileExtraSchema is the schema of all profile extras supported currently,
+// the properties are filled in when the storage policies are registered.
+var ProfileExtraSchema = &ExtraSchema{
+	Version:    ExtraSchemaVersion,
+	Properties: make(map[string]*ExtraPropertySchema),
+}
+
+// Validate checks if all the extra properties are defined in the schema and
+// match their types and allowed ranges.
+func (s *ExtraSchema) Validate(ext ExtraSpec) error {
+	// Sort the keys to report errors in a stable order.
+	var keys []string
+	for k := range ext {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p, ok := s.Properties[k]
+		if !ok {
+			return fmt.Errorf("extra property %s is not supported", k)
+		}
+		if err := p.Validate(ext[k]); err != nil {
+			return fmt.Errorf("extra property %s %v", k, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks if the value matches the type and allowed range of the
+// property.
+func (p *ExtraPropertySchema) Validate(val interface{}) error {
+	switch p.Type {
+	case ExtraTypeBoolean:
+		if _, ok := val.(bool); !ok {
+			return fmt.Errorf("must be a boolean, got %v", val)
+		}
+	case ExtraTypeString:
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("must be a string, got %v", val)
+		}
+		if len(p.Enum) != 0 && !containsString(p.Enum, s) {
+			return fmt.Errorf("must be one of %v, got %s", p.Enum, s)
+		}
+		if p.Pattern != "" {
+			re := p.pattern
+			if re == nil {
+				// The schema isn't compiled, such as the one decoded from
+				// json.
+				var err error
+				if re, err = regexp.Compile(p.Pattern); err != nil {
+					return fmt.Errorf("has invalid pattern %s in schema", p.Pattern)
+				}
+			}
+			if !re.MatchString(s) {
+				return fmt.Errorf("must match %s, got %s", p.Pattern, s)
+			}
+		}
+	case ExtraTypeInteger:
+		i, ok := toInteger(val)
+		if !ok {
+			return fmt.Errorf("must be an integer, got %v", val)
+		}
+		if p.Minimum != nil && i < *p.Minimum {
+			return fmt.Errorf("must be no less than %d %s, got %d", *p.Minimum, p.Unit, i)
+		}
+		if p.Maximum != nil && i > *p.Maximum {
+			return fmt.Errorf("must be no more than %d %s, got %d", *p.Maximum, p.Unit, i)
+		}
+	default:
+		return fmt.Errorf("has unknown type %s in schema", p.Type)
+	}
+	return nil
+}
+
+func toInteger(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		// Numbers decoded from json are always float64.
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int64(v), true
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+// VolumeTransferSpec hands a volume from the project which owns it to
+// another project without copying the data. The auth key is only returned
+// when the transfer is created, only the salted hash of it is recorded.
+type VolumeTransferSpec struct {
+	*BaseModel
+	Name     string `json:"name,omitempty"`
+	VolumeId string `json:"volumeId,omitempty"`
+	// ProjectId is the project which the volume is transferred from.
+	ProjectId   string `json:"projectId,omitempty"`
+	AuthKey     string `json:"authKey,omitempty"`
+	Salt        string `json:"salt,omitempty"`
+	AuthKeyHash string `json:"authKeyHash,omitempty"`
+}
+
+func (tr *VolumeTransferSpec) GetName() string {
+	return tr.Name
+}
+
+func (tr *VolumeTransferSpec) GetVolumeId() string {
+	return tr.VolumeId
+}
+
+func (tr *VolumeTransferSpec) GetProjectId() string {
+	return tr.ProjectId
+}
+
+func (tr *VolumeTransferSpec) GetAuthKey() string {
+	return tr.AuthKey
+}
+
+// VolumeTransferAcceptSpec is the request of accepting a transfer, which is
+// sent by the project the volume is transferred to.
+type VolumeTransferAcceptSpec struct {
+	AuthKey   string `json:"authKey"`
+	ProjectId string `json:"projectId"`
+}
+
+func (acc *VolumeTransferAcceptSpec) GetAuthKey() string {
+	return acc.AuthKey
+}
+
+func (acc *VolumeTransferAcceptSpec) GetProjectId() string {
+	return acc.ProjectId
+}
@@ -23,6 +23,16 @@ import (
 	"encoding/json"
 )
 
+// The statuses of volume which are maintained by opensds, the other ones
+// are reported by drivers.
+const (
+	VolumeAvailable        = "available"
+	VolumeInUse            = "in-use"
+	VolumeAwaitingTransfer = "awaiting-transfer"
+	// VolumeError is set on the volume whose backend data is found missing.
+	VolumeError = "error"
+)
+
 type VolumeSpec struct {
 	*BaseModel
 	Name             string            `json:"name,omitempty"`
@@ -32,7 +42,12 @@ type VolumeSpec struct {
 	Status           string            `json:"status,omitempty"`
 	PoolId           string            `json:"poolId,omitempty"`
 	ProfileId        string            `json:"profileId,omitempty"`
-	Metadata         map[string]string `json:"metadata, omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	// Multiattach indicates whether the volume could be attached to more
+	// than one host at the same time.
+	Multiattach bool `json:"multiattach,omitempty"`
+	// ProjectId is the project which owns the volume.
+	ProjectId string `json:"projectId,omitempty"`
 }
 
 func (vol *VolumeSpec) GetName() string {
@@ -63,6 +78,18 @@ func (vol *VolumeSpec) GetMetadata() map[string]string {
 	return vol.Metadata
 }
 
+func (vol *VolumeSpec) GetStatus() string {
+	return vol.Status
+}
+
+func (vol *VolumeSpec) GetProjectId() string {
+	return vol.ProjectId
+}
+
+func (vol *VolumeSpec) GetMultiattach() bool {
+	return vol.Multiattach
+}
+
 type VolumeAttachmentSpec struct {
 	*BaseModel
 	Name            string            `json:"name,omitempty"`
@@ -70,7 +97,7 @@ type VolumeAttachmentSpec struct {
 	VolumeId        string            `json:"volumeId,omitempty"`
 	Mountpoint      string            `json:"mountpoint,omitempty"`
 	Status          string            `json:"status,omitempty"`
-	Metadata        map[string]string `json:"metadata, omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
 	*HostInfo       `json:"hostInfo,omitempty"`
 	*ConnectionInfo `json:"connectionInfo,omitempty"`
 }
@@ -145,6 +172,10 @@ func (con *ConnectionInfo) EncodeConnectionData() []byte {
 	return conBody
 }
 
+// VolumeSnapshotError is set on the snapshot whose backend data is found
+// missing.
+const VolumeSnapshotError = "error"
+
 type VolumeSnapshotSpec struct {
 	*BaseModel
 	Name        string            `json:"name,omitempty"`
@@ -152,7 +183,7 @@ type VolumeSnapshotSpec struct {
 	Size        int64             `json:"size,omitempty"`
 	Status      string            `json:"status,omitempty"`
 	VolumeId    string            `json:"volumeId,omitempty"`
-	Metadata    map[string]string `json:"metadata, omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 func (snp *VolumeSnapshotSpec) GetName() string {
@@ -167,6 +198,10 @@ func (snp *VolumeSnapshotSpec) GetSize() int64 {
 	return snp.Size
 }
 
+func (snp *VolumeSnapshotSpec) GetStatus() string {
+	return snp.Status
+}
+
 func (snp *VolumeSnapshotSpec) GetVolumeId() string {
 	return snp.VolumeId
 }
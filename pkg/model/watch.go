@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements the common data structure.
+
+*/
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// The types of changes pushed to watchers.
+const (
+	WatchEventCreated = "created"
+	WatchEventUpdated = "updated"
+	WatchEventDeleted = "deleted"
+)
+
+// The resources which could be watched, named after their collections in the
+// api path.
+const (
+	WatchResourceVolumes     = "volumes"
+	WatchResourceAttachments = "attachments"
+	WatchResourceSnapshots   = "snapshots"
+	WatchResourceTransfers   = "transfers"
+)
+
+// WatchResources lists all resources which could be watched.
+var WatchResources = []string{
+	WatchResourceVolumes,
+	WatchResourceAttachments,
+	WatchResourceSnapshots,
+	WatchResourceTransfers,
+}
+
+// IsWatchResource returns true if changes of the resource could be watched.
+func IsWatchResource(resource string) bool {
+	for _, r := range WatchResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchEventSpec is one change of a resource pushed to watchers.
+type WatchEventSpec struct {
+	Type     string `json:"type"`
+	Resource string `json:"resource"`
+	// Object is the resource after the change, or the last state of it if
+	// the resource is deleted.
+	Object json.RawMessage `json:"object,omitempty"`
+}
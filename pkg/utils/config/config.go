@@ -158,7 +158,39 @@ func initConf(confFile string, conf interface{}) {
 	v := reflect.ValueOf(conf)
 	for i := 0; i < t.Elem().NumField(); i++ {
 		field := v.Elem().Field(i)
+		if field.Kind() != reflect.Struct {
+			continue
+		}
 		section := t.Elem().Field(i).Tag.Get("conf")
 		setSectionValue(section, field, cfg)
 	}
+	if c, ok := conf.(*Config); ok {
+		c.Backends = loadBackends(c.EnableBackends, cfg)
+	}
+}
+
+// loadBackends loads the sections of enabled backends, the name and driver
+// name of a backend default to its section name. The backends which aren't
+// configured are ignored.
+func loadBackends(names []string, cfg *ini.File) map[string]BackendProperties {
+	bes := map[string]BackendProperties{}
+	if cfg == nil {
+		return bes
+	}
+	for _, name := range names {
+		if _, err := cfg.GetSection(name); err != nil {
+			log.Warningf("Backend %s is enabled but not configured.", name)
+			continue
+		}
+		var b BackendProperties
+		setSectionValue(name, reflect.ValueOf(&b).Elem(), cfg)
+		if b.Name == "" {
+			b.Name = name
+		}
+		if b.DriverName == "" {
+			b.DriverName = name
+		}
+		bes[name] = b
+	}
+	return bes
 }
@@ -24,31 +24,66 @@ type OsdsLet struct {
 	ApiEndpoint string `conf:"api_endpoint,localhost:50040"`
 	Graceful    bool   `conf:"graceful,true"`
 	SocketOrder string `conf:"socket_order"`
+	// The interval in seconds of running the cleanups, which are run only
+	// on the leader of osdslet replicas.
+	CleanupInterval int `conf:"cleanup_interval,600"`
+	// The time in seconds after which the volume transfers not accepted are
+	// deleted, and they never expire if it's zero.
+	TransferExpiration int `conf:"transfer_expiration,0"`
+	// The interval in seconds of taking the snapshots due by the interval
+	// snapshot policies, which are taken only on the leader of osdslet
+	// replicas. The policies aren't run if it's zero.
+	SnapshotPolicyInterval int `conf:"snapshot_policy_interval,60"`
 }
 
 type OsdsDock struct {
-	ApiEndpoint    string   `conf:"api_endpoint,localhost:50050"`
-	EnableBackends []string `conf:"enabled_backends,ceph"`
-	CinderConfig   string   `conf:"cinder_config,/etc/opensds/driver/cinder.yaml"`
-	CephConfig     string   `conf:"ceph_config,/etc/opensds/driver/ceph.yaml"`
-	LVMConfig      string   `conf:"lvm_config,/etc/opensds/driver/lvm.yaml"`
+	ApiEndpoint     string   `conf:"api_endpoint,localhost:50050"`
+	MetricsEndpoint string   `conf:"metrics_endpoint,localhost:50051"`
+	EnableBackends  []string `conf:"enabled_backends,ceph"`
+	CinderConfig    string   `conf:"cinder_config,/etc/opensds/driver/cinder.yaml"`
+	CephConfig      string   `conf:"ceph_config,/etc/opensds/driver/ceph.yaml"`
+	LVMConfig       string   `conf:"lvm_config,/etc/opensds/driver/lvm.yaml"`
+	// The interval in seconds of reconciling database with backends, and the
+	// reconciliation is disabled if it's zero.
+	ReconcileInterval     int  `conf:"reconcile_interval,600"`
+	ReconcileMarkMissing  bool `conf:"reconcile_mark_missing,false"`
+	ReconcileAdoptOrphans bool `conf:"reconcile_adopt_orphans,false"`
 }
 
 type Database struct {
 	Credential string `conf:"credential,username:password@tcp(ip:port)/dbname"`
 	Driver     string `conf:"driver,etcd"`
 	Endpoint   string `conf:"endpoint,localhost:2379,localhost:2380"`
+	// The hours the events are kept for, 0 keeps them forever.
+	EventRetention int `conf:"event_retention,168"`
 }
 
 type BackendProperties struct {
 	Name        string `conf:"name"`
 	Description string `conf:"description"`
 	DriverName  string `conf:"driver_name"`
+	// The config file of the driver, the one of the driver in osdsdock
+	// section is used if it's empty.
+	ConfigPath string `conf:"config_path"`
+	// The maximum of concurrent requests to the backend, 0 means unlimited.
+	MaxConcurrency int `conf:"max_concurrency,0"`
 }
 
 type Ceph BackendProperties
 type Cinder BackendProperties
-type Sample BackendProperties
+
+// Sample configures the simulated backend of sample driver, whose latency in
+// milliseconds and failures could be injected to every driver call.
+type Sample struct {
+	Name           string   `conf:"name"`
+	Description    string   `conf:"description"`
+	DriverName     string   `conf:"driver_name"`
+	MaxConcurrency int      `conf:"max_concurrency,0"`
+	Latency        int      `conf:"latency,0"`
+	FailureRate    int      `conf:"failure_rate,0"`
+	FailOperations []string `conf:"fail_operations"`
+}
+
 type LVM BackendProperties
 
 type Config struct {
@@ -61,9 +96,13 @@ type Config struct {
 	Sample   `conf:"sample"`
 	LVM      `conf:"lvm"`
 	Flag     FlagSet
+	// Backends are the backends enabled in osdsdock section keyed by their
+	// section names, so several backends of the same driver could be served
+	// by one dock, such as [ceph-ssd] and [ceph-hdd].
+	Backends map[string]BackendProperties
 }
 
-//Create a Config and init default value.
+// Create a Config and init default value.
 func GetDefaultConfig() *Config {
 	var conf *Config = new(Config)
 	initConf("", conf)
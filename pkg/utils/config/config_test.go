@@ -304,3 +304,32 @@ func TestOpensdsConfig(t *testing.T) {
 
 }
 
+
+func TestLoadBackends(t *testing.T) {
+	conf := &Config{}
+	initConf("testdata/opensds.conf", conf)
+
+	if len(conf.Backends) != 5 {
+		t.Errorf("Expected 5 backends, got %v\n", conf.Backends)
+	}
+	if _, ok := conf.Backends["not-exist"]; ok {
+		t.Error("Expected backend not configured ignored")
+	}
+	expected := BackendProperties{
+		Name:           "ceph_ssd",
+		Description:    "Ceph SSD Test",
+		DriverName:     "ceph",
+		ConfigPath:     "/etc/opensds/driver/ceph-ssd.yaml",
+		MaxConcurrency: 4,
+	}
+	if b := conf.Backends["ceph-ssd"]; !reflect.DeepEqual(b, expected) {
+		t.Errorf("Expected %v, got %v\n", expected, b)
+	}
+	expected = BackendProperties{Name: "ceph-hdd", DriverName: "ceph"}
+	if b := conf.Backends["ceph-hdd"]; !reflect.DeepEqual(b, expected) {
+		t.Errorf("Expected %v, got %v\n", expected, b)
+	}
+	if b := conf.Backends["sample"]; b.Name != "sample" || b.DriverName != "sample" {
+		t.Errorf("Expected backend of fixed section loaded, got %v\n", b)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+// SelectItems filters, sorts and paginates the resources according to the
+// list options. The items must be a slice of pointers to resource structs,
+// and the result is a slice of the same type. The items are returned as is
+// if opts is nil.
+func SelectItems(items interface{}, opts *model.ListOptions) (interface{}, error) {
+	if opts == nil {
+		return items, nil
+	}
+
+	itemsValue := reflect.ValueOf(items)
+	if itemsValue.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Expected a slice of resources, got %T", items)
+	}
+	if opts.Limit < 0 || opts.Offset < 0 {
+		return nil, fmt.Errorf("Limit and offset could not be negative")
+	}
+	var desc bool
+	switch strings.ToLower(opts.SortDir) {
+	case "", model.SortDirAsc:
+	case model.SortDirDesc:
+		desc = true
+	default:
+		return nil, fmt.Errorf("Invalid sort direction %s, should be %s or %s",
+			opts.SortDir, model.SortDirAsc, model.SortDirDesc)
+	}
+	sortKey := opts.SortKey
+	if sortKey == "" {
+		sortKey = model.DefaultSortKey
+	}
+
+	// Filter the items.
+	var selected []reflect.Value
+	for i := 0; i < itemsValue.Len(); i++ {
+		item := itemsValue.Index(i)
+		ok, err := matchItem(item, opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, item)
+		}
+	}
+
+	// Sort the items, the ones with the same key are ordered by id so that
+	// the pages are stable.
+	var sortErr error
+	sort.SliceStable(selected, func(i, j int) bool {
+		less, err := lessItem(selected[i], selected[j], sortKey)
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		if less == 0 {
+			less = strings.Compare(itemId(selected[i]), itemId(selected[j]))
+		}
+		if desc {
+			return less > 0
+		}
+		return less < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	// Paginate the items.
+	start := 0
+	if opts.Marker != "" {
+		start = -1
+		for i, item := range selected {
+			if itemId(item) == opts.Marker {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("Marker %s could not be found", opts.Marker)
+		}
+	}
+	start += opts.Offset
+	if start > len(selected) {
+		start = len(selected)
+	}
+	end := len(selected)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+
+	result := reflect.MakeSlice(itemsValue.Type(), 0, end-start)
+	for _, item := range selected[start:end] {
+		result = reflect.Append(result, item)
+	}
+	return result.Interface(), nil
+}
+
+func matchItem(item reflect.Value, opts *model.ListOptions) (bool, error) {
+	for key, value := range opts.Filters {
+		field, ok := fieldByJSONName(item, key)
+		if !ok {
+			return false, fmt.Errorf("Filter key %s is not supported", key)
+		}
+		if !field.IsValid() || fmt.Sprint(field.Interface()) != value {
+			return false, nil
+		}
+	}
+
+	if opts.CreatedSince != "" || opts.CreatedUntil != "" {
+		field, ok := fieldByJSONName(item, "createdAt")
+		if !ok {
+			return false, fmt.Errorf("Filtering by created time is not supported")
+		}
+		// The time strings share the same layout, so comparing them
+		// lexically is the same as comparing the time they stand for.
+		if !field.IsValid() {
+			return false, nil
+		}
+		created := field.String()
+		if opts.CreatedSince != "" && created < opts.CreatedSince {
+			return false, nil
+		}
+		if opts.CreatedUntil != "" && created > opts.CreatedUntil {
+			return false, nil
+		}
+	}
+
+	if len(opts.Metadata) == 0 {
+		return true, nil
+	}
+	field, ok := fieldByJSONName(item, "metadata")
+	if !ok {
+		return false, fmt.Errorf("Filtering by metadata is not supported")
+	}
+	if !field.IsValid() {
+		return false, nil
+	}
+	meta, ok := field.Interface().(map[string]string)
+	if !ok {
+		return false, fmt.Errorf("Filtering by metadata is not supported")
+	}
+	for k, v := range opts.Metadata {
+		if mv, ok := meta[k]; !ok || mv != v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// lessItem compares the field of two items, it returns -1, 0 or 1 like
+// strings.Compare does.
+func lessItem(a, b reflect.Value, key string) (int, error) {
+	fa, ok := fieldByJSONName(a, key)
+	if !ok {
+		return 0, fmt.Errorf("Sort key %s is not supported", key)
+	}
+	fb, _ := fieldByJSONName(b, key)
+	// The items which don't have the field are put in the front.
+	switch {
+	case !fa.IsValid() && !fb.IsValid():
+		return 0, nil
+	case !fa.IsValid():
+		return -1, nil
+	case !fb.IsValid():
+		return 1, nil
+	}
+
+	switch fa.Kind() {
+	case reflect.String:
+		return strings.Compare(fa.String(), fb.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareFloat(float64(fa.Int()), float64(fb.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloat(float64(fa.Uint()), float64(fb.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(fa.Float(), fb.Float()), nil
+	case reflect.Bool:
+		return compareFloat(boolToFloat(fa.Bool()), boolToFloat(fb.Bool())), nil
+	}
+	return 0, fmt.Errorf("Sort key %s is not supported", key)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func itemId(item reflect.Value) string {
+	if m, ok := item.Interface().(model.Modeler); ok && !item.IsNil() {
+		return m.GetId()
+	}
+	return ""
+}
+
+// fieldByJSONName looks up the field of the struct by the name of its json
+// tag, the fields of embedded structs are looked up as well. The second value
+// reports whether the struct has the field, and the returned field is
+// invalid if it's in a nil embedded struct.
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fieldByJSONNameOfType(v.Type().Elem(), name)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		if jsonName(sf) == name {
+			return v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.Anonymous && jsonName(sf) == "" {
+			if f, ok := fieldByJSONName(v.Field(i), name); ok {
+				return f, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func fieldByJSONNameOfType(t reflect.Type, name string) (reflect.Value, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if jsonName(sf) == name {
+			return reflect.Value{}, true
+		}
+		if sf.Anonymous && jsonName(sf) == "" {
+			if _, ok := fieldByJSONNameOfType(sf.Type, name); ok {
+				return reflect.Value{}, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonName returns the name of the field in json tag, it's empty for the
+// embedded structs which are not tagged.
+func jsonName(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name := strings.TrimSpace(strings.Split(tag, ",")[0])
+	if name == "" && !sf.Anonymous {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opensds/opensds/pkg/model"
+)
+
+var sampleListVolumes = []*model.VolumeSpec{
+	{
+		BaseModel: &model.BaseModel{Id: "vol-1", CreatedAt: "2017-10-24T16:21:32"},
+		Name:      "sample-volume-1",
+		Size:      3,
+		Status:    "available",
+		PoolId:    "pool-1",
+		Metadata:  map[string]string{"tier": "gold"},
+	},
+	{
+		BaseModel: &model.BaseModel{Id: "vol-2", CreatedAt: "2017-10-23T16:21:32"},
+		Name:      "sample-volume-2",
+		Size:      1,
+		Status:    "error",
+		PoolId:    "pool-1",
+	},
+	{
+		BaseModel: &model.BaseModel{Id: "vol-3", CreatedAt: "2017-10-25T16:21:32"},
+		Name:      "sample-volume-3",
+		Size:      2,
+		Status:    "available",
+		PoolId:    "pool-2",
+		Metadata:  map[string]string{"tier": "gold", "zone": "default"},
+	},
+}
+
+func volumeIds(items interface{}) []string {
+	var ids []string
+	for _, vol := range items.([]*model.VolumeSpec) {
+		ids = append(ids, vol.Id)
+	}
+	return ids
+}
+
+func TestSelectItems(t *testing.T) {
+	testCases := []struct {
+		opts     *model.ListOptions
+		expected []string
+	}{
+		{nil, []string{"vol-1", "vol-2", "vol-3"}},
+		{&model.ListOptions{}, []string{"vol-2", "vol-1", "vol-3"}},
+		{&model.ListOptions{SortKey: "size", SortDir: "desc"}, []string{"vol-1", "vol-3", "vol-2"}},
+		{&model.ListOptions{SortKey: "name", Limit: 2}, []string{"vol-1", "vol-2"}},
+		{&model.ListOptions{SortKey: "name", Marker: "vol-1", Limit: 1}, []string{"vol-2"}},
+		{&model.ListOptions{SortKey: "name", Offset: 2}, []string{"vol-3"}},
+		{&model.ListOptions{Offset: 5}, nil},
+		{&model.ListOptions{Filters: map[string]string{"status": "available"}}, []string{"vol-1", "vol-3"}},
+		{&model.ListOptions{Filters: map[string]string{"status": "available", "poolId": "pool-2"}}, []string{"vol-3"}},
+		{&model.ListOptions{Filters: map[string]string{"id": "vol-2"}}, []string{"vol-2"}},
+		{&model.ListOptions{Metadata: map[string]string{"tier": "gold"}}, []string{"vol-1", "vol-3"}},
+		{&model.ListOptions{Metadata: map[string]string{"tier": "gold", "zone": "default"}}, []string{"vol-3"}},
+		{&model.ListOptions{CreatedSince: "2017-10-24T00:00:00"}, []string{"vol-1", "vol-3"}},
+		{&model.ListOptions{CreatedSince: "2017-10-24T00:00:00", CreatedUntil: "2017-10-25T00:00:00"}, []string{"vol-1"}},
+	}
+
+	for i, tc := range testCases {
+		items, err := SelectItems(sampleListVolumes, tc.opts)
+		if err != nil {
+			t.Errorf("Case %d: %v\n", i, err)
+			continue
+		}
+		ids := volumeIds(items)
+		if !reflect.DeepEqual(ids, tc.expected) {
+			t.Errorf("Case %d: expected %v, got %v\n", i, tc.expected, ids)
+		}
+	}
+}
+
+func TestSelectItemsWithInvalidOptions(t *testing.T) {
+	for i, opts := range []*model.ListOptions{
+		{Limit: -1},
+		{SortDir: "up"},
+		{SortKey: "metadata"},
+		{SortKey: "unknown"},
+		{Marker: "vol-4"},
+		{Filters: map[string]string{"unknown": "value"}},
+	} {
+		if _, err := SelectItems(sampleListVolumes, opts); err == nil {
+			t.Errorf("Case %d: expected error with options %+v\n", i, opts)
+		}
+	}
+
+	prfs := []*model.ProfileSpec{{BaseModel: &model.BaseModel{Id: "prf-1"}}}
+	opts := &model.ListOptions{Metadata: map[string]string{"tier": "gold"}}
+	if _, err := SelectItems(prfs, opts); err == nil {
+		t.Error("Expected error when filtering profiles by metadata")
+	}
+}
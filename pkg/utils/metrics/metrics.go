@@ -0,0 +1,429 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+/*
+This module implements a lightweight metrics registry which exposes metrics
+in the prometheus text exposition format (version 0.0.4), so that both
+osdslet and osdsdock could be scraped by prometheus server.
+
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// ContentType is the content type of prometheus text format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// DefBuckets are the default histogram buckets in seconds, which are suitable
+// for the latencies of api requests and driver calls.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Collector is a family of metrics which could be registered and exposed.
+type Collector interface {
+	// Name returns the metric name of the family.
+	Name() string
+	// Write writes the family in prometheus text format.
+	Write(w io.Writer) error
+}
+
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) Name() string {
+	return d.name
+}
+
+func (d *desc) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.typ)
+	return err
+}
+
+// labelString formats the label pairs, extra pairs such as le of histogram
+// buckets are appended to the end.
+func (d *desc) labelString(lvs []string, extra ...string) string {
+	if len(d.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var pairs []string
+	for i, l := range d.labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", l, escapeLabelValue(lvs[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extra[i], escapeLabelValue(extra[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (d *desc) checkLabels(lvs []string) {
+	if len(lvs) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(lvs)))
+	}
+}
+
+// series stores the values of a metric family keyed by the label values.
+type series struct {
+	sync.Mutex
+	values map[string]interface{}
+	lvs    map[string][]string
+}
+
+func newSeries() *series {
+	return &series{
+		values: make(map[string]interface{}),
+		lvs:    make(map[string][]string),
+	}
+}
+
+func seriesKey(lvs []string) string {
+	return strings.Join(lvs, "\xff")
+}
+
+// sortedKeys should be called with the lock held.
+func (s *series) sortedKeys() []string {
+	var keys []string
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a family of counters partitioned by label values.
+type CounterVec struct {
+	desc
+	*series
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		desc:   desc{name: name, help: help, typ: typeCounter, labels: labels},
+		series: newSeries(),
+	}
+}
+
+func (c *CounterVec) Inc(lvs ...string) {
+	c.Add(1, lvs...)
+}
+
+// Add adds the non-negative value to the counter.
+func (c *CounterVec) Add(v float64, lvs ...string) {
+	c.checkLabels(lvs)
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can't decrease", c.name))
+	}
+	c.Lock()
+	defer c.Unlock()
+	k := seriesKey(lvs)
+	cur, _ := c.values[k].(float64)
+	c.values[k], c.lvs[k] = cur+v, lvs
+}
+
+func (c *CounterVec) Write(w io.Writer) error {
+	return writeScalars(w, &c.desc, c.series)
+}
+
+// GaugeVec is a family of gauges partitioned by label values.
+type GaugeVec struct {
+	desc
+	*series
+}
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{
+		desc:   desc{name: name, help: help, typ: typeGauge, labels: labels},
+		series: newSeries(),
+	}
+}
+
+func (g *GaugeVec) Set(v float64, lvs ...string) {
+	g.checkLabels(lvs)
+	g.Lock()
+	defer g.Unlock()
+	k := seriesKey(lvs)
+	g.values[k], g.lvs[k] = v, lvs
+}
+
+func (g *GaugeVec) Add(v float64, lvs ...string) {
+	g.checkLabels(lvs)
+	g.Lock()
+	defer g.Unlock()
+	k := seriesKey(lvs)
+	cur, _ := g.values[k].(float64)
+	g.values[k], g.lvs[k] = cur+v, lvs
+}
+
+func (g *GaugeVec) Inc(lvs ...string) {
+	g.Add(1, lvs...)
+}
+
+func (g *GaugeVec) Dec(lvs ...string) {
+	g.Add(-1, lvs...)
+}
+
+// Value returns the current value of the gauge, which is mainly used for
+// testing.
+func (g *GaugeVec) Value(lvs ...string) float64 {
+	g.checkLabels(lvs)
+	g.Lock()
+	defer g.Unlock()
+	v, _ := g.values[seriesKey(lvs)].(float64)
+	return v
+}
+
+func (g *GaugeVec) Write(w io.Writer) error {
+	return writeScalars(w, &g.desc, g.series)
+}
+
+func writeScalars(w io.Writer, d *desc, s *series) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := d.writeHeader(w); err != nil {
+		return err
+	}
+	for _, k := range s.sortedKeys() {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", d.name, d.labelString(s.lvs[k]),
+			formatValue(s.values[k].(float64))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sample is a value of a gauge collected by GaugeFunc.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a family of gauges whose values are collected by calling the
+// function when scraping, such as the statistics loaded from database.
+type GaugeFunc struct {
+	desc
+	collect func() ([]Sample, error)
+}
+
+func NewGaugeFunc(name, help string, labels []string, collect func() ([]Sample, error)) *GaugeFunc {
+	return &GaugeFunc{
+		desc:    desc{name: name, help: help, typ: typeGauge, labels: labels},
+		collect: collect,
+	}
+}
+
+func (g *GaugeFunc) Write(w io.Writer) error {
+	samples, err := g.collect()
+	if err != nil {
+		return fmt.Errorf("collect %s failed: %v", g.name, err)
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return seriesKey(samples[i].LabelValues) < seriesKey(samples[j].LabelValues)
+	})
+	if err := g.writeHeader(w); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		g.checkLabels(s.LabelValues)
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", g.name, g.labelString(s.LabelValues),
+			formatValue(s.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type histogramValue struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// HistogramVec is a family of histograms partitioned by label values.
+type HistogramVec struct {
+	desc
+	*series
+	buckets []float64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	bs := append([]float64{}, buckets...)
+	sort.Float64s(bs)
+	return &HistogramVec{
+		desc:    desc{name: name, help: help, typ: typeHistogram, labels: labels},
+		series:  newSeries(),
+		buckets: bs,
+	}
+}
+
+func (h *HistogramVec) Observe(v float64, lvs ...string) {
+	h.checkLabels(lvs)
+	h.Lock()
+	defer h.Unlock()
+	k := seriesKey(lvs)
+	hv, ok := h.values[k].(*histogramValue)
+	if !ok {
+		hv = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[k], h.lvs[k] = hv, lvs
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			hv.counts[i]++
+		}
+	}
+	hv.sum += v
+	hv.count++
+}
+
+func (h *HistogramVec) Write(w io.Writer) error {
+	h.Lock()
+	defer h.Unlock()
+	if err := h.writeHeader(w); err != nil {
+		return err
+	}
+	for _, k := range h.sortedKeys() {
+		hv, lvs := h.values[k].(*histogramValue), h.lvs[k]
+		for i, b := range h.buckets {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+				h.labelString(lvs, "le", formatValue(b)), hv.counts[i]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, h.labelString(lvs, "le", "+Inf"), hv.count,
+			h.name, h.labelString(lvs), formatValue(hv.sum),
+			h.name, h.labelString(lvs), hv.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Registry holds the registered collectors and exposes them.
+type Registry struct {
+	sync.RWMutex
+	collectors map[string]Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+func (r *Registry) Register(c Collector) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.collectors[c.Name()]; ok {
+		return fmt.Errorf("metrics: %s is already registered", c.Name())
+	}
+	r.collectors[c.Name()] = c
+	return nil
+}
+
+func (r *Registry) MustRegister(cs ...Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Gather writes all the registered metric families sorted by name.
+func (r *Registry) Gather(w io.Writer) error {
+	r.RLock()
+	var names []string
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	var cs []Collector
+	sort.Strings(names)
+	for _, name := range names {
+		cs = append(cs, r.collectors[name])
+	}
+	r.RUnlock()
+
+	for _, c := range cs {
+		if err := c.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns the http handler for prometheus to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var buf bytes.Buffer
+		if err := r.Gather(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+// DefaultRegistry is the registry exposed by the daemons, and the metrics
+// defined by each module are registered into it when initializing.
+var DefaultRegistry = NewRegistry()
+
+func MustRegister(cs ...Collector) {
+	DefaultRegistry.MustRegister(cs...)
+}
+
+func Handler() http.Handler {
+	return DefaultRegistry.Handler()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
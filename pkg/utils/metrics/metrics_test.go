@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Huawei Technologies Co., Ltd. All Rights Reserved.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License"); you may
+//    not use this file except in compliance with the License. You may obtain
+//    a copy of the License at
+//
+//         http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+//    WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+//    License for the specific language governing permissions and limitations
+//    under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryGather(t *testing.T) {
+	r := NewRegistry()
+	reqs := NewCounterVec("test_requests_total", "Total requests.", "route", "code")
+	depth := NewGaugeVec("test_queue_depth", "Queue depth.")
+	r.MustRegister(reqs, depth)
+
+	reqs.Inc("/volumes", "200")
+	reqs.Add(2, "/volumes", "200")
+	reqs.Inc("/docks", "404")
+	depth.Inc()
+	depth.Inc()
+	depth.Dec()
+
+	var buf bytes.Buffer
+	if err := r.Gather(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_queue_depth Queue depth.
+# TYPE test_queue_depth gauge
+test_queue_depth 1
+# HELP test_requests_total Total requests.
+# TYPE test_requests_total counter
+test_requests_total{route="/docks",code="404"} 1
+test_requests_total{route="/volumes",code="200"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q\n", expected, buf.String())
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := NewHistogramVec("test_duration_seconds", "Duration.", []float64{1, 0.1}, "op")
+	r.MustRegister(h)
+
+	h.Observe(0.05, "create")
+	h.Observe(0.5, "create")
+	h.Observe(3, "create")
+
+	var buf bytes.Buffer
+	if err := r.Gather(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{op="create",le="0.1"} 1
+test_duration_seconds_bucket{op="create",le="1"} 2
+test_duration_seconds_bucket{op="create",le="+Inf"} 3
+test_duration_seconds_sum{op="create"} 3.55
+test_duration_seconds_count{op="create"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q\n", expected, buf.String())
+	}
+}
+
+func TestGaugeFuncAndHandler(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(NewGaugeFunc("test_volumes", "Volumes by status.", []string{"status"},
+		func() ([]Sample, error) {
+			return []Sample{
+				{LabelValues: []string{"error"}, Value: 1},
+				{LabelValues: []string{"available \"ok\""}, Value: 2},
+			}, nil
+		}))
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != 200 {
+		t.Fatalf("Expected status 200, got %d\n", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Expected content type %s, got %s\n", ContentType, ct)
+	}
+	body, _ := ioutil.ReadAll(w.Body)
+	expected := `# HELP test_volumes Volumes by status.
+# TYPE test_volumes gauge
+test_volumes{status="available \"ok\""} 2
+test_volumes{status="error"} 1
+`
+	if string(body) != expected {
+		t.Errorf("Expected %q, got %q\n", expected, string(body))
+	}
+}
+
+func TestRegisterDuplicated(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(NewGaugeVec("test_dup", "Dup.")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(NewCounterVec("test_dup", "Dup.")); err == nil {
+		t.Error("Expected error when registering duplicated metric")
+	}
+}
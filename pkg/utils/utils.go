@@ -17,8 +17,10 @@ package utils
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"time"
 
 	log "github.com/golang/glog"
@@ -46,13 +48,15 @@ func NewSetter() Setter {
 func (s *setter) SetUuid(m model.Modeler) error {
 	switch m.(type) {
 	case model.VolumeSpec, model.VolumeSnapshotSpec, model.VolumeAttachmentSpec,
-		model.ProfileSpec, model.DockSpec, model.StoragePoolSpec:
+		model.ProfileSpec, model.DockSpec, model.StoragePoolSpec, model.EventSpec, model.VolumeTransferSpec,
+		model.IdempotencyKeySpec:
 		// Set uuid.
 		m.SetId(uuid.NewV4().String())
 
 		return nil
 	case *model.VolumeSpec, *model.VolumeSnapshotSpec, *model.VolumeAttachmentSpec,
-		*model.ProfileSpec, *model.DockSpec, *model.StoragePoolSpec:
+		*model.ProfileSpec, *model.DockSpec, *model.StoragePoolSpec, *model.EventSpec, *model.VolumeTransferSpec,
+		*model.IdempotencyKeySpec:
 		// Set uuid.
 		m.SetId(uuid.NewV4().String())
 
@@ -65,13 +69,15 @@ func (s *setter) SetUuid(m model.Modeler) error {
 func (s *setter) SetCreatedTimeStamp(m model.Modeler) error {
 	switch m.(type) {
 	case model.VolumeSpec, model.VolumeSnapshotSpec, model.VolumeAttachmentSpec,
-		model.ProfileSpec, model.DockSpec, model.StoragePoolSpec:
+		model.ProfileSpec, model.DockSpec, model.StoragePoolSpec, model.EventSpec, model.VolumeTransferSpec,
+		model.IdempotencyKeySpec:
 		// Set created time.
 		m.SetCreatedTime(time.Now().Format(TimeFormat))
 
 		return nil
 	case *model.VolumeSpec, *model.VolumeSnapshotSpec, *model.VolumeAttachmentSpec,
-		*model.ProfileSpec, *model.DockSpec, *model.StoragePoolSpec:
+		*model.ProfileSpec, *model.DockSpec, *model.StoragePoolSpec, *model.EventSpec, *model.VolumeTransferSpec,
+		*model.IdempotencyKeySpec:
 		// Set created time.
 		m.SetCreatedTime(time.Now().Format(TimeFormat))
 
@@ -84,13 +90,15 @@ func (s *setter) SetCreatedTimeStamp(m model.Modeler) error {
 func (s *setter) SetUpdatedTimeStamp(m model.Modeler) error {
 	switch m.(type) {
 	case model.VolumeSpec, model.VolumeSnapshotSpec, model.VolumeAttachmentSpec,
-		model.ProfileSpec, model.DockSpec, model.StoragePoolSpec:
+		model.ProfileSpec, model.DockSpec, model.StoragePoolSpec, model.EventSpec, model.VolumeTransferSpec,
+		model.IdempotencyKeySpec:
 		// Set updated time.
 		m.SetUpdatedTime(time.Now().Format(TimeFormat))
 
 		return nil
 	case *model.VolumeSpec, *model.VolumeSnapshotSpec, *model.VolumeAttachmentSpec,
-		*model.ProfileSpec, *model.DockSpec, *model.StoragePoolSpec:
+		*model.ProfileSpec, *model.DockSpec, *model.StoragePoolSpec, *model.EventSpec, *model.VolumeTransferSpec,
+		*model.IdempotencyKeySpec:
 		// Set updated time.
 		m.SetUpdatedTime(time.Now().Format(TimeFormat))
 
@@ -100,6 +108,42 @@ func (s *setter) SetUpdatedTimeStamp(m model.Modeler) error {
 	}
 }
 
+// ErrConflict is returned by db module if the resource has been modified by
+// others since the version the caller read.
+var ErrConflict = errors.New("The resource has been modified by others, please get the latest version and retry")
+
+// NotFoundError is returned by volume drivers if the resource doesn't exist
+// in the backend.
+type NotFoundError string
+
+func (e NotFoundError) Error() string { return string(e) }
+
+// IsNotFound returns whether the error is a NotFoundError.
+func IsNotFound(err error) bool {
+	_, ok := err.(NotFoundError)
+	return ok
+}
+
+// AlreadyExistsError is returned by volume drivers if the resource to create
+// already exists in the backend.
+type AlreadyExistsError string
+
+func (e AlreadyExistsError) Error() string { return string(e) }
+
+// IsAlreadyExists returns whether the error is an AlreadyExistsError.
+func IsAlreadyExists(err error) bool {
+	_, ok := err.(AlreadyExistsError)
+	return ok
+}
+
+// MultiattachError is returned by db module if the volume which doesn't
+// support multiattach is attached once more, the value is the volume id.
+type MultiattachError string
+
+func (e MultiattachError) Error() string {
+	return "Volume " + string(e) + " has been attached and doesn't support multiattach"
+}
+
 type ErrorRes struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -160,3 +204,26 @@ func Contained(obj, target interface{}) bool {
 	}
 	return false
 }
+
+// ToInt64 converts a numeric value, which may be decoded from json, yaml or
+// be a plain string, into int64.
+func ToInt64(v interface{}) (int64, error) {
+	switch v.(type) {
+	case int:
+		return int64(v.(int)), nil
+	case int32:
+		return int64(v.(int32)), nil
+	case int64:
+		return v.(int64), nil
+	case float32:
+		return int64(v.(float32)), nil
+	case float64:
+		return int64(v.(float64)), nil
+	case json.Number:
+		return v.(json.Number).Int64()
+	case string:
+		return strconv.ParseInt(v.(string), 10, 64)
+	default:
+		return 0, fmt.Errorf("Unexpected numeric value %v of type %T", v, v)
+	}
+}
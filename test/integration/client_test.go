@@ -173,6 +173,10 @@ func TestClientListPools(t *testing.T) {
 	t.Log(string(polsBody))
 }
 
+// The volume tests below run as a lifecycle in order: the attachment and
+// snapshot are made of the created volume, and the volume is deleted last.
+var volID, atcID, snpID string
+
 func TestClientCreateVolume(t *testing.T) {
 	var body = &model.VolumeSpec{
 		Name:        "test",
@@ -185,14 +189,13 @@ func TestClientCreateVolume(t *testing.T) {
 		t.Error("create volume in client failed:", err)
 		return
 	}
+	volID = vol.Id
 
 	volBody, _ := json.MarshalIndent(vol, "", "	")
 	t.Log(string(volBody))
 }
 
 func TestClientGetVolume(t *testing.T) {
-	var volID = "bd5b12a8-a101-11e7-941e-d77981b584d8"
-
 	vol, err := c.GetVolume(volID)
 	if err != nil {
 		t.Error("get volume in client failed:", err)
@@ -214,24 +217,12 @@ func TestClientListVolumes(t *testing.T) {
 	t.Log(string(volsBody))
 }
 
-func TestClientDeleteVolume(t *testing.T) {
-	var volID = "bd5b12a8-a101-11e7-941e-d77981b584d8"
-	body := &model.VolumeSpec{}
-
-	if err := c.DeleteVolume(volID, body); err != nil {
-		t.Error("delete volume in client failed:", err)
-		return
-	}
-
-	t.Log("Delete volume success!")
-}
-
 func TestClientCreateVolumeAttachment(t *testing.T) {
 	var body = &model.VolumeAttachmentSpec{
 		Name:        "test",
 		Description: "This is a test",
-		VolumeId:    "bd5b12a8-a101-11e7-941e-d77981b584d8",
-		HostInfo:    &model.HostInfo{},
+		VolumeId:    volID,
+		HostInfo:    &model.HostInfo{Host: "localhost"},
 	}
 
 	atc, err := c.CreateVolumeAttachment(body)
@@ -239,15 +230,14 @@ func TestClientCreateVolumeAttachment(t *testing.T) {
 		t.Error("create volume attachment in client failed:", err)
 		return
 	}
+	atcID = atc.Id
 
 	atcBody, _ := json.MarshalIndent(atc, "", "	")
 	t.Log(string(atcBody))
 }
 
 func TestClientGetVolumeAttachment(t *testing.T) {
-	var atcID = "f2dda3d2-bf79-11e7-8665-f750b088f63e"
-
-	atc, err := c.GetVolumeAttachment(atcID)
+	atc, err := c.GetVolumeAttachment(volID, atcID)
 	if err != nil {
 		t.Error("get volume attachment in client failed:", err)
 		return
@@ -269,10 +259,9 @@ func TestClientListVolumeAttachments(t *testing.T) {
 }
 
 func TestClientDeleteVolumeAttachment(t *testing.T) {
-	var atcID = "f2dda3d2-bf79-11e7-8665-f750b088f63e"
 	body := &model.VolumeAttachmentSpec{
-		VolumeId: "bd5b12a8-a101-11e7-941e-d77981b584d8",
-		HostInfo: &model.HostInfo{},
+		VolumeId: volID,
+		HostInfo: &model.HostInfo{Host: "localhost"},
 	}
 
 	if err := c.DeleteVolumeAttachment(atcID, body); err != nil {
@@ -287,7 +276,7 @@ func TestClientCreateVolumeSnapshot(t *testing.T) {
 	var body = &model.VolumeSnapshotSpec{
 		Name:        "test",
 		Description: "This is a test",
-		VolumeId:    "bd5b12a8-a101-11e7-941e-d77981b584d8",
+		VolumeId:    volID,
 	}
 
 	snp, err := c.CreateVolumeSnapshot(body)
@@ -295,14 +284,13 @@ func TestClientCreateVolumeSnapshot(t *testing.T) {
 		t.Error("create volume snapshot in client failed:", err)
 		return
 	}
+	snpID = snp.Id
 
 	snpBody, _ := json.MarshalIndent(snp, "", "	")
 	t.Log(string(snpBody))
 }
 
 func TestClientGetVolumeSnapshot(t *testing.T) {
-	var snpID = "3769855c-a102-11e7-b772-17b880d2f537"
-
 	snp, err := c.GetVolumeSnapshot(snpID)
 	if err != nil {
 		t.Error("get volume snapshot in client failed:", err)
@@ -325,9 +313,8 @@ func TestClientListVolumeSnapshots(t *testing.T) {
 }
 
 func TestClientDeleteVolumeSnapshot(t *testing.T) {
-	var snpID = "3769855c-a102-11e7-b772-17b880d2f537"
 	body := &model.VolumeSnapshotSpec{
-		VolumeId: "bd5b12a8-a101-11e7-941e-d77981b584d8",
+		VolumeId: volID,
 	}
 
 	if err := c.DeleteVolumeSnapshot(snpID, body); err != nil {
@@ -337,3 +324,14 @@ func TestClientDeleteVolumeSnapshot(t *testing.T) {
 
 	t.Log("Delete volume snapshot success!")
 }
+
+func TestClientDeleteVolume(t *testing.T) {
+	body := &model.VolumeSpec{}
+
+	if err := c.DeleteVolume(volID, body); err != nil {
+		t.Error("delete volume in client failed:", err)
+		return
+	}
+
+	t.Log("Delete volume success!")
+}
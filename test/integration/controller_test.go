@@ -23,6 +23,7 @@ import (
 	"github.com/opensds/opensds/pkg/controller/volume"
 	pb "github.com/opensds/opensds/pkg/dock/proto"
 	"github.com/opensds/opensds/pkg/model"
+	"golang.org/x/net/context"
 )
 
 var vc = volume.NewController()
@@ -32,36 +33,31 @@ var dckInfo = &model.DockSpec{
 	DriverName: "default",
 }
 
+// The tests below run as a lifecycle in order, and the volume created first
+// is deleted last.
+var ctrlVolID, ctrlSnpID string
+
 func TestControllerCreateVolume(t *testing.T) {
 	vc.SetDock(dckInfo)
 
-	vol, err := vc.CreateVolume(&pb.CreateVolumeOpts{})
+	vol, err := vc.CreateVolume(context.Background(), &pb.CreateVolumeOpts{Name: "test", Size: 1})
 	if err != nil {
 		t.Error("create volume in controller failed:", err)
 		return
 	}
+	ctrlVolID = vol.Id
 
 	volBody, _ := json.MarshalIndent(vol, "", "	")
 	t.Log(string(volBody))
 }
 
-func TestControllerDeleteVolume(t *testing.T) {
-	vc.SetDock(dckInfo)
-
-	res := vc.DeleteVolume(&pb.DeleteVolumeOpts{})
-	if err := res.ToError(); err != nil {
-		t.Error("delete volume in controller failed:", err)
-		return
-	}
-
-	resBody, _ := json.MarshalIndent(res, "", "	")
-	t.Log(string(resBody))
-}
-
 func TestControllerCreateVolumeAttachment(t *testing.T) {
 	vc.SetDock(dckInfo)
 
-	atc, err := vc.CreateVolumeAttachment(&pb.CreateAttachmentOpts{})
+	atc, err := vc.CreateVolumeAttachment(context.Background(), &pb.CreateAttachmentOpts{
+		VolumeId: ctrlVolID,
+		HostInfo: &pb.HostInfo{Host: "localhost"},
+	})
 	if err != nil {
 		t.Error("create volume attachment in controller failed:", err)
 		return
@@ -74,7 +70,10 @@ func TestControllerCreateVolumeAttachment(t *testing.T) {
 func TestControllerDeleteVolumeAttachment(t *testing.T) {
 	vc.SetDock(dckInfo)
 
-	res := vc.DeleteVolumeAttachment(&pb.DeleteAttachmentOpts{})
+	res := vc.DeleteVolumeAttachment(context.Background(), &pb.DeleteAttachmentOpts{
+		VolumeId: ctrlVolID,
+		HostInfo: &pb.HostInfo{Host: "localhost"},
+	})
 	if err := res.ToError(); err != nil {
 		t.Error("delete volume attachment in controller failed:", err)
 		return
@@ -87,11 +86,12 @@ func TestControllerDeleteVolumeAttachment(t *testing.T) {
 func TestControllerCreateVolumeSnapshot(t *testing.T) {
 	vc.SetDock(dckInfo)
 
-	snp, err := vc.CreateVolumeSnapshot(&pb.CreateVolumeSnapshotOpts{})
+	snp, err := vc.CreateVolumeSnapshot(context.Background(), &pb.CreateVolumeSnapshotOpts{Name: "test", VolumeId: ctrlVolID})
 	if err != nil {
 		t.Error("create volume snapshot in controller failed:", err)
 		return
 	}
+	ctrlSnpID = snp.Id
 
 	snpBody, _ := json.MarshalIndent(snp, "", "	")
 	t.Log(string(snpBody))
@@ -100,7 +100,7 @@ func TestControllerCreateVolumeSnapshot(t *testing.T) {
 func TestControllerDeleteVolumeSnapshot(t *testing.T) {
 	vc.SetDock(dckInfo)
 
-	res := vc.DeleteVolumeSnapshot(&pb.DeleteVolumeSnapshotOpts{})
+	res := vc.DeleteVolumeSnapshot(context.Background(), &pb.DeleteVolumeSnapshotOpts{Id: ctrlSnpID, VolumeId: ctrlVolID})
 	if err := res.ToError(); err != nil {
 		t.Error("delete volume snapshot in controller failed:", err)
 		return
@@ -109,3 +109,16 @@ func TestControllerDeleteVolumeSnapshot(t *testing.T) {
 	resBody, _ := json.MarshalIndent(res, "", "	")
 	t.Log(string(resBody))
 }
+
+func TestControllerDeleteVolume(t *testing.T) {
+	vc.SetDock(dckInfo)
+
+	res := vc.DeleteVolume(context.Background(), &pb.DeleteVolumeOpts{Id: ctrlVolID})
+	if err := res.ToError(); err != nil {
+		t.Error("delete volume in controller failed:", err)
+		return
+	}
+
+	resBody, _ := json.MarshalIndent(res, "", "	")
+	t.Log(string(resBody))
+}